@@ -0,0 +1,291 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// ErrAuditLoggerClosed is returned when logging to an AsyncAuditLogger after Close
+var ErrAuditLoggerClosed = errors.New("audit logger is closed")
+
+// ErrAuditQueueTimeout is returned by LogEvent under OverflowBlockWithTimeout when the queue
+// stays full for longer than the block timeout
+var ErrAuditQueueTimeout = errors.New("audit queue full: timed out waiting for space")
+
+// DefaultOverflowTimeout is how long OverflowBlockWithTimeout waits when no timeout is set
+const DefaultOverflowTimeout = time.Second
+
+// OverflowPolicy decides what LogEvent does when the queue is full.
+//
+// Blocking policies never lose an event, but a stalled sink stalls every caller; that is the
+// right tradeoff for compliance workloads. Dropping policies keep callers fast at the cost of
+// losing events under sustained overload, which suits best-effort telemetry. Every drop is
+// counted (see Dropped) and logged.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for queue space for as long as it takes
+	OverflowBlock OverflowPolicy = config.AuditOverflowBlock
+	// OverflowDropNewest discards the event being logged, keeping the queued ones
+	OverflowDropNewest OverflowPolicy = config.AuditOverflowDropNewest
+	// OverflowDropOldest discards the oldest queued event to make room, favouring recent events
+	OverflowDropOldest OverflowPolicy = config.AuditOverflowDropOldest
+	// OverflowBlockWithTimeout waits up to OverflowTimeout, then fails with ErrAuditQueueTimeout
+	OverflowBlockWithTimeout OverflowPolicy = config.AuditOverflowBlockWithTimeout
+)
+
+// AsyncAuditLoggerOptions configures an AsyncAuditLogger
+type AsyncAuditLoggerOptions struct {
+	// BufferSize is the number of events that can be queued before OverflowPolicy applies
+	BufferSize int
+	// Workers is the number of goroutines draining the queue into the wrapped logger.
+	// With more than one worker, events may reach the sink out of order; use a single
+	// worker (see NewOrderedAsyncAuditLogger) when strict ordering matters.
+	Workers int
+	// OverflowPolicy applies when the queue is full; empty means OverflowBlock
+	OverflowPolicy OverflowPolicy
+	// OverflowTimeout bounds the wait under OverflowBlockWithTimeout; zero means DefaultOverflowTimeout
+	OverflowTimeout time.Duration
+}
+
+// DefaultAsyncAuditLoggerOptions returns the default async audit logger options
+func DefaultAsyncAuditLoggerOptions() AsyncAuditLoggerOptions {
+	return AsyncAuditLoggerOptions{
+		BufferSize:      1024,
+		Workers:         1,
+		OverflowPolicy:  OverflowBlock,
+		OverflowTimeout: DefaultOverflowTimeout,
+	}
+}
+
+// AsyncAuditLoggerOptionsFromConfig returns the default options with the configured overflow
+// policy and number of workers
+func AsyncAuditLoggerOptionsFromConfig(cfg *config.RustFSConfig) AsyncAuditLoggerOptions {
+	options := DefaultAsyncAuditLoggerOptions()
+	if cfg.AuditWorkers > 0 {
+		options.Workers = cfg.AuditWorkers
+	}
+	if cfg.AuditOverflowPolicy != "" {
+		options.OverflowPolicy = OverflowPolicy(cfg.AuditOverflowPolicy)
+	}
+	if cfg.AuditOverflowTimeout > 0 {
+		options.OverflowTimeout = cfg.AuditOverflowTimeout
+	}
+	return options
+}
+
+// AsyncAuditLogger implements audittypes.AuditLogger by queueing events and writing them
+// to a wrapped logger from a pool of background workers, keeping slow sinks off the hot path
+type AsyncAuditLogger struct {
+	next    audittypes.AuditLogger
+	options AsyncAuditLoggerOptions
+	events  chan queuedEvent
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	dropped     atomic.Int64
+	lastDropLog atomic.Int64
+}
+
+// queuedEvent is an event waiting to be written along with its originating context
+type queuedEvent struct {
+	ctx   context.Context
+	event *audittypes.AuditEvent
+}
+
+// NewAsyncAuditLogger creates an async audit logger writing to next with the given options
+func NewAsyncAuditLogger(next audittypes.AuditLogger, options AsyncAuditLoggerOptions) *AsyncAuditLogger {
+	if options.BufferSize < 0 {
+		options.BufferSize = 0
+	}
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.OverflowPolicy == "" {
+		options.OverflowPolicy = OverflowBlock
+	}
+	if options.OverflowTimeout <= 0 {
+		options.OverflowTimeout = DefaultOverflowTimeout
+	}
+
+	l := &AsyncAuditLogger{
+		next:    next,
+		options: options,
+		events:  make(chan queuedEvent, options.BufferSize),
+	}
+
+	for i := 0; i < options.Workers; i++ {
+		l.wg.Add(1)
+		go l.worker()
+	}
+
+	return l
+}
+
+// NewOrderedAsyncAuditLogger creates an async audit logger with a single worker,
+// preserving the order in which events were logged
+func NewOrderedAsyncAuditLogger(next audittypes.AuditLogger, bufferSize int) *AsyncAuditLogger {
+	return NewAsyncAuditLogger(next, AsyncAuditLoggerOptions{
+		BufferSize: bufferSize,
+		Workers:    1,
+	})
+}
+
+// LogEvent implements audittypes.AuditLogger by queueing the event for a worker
+func (l *AsyncAuditLogger) LogEvent(ctx context.Context, event *audittypes.AuditEvent) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return ErrAuditLoggerClosed
+	}
+
+	// The write outlives the caller, so detach from its cancellation but keep its values
+	queued := queuedEvent{ctx: context.WithoutCancel(ctx), event: event}
+
+	switch l.options.OverflowPolicy {
+	case OverflowDropNewest:
+		select {
+		case l.events <- queued:
+		default:
+			l.recordDrop()
+		}
+		return nil
+
+	case OverflowDropOldest:
+		// Without a buffer there is nothing queued to evict, so the new event is dropped instead
+		if cap(l.events) == 0 {
+			select {
+			case l.events <- queued:
+			default:
+				l.recordDrop()
+			}
+			return nil
+		}
+		for {
+			select {
+			case l.events <- queued:
+				return nil
+			default:
+			}
+			select {
+			case <-l.events:
+				l.recordDrop()
+			default:
+			}
+		}
+
+	case OverflowBlockWithTimeout:
+		timer := time.NewTimer(l.options.OverflowTimeout)
+		defer timer.Stop()
+		select {
+		case l.events <- queued:
+			return nil
+		case <-timer.C:
+			l.recordDrop()
+			return ErrAuditQueueTimeout
+		}
+
+	default:
+		l.events <- queued
+		return nil
+	}
+}
+
+// recordDrop counts a lost event and logs the running total, at most once per second
+func (l *AsyncAuditLogger) recordDrop() {
+	dropped := l.dropped.Add(1)
+
+	now := time.Now().UnixNano()
+	last := l.lastDropLog.Load()
+	if now-last >= int64(time.Second) && l.lastDropLog.CompareAndSwap(last, now) {
+		log.Printf("[AUDIT] audit queue full, %d events dropped so far (policy %s)", dropped, l.options.OverflowPolicy)
+	}
+}
+
+// Dropped returns the number of events lost to the overflow policy, including events that
+// timed out under OverflowBlockWithTimeout
+func (l *AsyncAuditLogger) Dropped() int64 {
+	return l.dropped.Load()
+}
+
+// LogAuthEvent implements audittypes.AuditLogger
+func (l *AsyncAuditLogger) LogAuthEvent(ctx context.Context, eventType audittypes.AuditEventType, userID, reason string, success bool, metadata map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Success:   success,
+		Reason:    reason,
+		Metadata:  metadata,
+	})
+}
+
+// LogAccessEvent implements audittypes.AuditLogger
+func (l *AsyncAuditLogger) LogAccessEvent(ctx context.Context, userID, resource, action, resourceID string, success bool, reason string) error {
+	eventType := audittypes.AuditEventAccessGranted
+	if !success {
+		eventType = audittypes.AuditEventAccessDenied
+	}
+
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Success:    success,
+		Reason:     reason,
+	})
+}
+
+// LogSecurityEvent implements audittypes.AuditLogger
+func (l *AsyncAuditLogger) LogSecurityEvent(ctx context.Context, eventType audittypes.AuditEventType, details map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		Metadata:  details,
+	})
+}
+
+// Workers returns the number of background workers
+func (l *AsyncAuditLogger) Workers() int {
+	return l.options.Workers
+}
+
+// Policy returns the overflow policy applied when the queue is full
+func (l *AsyncAuditLogger) Policy() OverflowPolicy {
+	return l.options.OverflowPolicy
+}
+
+// Close stops accepting events and waits until every queued event has been written
+func (l *AsyncAuditLogger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.events)
+	l.mu.Unlock()
+
+	l.wg.Wait()
+	return nil
+}
+
+func (l *AsyncAuditLogger) worker() {
+	defer l.wg.Done()
+
+	for queued := range l.events {
+		if err := l.next.LogEvent(queued.ctx, queued.event); err != nil {
+			log.Printf("[AUDIT] failed to write audit event: %v", err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestAsyncAuditLoggerOptionsFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		timeout     time.Duration
+		workers     int
+		wantPolicy  OverflowPolicy
+		wantTimeout time.Duration
+		wantWorkers int
+	}{
+		{"defaults", "", 0, 0, OverflowBlock, DefaultOverflowTimeout, 1},
+		{"drop newest", config.AuditOverflowDropNewest, 0, 0, OverflowDropNewest, DefaultOverflowTimeout, 1},
+		{"block with timeout", config.AuditOverflowBlockWithTimeout, 250 * time.Millisecond, 0, OverflowBlockWithTimeout, 250 * time.Millisecond, 1},
+		{"workers", "", 0, 4, OverflowBlock, DefaultOverflowTimeout, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := AsyncAuditLoggerOptionsFromConfig(&config.RustFSConfig{
+				AuditOverflowPolicy:  tt.policy,
+				AuditOverflowTimeout: tt.timeout,
+				AuditWorkers:         tt.workers,
+			})
+			if options.Workers != tt.wantWorkers {
+				t.Errorf("Workers = %d, want %d", options.Workers, tt.wantWorkers)
+			}
+			if options.OverflowPolicy != tt.wantPolicy {
+				t.Errorf("OverflowPolicy = %q, want %q", options.OverflowPolicy, tt.wantPolicy)
+			}
+			if options.OverflowTimeout != tt.wantTimeout {
+				t.Errorf("OverflowTimeout = %v, want %v", options.OverflowTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+// gatedBackend holds every write until release is closed, recording the written resource IDs
+type gatedBackend struct {
+	started chan string
+	release chan struct{}
+
+	mu      sync.Mutex
+	written []string
+}
+
+func newGatedBackend() *gatedBackend {
+	return &gatedBackend{started: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (b *gatedBackend) Write(ctx context.Context, event *audittypes.AuditEvent) error {
+	b.started <- event.ResourceID
+	<-b.release
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.written = append(b.written, event.ResourceID)
+	return nil
+}
+
+func (b *gatedBackend) writes() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.written)
+}
+
+func TestAsyncAuditLoggerOverflow(t *testing.T) {
+	tests := []struct {
+		policy      OverflowPolicy
+		wantErr     error
+		wantBlocked bool
+		wantDropped int64
+		wantWritten []string
+	}{
+		{OverflowBlock, nil, true, 0, []string{"first", "second", "third"}},
+		{OverflowBlockWithTimeout, ErrAuditQueueTimeout, true, 1, []string{"first", "second"}},
+		{OverflowDropNewest, nil, false, 1, []string{"first", "second"}},
+		{OverflowDropOldest, nil, false, 1, []string{"first", "third"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			backend := newGatedBackend()
+			logger := NewAsyncAuditLogger(NewFanOutAuditLogger("test", backend), AsyncAuditLoggerOptions{
+				BufferSize:      1,
+				Workers:         1,
+				OverflowPolicy:  tt.policy,
+				OverflowTimeout: 50 * time.Millisecond,
+			})
+			logEvent := func(id string) error {
+				return logger.LogEvent(context.Background(), &audittypes.AuditEvent{ResourceID: id})
+			}
+
+			// The worker holds "first" in the stalled sink and "second" fills the queue
+			if err := logEvent("first"); err != nil {
+				t.Fatalf("LogEvent(first): %v", err)
+			}
+			<-backend.started
+			if err := logEvent("second"); err != nil {
+				t.Fatalf("LogEvent(second): %v", err)
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- logEvent("third") }()
+
+			blocked := false
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("LogEvent(third) = %v, want nil", err)
+				}
+			case <-time.After(20 * time.Millisecond):
+				blocked = true
+			}
+			if blocked != tt.wantBlocked {
+				t.Errorf("LogEvent(third) blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+
+			if blocked {
+				if tt.wantErr != nil {
+					// The timeout expires while the sink is still stalled
+					if err := <-done; !errors.Is(err, tt.wantErr) {
+						t.Errorf("LogEvent(third) = %v, want %v", err, tt.wantErr)
+					}
+					close(backend.release)
+				} else {
+					close(backend.release)
+					if err := <-done; err != nil {
+						t.Errorf("LogEvent(third) = %v, want nil once the sink drains", err)
+					}
+				}
+			} else {
+				close(backend.release)
+			}
+
+			if err := logger.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if got := logger.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+			if got := backend.writes(); !slices.Equal(got, tt.wantWritten) {
+				t.Errorf("written %v, want %v", got, tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestAsyncAuditLoggerWorkersInFlight(t *testing.T) {
+	const workers = 3
+	backend := newGatedBackend()
+	logger := NewAsyncAuditLogger(NewFanOutAuditLogger("test", backend), AsyncAuditLoggerOptions{
+		BufferSize: workers,
+		Workers:    workers,
+	})
+
+	for i := 0; i < workers; i++ {
+		if err := logger.LogEvent(context.Background(), &audittypes.AuditEvent{ResourceID: "event"}); err != nil {
+			t.Fatalf("LogEvent: %v", err)
+		}
+	}
+
+	// Every write is held by the sink, so each one reaching it needs its own worker
+	timeout := time.After(time.Second)
+	for i := 0; i < workers; i++ {
+		select {
+		case <-backend.started:
+		case <-timeout:
+			t.Fatalf("%d of %d events reached the sink concurrently", i, workers)
+		}
+	}
+
+	close(backend.release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := len(backend.writes()); got != workers {
+		t.Errorf("%d events written, want %d", got, workers)
+	}
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// GzipWriter gzip-compresses audit output written to an underlying writer. Flush ends the
+// current gzip member, so everything flushed so far is a complete, readable gzip file; gzip
+// readers treat the consecutive members as one stream. It is safe for concurrent use.
+type GzipWriter struct {
+	w  io.Writer
+	gz *gzip.Writer
+
+	mu   sync.Mutex
+	open bool
+}
+
+// NewGzipWriter creates a writer compressing into w at the given level, from gzip.HuffmanOnly
+// to gzip.BestCompression; gzip.DefaultCompression picks the library default
+func NewGzipWriter(w io.Writer, level int) (*GzipWriter, error) {
+	gz, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit compression level: %w", err)
+	}
+	return &GzipWriter{w: w, gz: gz}, nil
+}
+
+// OpenGzipAuditFile opens path for appending compressed JSON lines, creating it if needed.
+// Name the file with a .jsonl.gz extension; appending to an existing file adds gzip members,
+// which keeps it readable.
+func OpenGzipAuditFile(path string, level int) (*GzipWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	w, err := NewGzipWriter(file, level)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements io.Writer, starting a new gzip member after a Flush
+func (w *GzipWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.open {
+		w.gz.Reset(w.w)
+		w.open = true
+	}
+	return w.gz.Write(p)
+}
+
+// Flush completes the current gzip member and writes it to the underlying writer
+func (w *GzipWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushLocked()
+}
+
+// Close flushes pending output and closes the underlying writer if it is an io.Closer
+func (w *GzipWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.flushLocked(); err != nil {
+		return err
+	}
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (w *GzipWriter) flushLocked() error {
+	if !w.open {
+		return nil
+	}
+	w.open = false
+	return w.gz.Close()
+}
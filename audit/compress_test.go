@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+// readGzipEvents decompresses JSON-lines audit output, which may span several gzip members
+func readGzipEvents(t *testing.T, r io.Reader) []audittypes.AuditEvent {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gz.Close()
+
+	var events []audittypes.AuditEvent
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var event audittypes.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decompressed line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading compressed audit output: %v", err)
+	}
+	return events
+}
+
+// logUploads logs an upload event for each path
+func logUploads(logger *RustFSAuditLogger, paths ...string) {
+	for _, path := range paths {
+		logger.LogFileUpload(context.Background(), "alice", &FileOperationMetadata{FilePath: path, FileSize: 2048}, nil)
+	}
+}
+
+// resourceIDs returns the resource of each event
+func resourceIDs(events []audittypes.AuditEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ResourceID)
+	}
+	return ids
+}
+
+func TestGzipAuditFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl.gz")
+
+	w, err := OpenGzipAuditFile(path, gzip.BestCompression)
+	if err != nil {
+		t.Fatalf("OpenGzipAuditFile: %v", err)
+	}
+	backend := NewFormattedBackend(&JSONFormatter{}, w)
+	logger := NewFanOutRustFSAuditLogger("media", nil, backend)
+
+	logUploads(logger, "a.txt", "b.txt")
+	if err := backend.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	// Everything flushed so far is a complete gzip file, though the writer stays open
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(resourceIDs(readGzipEvents(t, bytes.NewReader(data)))); got != "[a.txt b.txt]" {
+		t.Fatalf("events after Flush = %s, want [a.txt b.txt]", got)
+	}
+
+	logUploads(logger, "c.txt")
+	if err := backend.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Reopening appends another gzip member, read back as one stream
+	w, err = OpenGzipAuditFile(path, gzip.BestSpeed)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	backend = NewFormattedBackend(&JSONFormatter{}, w)
+	logUploads(NewFanOutRustFSAuditLogger("media", nil, backend), "d.txt")
+	if err := backend.Close(); err != nil {
+		t.Fatalf("Close after reopening: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	events := readGzipEvents(t, file)
+	if got := fmt.Sprint(resourceIDs(events)); got != "[a.txt b.txt c.txt d.txt]" {
+		t.Fatalf("events = %s, want [a.txt b.txt c.txt d.txt]", got)
+	}
+	for _, event := range events {
+		if event.UserID != "alice" || event.EventType != AuditEventFileUploaded || event.Metadata["file_size"] != float64(2048) {
+			t.Errorf("round-tripped event = %+v", event)
+		}
+	}
+}
+
+func TestGzipWriterLevels(t *testing.T) {
+	var raw bytes.Buffer
+	logUploads(NewFanOutRustFSAuditLogger("media", nil, NewFormattedBackend(&JSONFormatter{}, &raw)), "a.txt", "a.txt", "a.txt", "a.txt")
+
+	for _, level := range []int{gzip.HuffmanOnly, gzip.DefaultCompression, gzip.BestSpeed, gzip.BestCompression} {
+		t.Run(fmt.Sprint(level), func(t *testing.T) {
+			var out bytes.Buffer
+			w, err := NewGzipWriter(&out, level)
+			if err != nil {
+				t.Fatalf("NewGzipWriter: %v", err)
+			}
+			if _, err := w.Write(raw.Bytes()); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if len(readGzipEvents(t, bytes.NewReader(out.Bytes()))) != 4 {
+				t.Fatalf("round trip lost events")
+			}
+			if level != gzip.HuffmanOnly && out.Len() >= raw.Len() {
+				t.Errorf("compressed %d bytes to %d", raw.Len(), out.Len())
+			}
+		})
+	}
+
+	if _, err := NewGzipWriter(io.Discard, 42); err == nil {
+		t.Error("NewGzipWriter accepted an invalid compression level")
+	}
+}
+
+func TestGzipWriterFlushWithoutEvents(t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewGzipWriter(&out, gzip.DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewGzipWriter: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("flushing without events wrote %d bytes", out.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+// RequestInfo carries the audit-relevant attributes of an inbound request
+type RequestInfo struct {
+	UserID    string
+	RequestID string
+	TraceID   string
+	IP        string
+	UserAgent string
+}
+
+// requestInfoKey is the context key under which RequestInfo is stored
+type requestInfoKey struct{}
+
+// NewRequestContext returns a context carrying info, so every storage call made with it is
+// audited with the same user, request, trace and client attributes
+func NewRequestContext(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the request attributes carried by ctx. Contexts built without
+// NewRequestContext fall back to the plain "user_id" and "request_id" values.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	if info, ok := ctx.Value(requestInfoKey{}).(RequestInfo); ok {
+		return info, true
+	}
+
+	var info RequestInfo
+	info.UserID, _ = ctx.Value("user_id").(string)
+	info.RequestID, _ = ctx.Value("request_id").(string)
+	return info, info.UserID != "" || info.RequestID != ""
+}
+
+// applyRequestInfo fills event attributes left empty by the caller from the request context
+func applyRequestInfo(ctx context.Context, event *audittypes.AuditEvent) {
+	info, ok := RequestInfoFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if event.UserID == "" {
+		event.UserID = info.UserID
+	}
+	if event.RequestID == "" {
+		event.RequestID = info.RequestID
+	}
+	if event.IPAddress == "" {
+		event.IPAddress = info.IP
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = info.UserAgent
+	}
+	if info.TraceID != "" {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]interface{})
+		}
+		if _, exists := event.Metadata["trace_id"]; !exists {
+			event.Metadata["trace_id"] = info.TraceID
+		}
+	}
+}
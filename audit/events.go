@@ -33,6 +33,7 @@ const (
 	AuditEventUploadTimeout     types.AuditEventType = "upload_timeout"
 	AuditEventStorageFull       types.AuditEventType = "storage_full"
 	AuditEventHighResourceUsage types.AuditEventType = "high_resource_usage"
+	AuditEventBatchThroughput   types.AuditEventType = "batch_throughput"
 )
 
 // FileOperationMetadata represents metadata for file operations
@@ -85,6 +86,20 @@ type PerformanceEventMetadata struct {
 	Additional    map[string]interface{} `json:"additional,omitempty"`
 }
 
+// OperationRecord accumulates the signals of one logical operation, such as an upload and the
+// slow-upload warning it raised, so they can be logged as a single combined event
+type OperationRecord struct {
+	Operation string `json:"operation"`
+	// EventType is logged when the operation succeeds; failures log AuditEventStorageError
+	EventType   types.AuditEventType     `json:"event_type"`
+	File        *FileOperationMetadata   `json:"file,omitempty"`
+	Performance PerformanceEventMetadata `json:"performance"`
+	// Signals lists the performance events raised during the operation, e.g. AuditEventUploadSlow
+	Signals   []types.AuditEventType `json:"signals,omitempty"`
+	ErrorCode string                 `json:"error_code,omitempty"`
+	Err       error                  `json:"-"`
+}
+
 // Update severity mapping for RustFS events
 func GetSeverity(eventType types.AuditEventType) types.AuditSeverity {
 	switch eventType {
@@ -111,6 +126,8 @@ func GetSeverity(eventType types.AuditEventType) types.AuditSeverity {
 		return types.AuditSeverityCritical
 
 	// Performance events
+	case AuditEventBatchThroughput:
+		return types.AuditSeverityLow
 	case AuditEventUploadSlow:
 		return types.AuditSeverityMedium
 	case AuditEventUploadTimeout, AuditEventHighResourceUsage:
@@ -135,7 +152,7 @@ func IsSecurityEvent(eventType types.AuditEventType) bool {
 // IsPerformanceEvent checks if an event type is performance-related
 func IsPerformanceEvent(eventType types.AuditEventType) bool {
 	switch eventType {
-	case AuditEventUploadSlow, AuditEventUploadTimeout, AuditEventStorageFull, AuditEventHighResourceUsage:
+	case AuditEventUploadSlow, AuditEventUploadTimeout, AuditEventStorageFull, AuditEventHighResourceUsage, AuditEventBatchThroughput:
 		return true
 	default:
 		return false
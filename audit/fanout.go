@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+// FormattedBackend writes each audit event to an io.Writer using its own formatter,
+// one event per line
+type FormattedBackend struct {
+	Formatter EventFormatter
+	Writer    io.Writer
+
+	mu sync.Mutex
+}
+
+// NewFormattedBackend creates a backend that renders events with formatter and writes them to writer
+func NewFormattedBackend(formatter EventFormatter, writer io.Writer) *FormattedBackend {
+	return &FormattedBackend{
+		Formatter: formatter,
+		Writer:    writer,
+	}
+}
+
+// Write implements audittypes.AuditLoggerBackend
+func (b *FormattedBackend) Write(ctx context.Context, event *audittypes.AuditEvent) error {
+	line, err := b.Formatter.Format(event)
+	if err != nil {
+		return fmt.Errorf("failed to format audit event: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, err = b.Writer.Write(append(line, '\n'))
+	return err
+}
+
+// Flush flushes the writer if it buffers output, such as a GzipWriter
+func (b *FormattedBackend) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if flusher, ok := b.Writer.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the writer if it is an io.Closer
+func (b *FormattedBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if closer, ok := b.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// FanOutAuditLogger implements audittypes.AuditLogger by building the canonical event once
+// and handing it to every backend, each of which renders it in its own format
+type FanOutAuditLogger struct {
+	service  string
+	backends []audittypes.AuditLoggerBackend
+}
+
+// NewFanOutAuditLogger creates a new fan-out audit logger
+func NewFanOutAuditLogger(service string, backends ...audittypes.AuditLoggerBackend) *FanOutAuditLogger {
+	return &FanOutAuditLogger{
+		service:  service,
+		backends: backends,
+	}
+}
+
+// LogEvent implements audittypes.AuditLogger. A failing backend does not prevent the
+// event from reaching the others; all backend errors are returned joined.
+func (l *FanOutAuditLogger) LogEvent(ctx context.Context, event *audittypes.AuditEvent) error {
+	completeEvent(ctx, event, l.service)
+
+	var errs []error
+	for _, backend := range l.backends {
+		if err := backend.Write(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LogAuthEvent implements audittypes.AuditLogger
+func (l *FanOutAuditLogger) LogAuthEvent(ctx context.Context, eventType audittypes.AuditEventType, userID, reason string, success bool, metadata map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Success:   success,
+		Reason:    reason,
+		Metadata:  metadata,
+	})
+}
+
+// LogAccessEvent implements audittypes.AuditLogger
+func (l *FanOutAuditLogger) LogAccessEvent(ctx context.Context, userID, resource, action, resourceID string, success bool, reason string) error {
+	eventType := audittypes.AuditEventAccessGranted
+	if !success {
+		eventType = audittypes.AuditEventAccessDenied
+	}
+
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Success:    success,
+		Reason:     reason,
+	})
+}
+
+// LogSecurityEvent implements audittypes.AuditLogger
+func (l *FanOutAuditLogger) LogSecurityEvent(ctx context.Context, eventType audittypes.AuditEventType, details map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		Metadata:  details,
+	})
+}
+
+// completeEvent fills the canonical attributes of an event that the caller left empty: its ID,
+// timestamp, service and the attributes of the request carried by ctx. Filled attributes are
+// kept, so completing an event twice is harmless.
+func completeEvent(ctx context.Context, event *audittypes.AuditEvent, service string) {
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Service == "" {
+		event.Service = service
+	}
+	applyRequestInfo(ctx, event)
+}
+
+// newEventID generates a random RFC 4122 version 4 UUID
+func newEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+func TestFanOutFormats(t *testing.T) {
+	var jsonOut, logfmtOut, cefOut bytes.Buffer
+	logger := NewFanOutRustFSAuditLogger("media", nil,
+		NewFormattedBackend(&JSONFormatter{}, &jsonOut),
+		NewFormattedBackend(&LogfmtFormatter{}, &logfmtOut),
+		NewFormattedBackend(NewCEFFormatter(), &cefOut),
+	)
+
+	ctx := NewRequestContext(context.Background(), RequestInfo{
+		RequestID: "req-42",
+		IP:        "203.0.113.7",
+		UserAgent: "uploader/1.0",
+	})
+	logger.LogFileUpload(ctx, "alice", &FileOperationMetadata{
+		FilePath: "photos/cat one.jpg",
+		FileSize: 2048,
+	}, nil)
+
+	var event audittypes.AuditEvent
+	if err := json.Unmarshal(jsonOut.Bytes(), &event); err != nil {
+		t.Fatalf("JSON output %q: %v", jsonOut.String(), err)
+	}
+	if event.ID == "" || event.Timestamp.IsZero() {
+		t.Errorf("JSON event has no ID or timestamp: %+v", event)
+	}
+	jsonFields := []struct{ name, got, want string }{
+		{"service", event.Service, "media"},
+		{"event_type", string(event.EventType), string(AuditEventFileUploaded)},
+		{"user_id", event.UserID, "alice"},
+		{"resource_id", event.ResourceID, "photos/cat one.jpg"},
+		{"request_id", event.RequestID, "req-42"},
+		{"ip_address", event.IPAddress, "203.0.113.7"},
+	}
+	for _, field := range jsonFields {
+		if field.got != field.want {
+			t.Errorf("JSON %s = %q, want %q", field.name, field.got, field.want)
+		}
+	}
+
+	// Every format renders the same canonical event
+	formats := []struct {
+		name   string
+		output string
+		fields []string
+	}{
+		{"logfmt", logfmtOut.String(), []string{
+			"id=" + event.ID,
+			"service=media",
+			"event_type=" + string(AuditEventFileUploaded),
+			"user_id=alice",
+			`resource_id="photos/cat one.jpg"`,
+			"success=true",
+			"request_id=req-42",
+			"ip_address=203.0.113.7",
+			"metadata.file_size=2048",
+		}},
+		{"CEF", cefOut.String(), []string{
+			"CEF:0|RustFS|rustfs-client|1.0.0|" + string(AuditEventFileUploaded) + "|",
+			"externalId=" + event.ID,
+			"suser=alice",
+			"src=203.0.113.7",
+			"requestClientApplication=uploader/1.0",
+			"request=photos/cat one.jpg",
+			"outcome=success",
+			"cs1=media",
+			"cs3=req-42",
+		}},
+	}
+	for _, format := range formats {
+		if strings.Count(format.output, "\n") != 1 {
+			t.Errorf("%s output is not one line: %q", format.name, format.output)
+		}
+		for _, field := range format.fields {
+			if !strings.Contains(format.output, field) {
+				t.Errorf("%s output %q lacks %q", format.name, format.output, field)
+			}
+		}
+	}
+}
+
+// failingBackend rejects every event
+type failingBackend struct{}
+
+func (failingBackend) Write(ctx context.Context, event *audittypes.AuditEvent) error {
+	return errors.New("sink offline")
+}
+
+func TestFanOutFailingBackend(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewFanOutAuditLogger("media", failingBackend{}, NewFormattedBackend(&LogfmtFormatter{}, &out))
+
+	err := logger.LogEvent(context.Background(), &audittypes.AuditEvent{EventType: AuditEventFileDeleted})
+	if err == nil || !strings.Contains(err.Error(), "sink offline") {
+		t.Errorf("LogEvent = %v, want the failing backend's error", err)
+	}
+	if !strings.Contains(out.String(), "event_type="+string(AuditEventFileDeleted)) {
+		t.Errorf("healthy backend got %q, want the event", out.String())
+	}
+}
@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+// EventFormatter renders an audit event into the wire format expected by a backend
+type EventFormatter interface {
+	Format(event *audittypes.AuditEvent) ([]byte, error)
+}
+
+// JSONFormatter renders events as a single JSON object, suitable for data lakes and log pipelines
+type JSONFormatter struct{}
+
+// Format implements EventFormatter
+func (f *JSONFormatter) Format(event *audittypes.AuditEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// LogfmtFormatter renders events as key=value pairs, suitable for humans and grep
+type LogfmtFormatter struct{}
+
+// Format implements EventFormatter
+func (f *LogfmtFormatter) Format(event *audittypes.AuditEvent) ([]byte, error) {
+	var b strings.Builder
+
+	writePair := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(value))
+	}
+
+	writePair("time", event.Timestamp.Format(time.RFC3339Nano))
+	writePair("id", event.ID)
+	writePair("service", event.Service)
+	writePair("event_type", string(event.EventType))
+	writePair("severity", string(GetSeverity(event.EventType)))
+	writePair("user_id", event.UserID)
+	writePair("resource", event.Resource)
+	writePair("resource_id", event.ResourceID)
+	writePair("action", event.Action)
+	writePair("success", strconv.FormatBool(event.Success))
+	writePair("reason", event.Reason)
+	writePair("ip_address", event.IPAddress)
+	writePair("user_agent", event.UserAgent)
+	writePair("request_id", event.RequestID)
+
+	keys := make([]string, 0, len(event.Metadata))
+	for k := range event.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writePair("metadata."+k, formatMetadataValue(event.Metadata[k]))
+	}
+
+	return []byte(b.String()), nil
+}
+
+// CEFFormatter renders events in ArcSight Common Event Format, suitable for SIEMs
+type CEFFormatter struct {
+	Vendor  string
+	Product string
+	Version string
+}
+
+// NewCEFFormatter creates a CEF formatter with the default device identification
+func NewCEFFormatter() *CEFFormatter {
+	return &CEFFormatter{
+		Vendor:  "RustFS",
+		Product: "rustfs-client",
+		Version: "1.0.0",
+	}
+}
+
+// Format implements EventFormatter
+func (f *CEFFormatter) Format(event *audittypes.AuditEvent) ([]byte, error) {
+	header := strings.Join([]string{
+		"CEF:0",
+		cefHeaderValue(f.Vendor),
+		cefHeaderValue(f.Product),
+		cefHeaderValue(f.Version),
+		cefHeaderValue(string(event.EventType)),
+		cefHeaderValue(strings.ReplaceAll(string(event.EventType), "_", " ")),
+		strconv.Itoa(cefSeverity(GetSeverity(event.EventType))),
+	}, "|")
+
+	outcome := "failure"
+	if event.Success {
+		outcome = "success"
+	}
+
+	extensions := []string{
+		"rt=" + strconv.FormatInt(event.Timestamp.UnixMilli(), 10),
+		"outcome=" + outcome,
+	}
+	addExtension := func(key, value string) {
+		if value != "" {
+			extensions = append(extensions, key+"="+cefExtensionValue(value))
+		}
+	}
+	addExtension("externalId", event.ID)
+	addExtension("suser", event.UserID)
+	addExtension("src", event.IPAddress)
+	addExtension("requestClientApplication", event.UserAgent)
+	addExtension("act", event.Action)
+	addExtension("request", event.ResourceID)
+	addExtension("reason", event.Reason)
+	if event.Service != "" {
+		addExtension("cs1Label", "service")
+		addExtension("cs1", event.Service)
+	}
+	if event.Resource != "" {
+		addExtension("cs2Label", "resource")
+		addExtension("cs2", event.Resource)
+	}
+	if event.RequestID != "" {
+		addExtension("cs3Label", "request_id")
+		addExtension("cs3", event.RequestID)
+	}
+
+	return []byte(header + "|" + strings.Join(extensions, " ")), nil
+}
+
+// Helper functions
+
+func logfmtValue(value string) string {
+	if strings.ContainsAny(value, " =\"\t\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func formatMetadataValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func cefHeaderValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+func cefExtensionValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "=", `\=`)
+	value = strings.ReplaceAll(value, "\r", `\r`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+func cefSeverity(severity audittypes.AuditSeverity) int {
+	switch severity {
+	case audittypes.AuditSeverityCritical:
+		return 10
+	case audittypes.AuditSeverityHigh:
+		return 8
+	case audittypes.AuditSeverityMedium:
+		return 5
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"sort"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+// HistoryEntry is one step in an object's audit-derived timeline
+type HistoryEntry struct {
+	Timestamp time.Time                 `json:"timestamp"`
+	Actor     string                    `json:"actor"`
+	Action    string                    `json:"action"`
+	EventType audittypes.AuditEventType `json:"event_type"`
+	Success   bool                      `json:"success"`
+	Reason    string                    `json:"reason,omitempty"`
+	RequestID string                    `json:"request_id,omitempty"`
+}
+
+// historyPathKeys are metadata keys that may name the object an event concerns,
+// covering events whose ResourceID is not the object path (such as copies and moves)
+var historyPathKeys = []string{"file_path", "source_path", "target_path"}
+
+// ReconstructHistory filters events down to those concerning resourceID and orders them
+// chronologically into a timeline. Events with equal timestamps keep their input order.
+func ReconstructHistory(events []audittypes.AuditEvent, resourceID string) []HistoryEntry {
+	history := make([]HistoryEntry, 0)
+	for _, event := range events {
+		if !eventConcerns(event, resourceID) {
+			continue
+		}
+
+		action := event.Action
+		if action == "" {
+			action = string(event.EventType)
+		}
+
+		history = append(history, HistoryEntry{
+			Timestamp: event.Timestamp,
+			Actor:     event.UserID,
+			Action:    action,
+			EventType: event.EventType,
+			Success:   event.Success,
+			Reason:    event.Reason,
+			RequestID: event.RequestID,
+		})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Timestamp.Before(history[j].Timestamp)
+	})
+
+	return history
+}
+
+// eventConcerns reports whether event refers to the object resourceID
+func eventConcerns(event audittypes.AuditEvent, resourceID string) bool {
+	if resourceID == "" {
+		return false
+	}
+	if event.ResourceID == resourceID {
+		return true
+	}
+	for _, key := range historyPathKeys {
+		if path, ok := event.Metadata[key].(string); ok && path == resourceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+)
+
+func TestReconstructHistory(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// The stream is out of order and interleaves events for other objects
+	events := []audittypes.AuditEvent{
+		{EventType: AuditEventFileDownloaded, UserID: "bob", ResourceID: "docs/a.txt", Action: "download", Success: true, Timestamp: at(3)},
+		{EventType: AuditEventFileUploaded, UserID: "carol", ResourceID: "docs/b.txt", Action: "upload", Success: true, Timestamp: at(1)},
+		{EventType: AuditEventFileUploaded, UserID: "alice", ResourceID: "docs/a.txt", Action: "upload", Success: true, Timestamp: at(0), RequestID: "req-1"},
+		{EventType: AuditEventFileUpdated, UserID: "alice", ResourceID: "docs/a.txt", Success: false, Reason: "access denied", Timestamp: at(5)},
+		{EventType: AuditEventFileAccessed, UserID: "bob", ResourceID: "docs/a.txt", Action: "get_info", Success: true, Timestamp: at(3)},
+		{EventType: AuditEventFileMoved, UserID: "alice", ResourceID: "archive/a.txt", Action: "move", Success: true, Timestamp: at(7),
+			Metadata: map[string]interface{}{"source_path": "docs/a.txt", "target_path": "archive/a.txt"}},
+		{EventType: AuditEventFileCopied, UserID: "carol", ResourceID: "docs/c.txt", Action: "copy", Success: true, Timestamp: at(6),
+			Metadata: map[string]interface{}{"source_path": "docs/b.txt", "target_path": "docs/c.txt"}},
+		{EventType: AuditEventStorageMaintenance, UserID: "system", ResourceID: "storage", Action: "client_summary", Success: true, Timestamp: at(8)},
+	}
+
+	want := []HistoryEntry{
+		{Timestamp: at(0), Actor: "alice", Action: "upload", EventType: AuditEventFileUploaded, Success: true, RequestID: "req-1"},
+		{Timestamp: at(3), Actor: "bob", Action: "download", EventType: AuditEventFileDownloaded, Success: true},
+		{Timestamp: at(3), Actor: "bob", Action: "get_info", EventType: AuditEventFileAccessed, Success: true},
+		{Timestamp: at(5), Actor: "alice", Action: string(AuditEventFileUpdated), EventType: AuditEventFileUpdated, Reason: "access denied"},
+		{Timestamp: at(7), Actor: "alice", Action: "move", EventType: AuditEventFileMoved, Success: true},
+	}
+
+	if got := ReconstructHistory(events, "docs/a.txt"); !reflect.DeepEqual(got, want) {
+		t.Errorf("history of docs/a.txt =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := ReconstructHistory(events, "archive/a.txt"); len(got) != 1 || got[0].Action != "move" {
+		t.Errorf("history of archive/a.txt = %+v, want only the move", got)
+	}
+	if got := ReconstructHistory(events, "docs/missing.txt"); got == nil || len(got) != 0 {
+		t.Errorf("history of an unknown path = %#v, want an empty timeline", got)
+	}
+	if got := ReconstructHistory(events, ""); len(got) != 0 {
+		t.Errorf("history of an empty path = %+v, want none", got)
+	}
+}
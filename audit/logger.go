@@ -13,6 +13,7 @@ type RustFSAuditLogger struct {
 	auditLogger audittypes.AuditLogger
 	service     string
 	config      map[string]interface{}
+	limits      MetadataLimits
 }
 
 // NewRustFSAuditLogger creates a new RustFS-specific audit logger
@@ -21,9 +22,21 @@ func NewRustFSAuditLogger(service string, auditLogger audittypes.AuditLogger, co
 		auditLogger: auditLogger,
 		service:     service,
 		config:      config,
+		limits:      DefaultMetadataLimits(),
 	}
 }
 
+// NewFanOutRustFSAuditLogger creates a RustFS audit logger that builds each event once and
+// hands it to every backend through a FanOutAuditLogger, each rendering it in its own format
+func NewFanOutRustFSAuditLogger(service string, config map[string]interface{}, backends ...audittypes.AuditLoggerBackend) *RustFSAuditLogger {
+	return NewRustFSAuditLogger(service, NewFanOutAuditLogger(service, backends...), config)
+}
+
+// SetMetadataLimits sets the limits applied when sanitizing event metadata
+func (l *RustFSAuditLogger) SetMetadataLimits(limits MetadataLimits) {
+	l.limits = limits
+}
+
 // LogFileUpload logs a file upload event
 func (l *RustFSAuditLogger) LogFileUpload(ctx context.Context, userID string, metadata *FileOperationMetadata, err error) {
 	eventType := AuditEventFileUploaded
@@ -124,6 +137,85 @@ func (l *RustFSAuditLogger) LogFileDownload(ctx context.Context, userID, filePat
 	l.logEvent(ctx, event)
 }
 
+// LogFileUpdate logs an in-place file update event such as a metadata change
+func (l *RustFSAuditLogger) LogFileUpdate(ctx context.Context, userID, filePath string, metadata *FileOperationMetadata, err error) {
+	eventType := AuditEventFileUpdated
+	success := err == nil
+
+	auditMetadata := l.buildFileMetadata(metadata)
+	if err != nil {
+		eventType = AuditEventStorageError
+		auditMetadata["error"] = err.Error()
+		auditMetadata["error_type"] = "update_failed"
+	}
+
+	event := &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   "file",
+		ResourceID: filePath,
+		Success:    success,
+		Reason:     l.getReason(success, err),
+		Metadata:   auditMetadata,
+	}
+
+	l.logEvent(ctx, event)
+}
+
+// LogFileMove logs a move of sourcePath to targetPath, recording both paths
+func (l *RustFSAuditLogger) LogFileMove(ctx context.Context, userID, sourcePath, targetPath string, metadata *FileOperationMetadata, err error) {
+	eventType := AuditEventFileMoved
+	success := err == nil
+
+	auditMetadata := l.buildFileMetadata(metadata)
+	auditMetadata["source_path"] = sourcePath
+	auditMetadata["target_path"] = targetPath
+	if err != nil {
+		eventType = AuditEventStorageError
+		auditMetadata["error"] = err.Error()
+		auditMetadata["error_type"] = "move_failed"
+	}
+
+	event := &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   "file",
+		ResourceID: targetPath,
+		Success:    success,
+		Reason:     l.getReason(success, err),
+		Metadata:   auditMetadata,
+	}
+
+	l.logEvent(ctx, event)
+}
+
+// LogFileCopy logs a server-side copy of sourcePath to targetPath
+func (l *RustFSAuditLogger) LogFileCopy(ctx context.Context, userID, sourcePath, targetPath string, metadata *FileOperationMetadata, err error) {
+	eventType := AuditEventFileCopied
+	success := err == nil
+
+	auditMetadata := l.buildFileMetadata(metadata)
+	auditMetadata["source_path"] = sourcePath
+	auditMetadata["target_path"] = targetPath
+	if err != nil {
+		eventType = AuditEventStorageError
+		auditMetadata["error"] = err.Error()
+		auditMetadata["error_type"] = "copy_failed"
+	}
+
+	event := &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   "file",
+		ResourceID: targetPath,
+		Success:    success,
+		Reason:     l.getReason(success, err),
+		Metadata:   auditMetadata,
+	}
+
+	l.logEvent(ctx, event)
+}
+
 // LogStorageError logs a storage error event
 func (l *RustFSAuditLogger) LogStorageError(ctx context.Context, userID, operation string, metadata *StorageErrorMetadata) {
 	auditMetadata := map[string]interface{}{
@@ -227,6 +319,53 @@ func (l *RustFSAuditLogger) LogPerformanceEvent(ctx context.Context, userID stri
 	l.logEvent(ctx, event)
 }
 
+// LogOperation logs one combined event for a logical operation. The file metadata sits at the
+// top level as in LogFileUpload, with an outcome, a performance section and any error.
+func (l *RustFSAuditLogger) LogOperation(ctx context.Context, userID string, record *OperationRecord) {
+	eventType := record.EventType
+	success := record.Err == nil
+
+	auditMetadata := l.buildFileMetadata(record.File)
+	auditMetadata["service"] = l.service
+	auditMetadata["operation"] = record.Operation
+	auditMetadata["outcome"] = "success"
+
+	performance := map[string]interface{}{
+		"duration":        record.Performance.Duration,
+		"file_size":       record.Performance.FileSize,
+		"throughput_mbps": record.Performance.Throughput,
+	}
+	if len(record.Signals) > 0 {
+		performance["threshold"] = record.Performance.Threshold
+		performance["signals"] = record.Signals
+	}
+	auditMetadata["performance"] = performance
+
+	if !success {
+		eventType = AuditEventStorageError
+		auditMetadata["outcome"] = "failure"
+		auditMetadata["error"] = record.Err.Error()
+		auditMetadata["error_code"] = record.ErrorCode
+	}
+
+	resourceID := ""
+	if record.File != nil {
+		resourceID = record.File.FilePath
+	}
+
+	event := &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   "file",
+		ResourceID: resourceID,
+		Success:    success,
+		Reason:     l.getReason(success, record.Err),
+		Metadata:   auditMetadata,
+	}
+
+	l.logEvent(ctx, event)
+}
+
 // LogQuotaExceeded logs a quota exceeded event
 func (l *RustFSAuditLogger) LogQuotaExceeded(ctx context.Context, userID string, currentUsage, quotaLimit int64, metadata *FileOperationMetadata) {
 	auditMetadata := l.buildFileMetadata(metadata)
@@ -267,10 +406,43 @@ func (l *RustFSAuditLogger) LogConfigChange(ctx context.Context, userID string,
 	l.logEvent(ctx, event)
 }
 
+// LogMaintenanceEvent logs a storage housekeeping event such as cleaning up dangling uploads
+func (l *RustFSAuditLogger) LogMaintenanceEvent(ctx context.Context, userID, operation string, details map[string]interface{}, err error) {
+	success := err == nil
+
+	auditMetadata := map[string]interface{}{
+		"operation": operation,
+		"service":   l.service,
+	}
+
+	for k, v := range details {
+		auditMetadata[k] = v
+	}
+
+	if err != nil {
+		auditMetadata["error"] = err.Error()
+	}
+
+	event := &audittypes.AuditEvent{
+		EventType: AuditEventStorageMaintenance,
+		UserID:    userID,
+		Resource:  "storage",
+		Action:    operation,
+		Success:   success,
+		Reason:    l.getReason(success, err),
+		Metadata:  auditMetadata,
+	}
+
+	l.logEvent(ctx, event)
+}
+
 // Helper methods
 
 func (l *RustFSAuditLogger) logEvent(ctx context.Context, event *audittypes.AuditEvent) {
 	if l.auditLogger != nil {
+		completeEvent(ctx, event, l.service)
+		// Metadata may carry caller-supplied values; bound it before it reaches a serializer
+		event.Metadata = SanitizeMetadata(event.Metadata, l.limits)
 		l.auditLogger.LogEvent(ctx, event)
 	}
 }
@@ -373,3 +545,12 @@ func (l *RustFSAuditLogger) GetService() string {
 func (l *RustFSAuditLogger) IsEnabled() bool {
 	return l.auditLogger != nil
 }
+
+// Close closes the wrapped logger when it holds resources, such as the queue and workers of an
+// AsyncAuditLogger, waiting for queued events to be written
+func (l *RustFSAuditLogger) Close() error {
+	if closer, ok := l.auditLogger.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package otelaudit records RustFS audit events as OpenTelemetry span events.
+//
+// It lives in its own package so that only services importing it take on the
+// OpenTelemetry dependency.
+package otelaudit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanEventAuditLogger implements audittypes.AuditLogger by adding each audit event as a
+// span event on the span carried by the context. Events logged without a recording span
+// are only forwarded to the optional next logger.
+type SpanEventAuditLogger struct {
+	service string
+	next    audittypes.AuditLogger
+}
+
+// NewSpanEventAuditLogger creates a span event audit logger. If next is non-nil, every
+// event is also forwarded to it, so tracing can be added alongside an existing audit sink.
+func NewSpanEventAuditLogger(service string, next audittypes.AuditLogger) *SpanEventAuditLogger {
+	return &SpanEventAuditLogger{
+		service: service,
+		next:    next,
+	}
+}
+
+// LogEvent implements audittypes.AuditLogger
+func (l *SpanEventAuditLogger) LogEvent(ctx context.Context, event *audittypes.AuditEvent) error {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Service == "" {
+		event.Service = l.service
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.AddEvent("audit."+string(event.EventType),
+			trace.WithTimestamp(event.Timestamp),
+			trace.WithAttributes(EventAttributes(event)...),
+		)
+	}
+
+	if l.next != nil {
+		return l.next.LogEvent(ctx, event)
+	}
+	return nil
+}
+
+// LogAuthEvent implements audittypes.AuditLogger
+func (l *SpanEventAuditLogger) LogAuthEvent(ctx context.Context, eventType audittypes.AuditEventType, userID, reason string, success bool, metadata map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Success:   success,
+		Reason:    reason,
+		Metadata:  metadata,
+	})
+}
+
+// LogAccessEvent implements audittypes.AuditLogger
+func (l *SpanEventAuditLogger) LogAccessEvent(ctx context.Context, userID, resource, action, resourceID string, success bool, reason string) error {
+	eventType := audittypes.AuditEventAccessGranted
+	if !success {
+		eventType = audittypes.AuditEventAccessDenied
+	}
+
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType:  eventType,
+		UserID:     userID,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Action:     action,
+		Success:    success,
+		Reason:     reason,
+	})
+}
+
+// LogSecurityEvent implements audittypes.AuditLogger
+func (l *SpanEventAuditLogger) LogSecurityEvent(ctx context.Context, eventType audittypes.AuditEventType, details map[string]interface{}) error {
+	return l.LogEvent(ctx, &audittypes.AuditEvent{
+		EventType: eventType,
+		Metadata:  details,
+	})
+}
+
+// EventAttributes maps an audit event onto span attributes. Top-level fields use the
+// "audit." namespace and metadata entries use "audit.metadata.<key>".
+func EventAttributes(event *audittypes.AuditEvent) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("audit.event_type", string(event.EventType)),
+		attribute.Bool("audit.success", event.Success),
+	}
+
+	addString := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, attribute.String(key, value))
+		}
+	}
+	addString("audit.id", event.ID)
+	addString("audit.service", event.Service)
+	addString("audit.user_id", event.UserID)
+	addString("audit.resource", event.Resource)
+	addString("audit.resource_id", event.ResourceID)
+	addString("audit.action", event.Action)
+	addString("audit.reason", event.Reason)
+	addString("audit.ip_address", event.IPAddress)
+	addString("audit.user_agent", event.UserAgent)
+	addString("audit.request_id", event.RequestID)
+
+	for k, v := range event.Metadata {
+		attrs = append(attrs, metadataAttribute("audit.metadata."+k, v))
+	}
+
+	return attrs
+}
+
+// metadataAttribute converts a metadata value into a typed attribute, falling back to JSON
+func metadataAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return attribute.String(key, fmt.Sprintf("%v", value))
+	}
+	return attribute.String(key, string(encoded))
+}
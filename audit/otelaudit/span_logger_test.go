@@ -0,0 +1,122 @@
+package otelaudit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// spanEvent is an event added to a recordingSpan
+type spanEvent struct {
+	name       string
+	timestamp  time.Time
+	attributes map[attribute.Key]attribute.Value
+}
+
+// recordingSpan keeps its events in memory, standing in for an SDK span so the package
+// doesn't depend on the OpenTelemetry SDK
+type recordingSpan struct {
+	noop.Span
+
+	mu     sync.Mutex
+	events []spanEvent
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	config := trace.NewEventConfig(options...)
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range config.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, spanEvent{name: name, timestamp: config.Timestamp(), attributes: attributes})
+}
+
+// countingLogger counts the events forwarded to it; only LogEvent may be called
+type countingLogger struct {
+	audittypes.AuditLogger
+	forwarded int
+}
+
+func (l *countingLogger) LogEvent(ctx context.Context, event *audittypes.AuditEvent) error {
+	l.forwarded++
+	return nil
+}
+
+func TestSpanEventAuditLogger(t *testing.T) {
+	next := &countingLogger{}
+	logger := NewSpanEventAuditLogger("media", next)
+
+	span := &recordingSpan{}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	timestamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	err := logger.LogEvent(ctx, &audittypes.AuditEvent{
+		ID:         "event-1",
+		Timestamp:  timestamp,
+		EventType:  "file_uploaded",
+		UserID:     "alice",
+		ResourceID: "photos/cat.jpg",
+		Success:    true,
+		Metadata: map[string]interface{}{
+			"file_size": int64(2048),
+			"tags":      []string{"pets"},
+			"nested":    map[string]interface{}{"a": 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("LogEvent: %v", err)
+	}
+
+	if len(span.events) != 1 {
+		t.Fatalf("span has %d events, want 1", len(span.events))
+	}
+	event := span.events[0]
+	if event.name != "audit.file_uploaded" {
+		t.Errorf("event name = %q, want audit.file_uploaded", event.name)
+	}
+	if !event.timestamp.Equal(timestamp) {
+		t.Errorf("event timestamp = %v, want the audit timestamp %v", event.timestamp, timestamp)
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"audit.id":                 attribute.StringValue("event-1"),
+		"audit.service":            attribute.StringValue("media"),
+		"audit.event_type":         attribute.StringValue("file_uploaded"),
+		"audit.user_id":            attribute.StringValue("alice"),
+		"audit.resource_id":        attribute.StringValue("photos/cat.jpg"),
+		"audit.success":            attribute.BoolValue(true),
+		"audit.metadata.file_size": attribute.Int64Value(2048),
+		"audit.metadata.tags":      attribute.StringSliceValue([]string{"pets"}),
+		"audit.metadata.nested":    attribute.StringValue(`{"a":1}`),
+	}
+	for key, value := range want {
+		if got, ok := event.attributes[key]; !ok || got != value {
+			t.Errorf("attribute %s = %v, want %v", key, got.Emit(), value.Emit())
+		}
+	}
+	if _, ok := event.attributes["audit.reason"]; ok {
+		t.Errorf("empty reason was recorded as an attribute")
+	}
+	if next.forwarded != 1 {
+		t.Errorf("event forwarded %d times, want 1", next.forwarded)
+	}
+
+	// Without a recording span the event only reaches the next logger
+	if err := logger.LogEvent(context.Background(), &audittypes.AuditEvent{EventType: "file_deleted"}); err != nil {
+		t.Fatalf("LogEvent: %v", err)
+	}
+	if len(span.events) != 1 || next.forwarded != 2 {
+		t.Errorf("span has %d events and %d were forwarded, want 1 and 2", len(span.events), next.forwarded)
+	}
+}
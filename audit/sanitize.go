@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+	"unicode/utf8"
+)
+
+// MetadataLimits bounds the shape of audit metadata so that downstream serialization
+// cannot blow up on deeply nested, self-referential, or very large values
+type MetadataLimits struct {
+	// MaxDepth is the maximum nesting depth of maps and slices; deeper values are replaced by a marker
+	MaxDepth int `json:"max_depth"`
+	// MaxValueLength is the maximum length of a string value; longer values are truncated
+	MaxValueLength int `json:"max_value_length"`
+	// MaxEntries is the maximum number of entries kept per map or slice
+	MaxEntries int `json:"max_entries"`
+}
+
+const (
+	truncatedDepthMarker = "[truncated: max depth exceeded]"
+	circularMarker       = "[circular reference]"
+	truncatedSuffix      = "...[truncated]"
+)
+
+// DefaultMetadataLimits returns the default audit metadata limits
+func DefaultMetadataLimits() MetadataLimits {
+	return MetadataLimits{
+		MaxDepth:       5,
+		MaxValueLength: 1024,
+		MaxEntries:     100,
+	}
+}
+
+// SanitizeMetadata returns a JSON-safe copy of metadata bounded by limits. Nested maps and
+// slices are copied up to MaxDepth, cycles are replaced by a marker, long strings are truncated,
+// and values of unknown types are stringified.
+func SanitizeMetadata(metadata map[string]interface{}, limits MetadataLimits) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	s := &sanitizer{
+		limits:  limits,
+		visited: make(map[uintptr]bool),
+	}
+	return s.sanitizeMap(reflect.ValueOf(metadata), 1)
+}
+
+type sanitizer struct {
+	limits  MetadataLimits
+	visited map[uintptr]bool
+}
+
+func (s *sanitizer) sanitizeValue(value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return s.truncate(v)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return s.truncate(v.Error())
+	case json.RawMessage:
+		return s.truncate(string(v))
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return s.truncate(fmt.Sprintf("[unsupported map key type %s]", rv.Type().Key()))
+		}
+		if depth >= s.limits.MaxDepth {
+			return truncatedDepthMarker
+		}
+		return s.sanitizeMap(rv, depth+1)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return s.truncate(string(rv.Bytes()))
+		}
+		if depth >= s.limits.MaxDepth {
+			return truncatedDepthMarker
+		}
+		return s.sanitizeSlice(rv, depth+1)
+	case reflect.String:
+		return s.truncate(rv.String())
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+
+	if stringer, ok := value.(fmt.Stringer); ok {
+		return s.truncate(stringer.String())
+	}
+
+	// Structs and other values: stringify through JSON, which cannot recurse through
+	// unexported fields, falling back to the type name
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("[unserializable %T]", value)
+	}
+	return s.truncate(string(encoded))
+}
+
+func (s *sanitizer) sanitizeMap(rv reflect.Value, depth int) map[string]interface{} {
+	if rv.IsNil() {
+		return nil
+	}
+
+	ptr := rv.Pointer()
+	if s.visited[ptr] {
+		return map[string]interface{}{"_": circularMarker}
+	}
+	s.visited[ptr] = true
+	defer delete(s.visited, ptr)
+
+	result := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		if s.limits.MaxEntries > 0 && len(result) >= s.limits.MaxEntries {
+			result["_truncated_entries"] = rv.Len() - s.limits.MaxEntries
+			break
+		}
+		result[iter.Key().String()] = s.sanitizeValue(iter.Value().Interface(), depth)
+	}
+	return result
+}
+
+func (s *sanitizer) sanitizeSlice(rv reflect.Value, depth int) []interface{} {
+	if rv.Kind() == reflect.Slice {
+		if rv.IsNil() {
+			return nil
+		}
+		ptr := rv.Pointer()
+		if ptr != 0 && s.visited[ptr] {
+			return []interface{}{circularMarker}
+		}
+		if ptr != 0 {
+			s.visited[ptr] = true
+			defer delete(s.visited, ptr)
+		}
+	}
+
+	n := rv.Len()
+	if s.limits.MaxEntries > 0 && n > s.limits.MaxEntries {
+		n = s.limits.MaxEntries
+	}
+
+	result := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		result = append(result, s.sanitizeValue(rv.Index(i).Interface(), depth))
+	}
+	return result
+}
+
+// truncate cuts value to at most MaxValueLength bytes, backing off to a rune boundary so a
+// multi-byte character is never split
+func (s *sanitizer) truncate(value string) string {
+	if s.limits.MaxValueLength <= 0 || len(value) <= s.limits.MaxValueLength {
+		return value
+	}
+
+	cut := s.limits.MaxValueLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + truncatedSuffix
+}
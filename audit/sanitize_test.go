@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeMetadataTruncate(t *testing.T) {
+	limits := MetadataLimits{MaxDepth: 5, MaxValueLength: 8, MaxEntries: 100}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"short value", "caption", "caption"},
+		{"exactly at the limit", "12345678", "12345678"},
+		{"ascii cut", "0123456789", "01234567" + truncatedSuffix},
+		// "é" is two bytes starting at byte 7, so the cut backs off before it
+		{"two-byte rune across the limit", "abcdefgé", "abcdefg" + truncatedSuffix},
+		// "日" is three bytes starting at byte 6
+		{"three-byte rune across the limit", "abcdef日本", "abcdef" + truncatedSuffix},
+		{"four-byte rune across the limit", "abcde🎉🎉", "abcde" + truncatedSuffix},
+		{"rune ending at the limit", "abcde日x", "abcde日" + truncatedSuffix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeMetadata(map[string]interface{}{"value": tt.value}, limits)["value"].(string)
+			if got != tt.want {
+				t.Errorf("truncated to %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncated value %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestSanitizeMetadataNestedAndLarge(t *testing.T) {
+	limits := MetadataLimits{MaxDepth: 3, MaxValueLength: 16, MaxEntries: 4}
+	long := strings.Repeat("ü", 100)
+
+	items := make([]interface{}, 10)
+	for i := range items {
+		items[i] = long
+	}
+	large := make(map[string]interface{}, 10)
+	for i := 0; i < 10; i++ {
+		large[strings.Repeat("k", i+1)] = i
+	}
+	metadata := map[string]interface{}{
+		"nested": map[string]interface{}{
+			"inner": map[string]interface{}{
+				"deepest": map[string]interface{}{"value": "lost"},
+				"note":    long,
+			},
+		},
+		"items": items,
+		"large": large,
+	}
+
+	sanitized := SanitizeMetadata(metadata, limits)
+	if _, err := json.Marshal(sanitized); err != nil {
+		t.Fatalf("sanitized metadata doesn't serialize: %v", err)
+	}
+
+	inner := sanitized["nested"].(map[string]interface{})["inner"].(map[string]interface{})
+	if inner["deepest"] != truncatedDepthMarker {
+		t.Errorf("value past MaxDepth = %v, want the depth marker", inner["deepest"])
+	}
+	note := inner["note"].(string)
+	if !utf8.ValidString(note) || note != strings.Repeat("ü", 8)+truncatedSuffix {
+		t.Errorf("nested long value = %q, want 8 whole runes and the suffix", note)
+	}
+
+	list := sanitized["items"].([]interface{})
+	if len(list) != limits.MaxEntries {
+		t.Errorf("slice kept %d entries, want %d", len(list), limits.MaxEntries)
+	}
+	for i, item := range list {
+		if s := item.(string); !utf8.ValidString(s) || !strings.HasSuffix(s, truncatedSuffix) {
+			t.Errorf("item %d = %q, want a valid truncated string", i, s)
+		}
+	}
+
+	kept := sanitized["large"].(map[string]interface{})
+	if kept["_truncated_entries"] != 6 || len(kept) != limits.MaxEntries+1 {
+		t.Errorf("large map = %v, want %d entries and a count of the 6 dropped", kept, limits.MaxEntries)
+	}
+}
@@ -2,11 +2,15 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"mime/multipart"
 	"time"
 
 	"github.com/garyjdn/go-apperror"
+	audittypes "github.com/garyjdn/go-auditlogger/types"
 	"github.com/garyjdn/go-rustfs/audit"
 	"github.com/garyjdn/go-rustfs/config"
 	"github.com/garyjdn/go-rustfs/types"
@@ -38,6 +42,26 @@ func (c *AuditableRustFSClient) UploadFile(ctx context.Context, req *types.Uploa
 
 // UploadFileWithAudit uploads a file with audit logging
 func (c *AuditableRustFSClient) UploadFileWithAudit(ctx context.Context, req *types.UploadRequest, userID string) (*types.UploadResponse, error) {
+	return c.uploadWithAudit(ctx, req, userID, c.client.UploadFile)
+}
+
+// UploadFileWithOptionsAudit uploads a file with options and audit logging. Compressed uploads
+// record the achieved compression ratio in the audit metadata.
+func (c *AuditableRustFSClient) UploadFileWithOptionsAudit(ctx context.Context, req *types.UploadRequest, opts *UploadOptions, userID string) (*types.UploadResponse, error) {
+	uploader, ok := c.client.(interface {
+		UploadFileWithOptions(ctx context.Context, req *types.UploadRequest, opts *UploadOptions) (*types.UploadResponse, error)
+	})
+	if !ok {
+		closeUploadSource(req)
+		return nil, newSentinelError(501, "UNSUPPORTED_OPERATION", ErrUnsupportedOperation, fmt.Errorf("upload options"))
+	}
+	return c.uploadWithAudit(ctx, req, userID, func(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+		return uploader.UploadFileWithOptions(ctx, req, opts)
+	})
+}
+
+// uploadWithAudit validates req, uploads it with upload and logs the outcome
+func (c *AuditableRustFSClient) uploadWithAudit(ctx context.Context, req *types.UploadRequest, userID string, upload func(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error)) (*types.UploadResponse, error) {
 	startTime := time.Now()
 
 	// Pre-upload audit metadata
@@ -47,17 +71,21 @@ func (c *AuditableRustFSClient) UploadFileWithAudit(ctx context.Context, req *ty
 		ContentType: req.ContentType,
 		FilePath:    req.BucketPath,
 		BucketName:  c.config.BucketName,
-		Additional:  req.Metadata,
+		// A copy, so the upload details logged later neither land in req.Metadata nor write to a nil map
+		Additional: make(map[string]interface{}, len(req.Metadata)),
 	}
+	maps.Copy(preUploadMetadata.Additional, req.Metadata)
 
 	// Validate file before upload
 	if err := c.validateUploadRequest(req); err != nil {
+		// The wrapped client never sees the request, so release a source it would have owned
+		closeUploadSource(req)
 		c.logUploadError(ctx, userID, preUploadMetadata, err, startTime)
 		return nil, c.wrapError(err, "VALIDATION_ERROR")
 	}
 
 	// Execute upload
-	result, err := c.client.UploadFile(ctx, req)
+	result, err := upload(ctx, req)
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -65,20 +93,21 @@ func (c *AuditableRustFSClient) UploadFileWithAudit(ctx context.Context, req *ty
 		return nil, c.wrapError(err, "UPLOAD_FAILED")
 	}
 
-	// Log successful upload
-	c.logUploadSuccess(ctx, userID, preUploadMetadata, result, duration)
-
-	// Log performance if upload is slow
+	// Flag the upload as slow past the timeout
+	var slow *audit.PerformanceEventMetadata
 	if duration > c.config.Timeout {
-		c.auditLogger.LogPerformanceEvent(ctx, userID, audit.AuditEventUploadSlow, &audit.PerformanceEventMetadata{
+		slow = &audit.PerformanceEventMetadata{
 			Operation:  "upload",
 			Duration:   duration.String(),
 			FileSize:   req.FileSize,
 			Throughput: c.calculateThroughput(req.FileSize, duration),
 			Threshold:  float64(c.config.Timeout.Milliseconds()),
-		})
+		}
 	}
 
+	// Log successful upload
+	c.logUploadSuccess(ctx, userID, preUploadMetadata, result, duration, slow)
+
 	return result, nil
 }
 
@@ -103,25 +132,40 @@ func (c *AuditableRustFSClient) DeleteFileWithAudit(ctx context.Context, path, u
 	duration := time.Since(startTime)
 
 	if err != nil {
-		c.auditLogger.LogFileDelete(ctx, userID, path, preDeleteMetadata, err)
+		c.logDelete(ctx, userID, path, preDeleteMetadata, duration, err)
 		return c.wrapError(err, "DELETE_FAILED")
 	}
 
 	// Log successful deletion
-	c.auditLogger.LogFileDelete(ctx, userID, path, preDeleteMetadata, nil)
+	c.logDelete(ctx, userID, path, preDeleteMetadata, duration, nil)
 
-	// Log performance if delete is slow
-	if duration > c.config.Timeout/2 { // Half of upload timeout for delete
-		c.auditLogger.LogPerformanceEvent(ctx, userID, audit.AuditEventUploadSlow, &audit.PerformanceEventMetadata{
-			Operation:  "delete",
-			Duration:   duration.String(),
-			FileSize:   0,
-			Throughput: 0,
-			Threshold:  float64((c.config.Timeout / 2).Milliseconds()),
-		})
+	return nil
+}
+
+// DeleteFileResultWithAudit deletes a file with audit logging and reports whether it existed
+func (c *AuditableRustFSClient) DeleteFileResultWithAudit(ctx context.Context, path, userID string) (bool, error) {
+	reporter, ok := c.client.(DeleteResultReporter)
+	if !ok {
+		return false, fmt.Errorf("client does not support delete results")
 	}
 
-	return nil
+	metadata := &audit.FileOperationMetadata{
+		FilePath:   path,
+		BucketName: c.config.BucketName,
+		AccessTime: time.Now().Format(time.RFC3339),
+	}
+
+	deleted, err := reporter.DeleteFileResult(ctx, path)
+	metadata.Additional = map[string]interface{}{
+		"deleted": deleted,
+	}
+
+	c.auditLogger.LogFileDelete(ctx, userID, path, metadata, err)
+	if err != nil {
+		return false, c.wrapError(err, "DELETE_FAILED")
+	}
+
+	return deleted, nil
 }
 
 // GetFileURL implements FileStorage interface
@@ -132,7 +176,10 @@ func (c *AuditableRustFSClient) GetFileURL(path string) string {
 // GetFileInfo implements FileStorage interface
 func (c *AuditableRustFSClient) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
 	startTime := time.Now()
-	userID := c.extractUserID(ctx)
+	userID, err := c.resolveUserID(ctx, "get_info", path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Pre-access audit metadata
 	preAccessMetadata := &audit.FileOperationMetadata{
@@ -167,8 +214,269 @@ func (c *AuditableRustFSClient) GetFileInfo(ctx context.Context, path string) (*
 	return result, nil
 }
 
+// UpdateMetadata updates a file's metadata in place with audit logging. The file_updated event
+// carries a diff of the old and new metadata.
+func (c *AuditableRustFSClient) UpdateMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) (*types.FileInfo, error) {
+	updater, ok := c.client.(MetadataUpdater)
+	if !ok {
+		return nil, fmt.Errorf("client does not support metadata updates")
+	}
+
+	userID, err := c.resolveUserID(ctx, "update_metadata", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The old metadata only feeds the diff, so an update isn't blocked by failing to read it
+	var old map[string]interface{}
+	if current, err := c.client.GetFileInfo(ctx, path); err == nil {
+		old = current.Metadata
+	}
+
+	updateMetadata := &audit.FileOperationMetadata{
+		FilePath:   path,
+		BucketName: c.config.BucketName,
+		AccessTime: time.Now().Format(time.RFC3339),
+		Additional: map[string]interface{}{
+			"operation":     "update_metadata",
+			"replace":       replace,
+			"metadata_keys": len(metadata),
+		},
+	}
+
+	result, err := updater.UpdateMetadata(ctx, path, metadata, replace)
+	if err != nil {
+		c.auditLogger.LogFileUpdate(ctx, userID, path, updateMetadata, err)
+		return nil, c.wrapError(err, "UPDATE_METADATA_FAILED")
+	}
+
+	updateMetadata.ETag = result.ETag
+	updateMetadata.Additional["metadata_diff"] = metadataDiff(old, result.Metadata)
+	c.auditLogger.LogFileUpdate(ctx, userID, path, updateMetadata, nil)
+
+	return result, nil
+}
+
+// DownloadFile streams a file with audit logging. The caller is responsible for closing the returned reader.
+func (c *AuditableRustFSClient) DownloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	downloader, ok := c.client.(Downloader)
+	if !ok {
+		return nil, nil, fmt.Errorf("client does not support downloads")
+	}
+
+	userID, err := c.resolveUserID(ctx, "download", path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	downloadMetadata := &audit.FileOperationMetadata{
+		FilePath:     path,
+		BucketName:   c.config.BucketName,
+		DownloadTime: time.Now().Format(time.RFC3339),
+	}
+
+	body, info, err := downloader.DownloadFile(ctx, path)
+	if err != nil {
+		c.auditLogger.LogFileDownload(ctx, userID, path, downloadMetadata, err)
+		return nil, nil, c.wrapError(err, "DOWNLOAD_FAILED")
+	}
+
+	downloadMetadata.FileSize = info.Size
+	downloadMetadata.ContentType = info.ContentType
+	downloadMetadata.ETag = info.ETag
+	c.auditLogger.LogFileDownload(ctx, userID, path, downloadMetadata, nil)
+
+	return body, info, nil
+}
+
+// BatchUpload uploads requests concurrently, auditing each upload, and logs the aggregate
+// throughput of the batch as a performance event. Bytes are counted from each completed
+// upload's size, so sources reach the client unwrapped and keep their parallel upload paths.
+func (c *AuditableRustFSClient) BatchUpload(ctx context.Context, requests []*types.UploadRequest) ([]*types.UploadResponse, error) {
+	userID, err := c.resolveUserID(ctx, "batch_upload", "")
+	if err != nil {
+		for _, req := range requests {
+			closeUploadSource(req)
+		}
+		return nil, err
+	}
+
+	meter := utils.NewThroughputMeter(utils.DefaultThroughputWindow)
+	meter.Start()
+	responses, err := batchUploadRequests(ctx, func(ctx context.Context, req *types.UploadRequest, _ *UploadOptions) (*types.UploadResponse, error) {
+		response, err := c.UploadFileWithAudit(ctx, req, userID)
+		if err == nil {
+			meter.Add(response.Size)
+		}
+		return response, err
+	}, requests, c.config.ConcurrentUploads)
+	meter.Stop()
+
+	failed := 0
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		failed = len(batchErr.Failures)
+	}
+
+	stats := meter.Stats()
+	c.auditLogger.LogPerformanceEvent(ctx, userID, audit.AuditEventBatchThroughput, &audit.PerformanceEventMetadata{
+		Operation:   "batch_upload",
+		Duration:    stats.Duration.String(),
+		FileSize:    stats.TotalBytes,
+		Throughput:  stats.AggregateMBps,
+		Concurrency: c.config.ConcurrentUploads,
+		Additional: map[string]interface{}{
+			"peak_throughput_mbps": stats.PeakMBps,
+			"item_count":           len(requests),
+			"failed_count":         failed,
+		},
+	})
+
+	return responses, err
+}
+
+// BatchDelete deletes paths concurrently, auditing each delete. Failures are reported together
+// in a *BatchError.
+func (c *AuditableRustFSClient) BatchDelete(ctx context.Context, paths []string) error {
+	userID, err := c.resolveUserID(ctx, "batch_delete", "")
+	if err != nil {
+		return err
+	}
+
+	return batchDelete(ctx, func(ctx context.Context, path string) error {
+		return c.DeleteFileWithAudit(ctx, path, userID)
+	}, paths, c.config.ConcurrentUploads)
+}
+
+// MoveFile moves an object and logs a file_moved event carrying the old and new paths
+func (c *AuditableRustFSClient) MoveFile(ctx context.Context, sourcePath, destPath string) error {
+	mover, ok := c.client.(Mover)
+	if !ok {
+		return fmt.Errorf("client does not support moves")
+	}
+
+	userID, err := c.resolveUserID(ctx, "move", destPath)
+	if err != nil {
+		return err
+	}
+
+	err = mover.MoveFile(ctx, sourcePath, destPath)
+	c.auditLogger.LogFileMove(ctx, userID, sourcePath, destPath, &audit.FileOperationMetadata{
+		FilePath:   destPath,
+		BucketName: c.config.BucketName,
+	}, err)
+	if err != nil {
+		return c.wrapError(err, "MOVE_FAILED")
+	}
+	return nil
+}
+
+// CopyFile copies an object server-side and logs a file_copied event carrying the source and
+// destination paths
+func (c *AuditableRustFSClient) CopyFile(ctx context.Context, sourcePath, destPath string) error {
+	copier, ok := c.client.(Copier)
+	if !ok {
+		return fmt.Errorf("client does not support copies")
+	}
+	return c.copyWithAudit(ctx, sourcePath, destPath, MetadataDirectiveCopy, func() error {
+		return copier.CopyFile(ctx, sourcePath, destPath)
+	})
+}
+
+// CopyFileWithOptions copies an object server-side with opts and logs a file_copied event
+// carrying the source and destination paths
+func (c *AuditableRustFSClient) CopyFileWithOptions(ctx context.Context, sourcePath, destPath string, opts *CopyOptions) error {
+	copier, ok := c.client.(OptionsCopier)
+	if !ok {
+		return fmt.Errorf("client does not support copy options")
+	}
+	normalized, err := normalizeCopyOptions(opts)
+	if err != nil {
+		return err
+	}
+	return c.copyWithAudit(ctx, sourcePath, destPath, normalized.MetadataDirective, func() error {
+		return copier.CopyFileWithOptions(ctx, sourcePath, destPath, &normalized)
+	})
+}
+
+// copyWithAudit runs copyFile and logs its outcome
+func (c *AuditableRustFSClient) copyWithAudit(ctx context.Context, sourcePath, destPath, directive string, copyFile func() error) error {
+	userID, err := c.resolveUserID(ctx, "copy", destPath)
+	if err != nil {
+		return err
+	}
+
+	err = copyFile()
+	c.auditLogger.LogFileCopy(ctx, userID, sourcePath, destPath, &audit.FileOperationMetadata{
+		FilePath:   destPath,
+		BucketName: c.config.BucketName,
+		Additional: map[string]interface{}{
+			"operation":          "copy",
+			"metadata_directive": directive,
+		},
+	}, err)
+	if err != nil {
+		return c.wrapError(err, "COPY_FAILED")
+	}
+	return nil
+}
+
+// BatchCopy copies objects server-side, logging a copy event per pair and a batch summary
+func (c *AuditableRustFSClient) BatchCopy(ctx context.Context, copies []FileCopy) (map[string]error, error) {
+	copier, ok := c.client.(BatchCopier)
+	if !ok {
+		return nil, fmt.Errorf("client does not support batch copies")
+	}
+
+	userID, err := c.resolveUserID(ctx, "batch_copy", "")
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	results, err := copier.BatchCopy(ctx, copies)
+
+	failed := 0
+	if err == nil {
+		for _, item := range copies {
+			copyErr, ok := results[item.DestPath]
+			if !ok {
+				continue
+			}
+			if copyErr != nil {
+				failed++
+			}
+			c.auditLogger.LogFileCopy(ctx, userID, item.SourcePath, item.DestPath, &audit.FileOperationMetadata{
+				FilePath:   item.DestPath,
+				BucketName: c.config.BucketName,
+				Additional: map[string]interface{}{
+					"operation": "batch_copy",
+				},
+			}, copyErr)
+		}
+	}
+
+	c.auditLogger.LogMaintenanceEvent(ctx, userID, "batch_copy", map[string]interface{}{
+		"copy_count":   len(copies),
+		"failed_count": failed,
+		"bucket_name":  c.config.BucketName,
+		"duration":     time.Since(startTime).String(),
+	}, err)
+
+	if err != nil {
+		return nil, c.wrapError(err, "BATCH_COPY_FAILED")
+	}
+
+	return results, nil
+}
+
 // UploadSnapshot implements SnapshotStorage interface
 func (c *AuditableRustFSClient) UploadSnapshot(ctx context.Context, file multipart.File, header *multipart.FileHeader) (string, error) {
+	userID, err := c.resolveUserID(ctx, "upload_snapshot", header.Filename)
+	if err != nil {
+		return "", err
+	}
+
 	// Read file info
 	fileInfo, err := header.Open()
 	if err != nil {
@@ -190,7 +498,7 @@ func (c *AuditableRustFSClient) UploadSnapshot(ctx context.Context, file multipa
 	}
 
 	// Upload with audit
-	result, err := c.UploadFileWithAudit(ctx, req, c.extractUserID(ctx))
+	result, err := c.UploadFileWithAudit(ctx, req, userID)
 	if err != nil {
 		return "", err
 	}
@@ -200,7 +508,12 @@ func (c *AuditableRustFSClient) UploadSnapshot(ctx context.Context, file multipa
 
 // DeleteSnapshot implements SnapshotStorage interface
 func (c *AuditableRustFSClient) DeleteSnapshot(ctx context.Context, path string) error {
-	return c.DeleteFileWithAudit(ctx, path, c.extractUserID(ctx))
+	userID, err := c.resolveUserID(ctx, "delete_snapshot", path)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteFileWithAudit(ctx, path, userID)
 }
 
 // GetSnapshotURL implements SnapshotStorage interface
@@ -208,6 +521,50 @@ func (c *AuditableRustFSClient) GetSnapshotURL(path string) string {
 	return c.GetFileURL(path)
 }
 
+// ListIncompleteUploads lists dangling multipart uploads if the underlying client supports it
+func (c *AuditableRustFSClient) ListIncompleteUploads(ctx context.Context, prefix string) ([]types.IncompleteUpload, error) {
+	maintainer, ok := c.client.(MultipartMaintenance)
+	if !ok {
+		return nil, fmt.Errorf("client does not support multipart maintenance")
+	}
+
+	uploads, err := maintainer.ListIncompleteUploads(ctx, prefix)
+	if err != nil {
+		return nil, c.wrapError(err, "LIST_MULTIPART_FAILED")
+	}
+
+	return uploads, nil
+}
+
+// AbortIncompleteUploads aborts dangling multipart uploads older than olderThan and audits the cleanup
+func (c *AuditableRustFSClient) AbortIncompleteUploads(ctx context.Context, olderThan time.Duration) (int, error) {
+	maintainer, ok := c.client.(MultipartMaintenance)
+	if !ok {
+		return 0, fmt.Errorf("client does not support multipart maintenance")
+	}
+
+	userID, err := c.resolveUserID(ctx, "abort_incomplete_uploads", "")
+	if err != nil {
+		return 0, err
+	}
+
+	startTime := time.Now()
+	aborted, err := maintainer.AbortIncompleteUploads(ctx, olderThan)
+
+	c.auditLogger.LogMaintenanceEvent(ctx, userID, "abort_incomplete_uploads", map[string]interface{}{
+		"aborted_count": aborted,
+		"older_than":    olderThan.String(),
+		"bucket_name":   c.config.BucketName,
+		"duration":      time.Since(startTime).String(),
+	}, err)
+
+	if err != nil {
+		return aborted, c.wrapError(err, "ABORT_MULTIPART_FAILED")
+	}
+
+	return aborted, nil
+}
+
 // Helper methods
 
 func (c *AuditableRustFSClient) validateUploadRequest(req *types.UploadRequest) error {
@@ -229,17 +586,88 @@ func (c *AuditableRustFSClient) validateUploadRequest(req *types.UploadRequest)
 	return nil
 }
 
-func (c *AuditableRustFSClient) logUploadSuccess(ctx context.Context, userID string, metadata *audit.FileOperationMetadata, result *types.UploadResponse, duration time.Duration) {
+// metadataDiff describes how metadata changed from old to updated: keys added with their
+// values, keys removed with their old values, and changed keys with both values
+func metadataDiff(old, updated map[string]interface{}) map[string]interface{} {
+	added := make(map[string]interface{})
+	removed := make(map[string]interface{})
+	changed := make(map[string]interface{})
+	for k, v := range updated {
+		previous, ok := old[k]
+		switch {
+		case !ok:
+			added[k] = v
+		case fmt.Sprint(previous) != fmt.Sprint(v):
+			changed[k] = map[string]interface{}{"old": previous, "new": v}
+		}
+	}
+	for k, v := range old {
+		if _, ok := updated[k]; !ok {
+			removed[k] = v
+		}
+	}
+	return map[string]interface{}{"added": added, "removed": removed, "changed": changed}
+}
+
+// combinedAudit reports whether each operation logs one combined event instead of one per signal
+func (c *AuditableRustFSClient) combinedAudit() bool {
+	return c.config.AuditEventMode == config.AuditEventModeCombined
+}
+
+// logUploadSuccess logs a completed upload and, when slow is set, the slow-upload signal
+func (c *AuditableRustFSClient) logUploadSuccess(ctx context.Context, userID string, metadata *audit.FileOperationMetadata, result *types.UploadResponse, duration time.Duration, slow *audit.PerformanceEventMetadata) {
 	// Update metadata with result info
 	metadata.ETag = result.ETag
 	metadata.UploadTime = time.Now().Format(time.RFC3339)
 	metadata.Additional["upload_duration"] = duration.String()
 	metadata.Additional["upload_speed"] = c.calculateThroughput(result.Size, duration)
+	if result.UncompressedSize > 0 && result.Size > 0 {
+		metadata.Additional["compression_ratio"] = float64(result.UncompressedSize) / float64(result.Size)
+	}
+
+	if c.combinedAudit() {
+		record := &audit.OperationRecord{
+			Operation: "upload",
+			EventType: audit.AuditEventFileUploaded,
+			File:      metadata,
+			Performance: audit.PerformanceEventMetadata{
+				Operation:  "upload",
+				Duration:   duration.String(),
+				FileSize:   result.Size,
+				Throughput: c.calculateThroughput(result.Size, duration),
+			},
+		}
+		if slow != nil {
+			record.Performance.Threshold = slow.Threshold
+			record.Signals = []audittypes.AuditEventType{audit.AuditEventUploadSlow}
+		}
+		c.auditLogger.LogOperation(ctx, userID, record)
+		return
+	}
 
 	c.auditLogger.LogFileUpload(ctx, userID, metadata, nil)
+	if slow != nil {
+		c.auditLogger.LogPerformanceEvent(ctx, userID, audit.AuditEventUploadSlow, slow)
+	}
 }
 
 func (c *AuditableRustFSClient) logUploadError(ctx context.Context, userID string, metadata *audit.FileOperationMetadata, err error, startTime time.Time) {
+	if c.combinedAudit() {
+		c.auditLogger.LogOperation(ctx, userID, &audit.OperationRecord{
+			Operation: "upload",
+			EventType: audit.AuditEventFileUploaded,
+			File:      metadata,
+			Performance: audit.PerformanceEventMetadata{
+				Operation: "upload",
+				Duration:  time.Since(startTime).String(),
+				FileSize:  metadata.FileSize,
+			},
+			ErrorCode: "UPLOAD_ERROR",
+			Err:       err,
+		})
+		return
+	}
+
 	// Create storage error metadata
 	storageErrorMetadata := &audit.StorageErrorMetadata{
 		Operation:    "upload",
@@ -258,6 +686,46 @@ func (c *AuditableRustFSClient) logUploadError(ctx context.Context, userID strin
 	c.auditLogger.LogStorageError(ctx, userID, "upload", storageErrorMetadata)
 }
 
+// logDelete logs a delete and, in multi-event mode, a separate event when it was slow. Deletes
+// are slow past half the upload timeout.
+func (c *AuditableRustFSClient) logDelete(ctx context.Context, userID, path string, metadata *audit.FileOperationMetadata, duration time.Duration, err error) {
+	threshold := c.config.Timeout / 2
+	slow := err == nil && duration > threshold
+
+	if c.combinedAudit() {
+		record := &audit.OperationRecord{
+			Operation: "delete",
+			EventType: audit.AuditEventFileDeleted,
+			File:      metadata,
+			Performance: audit.PerformanceEventMetadata{
+				Operation: "delete",
+				Duration:  duration.String(),
+			},
+			Err: err,
+		}
+		if err != nil {
+			record.ErrorCode = "DELETE_ERROR"
+		}
+		if slow {
+			record.Performance.Threshold = float64(threshold.Milliseconds())
+			record.Signals = []audittypes.AuditEventType{audit.AuditEventUploadSlow}
+		}
+		c.auditLogger.LogOperation(ctx, userID, record)
+		return
+	}
+
+	c.auditLogger.LogFileDelete(ctx, userID, path, metadata, err)
+	if slow {
+		c.auditLogger.LogPerformanceEvent(ctx, userID, audit.AuditEventUploadSlow, &audit.PerformanceEventMetadata{
+			Operation:  "delete",
+			Duration:   duration.String(),
+			FileSize:   0,
+			Throughput: 0,
+			Threshold:  float64(threshold.Milliseconds()),
+		})
+	}
+}
+
 func (c *AuditableRustFSClient) wrapError(err error, code string) *apperror.AppError {
 	if appErr, ok := err.(*apperror.AppError); ok {
 		return appErr
@@ -274,14 +742,41 @@ func (c *AuditableRustFSClient) calculateThroughput(bytes int64, duration time.D
 }
 
 func (c *AuditableRustFSClient) extractUserID(ctx context.Context) string {
-	if userID := ctx.Value("user_id"); userID != nil {
-		if id, ok := userID.(string); ok {
-			return id
-		}
+	if info, ok := audit.RequestInfoFromContext(ctx); ok && info.UserID != "" {
+		return info.UserID
+	}
+
+	if c.config.MissingUserPolicy == config.MissingUserPolicyAnonymous {
+		return c.config.AnonymousPrincipal
 	}
 	return "system"
 }
 
+// resolveUserID returns the acting user for an operation, applying the configured missing-user policy.
+// Under the reject policy an unauthenticated call is audited as unauthorized access and refused.
+func (c *AuditableRustFSClient) resolveUserID(ctx context.Context, operation, path string) (string, error) {
+	if info, ok := audit.RequestInfoFromContext(ctx); ok && info.UserID != "" {
+		return info.UserID, nil
+	}
+
+	if c.config.MissingUserPolicy != config.MissingUserPolicyReject {
+		return c.extractUserID(ctx), nil
+	}
+
+	c.auditLogger.LogSecurityEvent(ctx, "", audit.AuditEventUnauthorizedAccess, &audit.SecurityEventMetadata{
+		ThreatType:  "missing_user",
+		ThreatLevel: "medium",
+		Blocked:     true,
+		Action:      "rejected",
+		Additional: map[string]interface{}{
+			"operation": operation,
+			"file_path": path,
+		},
+	})
+
+	return "", newSentinelError(401, "UNAUTHENTICATED", ErrUnauthenticated, nil)
+}
+
 // GetConfig returns client configuration
 func (c *AuditableRustFSClient) GetConfig() *config.RustFSConfig {
 	return c.config
@@ -315,32 +810,154 @@ func (c *AuditableRustFSClient) Close() error {
 				"action":    "client_shutdown",
 				"service":   c.service,
 				"timestamp": time.Now().Format(time.RFC3339),
+				"config":    c.config.Redacted(),
 			})
+
+		if summarizer, ok := c.client.(ActivitySummarizer); ok {
+			c.auditLogger.LogMaintenanceEvent(context.Background(), c.extractUserID(context.Background()),
+				"client_summary", summarizer.Summary().ToMap(), nil)
+		}
+	}
+
+	// Write out queued audit events, including the shutdown events above
+	var auditErr error
+	if c.auditLogger != nil {
+		auditErr = c.auditLogger.Close()
 	}
 
 	// Close underlying client if it has a Close method
 	if closer, ok := c.client.(interface{ Close() error }); ok {
-		return closer.Close()
+		return errors.Join(auditErr, closer.Close())
 	}
 
-	return nil
+	return auditErr
 }
 
 // HealthCheck performs a health check on the storage client
 func (c *AuditableRustFSClient) HealthCheck(ctx context.Context) error {
-	// Try to get storage stats
 	if healthChecker, ok := c.client.(interface {
 		CheckHealth(ctx context.Context) error
 	}); ok {
 		return healthChecker.CheckHealth(ctx)
 	}
 
-	// Fallback: try to get file info for a non-existent file
-	_, err := c.client.GetFileInfo(ctx, "health-check-"+time.Now().Format("20060102"))
+	_, err := c.HealthReport(ctx)
+	return err
+}
+
+// HealthReport performs a health check and reports the storage capacity and usage of the
+// underlying client. Stats are nil, with a nil error, for clients that can't report them.
+func (c *AuditableRustFSClient) HealthReport(ctx context.Context) (*types.StorageStats, error) {
+	healthChecker, canCheck := c.client.(interface {
+		CheckHealth(ctx context.Context) error
+	})
+	if canCheck {
+		if err := healthChecker.CheckHealth(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetching storage stats also proves the server is reachable
+	if statsReader, ok := c.client.(interface {
+		GetStorageStats(ctx context.Context) (*types.StorageStats, error)
+	}); ok {
+		stats, err := statsReader.GetStorageStats(ctx)
+		if err != nil {
+			return nil, c.wrapError(err, "HEALTH_CHECK_FAILED")
+		}
+		return stats, nil
+	}
+	if canCheck {
+		return nil, nil
+	}
+
+	// Last resort: look up a file that doesn't exist; a clean "not found" proves the server answers
+	probe := "health-check-" + time.Now().Format("20060102")
+	if checker, ok := c.client.(ExistenceChecker); ok {
+		if _, err := checker.FileExists(ctx, probe); err != nil {
+			return nil, c.wrapError(err, "HEALTH_CHECK_FAILED")
+		}
+		return nil, nil
+	}
+	if _, err := c.client.GetFileInfo(ctx, probe); err != nil && !errors.Is(err, ErrFileNotFound) {
+		return nil, c.wrapError(err, "HEALTH_CHECK_FAILED")
+	}
+	return nil, nil
+}
+
+// FileExists reports whether an object is stored at path, if the underlying client supports it
+func (c *AuditableRustFSClient) FileExists(ctx context.Context, path string) (bool, error) {
+	checker, ok := c.client.(ExistenceChecker)
+	if !ok {
+		return false, fmt.Errorf("client does not support existence checks")
+	}
+	return checker.FileExists(ctx, path)
+}
+
+// DownloadRange streams a byte window of a file and logs the download
+func (c *AuditableRustFSClient) DownloadRange(ctx context.Context, path string, start, end int64) (io.ReadCloser, error) {
+	downloader, ok := c.client.(RangeDownloader)
+	if !ok {
+		return nil, fmt.Errorf("client does not support range downloads")
+	}
+
+	userID, err := c.resolveUserID(ctx, "download", path)
 	if err != nil {
-		// Expected error for non-existent file is OK
-		return nil
+		return nil, err
 	}
 
-	return nil
+	downloadMetadata := &audit.FileOperationMetadata{
+		FilePath:     path,
+		BucketName:   c.config.BucketName,
+		DownloadTime: time.Now().Format(time.RFC3339),
+		Additional: map[string]interface{}{
+			"range": fmt.Sprintf("bytes=%d-%d", start, end),
+		},
+	}
+
+	body, err := downloader.DownloadRange(ctx, path, start, end)
+	c.auditLogger.LogFileDownload(ctx, userID, path, downloadMetadata, err)
+	if err != nil {
+		return nil, c.wrapError(err, "DOWNLOAD_FAILED")
+	}
+	return body, nil
+}
+
+// GetStorageStats reports the storage usage and capacity of the underlying client
+func (c *AuditableRustFSClient) GetStorageStats(ctx context.Context) (*types.StorageStats, error) {
+	statsReader, ok := c.client.(interface {
+		GetStorageStats(ctx context.Context) (*types.StorageStats, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("client does not support storage stats")
+	}
+
+	stats, err := statsReader.GetStorageStats(ctx)
+	if err != nil {
+		return nil, c.wrapError(err, "STATS_FAILED")
+	}
+	return stats, nil
+}
+
+// Ping performs a lightweight liveness check on the storage client
+func (c *AuditableRustFSClient) Ping(ctx context.Context) error {
+	if pinger, ok := c.client.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return c.HealthCheck(ctx)
+}
+
+// CancelAll aborts every in-flight operation of the underlying client
+func (c *AuditableRustFSClient) CancelAll() {
+	if canceller, ok := c.client.(Canceller); ok {
+		canceller.CancelAll()
+	}
+}
+
+// ResetCancellation re-enables the underlying client after CancelAll
+func (c *AuditableRustFSClient) ResetCancellation() {
+	if canceller, ok := c.client.(Canceller); ok {
+		canceller.ResetCancellation()
+	}
 }
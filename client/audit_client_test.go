@@ -0,0 +1,414 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"github.com/garyjdn/go-rustfs/audit"
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestHealthReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		storage   func(t *testing.T) FileStorage
+		wantFiles int64
+		wantSize  int64
+	}{
+		{
+			name: "mock",
+			storage: func(t *testing.T) FileStorage {
+				return NewMockRustFSClientBuilder().
+					WithFile("a.txt", 10, "text/plain").
+					WithFile("b.txt", 32, "text/plain").
+					Build()
+			},
+			wantFiles: 2, wantSize: 42,
+		},
+		{
+			name: "client",
+			storage: func(t *testing.T) FileStorage {
+				c, fake := newFakeS3Client(t)
+				fake.put("a.txt", make([]byte, 10), nil)
+				fake.put("b.txt", make([]byte, 32), nil)
+				return c
+			},
+			wantFiles: 2, wantSize: 42,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := tt.storage(t)
+			c := NewAuditableRustFSClient(storage, nil, testConfig(t, "http://localhost:9000"), "test-service")
+
+			stats, err := c.HealthReport(context.Background())
+			if err != nil {
+				t.Fatalf("HealthReport: %v", err)
+			}
+			if stats == nil {
+				t.Fatal("HealthReport returned no stats")
+			}
+			if stats.TotalFiles != tt.wantFiles || stats.TotalSize != tt.wantSize {
+				t.Errorf("stats = %d files, %d bytes; want %d files, %d bytes",
+					stats.TotalFiles, stats.TotalSize, tt.wantFiles, tt.wantSize)
+			}
+			if err := c.HealthCheck(context.Background()); err != nil {
+				t.Errorf("HealthCheck: %v", err)
+			}
+		})
+	}
+}
+
+func TestHealthReportFailure(t *testing.T) {
+	m := NewMockRustFSClient()
+	m.SetFailureMode(true, errors.New("storage unavailable"))
+	c := NewAuditableRustFSClient(m, nil, testConfig(t, "http://localhost:9000"), "test-service")
+
+	stats, err := c.HealthReport(context.Background())
+	if err == nil {
+		t.Fatal("HealthReport succeeded on a failing client")
+	}
+	if stats != nil {
+		t.Errorf("stats = %+v, want none", stats)
+	}
+}
+
+func TestAuditEventModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		operation string // "upload" or "delete"
+		slow      bool
+		fail      bool
+		// wantEvents lists the event types logged, in order
+		wantEvents  []audittypes.AuditEventType
+		wantOutcome string
+		wantSignals bool
+	}{
+		{name: "multi upload", mode: config.AuditEventModeMulti, operation: "upload",
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileUploaded}},
+		{name: "multi slow upload", mode: config.AuditEventModeMulti, operation: "upload", slow: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileUploaded, audit.AuditEventUploadSlow}},
+		{name: "multi slow delete", mode: config.AuditEventModeMulti, operation: "delete", slow: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileDeleted, audit.AuditEventUploadSlow}},
+		{name: "combined upload", mode: config.AuditEventModeCombined, operation: "upload",
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileUploaded}, wantOutcome: "success"},
+		{name: "combined slow upload", mode: config.AuditEventModeCombined, operation: "upload", slow: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileUploaded}, wantOutcome: "success", wantSignals: true},
+		{name: "combined failed upload", mode: config.AuditEventModeCombined, operation: "upload", fail: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventStorageError}, wantOutcome: "failure"},
+		{name: "combined slow delete", mode: config.AuditEventModeCombined, operation: "delete", slow: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventFileDeleted}, wantOutcome: "success", wantSignals: true},
+		{name: "combined failed delete", mode: config.AuditEventModeCombined, operation: "delete", fail: true,
+			wantEvents: []audittypes.AuditEventType{audit.AuditEventStorageError}, wantOutcome: "failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost:9000")
+			cfg.AuditEventMode = tt.mode
+			cfg.AllowedTypes = []string{"text/plain"}
+			// Every operation outlasts a nanosecond threshold and none outlasts an hour
+			cfg.Timeout = time.Hour
+			if tt.slow {
+				cfg.Timeout = time.Nanosecond
+			}
+
+			m := NewMockRustFSClient()
+			if _, err := m.UploadFile(context.Background(), uploadRequest("doc.txt", "content")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			if tt.fail {
+				m.SetFailureMode(true, errors.New("storage unavailable"))
+			}
+			sink := &recordingAuditSink{}
+			c := NewAuditableRustFSClient(m, audit.NewRustFSAuditLogger("test-service", sink, nil), cfg, "test-service")
+
+			var err error
+			if tt.operation == "upload" {
+				req := uploadRequest("doc.txt", "content")
+				req.Filename = "doc.txt"
+				_, err = c.UploadFileWithAudit(context.Background(), req, "user-1")
+			} else {
+				err = c.DeleteFileWithAudit(context.Background(), "doc.txt", "user-1")
+			}
+			if (err != nil) != tt.fail {
+				t.Fatalf("%s error = %v, want failure %v", tt.operation, err, tt.fail)
+			}
+
+			var got []audittypes.AuditEventType
+			for _, event := range sink.events {
+				got = append(got, event.EventType)
+			}
+			if !reflect.DeepEqual(got, tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", got, tt.wantEvents)
+			}
+			if tt.mode != config.AuditEventModeCombined {
+				return
+			}
+
+			metadata := sink.events[0].Metadata
+			if metadata["operation"] != tt.operation || metadata["outcome"] != tt.wantOutcome {
+				t.Errorf("operation, outcome = %v, %v; want %s, %s", metadata["operation"], metadata["outcome"], tt.operation, tt.wantOutcome)
+			}
+			performance, ok := metadata["performance"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("performance = %#v, want a section", metadata["performance"])
+			}
+			for _, key := range []string{"duration", "file_size", "throughput_mbps"} {
+				if _, ok := performance[key]; !ok {
+					t.Errorf("performance has no %s: %v", key, performance)
+				}
+			}
+			_, hasSignals := performance["signals"]
+			_, hasThreshold := performance["threshold"]
+			if hasSignals != tt.wantSignals || hasThreshold != tt.wantSignals {
+				t.Errorf("performance signals, threshold present = %v, %v; want %v", hasSignals, hasThreshold, tt.wantSignals)
+			}
+			if tt.fail && (metadata["error"] == nil || metadata["error_code"] == nil) {
+				t.Errorf("failure event lacks error details: %v", metadata)
+			}
+		})
+	}
+}
+
+func TestUploadWithAuditLeavesRequestMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+	}{
+		{"no metadata", nil},
+		{"caller metadata", map[string]interface{}{"origin": "test"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost:9000")
+			cfg.AllowedTypes = []string{"text/plain"}
+			sink := &recordingAuditSink{}
+			c := NewAuditableRustFSClient(NewMockRustFSClient(), audit.NewRustFSAuditLogger("test-service", sink, nil), cfg, "test-service")
+
+			req := uploadRequest("doc.txt", "content")
+			req.Filename = "doc.txt"
+			req.Metadata = tt.metadata
+			if _, err := c.UploadFileWithAudit(context.Background(), req, "user-1"); err != nil {
+				t.Fatalf("UploadFileWithAudit: %v", err)
+			}
+			if !reflect.DeepEqual(req.Metadata, tt.metadata) {
+				t.Errorf("request metadata = %v, want %v", req.Metadata, tt.metadata)
+			}
+			if sink.count() != 1 || sink.events[0].Metadata["upload_duration"] == nil {
+				t.Errorf("upload event lacks the upload details: %v", sink.events)
+			}
+		})
+	}
+}
+
+// sourceRecordingStorage records the type of every upload source reaching the wrapped mock
+type sourceRecordingStorage struct {
+	*MockRustFSClient
+	mu      sync.Mutex
+	sources []string
+}
+
+func (s *sourceRecordingStorage) UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	s.mu.Lock()
+	s.sources = append(s.sources, fmt.Sprintf("%T", req.File))
+	s.mu.Unlock()
+	return s.MockRustFSClient.UploadFile(ctx, req)
+}
+
+func TestAuditedBatchUploadThroughput(t *testing.T) {
+	dir := t.TempDir()
+	contents := []string{strings.Repeat("a", 1000), strings.Repeat("b", 2500), strings.Repeat("c", 40)}
+
+	var requests []*types.UploadRequest
+	var files []*os.File
+	for i, content := range contents {
+		path := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+		requests = append(requests, &types.UploadRequest{
+			File:        file,
+			FileSize:    int64(len(content)),
+			ContentType: "text/plain",
+			Filename:    fmt.Sprintf("%d.txt", i),
+			BucketPath:  fmt.Sprintf("batch/%d.txt", i),
+			CloseSource: true,
+		})
+	}
+
+	cfg := testConfig(t, "http://localhost:9000")
+	cfg.AllowedTypes = []string{"text/plain"}
+	sink := &recordingAuditSink{}
+	storage := &sourceRecordingStorage{MockRustFSClient: NewMockRustFSClient()}
+	c := NewAuditableRustFSClient(storage, audit.NewRustFSAuditLogger("test-service", sink, nil), cfg, "test-service")
+
+	if _, err := c.BatchUpload(context.Background(), requests); err != nil {
+		t.Fatalf("BatchUpload: %v", err)
+	}
+
+	// Sources reach the client as is, so *os.File keeps io.ReaderAt for parallel parts
+	for _, source := range storage.sources {
+		if source != "*os.File" {
+			t.Errorf("client received a %s, want the *os.File", source)
+		}
+	}
+	for i, file := range files {
+		if _, err := file.Stat(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("source %d not closed after upload: %v", i, err)
+		}
+	}
+
+	var batch *audittypes.AuditEvent
+	for _, event := range sink.events {
+		if event.EventType == audit.AuditEventBatchThroughput {
+			batch = event
+		}
+	}
+	if batch == nil {
+		t.Fatal("no batch throughput event")
+	}
+	var total int64
+	for _, content := range contents {
+		total += int64(len(content))
+	}
+	if batch.Metadata["file_size"] != total || batch.Metadata["item_count"] != len(contents) || batch.Metadata["failed_count"] != 0 {
+		t.Errorf("batch event = %v, want %d bytes over %d items", batch.Metadata, total, len(contents))
+	}
+}
+
+func TestMissingUserPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		userID    string
+		wantUser  string
+		wantEvent audittypes.AuditEventType
+		wantErr   error
+	}{
+		{"system", config.MissingUserPolicySystem, "", "system", audit.AuditEventFileAccessed, nil},
+		{"default is system", "", "", "system", audit.AuditEventFileAccessed, nil},
+		{"anonymous principal", config.MissingUserPolicyAnonymous, "", "guest", audit.AuditEventFileAccessed, nil},
+		{"reject", config.MissingUserPolicyReject, "", "", audit.AuditEventUnauthorizedAccess, ErrUnauthenticated},
+		{"reject lets a known user through", config.MissingUserPolicyReject, "alice", "alice", audit.AuditEventFileAccessed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost:9000")
+			cfg.Timeout = time.Hour
+			cfg.MissingUserPolicy = tt.policy
+			cfg.AnonymousPrincipal = "guest"
+
+			m := NewMockRustFSClientBuilder().WithFile("doc.txt", 7, "text/plain").Build()
+			sink := &recordingAuditSink{}
+			c := NewAuditableRustFSClient(m, audit.NewRustFSAuditLogger("test-service", sink, nil), cfg, "test-service")
+
+			ctx := context.Background()
+			if tt.userID != "" {
+				ctx = audit.NewRequestContext(ctx, audit.RequestInfo{UserID: tt.userID})
+			}
+			info, err := c.GetFileInfo(ctx, "doc.txt")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFileInfo = %v, want %v", err, tt.wantErr)
+			}
+			if (info != nil) != (tt.wantErr == nil) {
+				t.Errorf("GetFileInfo returned info %v alongside error %v", info, err)
+			}
+
+			if len(sink.events) == 0 {
+				t.Fatal("no audit event logged")
+			}
+			event := sink.events[0]
+			if event.EventType != tt.wantEvent || event.UserID != tt.wantUser {
+				t.Errorf("event = %s by %q, want %s by %q", event.EventType, event.UserID, tt.wantEvent, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestRequestContextReachesAuditEvents(t *testing.T) {
+	m := NewMockRustFSClient()
+	if _, err := m.UploadFile(context.Background(), uploadRequest("doc.txt", "content")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	sink := &recordingAuditSink{}
+	cfg := testConfig(t, "http://localhost:9000")
+	cfg.Timeout = time.Hour
+	c := NewAuditableRustFSClient(m, audit.NewRustFSAuditLogger("test-service", sink, nil), cfg, "test-service")
+
+	info := RequestInfo{
+		UserID:    "alice",
+		RequestID: "req-42",
+		TraceID:   "trace-7",
+		IP:        "203.0.113.9",
+		UserAgent: "dashboard/1.0",
+	}
+	ctx := NewRequestContext(context.Background(), info)
+
+	operations := []struct {
+		name string
+		call func() error
+	}{
+		{"GetFileInfo", func() error {
+			_, err := c.GetFileInfo(ctx, "doc.txt")
+			return err
+		}},
+		{"DownloadFile", func() error {
+			body, _, err := c.DownloadFile(ctx, "doc.txt")
+			if err == nil {
+				io.Copy(io.Discard, body)
+				body.Close()
+			}
+			return err
+		}},
+		{"UpdateMetadata", func() error {
+			_, err := c.UpdateMetadata(ctx, "doc.txt", map[string]interface{}{"owner": "alice"}, false)
+			return err
+		}},
+		{"CopyFile", func() error { return c.CopyFile(ctx, "doc.txt", "copy.txt") }},
+		{"MoveFile", func() error { return c.MoveFile(ctx, "copy.txt", "moved.txt") }},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			before := sink.count()
+			if err := op.call(); err != nil {
+				t.Fatalf("%s: %v", op.name, err)
+			}
+			if sink.count() == before {
+				t.Fatalf("%s logged no audit event", op.name)
+			}
+
+			for _, event := range sink.events[before:] {
+				if event.UserID != info.UserID || event.RequestID != info.RequestID ||
+					event.IPAddress != info.IP || event.UserAgent != info.UserAgent {
+					t.Errorf("%s event = user %q, request %q, ip %q, agent %q; want the request's attributes",
+						event.EventType, event.UserID, event.RequestID, event.IPAddress, event.UserAgent)
+				}
+				if event.Metadata["trace_id"] != info.TraceID {
+					t.Errorf("%s event trace_id = %v, want %s", event.EventType, event.Metadata["trace_id"], info.TraceID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// usesBearerAuth reports whether requests carry the API key instead of a SigV4 signature
+func usesBearerAuth(cfg *config.RustFSConfig) bool {
+	return cfg.AuthScheme == config.AuthSchemeBearer
+}
+
+// bearerAuth sets the API key on every S3 request attempt. The S3 client uses anonymous
+// credentials under the bearer scheme, so the SDK leaves requests unsigned.
+func bearerAuth(apiKey string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		authorize := middleware.FinalizeMiddlewareFunc("RustFSBearerAuth", func(
+			ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+		) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if req, ok := in.Request.(*smithyhttp.Request); ok {
+				req.Header.Set("Authorization", "Bearer "+apiKey)
+			}
+			return next.HandleFinalize(ctx, in)
+		})
+		return stack.Finalize.Add(authorize, middleware.After)
+	}
+}
+
+// requireSigV4 fails with ErrUnsupportedOperation for operations that only work with SigV4
+// credentials, such as presigning URLs
+func (c *RustFSClient) requireSigV4(operation string) error {
+	if usesBearerAuth(c.config) {
+		return newSentinelError(501, "UNSUPPORTED_OPERATION", ErrUnsupportedOperation,
+			fmt.Errorf("%s requires the sigv4 auth scheme", operation))
+	}
+	return nil
+}
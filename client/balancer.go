@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// defaultEndpointProbeInterval is used when the config leaves EndpointProbeInterval unset
+const defaultEndpointProbeInterval = 10 * time.Second
+
+// endpointBalancer spreads S3 requests across the configured BaseURLs. It resolves the endpoint
+// of every request and, wrapped around the HTTP client, tracks requests in flight and
+// consecutive failures per endpoint. Endpoints failing too often leave the rotation until a
+// probe reaches them again.
+type endpointBalancer struct {
+	strategy      string
+	threshold     int
+	probeInterval time.Duration
+	probe         func(ctx context.Context, endpoint string) error
+	resolver      s3.EndpointResolverV2
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	byHost    map[string]*endpointState
+	next      int
+}
+
+// endpointState is the balancer's view of one endpoint
+type endpointState struct {
+	url         string
+	outstanding int
+	failures    int
+	down        bool
+	probing     bool
+	nextProbe   time.Time
+}
+
+// newEndpointBalancer creates a balancer over cfg.BaseURLs; probe reports whether a removed
+// endpoint answers again
+func newEndpointBalancer(cfg *config.RustFSConfig, probe func(ctx context.Context, endpoint string) error) *endpointBalancer {
+	b := &endpointBalancer{
+		strategy:      cfg.LoadBalanceStrategy,
+		threshold:     cfg.EndpointFailureThreshold,
+		probeInterval: cfg.EndpointProbeInterval,
+		probe:         probe,
+		resolver:      s3.NewDefaultEndpointResolverV2(),
+		byHost:        make(map[string]*endpointState, len(cfg.BaseURLs)),
+	}
+	if b.probeInterval <= 0 {
+		b.probeInterval = defaultEndpointProbeInterval
+	}
+
+	for _, baseURL := range cfg.BaseURLs {
+		state := &endpointState{url: strings.TrimSuffix(baseURL, "/")}
+		b.endpoints = append(b.endpoints, state)
+		if parsed, err := url.Parse(state.url); err == nil {
+			b.byHost[parsed.Host] = state
+		}
+	}
+	return b
+}
+
+// pinnedEndpointKey carries an endpoint chosen by the caller instead of the balancer
+type pinnedEndpointKey struct{}
+
+// withPinnedEndpoint returns a context whose S3 requests go to endpoint
+func withPinnedEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, pinnedEndpointKey{}, endpoint)
+}
+
+// addPickMiddleware chooses the endpoint once per request attempt, before the SDK resolves it.
+// The SDK resolves the endpoint more than once per attempt, so choosing in ResolveEndpoint would
+// advance the rotation several times per request.
+func (b *endpointBalancer) addPickMiddleware(stack *middleware.Stack) error {
+	pick := middleware.FinalizeMiddlewareFunc("RustFSPickEndpoint", func(
+		ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+	) (middleware.FinalizeOutput, middleware.Metadata, error) {
+		if _, ok := ctx.Value(pinnedEndpointKey{}).(string); !ok {
+			ctx = withPinnedEndpoint(ctx, b.pick())
+		}
+		return next.HandleFinalize(ctx, in)
+	})
+	return stack.Finalize.Insert(pick, "ResolveAuthScheme", middleware.Before)
+}
+
+// ResolveEndpoint implements s3.EndpointResolverV2, resolving against the endpoint chosen for the
+// request, or the next one in rotation when none was chosen
+func (b *endpointBalancer) ResolveEndpoint(ctx context.Context, params s3.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	endpoint, ok := ctx.Value(pinnedEndpointKey{}).(string)
+	if !ok {
+		endpoint = b.pick()
+	}
+	params.Endpoint = aws.String(endpoint)
+	return b.resolver.ResolveEndpoint(ctx, params)
+}
+
+// pick chooses the endpoint for the next request
+func (b *endpointBalancer) pick() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	candidates := b.available()
+	start := b.next % len(candidates)
+	b.next++
+
+	chosen := candidates[start]
+	if b.strategy == config.LoadBalanceLeastOutstanding {
+		// Scan from the round-robin position so ties don't always favor the first endpoint
+		for i := range candidates {
+			candidate := candidates[(start+i)%len(candidates)]
+			if candidate.outstanding < chosen.outstanding {
+				chosen = candidate
+			}
+		}
+	}
+	return chosen.url
+}
+
+// endpointFor returns the same endpoint for key as long as the set of healthy endpoints is
+// unchanged, using rendezvous hashing so removing one endpoint only moves its own keys
+func (b *endpointBalancer) endpointFor(key string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var chosen string
+	var best uint64
+	for _, candidate := range b.available() {
+		h := fnv.New64a()
+		h.Write([]byte(candidate.url))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		if score := h.Sum64(); chosen == "" || score > best {
+			chosen, best = candidate.url, score
+		}
+	}
+	return chosen
+}
+
+// available returns the endpoints in rotation, or all of them when every endpoint is down so
+// requests still have somewhere to go. It starts probes that are due; the caller must hold b.mu.
+func (b *endpointBalancer) available() []*endpointState {
+	now := time.Now()
+	healthy := make([]*endpointState, 0, len(b.endpoints))
+	for _, state := range b.endpoints {
+		if !state.down {
+			healthy = append(healthy, state)
+			continue
+		}
+		if !state.probing && !now.Before(state.nextProbe) {
+			state.probing = true
+			go b.runProbe(state)
+		}
+	}
+	if len(healthy) == 0 {
+		return b.endpoints
+	}
+	return healthy
+}
+
+// runProbe returns state to the rotation when its endpoint answers
+func (b *endpointBalancer) runProbe(state *endpointState) {
+	err := b.probe(context.Background(), state.url)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state.probing = false
+	if err != nil {
+		state.nextProbe = time.Now().Add(b.probeInterval)
+		return
+	}
+	state.down = false
+	state.failures = 0
+	log.Printf("[RUSTFS] endpoint %s is reachable again, returning it to rotation", state.url)
+}
+
+// started records a request sent to host and returns the endpoint tracking it, if any
+func (b *endpointBalancer) started(host string) *endpointState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.byHost[host]
+	if state != nil {
+		state.outstanding++
+	}
+	return state
+}
+
+// finished records the outcome of a request to state
+func (b *endpointBalancer) finished(state *endpointState, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state.outstanding--
+	if !failed {
+		state.failures = 0
+		return
+	}
+
+	state.failures++
+	if b.threshold > 0 && state.failures >= b.threshold && !state.down {
+		state.down = true
+		state.nextProbe = time.Now().Add(b.probeInterval)
+		log.Printf("[RUSTFS] endpoint %s removed from rotation after %d consecutive failures", state.url, state.failures)
+	}
+}
+
+// httpClient wraps next so the balancer sees every request and its outcome
+func (b *endpointBalancer) httpClient(next aws.HTTPClient) aws.HTTPClient {
+	return &balancedHTTPClient{next: next, balancer: b}
+}
+
+// balancedHTTPClient reports requests to an endpointBalancer
+type balancedHTTPClient struct {
+	next     aws.HTTPClient
+	balancer *endpointBalancer
+}
+
+// Do implements aws.HTTPClient. Connection errors and 5xx responses count as endpoint failures;
+// requests ended by their own context don't.
+func (h *balancedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	state := h.balancer.started(req.URL.Host)
+	resp, err := h.next.Do(req)
+	if state != nil {
+		failed := (err != nil && req.Context().Err() == nil) || (resp != nil && resp.StatusCode >= 500)
+		h.balancer.finished(state, failed)
+	}
+	return resp, err
+}
@@ -0,0 +1,298 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// newTestBalancer returns a balancer over endpoints whose probe succeeds while reachable is set
+func newTestBalancer(t *testing.T, strategy string, endpoints []string, reachable *atomic.Bool) *endpointBalancer {
+	t.Helper()
+	cfg := testConfig(t, endpoints[0])
+	cfg.BaseURLs = endpoints
+	cfg.LoadBalanceStrategy = strategy
+	cfg.EndpointFailureThreshold = 2
+	cfg.EndpointProbeInterval = time.Hour
+	return newEndpointBalancer(cfg, func(ctx context.Context, endpoint string) error {
+		if !reachable.Load() {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+}
+
+// picks counts the endpoints chosen by n picks
+func picks(b *endpointBalancer, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[b.pick()]++
+	}
+	return counts
+}
+
+func TestEndpointBalancerPick(t *testing.T) {
+	endpoints := []string{"http://a:9000", "http://b:9000", "http://c:9000"}
+
+	tests := []struct {
+		name     string
+		strategy string
+		// setup starts requests to or fails endpoints before picking
+		setup func(b *endpointBalancer)
+		want  map[string]int
+	}{
+		{
+			name: "round-robin spreads evenly", strategy: config.LoadBalanceRoundRobin,
+			setup: func(b *endpointBalancer) {},
+			want:  map[string]int{"http://a:9000": 3, "http://b:9000": 3, "http://c:9000": 3},
+		},
+		{
+			name: "endpoint past the failure threshold leaves the rotation", strategy: config.LoadBalanceRoundRobin,
+			setup: func(b *endpointBalancer) {
+				for i := 0; i < 2; i++ {
+					b.finished(b.started("b:9000"), true)
+				}
+			},
+			want: map[string]int{"http://a:9000": 5, "http://c:9000": 4},
+		},
+		{
+			name: "a success resets the failure count", strategy: config.LoadBalanceRoundRobin,
+			setup: func(b *endpointBalancer) {
+				b.finished(b.started("b:9000"), true)
+				b.finished(b.started("b:9000"), false)
+				b.finished(b.started("b:9000"), true)
+			},
+			want: map[string]int{"http://a:9000": 3, "http://b:9000": 3, "http://c:9000": 3},
+		},
+		{
+			name: "every endpoint down keeps all of them", strategy: config.LoadBalanceRoundRobin,
+			setup: func(b *endpointBalancer) {
+				for _, host := range []string{"a:9000", "b:9000", "c:9000"} {
+					b.finished(b.started(host), true)
+					b.finished(b.started(host), true)
+				}
+			},
+			want: map[string]int{"http://a:9000": 3, "http://b:9000": 3, "http://c:9000": 3},
+		},
+		{
+			name: "least-outstanding avoids busy endpoints", strategy: config.LoadBalanceLeastOutstanding,
+			setup: func(b *endpointBalancer) {
+				b.started("a:9000")
+				b.started("a:9000")
+				b.started("b:9000")
+			},
+			want: map[string]int{"http://c:9000": 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reachable := &atomic.Bool{}
+			b := newTestBalancer(t, tt.strategy, endpoints, reachable)
+			tt.setup(b)
+
+			got := picks(b, 9)
+			if len(got) != len(tt.want) {
+				t.Fatalf("picks = %v, want %v", got, tt.want)
+			}
+			for endpoint, n := range tt.want {
+				if got[endpoint] != n {
+					t.Errorf("picks = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestEndpointBalancerProbe(t *testing.T) {
+	endpoints := []string{"http://a:9000", "http://b:9000"}
+	reachable := &atomic.Bool{}
+	b := newTestBalancer(t, config.LoadBalanceRoundRobin, endpoints, reachable)
+
+	b.finished(b.started("b:9000"), true)
+	b.finished(b.started("b:9000"), true)
+
+	// A due probe that fails keeps the endpoint out and schedules the next probe
+	b.mu.Lock()
+	b.byHost["b:9000"].nextProbe = time.Now()
+	b.mu.Unlock()
+	if got := picks(b, 4); got["http://b:9000"] != 0 {
+		t.Fatalf("picks = %v, want b out of rotation", got)
+	}
+	waitFor(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		state := b.byHost["b:9000"]
+		return !state.probing && state.nextProbe.After(time.Now())
+	})
+
+	// A due probe that succeeds returns it
+	reachable.Store(true)
+	b.mu.Lock()
+	b.byHost["b:9000"].nextProbe = time.Now()
+	b.mu.Unlock()
+	b.pick()
+	waitFor(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return !b.byHost["b:9000"].down
+	})
+	if got := picks(b, 4); got["http://b:9000"] != 2 {
+		t.Errorf("picks = %v, want b back in rotation", got)
+	}
+}
+
+func TestEndpointBalancerEndpointFor(t *testing.T) {
+	endpoints := []string{"http://a:9000", "http://b:9000", "http://c:9000"}
+	reachable := &atomic.Bool{}
+	b := newTestBalancer(t, config.LoadBalanceRoundRobin, endpoints, reachable)
+
+	keys := []string{"a.txt", "b.txt", "c.txt", "docs/d.txt", "docs/e.txt", "img/f.png", "img/g.png", "h", "i", "j"}
+	before := make(map[string]string)
+	used := make(map[string]bool)
+	for _, key := range keys {
+		before[key] = b.endpointFor(key)
+		used[before[key]] = true
+		if again := b.endpointFor(key); again != before[key] {
+			t.Errorf("endpointFor(%q) = %s, then %s", key, before[key], again)
+		}
+	}
+	if len(used) < 2 {
+		t.Errorf("every key maps to %v", used)
+	}
+
+	// Removing b only moves b's keys
+	b.finished(b.started("b:9000"), true)
+	b.finished(b.started("b:9000"), true)
+	for _, key := range keys {
+		after := b.endpointFor(key)
+		if after == "http://b:9000" {
+			t.Errorf("endpointFor(%q) = %s, which is out of rotation", key, after)
+		}
+		if before[key] != "http://b:9000" && after != before[key] {
+			t.Errorf("endpointFor(%q) moved from %s to %s", key, before[key], after)
+		}
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newBalancedFakes starts one fakeS3 per endpoint, all storing doc.txt, and a client spreading
+// requests across them
+func newBalancedFakes(t *testing.T, n int, configure func(cfg *config.RustFSConfig)) (*RustFSClient, []*fakeS3, []string) {
+	t.Helper()
+	var fakes []*fakeS3
+	var urls []string
+	for i := 0; i < n; i++ {
+		fake := newFakeS3("default")
+		fake.put("doc.txt", []byte("content"), nil)
+		server := newTestServer(t, fake.ServeHTTP)
+		fakes = append(fakes, fake)
+		urls = append(urls, server.URL)
+	}
+
+	cfg := testConfig(t, urls[0])
+	cfg.BaseURLs = urls
+	cfg.EndpointProbeInterval = time.Hour
+	if configure != nil {
+		configure(cfg)
+	}
+	return NewRustFSClient(cfg), fakes, urls
+}
+
+func TestBalancedClientRoundRobin(t *testing.T) {
+	c, fakes, _ := newBalancedFakes(t, 3, nil)
+
+	for i := 0; i < 9; i++ {
+		if _, err := c.GetFileInfo(context.Background(), "doc.txt"); err != nil {
+			t.Fatalf("GetFileInfo: %v", err)
+		}
+	}
+	for i, fake := range fakes {
+		if got := len(fake.requests(http.MethodHead, "doc.txt")); got != 3 {
+			t.Errorf("endpoint %d served %d requests, want 3", i, got)
+		}
+	}
+}
+
+func TestBalancedClientRemovesFailingEndpoint(t *testing.T) {
+	c, fakes, _ := newBalancedFakes(t, 3, func(cfg *config.RustFSConfig) {
+		cfg.EndpointFailureThreshold = 2
+	})
+	var failed atomic.Int64
+	fakes[1].intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		failed.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	}
+
+	// The client retries, so every call succeeds on a healthy endpoint
+	for i := 0; i < 12; i++ {
+		if _, err := c.GetFileInfo(context.Background(), "doc.txt"); err != nil {
+			t.Fatalf("GetFileInfo %d: %v", i, err)
+		}
+	}
+
+	if got := failed.Load(); got != 2 {
+		t.Errorf("failing endpoint received %d requests, want 2 before leaving the rotation", got)
+	}
+	for _, i := range []int{0, 2} {
+		if got := len(fakes[i].requests(http.MethodHead, "doc.txt")); got < 6 {
+			t.Errorf("healthy endpoint %d served %d requests, want at least 6", i, got)
+		}
+	}
+}
+
+func TestBalancedClientPresignedURLs(t *testing.T) {
+	c, _, urls := newBalancedFakes(t, 3, nil)
+
+	hosts := make(map[string]bool)
+	for _, key := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt", "f.txt"} {
+		first, err := c.GenerateDownloadURL(context.Background(), key, time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateDownloadURL: %v", err)
+		}
+		second, err := c.GenerateUploadURL(context.Background(), key, "text/plain", time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateUploadURL: %v", err)
+		}
+
+		firstURL, _ := url.Parse(first)
+		secondURL, _ := url.Parse(second)
+		if firstURL.Host != secondURL.Host {
+			t.Errorf("URLs for %s point at %s and %s", key, firstURL.Host, secondURL.Host)
+		}
+		hosts[firstURL.Host] = true
+	}
+
+	for host := range hosts {
+		found := false
+		for _, u := range urls {
+			found = found || strings.Contains(u, host)
+		}
+		if !found {
+			t.Errorf("presigned URL host %s isn't a configured endpoint", host)
+		}
+	}
+	if len(hosts) < 2 {
+		t.Errorf("every presigned URL points at %v", hosts)
+	}
+}
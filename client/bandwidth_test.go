@@ -0,0 +1,67 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	const rate = 256 * 1024
+	// The bucket starts with one second of burst, so the remaining half-second's worth of
+	// bytes must wait for tokens
+	content := bytes.Repeat([]byte("x"), rate*3/2)
+	minElapsed := 400 * time.Millisecond
+
+	transfers := []struct {
+		name     string
+		transfer func(t *testing.T, c *RustFSClient)
+	}{
+		{"upload", func(t *testing.T, c *RustFSClient) {
+			req := uploadRequest("capped.bin", "")
+			req.File, req.FileSize = bytes.NewReader(content), int64(len(content))
+			if _, err := c.UploadFile(context.Background(), req); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+		}},
+		{"download", func(t *testing.T, c *RustFSClient) {
+			body, _, err := c.DownloadFile(context.Background(), "stored.bin")
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			defer body.Close()
+			if n, err := io.Copy(io.Discard, body); err != nil || n != int64(len(content)) {
+				t.Fatalf("downloaded %d bytes, %v", n, err)
+			}
+		}},
+	}
+
+	for _, tt := range transfers {
+		t.Run(tt.name+" at the limit", func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) { cfg.BandwidthLimit = rate })
+			fake.put("stored.bin", content, nil)
+
+			start := time.Now()
+			tt.transfer(t, c)
+			if elapsed := time.Since(start); elapsed < minElapsed {
+				t.Errorf("%d bytes at %d B/s took %v, want at least %v", len(content), rate, elapsed, minElapsed)
+			}
+		})
+
+		t.Run(tt.name+" without a limit", func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) { cfg.BandwidthLimit = rate })
+			fake.put("stored.bin", content, nil)
+			c.SetBandwidthLimit(0)
+
+			start := time.Now()
+			tt.transfer(t, c)
+			if elapsed := time.Since(start); elapsed >= minElapsed {
+				t.Errorf("unlimited transfer took %v", elapsed)
+			}
+		})
+	}
+}
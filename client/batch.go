@@ -0,0 +1,285 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// BatchUploadItem is one upload in a batch, configured independently of the other items
+type BatchUploadItem struct {
+	Request *types.UploadRequest
+	// Options apply to this item only; nil uploads the request as is
+	Options *UploadOptions
+}
+
+// BatchUploadResult is the outcome of one batch item. Exactly one of Response and Err is set.
+type BatchUploadResult struct {
+	Response *types.UploadResponse
+	Err      error
+}
+
+// BatchItemError reports the failure of one item of a batch operation
+type BatchItemError struct {
+	Index int
+	Path  string
+	Err   error
+}
+
+// BatchError reports every failed item of a batch operation; the remaining items succeeded
+type BatchError struct {
+	Operation string
+	Total     int
+	Failures  []BatchItemError
+}
+
+// Error implements error
+func (e *BatchError) Error() string {
+	failures := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		failures[i] = fmt.Sprintf("%s: %v", failure.Path, failure.Err)
+	}
+	return fmt.Sprintf("batch %s: %d of %d items failed: %s",
+		e.Operation, len(e.Failures), e.Total, strings.Join(failures, "; "))
+}
+
+// Unwrap returns the per-item errors so errors.Is and errors.As match any of them
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// uploadWithOptionsFunc uploads one batch item
+type uploadWithOptionsFunc func(ctx context.Context, req *types.UploadRequest, opts *UploadOptions) (*types.UploadResponse, error)
+
+// applyUploadOptions returns a copy of req with opts applied. Option metadata is merged over
+// the request's own metadata.
+func applyUploadOptions(req *types.UploadRequest, opts *UploadOptions) (*types.UploadRequest, error) {
+	if opts == nil {
+		return req, nil
+	}
+
+	switch opts.VerifyAfterUpload {
+	case VerifyNone, VerifyChecksum, VerifyRange, VerifyFull:
+	default:
+		return nil, apperror.NewAppError(400, "INVALID_VERIFICATION_SCOPE", fmt.Errorf("unknown verification scope %q", opts.VerifyAfterUpload))
+	}
+
+	applied := *req
+	if opts.IfNotExists {
+		applied.IfNotExists = true
+	}
+	if len(opts.Metadata) > 0 {
+		applied.Metadata = make(map[string]interface{}, len(req.Metadata)+len(opts.Metadata))
+		for k, v := range req.Metadata {
+			applied.Metadata[k] = v
+		}
+		for k, v := range opts.Metadata {
+			applied.Metadata[k] = v
+		}
+	}
+
+	return &applied, nil
+}
+
+// verifiableUploader is the storage surface needed for uploads with options
+type verifiableUploader interface {
+	UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error)
+	uploadVerifier
+}
+
+// uploadFileWithOptions applies opts to req, uploads it and, if requested, reads it back for
+// verification. Compressed uploads use compressionLevel. Encryption isn't an option: it is set
+// for the whole client, since downloads expect every object to carry encryption metadata.
+func uploadFileWithOptions(ctx context.Context, storage verifiableUploader, req *types.UploadRequest, opts *UploadOptions, compressionLevel int) (*types.UploadResponse, error) {
+	applied, err := applyUploadOptions(req, opts)
+	if err != nil {
+		closeUploadSource(req)
+		return nil, err
+	}
+	if opts == nil {
+		return storage.UploadFile(ctx, applied)
+	}
+	// The wrappers below hide the source's Close method, so close an owned source here
+	defer closeUploadSource(req)
+	applied.CloseSource = false
+
+	var progress *progressReporter
+	if opts.ProgressCallback != nil && applied.File != nil {
+		progress = newProgressReporter(opts.ProgressCallback, applied.FileSize)
+	}
+
+	var compression *uploadCompression
+	if opts.EnableCompression && shouldCompress(applied) {
+		// Progress counts source bytes, so wrap the source before it is compressed
+		if progress != nil {
+			applied.File = utils.WrapCountingReader(applied.File, progress.add)
+		}
+		applied, compression = compressUpload(applied, compressionLevel)
+		defer compression.close()
+	}
+
+	var digest func() *sourceDigest
+	if opts.VerifyAfterUpload != VerifyNone {
+		// Verification compares stored bytes, so a compressed upload is digested after compression
+		applied, digest, err = digestUploadSource(applied)
+		if err != nil {
+			return nil, apperror.NewAppError(500, "FILE_READ_ERROR", err)
+		}
+	}
+
+	if progress != nil && compression == nil {
+		applied.File = utils.WrapCountingReader(applied.File, progress.add)
+	}
+
+	response, err := storage.UploadFile(ctx, applied)
+	if err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress.finish()
+	}
+	if compression != nil {
+		response.UncompressedSize = compression.uncompressed()
+	}
+
+	if digest != nil {
+		if err := verifyUpload(ctx, storage, applied.BucketPath, opts.VerifyAfterUpload, digest()); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// batchUpload uploads items concurrently, at most limit at a time, continuing past failures.
+// Results are in item order.
+func batchUpload(ctx context.Context, upload uploadWithOptionsFunc, items []BatchUploadItem, limit int) []BatchUploadResult {
+	results := make([]BatchUploadResult, len(items))
+
+	// Tasks never fail, so one bad item doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, limit)
+	for i, item := range items {
+		i, item := i, item
+		group.Go(func() error {
+			if item.Request == nil {
+				results[i].Err = fmt.Errorf("batch item %d has no upload request", i)
+				return nil
+			}
+			results[i].Response, results[i].Err = upload(groupCtx, item.Request, item.Options)
+			return nil
+		})
+	}
+	group.Wait()
+
+	return results
+}
+
+// batchUploadRequests uploads requests without per-item options and collects failures in a BatchError
+func batchUploadRequests(ctx context.Context, upload uploadWithOptionsFunc, requests []*types.UploadRequest, limit int) ([]*types.UploadResponse, error) {
+	items := make([]BatchUploadItem, len(requests))
+	for i, req := range requests {
+		items[i] = BatchUploadItem{Request: req}
+	}
+
+	results := batchUpload(ctx, upload, items, limit)
+
+	responses := make([]*types.UploadResponse, len(results))
+	var failures []BatchItemError
+	for i, result := range results {
+		responses[i] = result.Response
+		if result.Err != nil {
+			path := ""
+			if requests[i] != nil {
+				path = requests[i].BucketPath
+			}
+			failures = append(failures, BatchItemError{Index: i, Path: path, Err: result.Err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return responses, &BatchError{Operation: "upload", Total: len(requests), Failures: failures}
+	}
+	return responses, nil
+}
+
+// UploadFileWithOptions uploads a file with per-upload options applied
+func (c *RustFSClient) UploadFileWithOptions(ctx context.Context, req *types.UploadRequest, opts *UploadOptions) (*types.UploadResponse, error) {
+	return uploadFileWithOptions(ctx, c, req, opts, c.config.CompressionLevel)
+}
+
+// BatchUploadWithOptions uploads items concurrently, each with its own options. Results are in
+// item order and report each item's outcome.
+func (c *RustFSClient) BatchUploadWithOptions(ctx context.Context, items []BatchUploadItem) []BatchUploadResult {
+	return batchUpload(ctx, c.UploadFileWithOptions, items, c.config.ConcurrentUploads)
+}
+
+// BatchUpload uploads requests concurrently. Responses are in request order, nil for failed
+// uploads, which are reported together in a *BatchError.
+func (c *RustFSClient) BatchUpload(ctx context.Context, requests []*types.UploadRequest) ([]*types.UploadResponse, error) {
+	return batchUploadRequests(ctx, c.UploadFileWithOptions, requests, c.config.ConcurrentUploads)
+}
+
+// UploadFileWithOptions uploads a file to mock storage with per-upload options applied
+func (m *MockRustFSClient) UploadFileWithOptions(ctx context.Context, req *types.UploadRequest, opts *UploadOptions) (*types.UploadResponse, error) {
+	return uploadFileWithOptions(ctx, m, req, opts, gzip.DefaultCompression)
+}
+
+// BatchUploadWithOptions uploads items to mock storage, each with its own options
+func (m *MockRustFSClient) BatchUploadWithOptions(ctx context.Context, items []BatchUploadItem) []BatchUploadResult {
+	return batchUpload(ctx, m.UploadFileWithOptions, items, 0)
+}
+
+// BatchUpload uploads requests to mock storage, reporting failures in a *BatchError
+func (m *MockRustFSClient) BatchUpload(ctx context.Context, requests []*types.UploadRequest) ([]*types.UploadResponse, error) {
+	return batchUploadRequests(ctx, m.UploadFileWithOptions, requests, 0)
+}
+
+// batchDelete deletes paths concurrently, at most limit at a time, continuing past failures
+// and reporting every failed path in a *BatchError
+func batchDelete(ctx context.Context, deleteFile func(ctx context.Context, path string) error, paths []string, limit int) error {
+	errs := make([]error, len(paths))
+
+	// Tasks never fail, so one bad delete doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, limit)
+	for i, path := range paths {
+		i, path := i, path
+		group.Go(func() error {
+			errs[i] = deleteFile(groupCtx, path)
+			return nil
+		})
+	}
+	group.Wait()
+
+	var failures []BatchItemError
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, BatchItemError{Index: i, Path: paths[i], Err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &BatchError{Operation: "delete", Total: len(paths), Failures: failures}
+	}
+	return nil
+}
+
+// BatchDelete deletes paths concurrently. Every path is attempted; failures are reported
+// together in a *BatchError so callers can retry just those paths.
+func (c *RustFSClient) BatchDelete(ctx context.Context, paths []string) error {
+	return batchDelete(ctx, c.DeleteFile, paths, c.config.ConcurrentUploads)
+}
+
+// BatchDelete deletes paths from mock storage, reporting failures in a *BatchError. Deleted
+// paths are recorded for GetDeletes.
+func (m *MockRustFSClient) BatchDelete(ctx context.Context, paths []string) error {
+	return batchDelete(ctx, m.DeleteFile, paths, 0)
+}
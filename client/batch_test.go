@@ -0,0 +1,192 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// batchStorage is the surface of both clients used by the batch tests
+type batchStorage interface {
+	BatchUploadWithOptions(ctx context.Context, items []BatchUploadItem) []BatchUploadResult
+	FileInfoReader
+	Downloader
+}
+
+func TestBatchUploadWithOptions(t *testing.T) {
+	text := strings.Repeat("thumbnail caption ", 200)
+
+	storages := []struct {
+		name string
+		new  func(t *testing.T) batchStorage
+	}{
+		{"RustFSClient", func(t *testing.T) batchStorage {
+			c, _ := newFakeS3Client(t)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T) batchStorage { return NewMockRustFSClient() }},
+	}
+
+	for _, storage := range storages {
+		t.Run(storage.name, func(t *testing.T) {
+			s := storage.new(t)
+			ctx := context.Background()
+
+			original := uploadRequest("photos/original.txt", text)
+			original.Metadata = map[string]interface{}{"source": "camera"}
+			thumbnail := uploadRequest("photos/thumb.txt", text)
+			thumbnail.Metadata = map[string]interface{}{"source": "camera"}
+			// An unseekable source is digested as it streams into the compressor
+			caption := uploadRequest("photos/caption.txt", text)
+			caption.File = &onceReader{r: strings.NewReader(text)}
+
+			results := s.BatchUploadWithOptions(ctx, []BatchUploadItem{
+				{Request: original},
+				{Request: thumbnail, Options: &UploadOptions{
+					EnableCompression: true,
+					Metadata:          map[string]interface{}{"variant": "thumbnail"},
+					VerifyAfterUpload: VerifyFull,
+				}},
+				{Request: nil},
+				{Request: caption, Options: &UploadOptions{EnableCompression: true, VerifyAfterUpload: VerifyRange}},
+				{Request: uploadRequest("photos/bad.txt", text), Options: &UploadOptions{VerifyAfterUpload: "everything"}},
+			})
+
+			tests := []struct {
+				path         string
+				wantErr      bool
+				wantEncoding string
+				wantMetadata map[string]string
+			}{
+				{path: "photos/original.txt", wantMetadata: map[string]string{"source": "camera"}},
+				{path: "photos/thumb.txt", wantEncoding: ContentEncodingGzip, wantMetadata: map[string]string{"source": "camera", "variant": "thumbnail"}},
+				{wantErr: true},
+				{path: "photos/caption.txt", wantEncoding: ContentEncodingGzip},
+				{path: "photos/bad.txt", wantErr: true},
+			}
+
+			if len(results) != len(tests) {
+				t.Fatalf("%d results for %d items", len(results), len(tests))
+			}
+			for i, tt := range tests {
+				result := results[i]
+				if tt.wantErr {
+					if result.Err == nil || result.Response != nil {
+						t.Errorf("item %d = %+v, want an error", i, result)
+					}
+					continue
+				}
+				if result.Err != nil {
+					t.Errorf("item %d: %v", i, result.Err)
+					continue
+				}
+				if result.Response.Path != tt.path {
+					t.Errorf("item %d response is for %s, want %s", i, result.Response.Path, tt.path)
+				}
+
+				info, err := s.GetFileInfo(ctx, tt.path)
+				if err != nil {
+					t.Fatalf("GetFileInfo(%s): %v", tt.path, err)
+				}
+				if info.ContentEncoding != tt.wantEncoding {
+					t.Errorf("%s Content-Encoding = %q, want %q", tt.path, info.ContentEncoding, tt.wantEncoding)
+				}
+				for k, v := range tt.wantMetadata {
+					if info.Metadata[k] != v {
+						t.Errorf("%s metadata %s = %v, want %s", tt.path, k, info.Metadata[k], v)
+					}
+				}
+				if _, ok := info.Metadata["variant"]; ok && tt.wantMetadata["variant"] == "" {
+					t.Errorf("%s has another item's metadata: %v", tt.path, info.Metadata)
+				}
+
+				body, _, err := s.DownloadFile(ctx, tt.path)
+				if err != nil {
+					t.Fatalf("DownloadFile(%s): %v", tt.path, err)
+				}
+				var content io.Reader = body
+				if tt.wantEncoding == ContentEncodingGzip {
+					if content, err = gzip.NewReader(body); err != nil {
+						t.Fatalf("%s isn't gzip: %v", tt.path, err)
+					}
+				}
+				data, err := io.ReadAll(content)
+				body.Close()
+				if err != nil || string(data) != text {
+					t.Errorf("%s holds %d bytes, %v; want the source", tt.path, len(data), err)
+				}
+			}
+		})
+	}
+}
+
+// countingSource counts the bytes read from it and is deliberately not an io.Seeker
+type countingSource struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingSource) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestDigestUploadSource(t *testing.T) {
+	content := strings.Repeat("0123456789", verifyRangeLength/5)
+	md5Sum := md5.Sum([]byte(content))
+	sha256Sum := sha256.Sum256([]byte(content))
+
+	tests := []struct {
+		name     string
+		seekable bool
+	}{
+		{"seekable source is hashed and rewound", true},
+		{"unseekable source is hashed as the upload reads it", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unseekable := &countingSource{r: strings.NewReader(content)}
+			var source io.Reader = unseekable
+			if tt.seekable {
+				source = strings.NewReader(content)
+			}
+
+			req, digest, err := digestUploadSource(&types.UploadRequest{File: source, BucketPath: "a.txt"})
+			if err != nil {
+				t.Fatalf("digestUploadSource: %v", err)
+			}
+			if unseekable.read != 0 {
+				t.Fatalf("digestUploadSource buffered %d bytes of an unseekable source", unseekable.read)
+			}
+
+			uploaded, err := io.ReadAll(req.File)
+			if err != nil || string(uploaded) != content {
+				t.Fatalf("upload read %d bytes, %v; want the whole source", len(uploaded), err)
+			}
+
+			got := digest()
+			if got.size != int64(len(content)) {
+				t.Errorf("size = %d, want %d", got.size, len(content))
+			}
+			if got.md5 != hex.EncodeToString(md5Sum[:]) {
+				t.Errorf("md5 = %s", got.md5)
+			}
+			if !bytes.Equal(got.sha256, sha256Sum[:]) {
+				t.Errorf("sha256 = %x", got.sha256)
+			}
+			if string(got.head) != content[:verifyRangeLength] {
+				t.Errorf("head is %d bytes, want the leading %d", len(got.head), verifyRangeLength)
+			}
+		})
+	}
+}
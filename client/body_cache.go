@@ -0,0 +1,120 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// bodyCacheEntry is a cached small object
+type bodyCacheEntry struct {
+	path      string
+	data      []byte
+	info      types.FileInfo
+	expiresAt time.Time
+}
+
+// bodyCache is an LRU cache of small object bodies bounded by per-object and total size
+type bodyCache struct {
+	mu            sync.Mutex
+	maxObjectSize int64
+	maxBytes      int64
+	ttl           time.Duration
+	size          int64
+	order         *list.List
+	entries       map[string]*list.Element
+}
+
+// newBodyCache creates a body cache, or returns nil when caching is disabled
+func newBodyCache(maxObjectSize, maxBytes int64, ttl time.Duration) *bodyCache {
+	if maxObjectSize <= 0 || maxBytes <= 0 || ttl <= 0 {
+		return nil
+	}
+
+	return &bodyCache{
+		maxObjectSize: maxObjectSize,
+		maxBytes:      maxBytes,
+		ttl:           ttl,
+		order:         list.New(),
+		entries:       make(map[string]*list.Element),
+	}
+}
+
+// cacheable reports whether an object of the given size may be cached
+func (bc *bodyCache) cacheable(size int64) bool {
+	return bc != nil && size >= 0 && size <= bc.maxObjectSize && size <= bc.maxBytes
+}
+
+// get returns a cached body and a copy of its file info if present and fresh
+func (bc *bodyCache) get(path string) ([]byte, *types.FileInfo, bool) {
+	if bc == nil {
+		return nil, nil, false
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	element, ok := bc.entries[path]
+	if !ok {
+		return nil, nil, false
+	}
+
+	entry := element.Value.(*bodyCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		bc.removeLocked(element)
+		return nil, nil, false
+	}
+
+	bc.order.MoveToFront(element)
+	info := entry.info
+	return entry.data, &info, true
+}
+
+// put caches a body, evicting the least recently used entries to stay within maxBytes
+func (bc *bodyCache) put(path string, data []byte, info *types.FileInfo) {
+	if !bc.cacheable(int64(len(data))) {
+		return
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if element, ok := bc.entries[path]; ok {
+		bc.removeLocked(element)
+	}
+
+	entry := &bodyCacheEntry{
+		path:      path,
+		data:      data,
+		info:      *info,
+		expiresAt: time.Now().Add(bc.ttl),
+	}
+	bc.entries[path] = bc.order.PushFront(entry)
+	bc.size += int64(len(data))
+
+	for bc.size > bc.maxBytes {
+		bc.removeLocked(bc.order.Back())
+	}
+}
+
+// invalidate drops a cached body, e.g. after the object is overwritten or deleted
+func (bc *bodyCache) invalidate(path string) {
+	if bc == nil {
+		return
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if element, ok := bc.entries[path]; ok {
+		bc.removeLocked(element)
+	}
+}
+
+func (bc *bodyCache) removeLocked(element *list.Element) {
+	entry := bc.order.Remove(element).(*bodyCacheEntry)
+	delete(bc.entries, entry.path)
+	bc.size -= int64(len(entry.data))
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestBodyCache(t *testing.T) {
+	// newCachingClient returns a client caching bodies of up to 16 bytes, 32 bytes in total
+	newCachingClient := func(t *testing.T, ttl time.Duration) (*RustFSClient, *fakeS3) {
+		return newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+			cfg.CacheEnabled = true
+			cfg.CacheTTL = ttl
+			cfg.BodyCacheMaxObjectSize = 16
+			cfg.BodyCacheMaxBytes = 32
+		})
+	}
+	download := func(t *testing.T, c *RustFSClient, path string) (string, error) {
+		t.Helper()
+		body, _, err := c.DownloadFile(context.Background(), path)
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		return string(data), nil
+	}
+	gets := func(fake *fakeS3, path string) int {
+		return len(fake.requests(http.MethodGet, path+"?"))
+	}
+
+	t.Run("second download is served from the cache", func(t *testing.T) {
+		c, fake := newCachingClient(t, time.Hour)
+		fake.put("avatar.png", []byte("small"), nil)
+
+		for range 3 {
+			if data, err := download(t, c, "avatar.png"); err != nil || data != "small" {
+				t.Fatalf("download = %q, %v; want the object", data, err)
+			}
+		}
+		if n := gets(fake, "avatar.png"); n != 1 {
+			t.Errorf("backend served %d GETs, want 1", n)
+		}
+	})
+
+	t.Run("delete invalidates the entry", func(t *testing.T) {
+		c, fake := newCachingClient(t, time.Hour)
+		fake.put("avatar.png", []byte("small"), nil)
+
+		if _, err := download(t, c, "avatar.png"); err != nil {
+			t.Fatalf("download: %v", err)
+		}
+		if err := c.DeleteFile(context.Background(), "avatar.png"); err != nil {
+			t.Fatalf("DeleteFile: %v", err)
+		}
+		if data, err := download(t, c, "avatar.png"); !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("download after delete = %q, %v; want ErrFileNotFound", data, err)
+		}
+	})
+
+	t.Run("overwrite invalidates the entry", func(t *testing.T) {
+		c, fake := newCachingClient(t, time.Hour)
+		fake.put("config.json", []byte("v1"), nil)
+
+		if _, err := download(t, c, "config.json"); err != nil {
+			t.Fatalf("download: %v", err)
+		}
+		if _, err := c.UploadFile(context.Background(), uploadRequest("config.json", "v2")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		if data, err := download(t, c, "config.json"); err != nil || data != "v2" {
+			t.Errorf("download after overwrite = %q, %v; want v2", data, err)
+		}
+	})
+
+	t.Run("entries expire after the TTL", func(t *testing.T) {
+		c, fake := newCachingClient(t, 50*time.Millisecond)
+		fake.put("avatar.png", []byte("small"), nil)
+
+		download(t, c, "avatar.png")
+		time.Sleep(100 * time.Millisecond)
+		download(t, c, "avatar.png")
+		if n := gets(fake, "avatar.png"); n != 2 {
+			t.Errorf("backend served %d GETs, want the expired entry refetched", n)
+		}
+	})
+
+	t.Run("objects over the size limit aren't cached", func(t *testing.T) {
+		c, fake := newCachingClient(t, time.Hour)
+		large := strings.Repeat("x", 17)
+		fake.put("large.bin", []byte(large), nil)
+
+		for range 2 {
+			if data, err := download(t, c, "large.bin"); err != nil || data != large {
+				t.Fatalf("download = %d bytes, %v; want the object", len(data), err)
+			}
+		}
+		if n := gets(fake, "large.bin"); n != 2 {
+			t.Errorf("backend served %d GETs, want every download", n)
+		}
+	})
+
+	t.Run("least recently used entry is evicted", func(t *testing.T) {
+		c, fake := newCachingClient(t, time.Hour)
+		for _, path := range []string{"a.bin", "b.bin", "c.bin"} {
+			fake.put(path, []byte(strings.Repeat(path[:1], 16)), nil)
+		}
+
+		download(t, c, "a.bin")
+		download(t, c, "b.bin")
+		download(t, c, "a.bin")
+		download(t, c, "c.bin") // the 32-byte budget evicts b
+		download(t, c, "a.bin")
+		download(t, c, "b.bin")
+
+		if n := gets(fake, "a.bin"); n != 1 {
+			t.Errorf("a.bin fetched %d times, want it kept as recently used", n)
+		}
+		if n := gets(fake, "b.bin"); n != 2 {
+			t.Errorf("b.bin fetched %d times, want it evicted", n)
+		}
+	})
+}
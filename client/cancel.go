@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// canceller holds a client's root context. Every operation derives its context from the root,
+// so cancelling the root aborts all in-flight operations at once.
+type canceller struct {
+	mu     sync.RWMutex
+	root   context.Context
+	cancel context.CancelFunc
+}
+
+// newCanceller creates a canceller with a live root context
+func newCanceller() *canceller {
+	cc := &canceller{}
+	cc.reset()
+	return cc
+}
+
+// derive returns a context cancelled when either ctx or the root is. It fails fast with
+// ErrClientCancelled once the root has been cancelled.
+func (cc *canceller) derive(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	cc.mu.RLock()
+	root := cc.root
+	cc.mu.RUnlock()
+
+	if root.Err() != nil {
+		return nil, nil, clientCancelledError(nil)
+	}
+
+	opCtx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(root, func() {
+		cancel(ErrClientCancelled)
+	})
+	return opCtx, func() {
+		stop()
+		cancel(context.Canceled)
+	}, nil
+}
+
+// cancelAll cancels the root context
+func (cc *canceller) cancelAll() {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	cc.cancel()
+}
+
+// reset replaces a cancelled root so new operations can run again
+func (cc *canceller) reset() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.root == nil || cc.root.Err() != nil {
+		cc.root, cc.cancel = context.WithCancel(context.Background())
+	}
+}
+
+// clientCancelledError wraps ErrClientCancelled, keeping the error the aborted operation returned
+func clientCancelledError(cause error) error {
+	return newSentinelError(503, "CLIENT_CANCELLED", ErrClientCancelled, cause)
+}
+
+// cancellationError replaces err with ErrClientCancelled when opCtx was aborted by CancelAll
+func cancellationError(opCtx context.Context, err error) error {
+	if err != nil && errors.Is(context.Cause(opCtx), ErrClientCancelled) {
+		return clientCancelledError(err)
+	}
+	return err
+}
+
+// cancelOnClose releases an operation context once a streamed body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// CancelAll aborts every in-flight operation of the client. Later operations fail with
+// ErrClientCancelled until ResetCancellation is called.
+func (c *RustFSClient) CancelAll() {
+	c.canceller.cancelAll()
+}
+
+// ResetCancellation re-enables a client after CancelAll
+func (c *RustFSClient) ResetCancellation() {
+	c.canceller.reset()
+}
+
+// CancelAll aborts every in-flight mock operation. Later operations fail with
+// ErrClientCancelled until ResetCancellation or Reset is called.
+func (m *MockRustFSClient) CancelAll() {
+	m.canceller.cancelAll()
+}
+
+// ResetCancellation re-enables the mock client after CancelAll
+func (m *MockRustFSClient) ResetCancellation() {
+	m.canceller.reset()
+}
+
+// SetLatency adds a delay to every mock upload, download, delete and info request, so tests
+// can observe operations in flight. The delay is cut short when the context is cancelled.
+func (m *MockRustFSClient) SetLatency(latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency = latency
+}
+
+// simulateDelay waits for the base delay of an operation plus the configured latency; the
+// caller must hold m.mu
+func (m *MockRustFSClient) simulateDelay(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay + m.latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// startOperations runs each operation in its own goroutine and returns a channel with
+// their errors, sent once all of them have started
+func startOperations(ops map[string]func() error) <-chan map[string]error {
+	done := make(chan map[string]error, 1)
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(ops))
+	)
+	for name, op := range ops {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := op()
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		done <- results
+	}()
+	return done
+}
+
+// waitCancelled waits for the operations to return after CancelAll and checks each failed
+// with ErrClientCancelled
+func waitCancelled(t *testing.T, done <-chan map[string]error) {
+	t.Helper()
+	select {
+	case results := <-done:
+		for name, err := range results {
+			if !errors.Is(err, ErrClientCancelled) {
+				t.Errorf("%s error = %v, want ErrClientCancelled", name, err)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight operations did not return after CancelAll")
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(3)
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		started.Done()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+	defer close(release)
+	ctx := context.Background()
+
+	done := startOperations(map[string]func() error{
+		"upload": func() error {
+			_, err := c.UploadFile(ctx, uploadRequest("slow.txt", "content"))
+			return err
+		},
+		"download": func() error {
+			body, _, err := c.DownloadFile(ctx, "slow.txt")
+			if err == nil {
+				body.Close()
+			}
+			return err
+		},
+		"info": func() error {
+			_, err := c.GetFileInfo(ctx, "slow.txt")
+			return err
+		},
+	})
+	started.Wait()
+
+	c.CancelAll()
+	waitCancelled(t, done)
+
+	start := time.Now()
+	if _, err := c.GetFileInfo(ctx, "slow.txt"); !errors.Is(err, ErrClientCancelled) {
+		t.Fatalf("GetFileInfo after CancelAll error = %v, want ErrClientCancelled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("operation after CancelAll took %v, want it to fail fast", elapsed)
+	}
+}
+
+func TestMockCancelAll(t *testing.T) {
+	m := NewMockRustFSClientBuilder().WithFile("slow.txt", 7, "text/plain").Build()
+	m.SetLatency(time.Minute)
+	ctx := context.Background()
+
+	done := startOperations(map[string]func() error{
+		"upload": func() error {
+			_, err := m.UploadFile(ctx, &types.UploadRequest{
+				File:        strings.NewReader("content"),
+				FileSize:    7,
+				ContentType: "text/plain",
+				BucketPath:  "new.txt",
+			})
+			return err
+		},
+		"download": func() error {
+			body, _, err := m.DownloadFile(ctx, "slow.txt")
+			if err == nil {
+				body.Close()
+			}
+			return err
+		},
+		"delete": func() error {
+			return m.DeleteFile(ctx, "slow.txt")
+		},
+		"info": func() error {
+			_, err := m.GetFileInfo(ctx, "slow.txt")
+			return err
+		},
+	})
+	// The latency keeps every operation in flight until cancelled
+	time.Sleep(50 * time.Millisecond)
+
+	m.CancelAll()
+	waitCancelled(t, done)
+
+	if _, err := m.GetFileInfo(ctx, "slow.txt"); !errors.Is(err, ErrClientCancelled) {
+		t.Fatalf("GetFileInfo after CancelAll error = %v, want ErrClientCancelled", err)
+	}
+
+	m.ResetCancellation()
+	m.SetLatency(0)
+	if _, err := m.GetFileInfo(ctx, "slow.txt"); err != nil {
+		t.Fatalf("GetFileInfo after ResetCancellation: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+)
+
+// Optional server capabilities gating client operations
+const (
+	CapabilityMultipart = "multipart"
+	CapabilityPresign   = "presign"
+	CapabilityRestore   = "restore"
+	CapabilitySearch    = "search"
+	CapabilityTagging   = "tagging"
+)
+
+// defaultCapabilities are assumed for S3-compatible servers without a capabilities endpoint
+var defaultCapabilities = []string{CapabilityMultipart, CapabilityPresign, CapabilityRestore, CapabilityTagging}
+
+// capabilitiesResponse is the body returned by the capabilities endpoint
+type capabilitiesResponse struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+// capabilityCache remembers which optional operations the server supports, refreshing
+// periodically. Overrides take precedence over anything the server reports.
+type capabilityCache struct {
+	mu        sync.Mutex
+	fetch     func(ctx context.Context) ([]string, error)
+	refresh   time.Duration
+	overrides map[string]bool
+	supported map[string]bool
+	fetchedAt time.Time
+}
+
+// newCapabilityCache creates a cache with force-enabled and force-disabled capabilities
+func newCapabilityCache(fetch func(ctx context.Context) ([]string, error), refresh time.Duration, enabled, disabled []string) *capabilityCache {
+	overrides := make(map[string]bool, len(enabled)+len(disabled))
+	for _, name := range enabled {
+		overrides[name] = true
+	}
+	for _, name := range disabled {
+		overrides[name] = false
+	}
+
+	return &capabilityCache{
+		fetch:     fetch,
+		refresh:   refresh,
+		overrides: overrides,
+	}
+}
+
+// supports reports whether a capability is available. When the server's capabilities have
+// never been fetched successfully the operation is allowed, so discovery failures never block work.
+func (cc *capabilityCache) supports(ctx context.Context, name string) bool {
+	if enabled, ok := cc.overrides[name]; ok {
+		return enabled
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.fetchedAt.IsZero() || (cc.refresh > 0 && time.Since(cc.fetchedAt) > cc.refresh) {
+		cc.fetchedAt = time.Now()
+		if capabilities, err := cc.fetch(ctx); err == nil {
+			cc.storeLocked(capabilities)
+		}
+	}
+
+	if cc.supported == nil {
+		return true
+	}
+	return cc.supported[name]
+}
+
+// store replaces the cached capabilities
+func (cc *capabilityCache) store(capabilities []string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.fetchedAt = time.Now()
+	cc.storeLocked(capabilities)
+}
+
+func (cc *capabilityCache) storeLocked(capabilities []string) {
+	cc.supported = make(map[string]bool, len(capabilities))
+	for _, name := range capabilities {
+		cc.supported[name] = true
+	}
+}
+
+// GetCapabilities fetches the server's optional capabilities and refreshes the capability cache.
+// Servers without a capabilities endpoint are assumed to offer the S3 baseline.
+func (c *RustFSClient) GetCapabilities(ctx context.Context) ([]string, error) {
+	capabilities, err := c.fetchCapabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.capabilities.store(capabilities)
+	return capabilities, nil
+}
+
+// fetchCapabilities queries the capabilities endpoint
+func (c *RustFSClient) fetchCapabilities(ctx context.Context) ([]string, error) {
+	resp, err := c.doSignedRequest(ctx, http.MethodGet, c.apiURL("capabilities"), nil)
+	if err != nil {
+		return nil, apperror.NewAppError(500, "CAPABILITIES_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return append([]string(nil), defaultCapabilities...), nil
+	default:
+		return nil, apperror.NewAppError(resp.StatusCode, "CAPABILITIES_FAILED",
+			fmt.Errorf("capabilities endpoint returned %s", resp.Status))
+	}
+
+	var body capabilitiesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, apperror.NewAppError(502, "CAPABILITIES_FAILED", err)
+	}
+
+	return body.Capabilities, nil
+}
+
+// requireCapability fails with ErrUnsupportedOperation when the server lacks a capability
+func (c *RustFSClient) requireCapability(ctx context.Context, name string) error {
+	if c.capabilities.supports(ctx, name) {
+		return nil
+	}
+	return newSentinelError(501, "UNSUPPORTED_OPERATION", ErrUnsupportedOperation,
+		fmt.Errorf("server does not support %s", name))
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestCapabilityGating(t *testing.T) {
+	operations := []struct {
+		name       string
+		capability string
+		call       func(c *RustFSClient) error
+	}{
+		{"UploadLargeFile", CapabilityMultipart, func(c *RustFSClient) error {
+			req := uploadRequest("big.txt", "content")
+			req.FileSize = c.config.PartSize() + 1
+			_, err := c.UploadLargeFile(context.Background(), req)
+			return err
+		}},
+		{"ListIncompleteUploads", CapabilityMultipart, func(c *RustFSClient) error {
+			_, err := c.ListIncompleteUploads(context.Background(), "")
+			return err
+		}},
+		{"SearchFiles", CapabilitySearch, func(c *RustFSClient) error {
+			_, err := c.SearchFiles(context.Background(), &SearchOptions{Query: "report"})
+			return err
+		}},
+		{"SearchByMetadata", CapabilitySearch, func(c *RustFSClient) error {
+			_, err := c.SearchByMetadata(context.Background(), "owner", "alice")
+			return err
+		}},
+		{"GetTags", CapabilityTagging, func(c *RustFSClient) error {
+			_, err := c.GetTags(context.Background(), "doc.txt")
+			return err
+		}},
+		{"SetTags", CapabilityTagging, func(c *RustFSClient) error {
+			return c.SetTags(context.Background(), "doc.txt", map[string]string{"team": "ops"})
+		}},
+		{"RestoreObject", CapabilityRestore, func(c *RustFSClient) error {
+			return c.RestoreObject(context.Background(), "doc.txt", 1)
+		}},
+	}
+
+	// newGatedClient returns a client whose server reports the given capabilities, and
+	// counters of capability lookups and of all other requests
+	newGatedClient := func(t *testing.T, reported []string, configure ...func(cfg *config.RustFSConfig)) (*RustFSClient, func() (int, int)) {
+		c, fake := newFakeS3Client(t, configure...)
+		var mu sync.Mutex
+		lookups, other := 0, 0
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path == apiPrefix+"capabilities" {
+				lookups++
+				json.NewEncoder(w).Encode(capabilitiesResponse{Capabilities: reported})
+				return true
+			}
+			other++
+			return false
+		}
+		return c, func() (int, int) {
+			mu.Lock()
+			defer mu.Unlock()
+			return lookups, other
+		}
+	}
+
+	for _, op := range operations {
+		t.Run(op.name+" reported missing", func(t *testing.T) {
+			c, counts := newGatedClient(t, nil)
+
+			err := op.call(c)
+			if !errors.Is(err, ErrUnsupportedOperation) || errorCode(err) != "UNSUPPORTED_OPERATION" {
+				t.Errorf("%s = %v, want ErrUnsupportedOperation", op.name, err)
+			}
+			if lookups, other := counts(); lookups != 1 || other != 0 {
+				t.Errorf("%d capability lookups and %d other requests, want 1 lookup and no operation requests", lookups, other)
+			}
+		})
+
+		t.Run(op.name+" disabled by config", func(t *testing.T) {
+			c, counts := newGatedClient(t, []string{op.capability}, func(cfg *config.RustFSConfig) {
+				cfg.DisabledCapabilities = []string{op.capability}
+			})
+
+			if err := op.call(c); !errors.Is(err, ErrUnsupportedOperation) {
+				t.Errorf("%s = %v, want ErrUnsupportedOperation", op.name, err)
+			}
+			if lookups, other := counts(); lookups != 0 || other != 0 {
+				t.Errorf("%d capability lookups and %d other requests, want none", lookups, other)
+			}
+		})
+	}
+
+	t.Run("capabilities are cached between calls", func(t *testing.T) {
+		c, counts := newGatedClient(t, nil)
+		for range 3 {
+			c.GetTags(context.Background(), "doc.txt")
+		}
+		if lookups, _ := counts(); lookups != 1 {
+			t.Errorf("%d capability lookups, want 1", lookups)
+		}
+	})
+
+	t.Run("enabled by config despite the server", func(t *testing.T) {
+		c, counts := newGatedClient(t, nil, func(cfg *config.RustFSConfig) {
+			cfg.EnabledCapabilities = []string{CapabilityTagging}
+		})
+		if err := c.SetTags(context.Background(), "doc.txt", map[string]string{"team": "ops"}); errors.Is(err, ErrUnsupportedOperation) {
+			t.Errorf("SetTags = %v, want the override to allow it", err)
+		}
+		if _, other := counts(); other == 0 {
+			t.Error("SetTags sent no request despite the override")
+		}
+	})
+}
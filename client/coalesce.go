@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// DefaultCoalesceWindow is how long a CoalescingFileInfoReader waits for more paths before
+// sending a batch
+const DefaultCoalesceWindow = 2 * time.Millisecond
+
+// FileInfoResult is the outcome of one path of a batched info lookup. Exactly one of Info and
+// Err is set.
+type FileInfoResult struct {
+	Info *types.FileInfo
+	Err  error
+}
+
+// getInfoEach looks up every path concurrently, bounded by limit, keyed by path
+func getInfoEach(ctx context.Context, storage FileInfoReader, paths []string, limit int) map[string]FileInfoResult {
+	var mu sync.Mutex
+	results := make(map[string]FileInfoResult, len(paths))
+
+	// Tasks never fail, so one missing file doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, limit)
+	for _, path := range paths {
+		path := path
+		group.Go(func() error {
+			info, err := storage.GetFileInfo(groupCtx, path)
+			mu.Lock()
+			results[path] = FileInfoResult{Info: info, Err: err}
+			mu.Unlock()
+			return nil
+		})
+	}
+	group.Wait()
+
+	return results
+}
+
+// GetFileInfos looks up the info of many files at once, keyed by path. Lookups run concurrently,
+// bounded by the configured concurrency, and a failed lookup doesn't affect the others.
+func (c *RustFSClient) GetFileInfos(ctx context.Context, paths []string) (map[string]FileInfoResult, error) {
+	return getInfoEach(ctx, c, paths, c.config.ConcurrentUploads), nil
+}
+
+// GetFileInfos looks up the info of many files in mock storage, keyed by path
+func (m *MockRustFSClient) GetFileInfos(ctx context.Context, paths []string) (map[string]FileInfoResult, error) {
+	return getInfoEach(ctx, m, paths, 0), nil
+}
+
+// CoalescingFileInfoReader reduces backend load from concurrent GetFileInfo calls. Calls for a
+// path already being looked up share that lookup, and paths requested within the window are
+// sent together as one GetFileInfos batch when the storage supports it. It is safe for
+// concurrent use.
+type CoalescingFileInfoReader struct {
+	storage FileInfoReader
+	window  time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*infoCall
+	queued  []string
+	// batchCtx carries the values of the first caller of the queued batch
+	batchCtx context.Context
+}
+
+// infoCall is one shared lookup; done is closed once info and err are set
+type infoCall struct {
+	done chan struct{}
+	info *types.FileInfo
+	err  error
+}
+
+// NewCoalescingFileInfoReader wraps storage, batching paths requested within window. A window of
+// zero or less uses DefaultCoalesceWindow.
+func NewCoalescingFileInfoReader(storage FileInfoReader, window time.Duration) *CoalescingFileInfoReader {
+	if window <= 0 {
+		window = DefaultCoalesceWindow
+	}
+	return &CoalescingFileInfoReader{
+		storage: storage,
+		window:  window,
+		pending: make(map[string]*infoCall),
+	}
+}
+
+// NewCoalescingFileInfoReaderFromConfig wraps storage like NewCoalescingFileInfoReader, using
+// the configured InfoCoalesceWindow
+func NewCoalescingFileInfoReaderFromConfig(storage FileInfoReader, cfg *config.RustFSConfig) *CoalescingFileInfoReader {
+	return NewCoalescingFileInfoReader(storage, cfg.InfoCoalesceWindow)
+}
+
+// GetFileInfo returns the info of path, sharing a lookup with concurrent calls. Cancelling ctx
+// abandons the wait but not the shared lookup, which other callers may still need.
+func (r *CoalescingFileInfoReader) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	r.mu.Lock()
+	call, ok := r.pending[path]
+	if !ok {
+		call = &infoCall{done: make(chan struct{})}
+		r.pending[path] = call
+		r.queued = append(r.queued, path)
+		if len(r.queued) == 1 {
+			r.batchCtx = context.WithoutCancel(ctx)
+			time.AfterFunc(r.window, r.flush)
+		}
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.info, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush looks up the queued paths and completes their calls
+func (r *CoalescingFileInfoReader) flush() {
+	r.mu.Lock()
+	paths, ctx := r.queued, r.batchCtx
+	r.queued, r.batchCtx = nil, nil
+	r.mu.Unlock()
+
+	results := r.lookup(ctx, paths)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, path := range paths {
+		call := r.pending[path]
+		delete(r.pending, path)
+		call.info, call.err = results[path].Info, results[path].Err
+		close(call.done)
+	}
+}
+
+// lookup fetches the info of paths with a single request where possible
+func (r *CoalescingFileInfoReader) lookup(ctx context.Context, paths []string) map[string]FileInfoResult {
+	if len(paths) == 1 {
+		info, err := r.storage.GetFileInfo(ctx, paths[0])
+		return map[string]FileInfoResult{paths[0]: {Info: info, Err: err}}
+	}
+
+	batcher, ok := r.storage.(FileInfoBatchReader)
+	if !ok {
+		return getInfoEach(ctx, r.storage, paths, 0)
+	}
+
+	results, err := batcher.GetFileInfos(ctx, paths)
+	if err != nil {
+		results = make(map[string]FileInfoResult, len(paths))
+		for _, path := range paths {
+			results[path] = FileInfoResult{Err: err}
+		}
+		return results
+	}
+	for _, path := range paths {
+		if _, ok := results[path]; !ok {
+			results[path] = FileInfoResult{Err: newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, nil)}
+		}
+	}
+	return results
+}
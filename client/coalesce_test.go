@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// countingInfoStorage counts single and batched info lookups of files that all exist
+type countingInfoStorage struct {
+	single  atomic.Int32
+	batches atomic.Int32
+}
+
+func (s *countingInfoStorage) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	s.single.Add(1)
+	return &types.FileInfo{Path: path}, nil
+}
+
+func (s *countingInfoStorage) GetFileInfos(ctx context.Context, paths []string) (map[string]FileInfoResult, error) {
+	s.batches.Add(1)
+	results := make(map[string]FileInfoResult, len(paths))
+	for _, path := range paths {
+		results[path] = FileInfoResult{Info: &types.FileInfo{Path: path}}
+	}
+	return results, nil
+}
+
+func TestCoalescingFileInfoReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		paths       []string
+		wantSingle  int32
+		wantBatches int32
+	}{
+		{"concurrent calls for one path", []string{"a", "a", "a", "a", "a", "a", "a", "a"}, 1, 0},
+		{"concurrent calls for many paths", []string{"a", "b", "c", "a", "b", "c"}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &countingInfoStorage{}
+			reader := NewCoalescingFileInfoReader(storage, 50*time.Millisecond)
+
+			var wg sync.WaitGroup
+			for _, path := range tt.paths {
+				wg.Add(1)
+				go func(path string) {
+					defer wg.Done()
+					info, err := reader.GetFileInfo(context.Background(), path)
+					if err != nil || info.Path != path {
+						t.Errorf("GetFileInfo(%q) = %v, %v", path, info, err)
+					}
+				}(path)
+			}
+			wg.Wait()
+
+			if got := storage.single.Load(); got != tt.wantSingle {
+				t.Errorf("single lookups = %d, want %d", got, tt.wantSingle)
+			}
+			if got := storage.batches.Load(); got != tt.wantBatches {
+				t.Errorf("batched lookups = %d, want %d", got, tt.wantBatches)
+			}
+		})
+	}
+}
+
+func TestCoalescingFileInfoReaderSendsOneRequest(t *testing.T) {
+	c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+		cfg.InfoCoalesceWindow = 50 * time.Millisecond
+	})
+	fake.put("doc.txt", []byte("content"), nil)
+	reader := NewCoalescingFileInfoReaderFromConfig(c, c.config)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := reader.GetFileInfo(context.Background(), "doc.txt"); err != nil {
+				t.Errorf("GetFileInfo: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if heads := fake.requests(http.MethodHead, "doc.txt"); len(heads) != 1 {
+		t.Errorf("%d callers sent %d HEAD requests, want 1", callers, len(heads))
+	}
+}
+
+func TestNewCoalescingFileInfoReaderFromConfig(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   time.Duration
+	}{
+		{0, DefaultCoalesceWindow},
+		{-time.Second, DefaultCoalesceWindow},
+		{25 * time.Millisecond, 25 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.window), func(t *testing.T) {
+			reader := NewCoalescingFileInfoReaderFromConfig(&countingInfoStorage{}, &config.RustFSConfig{InfoCoalesceWindow: tt.window})
+			if reader.window != tt.want {
+				t.Errorf("window = %v, want %v", reader.window, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// ContentEncodingGzip is the Content-Encoding of uploads compressed by the client
+const ContentEncodingGzip = "gzip"
+
+// incompressibleContentTypes are formats that are already compressed, where gzip only costs CPU
+var incompressibleContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"image/avif",
+	"video/*",
+	"audio/*",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/zstd",
+	"font/woff2",
+}
+
+// shouldCompress reports whether req is worth compressing: it has a body, isn't encoded
+// already and its content type isn't a compressed format
+func shouldCompress(req *types.UploadRequest) bool {
+	if req.File == nil || req.ContentEncoding != "" {
+		return false
+	}
+
+	contentType := req.ContentType
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	for _, pattern := range incompressibleContentTypes {
+		if utils.MatchContentType(pattern, contentType) {
+			return false
+		}
+	}
+	return true
+}
+
+// uploadCompression gzips an upload source on the fly. The compressed size isn't known up
+// front, so the request it produces has no FileSize.
+type uploadCompression struct {
+	reader *io.PipeReader
+	source *utils.CountingReader
+	done   chan struct{}
+}
+
+// compressUpload returns a copy of req whose File is the gzip stream of req.File at level
+func compressUpload(req *types.UploadRequest, level int) (*types.UploadRequest, *uploadCompression) {
+	pr, pw := io.Pipe()
+	compression := &uploadCompression{
+		reader: pr,
+		source: utils.NewCountingReader(req.File, nil),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(compression.done)
+		gz, err := gzip.NewWriterLevel(pw, level)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(gz, compression.source); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(gz.Close())
+	}()
+
+	compressed := *req
+	compressed.File = pr
+	compressed.FileSize = 0
+	compressed.ContentEncoding = ContentEncodingGzip
+	return &compressed, compression
+}
+
+// uncompressed returns the number of source bytes compressed so far
+func (u *uploadCompression) uncompressed() int64 {
+	return u.source.Count()
+}
+
+// close stops the compressor, e.g. when the upload failed before reading the whole stream, and
+// waits for it to exit
+func (u *uploadCompression) close() {
+	u.reader.Close()
+	<-u.done
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+func TestCompressedUploadStreams(t *testing.T) {
+	// Random bytes don't compress, so the large source stays larger than a part once gzipped
+	random := make([]byte, 2*utils.MinPartSize+1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		name          string
+		source        []byte
+		wantMultipart bool
+	}{
+		{"small source is sent in one request", []byte(strings.Repeat("compressible ", 1000)), false},
+		{"large source streams in parts", random, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+
+			response, err := c.UploadFileWithOptions(context.Background(), &types.UploadRequest{
+				File:        bytes.NewReader(tt.source),
+				FileSize:    int64(len(tt.source)),
+				ContentType: "application/octet-stream",
+				BucketPath:  "data.bin",
+			}, &UploadOptions{EnableCompression: true})
+			if err != nil {
+				t.Fatalf("UploadFileWithOptions: %v", err)
+			}
+
+			multipart := len(fake.requests(http.MethodPost, "data.bin?uploads")) > 0
+			if multipart != tt.wantMultipart {
+				t.Errorf("multipart = %v, want %v", multipart, tt.wantMultipart)
+			}
+
+			object, ok := fake.get("data.bin")
+			if !ok {
+				t.Fatal("object not stored")
+			}
+			if response.Size != int64(len(object.data)) {
+				t.Errorf("response size = %d, stored %d bytes", response.Size, len(object.data))
+			}
+			if response.UncompressedSize != int64(len(tt.source)) {
+				t.Errorf("uncompressed size = %d, want %d", response.UncompressedSize, len(tt.source))
+			}
+
+			gz, err := gzip.NewReader(bytes.NewReader(object.data))
+			if err != nil {
+				t.Fatalf("stored object isn't gzip: %v", err)
+			}
+			data, err := io.ReadAll(gz)
+			if err != nil || !bytes.Equal(data, tt.source) {
+				t.Errorf("stored object decompresses to %d bytes, %v; want the source", len(data), err)
+			}
+		})
+	}
+}
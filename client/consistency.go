@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// consistencyRetryConfig is the backoff used while waiting for a new object to become visible
+var consistencyRetryConfig = &types.RetryConfig{
+	MaxAttempts: math.MaxInt32, // bounded by the consistency window instead
+	Delay:       50 * time.Millisecond,
+	Backoff:     1.5,
+}
+
+// GetFileInfoConsistent reads file information, retrying while the object is not found until it
+// appears or within elapses. This covers the read-after-write gap of eventually consistent
+// backends. Errors other than ErrFileNotFound are returned immediately.
+func GetFileInfoConsistent(ctx context.Context, storage FileInfoReader, path string, within time.Duration) (*types.FileInfo, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, within)
+	defer cancel()
+
+	var info *types.FileInfo
+	var notFound, permanent error
+
+	utils.RetryWithBackoffWithContext(waitCtx, func(ctx context.Context) error {
+		result, err := storage.GetFileInfo(ctx, path)
+		switch {
+		case err == nil:
+			info = result
+			return nil
+		case errors.Is(err, ErrFileNotFound):
+			notFound = err
+			return err
+		default:
+			// Stop retrying and surface the error as is
+			permanent = err
+			return nil
+		}
+	}, consistencyRetryConfig)
+
+	switch {
+	case info != nil:
+		return info, nil
+	case permanent != nil:
+		return nil, permanent
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case notFound != nil:
+		return nil, notFound
+	default:
+		return nil, waitCtx.Err()
+	}
+}
+
+// GetFileInfoConsistent retrieves file information, waiting up to within for a newly written object to appear
+func (c *RustFSClient) GetFileInfoConsistent(ctx context.Context, path string, within time.Duration) (*types.FileInfo, error) {
+	return GetFileInfoConsistent(ctx, c, path, within)
+}
+
+// GetFileInfoConsistent retrieves file information from mock storage, waiting up to within for it to appear
+func (m *MockRustFSClient) GetFileInfoConsistent(ctx context.Context, path string, within time.Duration) (*types.FileInfo, error) {
+	return GetFileInfoConsistent(ctx, m, path, within)
+}
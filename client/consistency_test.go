@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// laggingReader hides mock objects until visibleAt, as an eventually consistent backend would
+type laggingReader struct {
+	*MockRustFSClient
+	visibleAt time.Time
+	err       error
+	calls     atomic.Int64
+}
+
+func (r *laggingReader) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	r.calls.Add(1)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if time.Now().Before(r.visibleAt) {
+		return nil, ErrFileNotFound
+	}
+	return r.MockRustFSClient.GetFileInfo(ctx, path)
+}
+
+func TestGetFileInfoConsistent(t *testing.T) {
+	newReader := func(lag time.Duration) *laggingReader {
+		m := NewMockRustFSClientBuilder().WithFile("new.txt", 7, "text/plain").Build()
+		return &laggingReader{MockRustFSClient: m, visibleAt: time.Now().Add(lag)}
+	}
+
+	t.Run("object appears within the window", func(t *testing.T) {
+		r := newReader(150 * time.Millisecond)
+
+		info, err := GetFileInfoConsistent(context.Background(), r, "new.txt", 2*time.Second)
+		if err != nil {
+			t.Fatalf("GetFileInfoConsistent: %v", err)
+		}
+		if info.Path != "new.txt" {
+			t.Errorf("info is for %s, want new.txt", info.Path)
+		}
+		if r.calls.Load() < 2 {
+			t.Errorf("%d lookups, want retries while the object was missing", r.calls.Load())
+		}
+	})
+
+	t.Run("permanently missing object times out", func(t *testing.T) {
+		r := newReader(time.Hour)
+
+		start := time.Now()
+		_, err := GetFileInfoConsistent(context.Background(), r, "new.txt", 200*time.Millisecond)
+		elapsed := time.Since(start)
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("GetFileInfoConsistent = %v, want ErrFileNotFound", err)
+		}
+		if elapsed < 200*time.Millisecond || elapsed > time.Second {
+			t.Errorf("gave up after %v, want the 200ms window", elapsed)
+		}
+	})
+
+	t.Run("other errors fail at once", func(t *testing.T) {
+		r := newReader(0)
+		r.err = ErrUnauthenticated
+
+		_, err := GetFileInfoConsistent(context.Background(), r, "new.txt", 2*time.Second)
+		if !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("GetFileInfoConsistent = %v, want ErrUnauthenticated", err)
+		}
+		if r.calls.Load() != 1 {
+			t.Errorf("%d lookups, want no retry", r.calls.Load())
+		}
+	})
+
+	t.Run("cancelled caller", func(t *testing.T) {
+		r := newReader(time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := GetFileInfoConsistent(ctx, r, "new.txt", time.Hour)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetFileInfoConsistent = %v, want the caller's deadline", err)
+		}
+	})
+}
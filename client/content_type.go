@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"mime"
+	"net/http"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// sniffLength is the number of leading bytes examined by content type detection
+const sniffLength = 512
+
+// FixContentType sniffs an object's content type from its first bytes and, if it differs from
+// the stored type, updates the object in place without re-uploading it. The returned info
+// reflects the object after any repair.
+func (c *RustFSClient) FixContentType(ctx context.Context, path string) (*types.FileInfo, error) {
+	info, _, err := c.fixContentType(ctx, path)
+	return info, err
+}
+
+// fixContentType implements FixContentType, also reporting whether the object was changed
+func (c *RustFSClient) fixContentType(ctx context.Context, path string) (*types.FileInfo, bool, error) {
+	path = c.objectKey(path)
+	current, err := c.GetFileInfo(ctx, path)
+	if err != nil {
+		return nil, false, err
+	}
+	// Empty objects have nothing to sniff, and a ranged read of them fails with 416
+	if current.Size == 0 {
+		return current, false, nil
+	}
+
+	head, err := c.readHead(ctx, path, sniffLength)
+	if err != nil {
+		return nil, false, err
+	}
+
+	contentType, ok := correctedContentType(current.ContentType, head)
+	if !ok {
+		return current, false, nil
+	}
+
+	info, err := c.rewriteObjectHeaders(ctx, current, current.Metadata, contentType)
+	if err != nil {
+		return nil, false, err
+	}
+	return info, true, nil
+}
+
+// FixContentTypePrefix repairs the content type of every object under prefix and returns
+// the objects that were changed
+func (c *RustFSClient) FixContentTypePrefix(ctx context.Context, prefix string) ([]*types.FileInfo, error) {
+	return fixContentTypePrefix(ctx, c, prefix)
+}
+
+// contentTypeRepairer is the storage surface needed for bulk content type repair
+type contentTypeRepairer interface {
+	WalkFiles(ctx context.Context, opts *ListOptions, fn func(file *types.FileInfo) error) error
+	fixContentType(ctx context.Context, path string) (*types.FileInfo, bool, error)
+}
+
+// fixContentTypePrefix walks prefix and repairs each object, collecting the changed ones.
+// On error the objects repaired so far are returned alongside it.
+func fixContentTypePrefix(ctx context.Context, storage contentTypeRepairer, prefix string) ([]*types.FileInfo, error) {
+	var paths []string
+	if err := storage.WalkFiles(ctx, &ListOptions{Prefix: prefix}, func(file *types.FileInfo) error {
+		paths = append(paths, file.Path)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	fixed := make([]*types.FileInfo, 0)
+	for _, path := range paths {
+		info, changed, err := storage.fixContentType(ctx, path)
+		if err != nil {
+			return fixed, err
+		}
+		if changed {
+			fixed = append(fixed, info)
+		}
+	}
+
+	return fixed, nil
+}
+
+// correctedContentType returns the sniffed type of head if it should replace current.
+// Sniffing only recognises a limited set of formats, so an unrecognised result never replaces
+// a stored type, and the generic text/plain only replaces a missing or octet-stream type.
+func correctedContentType(current string, head []byte) (string, bool) {
+	sniffed := http.DetectContentType(head)
+	sniffedType, _, _ := mime.ParseMediaType(sniffed)
+	currentType, _, _ := mime.ParseMediaType(current)
+
+	generic := currentType == "" || currentType == "application/octet-stream"
+
+	switch {
+	case sniffedType == "application/octet-stream":
+		return "", false
+	case sniffedType == currentType:
+		return "", false
+	case sniffedType == "text/plain" && !generic:
+		return "", false
+	default:
+		return sniffed, true
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFixContentTypePrefix(t *testing.T) {
+	c, fake := newFakeS3Client(t)
+	fake.put("docs/empty.txt", nil, nil)
+	fake.put("docs/page.html", []byte("<!DOCTYPE html><html><body>hi</body></html>"), nil)
+	fake.put("docs/data.bin", []byte{0x00, 0x01, 0x02}, nil)
+
+	fixed, err := c.FixContentTypePrefix(context.Background(), "docs/")
+	if err != nil {
+		t.Fatalf("FixContentTypePrefix: %v", err)
+	}
+	if len(fixed) != 1 || fixed[0].Path != "docs/page.html" {
+		t.Fatalf("fixed = %v, want only docs/page.html", fixed)
+	}
+	if object, _ := fake.get("docs/page.html"); object.contentType != "text/html; charset=utf-8" {
+		t.Errorf("content type = %q, want text/html", object.contentType)
+	}
+}
+
+func TestFixContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data []byte
+		want string
+	}{
+		{"empty object", "empty.txt", nil, "application/octet-stream"},
+		{"unnormalized path", "/docs//page.html", []byte("<html><body>hi</body></html>"), "text/html; charset=utf-8"},
+		{"binary content", "data.bin", []byte{0x00, 0x01, 0x02}, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			key := c.objectKey(tt.path)
+			fake.put(key, tt.data, nil)
+
+			info, err := c.FixContentType(context.Background(), tt.path)
+			if err != nil {
+				t.Fatalf("FixContentType: %v", err)
+			}
+			if info.ContentType != tt.want {
+				t.Errorf("returned content type = %q, want %q", info.ContentType, tt.want)
+			}
+			if object, _ := fake.get(key); object.contentType != tt.want {
+				t.Errorf("stored content type = %q, want %q", object.contentType, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockFixContentTypeSkipsEmptyFiles(t *testing.T) {
+	m := NewMockRustFSClientBuilder().WithFile("empty.txt", 0, "application/octet-stream").Build()
+
+	info, err := m.FixContentType(context.Background(), "empty.txt")
+	if err != nil {
+		t.Fatalf("FixContentType: %v", err)
+	}
+	if info.ContentType != "application/octet-stream" {
+		t.Errorf("content type = %q, want it unchanged", info.ContentType)
+	}
+}
+
+func TestFixContentTypeRetriesSniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int64
+		status   int
+		code     string
+		wantCode string
+	}{
+		{"transient failure is retried", 1, http.StatusServiceUnavailable, "SlowDown", ""},
+		{"missing object isn't retried", 1, http.StatusNotFound, "NoSuchKey", "FILE_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			fake.put("page.html", []byte("<html><body>hi</body></html>"), nil)
+
+			var sniffs atomic.Int64
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method != http.MethodGet || r.Header.Get("Range") == "" {
+					return false
+				}
+				if sniffs.Add(1) <= tt.failures {
+					writeS3Error(w, tt.status, tt.code)
+					return true
+				}
+				return false
+			}
+
+			_, err := c.FixContentType(context.Background(), "page.html")
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("FixContentType error = %v, want code %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				if sniffs.Load() != 1 {
+					t.Errorf("sniffed %d times, want once", sniffs.Load())
+				}
+				return
+			}
+			if object, _ := fake.get("page.html"); object.contentType != "text/html; charset=utf-8" {
+				t.Errorf("content type = %q, want text/html after the retry", object.contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+
+	"github.com/garyjdn/go-rustfs/audit"
+)
+
+// contextKey is the type for context keys defined by this package
+type contextKey string
+
+const (
+	priorityContextKey contextKey = "rustfs_priority"
+)
+
+// RequestInfo carries the audit-relevant attributes of an inbound request
+type RequestInfo = audit.RequestInfo
+
+// NewRequestContext returns a context carrying all audit attributes of a request at once.
+// Set it once at the request boundary; every storage call made with the context inherits it.
+func NewRequestContext(ctx context.Context, info RequestInfo) context.Context {
+	return audit.NewRequestContext(ctx, info)
+}
+
+// Priority represents the scheduling priority of an operation when the client is concurrency-limited
+type Priority int
+
+const (
+	PriorityLow    Priority = -10
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 10
+)
+
+// WithPriority returns a context carrying the given operation priority
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey, priority)
+}
+
+// PriorityFromContext returns the operation priority carried by ctx, or PriorityNormal if none is set
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityContextKey).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// CapabilityBatchCopy marks servers that can perform many copies in a single request
+const CapabilityBatchCopy = "batch_copy"
+
+// FileCopy defines a server-side copy of one object
+type FileCopy struct {
+	SourcePath string `json:"source"`
+	DestPath   string `json:"dest"`
+}
+
+// Metadata directives for CopyFileWithOptions, named after S3's x-amz-metadata-directive
+const (
+	MetadataDirectiveCopy    = "COPY"    // keep the source's metadata, with CopyOptions.Metadata merged over it
+	MetadataDirectiveReplace = "REPLACE" // store only CopyOptions.Metadata
+)
+
+// CopyOptions configures a server-side copy
+type CopyOptions struct {
+	// MetadataDirective is MetadataDirectiveCopy or MetadataDirectiveReplace; empty means COPY
+	MetadataDirective string
+	// Metadata is merged over the source's metadata under COPY and replaces it under REPLACE
+	Metadata map[string]interface{}
+}
+
+// normalizeCopyOptions validates opts and returns a copy with the default directive filled in
+func normalizeCopyOptions(opts *CopyOptions) (CopyOptions, error) {
+	var normalized CopyOptions
+	if opts != nil {
+		normalized = *opts
+	}
+
+	switch normalized.MetadataDirective {
+	case "":
+		normalized.MetadataDirective = MetadataDirectiveCopy
+	case MetadataDirectiveCopy, MetadataDirectiveReplace:
+	default:
+		return normalized, apperror.NewAppError(400, "INVALID_COPY",
+			fmt.Errorf("metadata directive %q must be COPY or REPLACE", normalized.MetadataDirective))
+	}
+	return normalized, nil
+}
+
+// batchCopyRequest is the body sent to the batch copy endpoint
+type batchCopyRequest struct {
+	Copies []FileCopy `json:"copies"`
+}
+
+// batchCopyResponse is the body returned by the batch copy endpoint, one result per copy
+type batchCopyResponse struct {
+	Results []struct {
+		Dest  string `json:"dest"`
+		Error string `json:"error,omitempty"`
+	} `json:"results"`
+}
+
+// copyFiler is the storage surface needed to copy objects one at a time
+type copyFiler interface {
+	CopyFile(ctx context.Context, sourcePath, destPath string) error
+}
+
+// validateFileCopies rejects copies without paths and copies sharing a destination, since
+// results are keyed by destination
+func validateFileCopies(copies []FileCopy) error {
+	seen := make(map[string]bool, len(copies))
+	for i, item := range copies {
+		if item.SourcePath == "" || item.DestPath == "" {
+			return apperror.NewAppError(400, "INVALID_COPY", fmt.Errorf("copy %d is missing a source or destination path", i))
+		}
+		if seen[item.DestPath] {
+			return apperror.NewAppError(400, "INVALID_COPY", fmt.Errorf("destination %s appears more than once", item.DestPath))
+		}
+		seen[item.DestPath] = true
+	}
+	return nil
+}
+
+// copyEach copies concurrently, at most limit at a time, continuing past failures
+func copyEach(ctx context.Context, storage copyFiler, copies []FileCopy, limit int) map[string]error {
+	var mu sync.Mutex
+	results := make(map[string]error, len(copies))
+
+	// Tasks never fail, so one bad copy doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, limit)
+	for _, item := range copies {
+		item := item
+		group.Go(func() error {
+			err := storage.CopyFile(groupCtx, item.SourcePath, item.DestPath)
+			mu.Lock()
+			results[item.DestPath] = err
+			mu.Unlock()
+			return nil
+		})
+	}
+	group.Wait()
+
+	return results
+}
+
+// CopyFile copies an object server-side, keeping its metadata
+func (c *RustFSClient) CopyFile(ctx context.Context, sourcePath, destPath string) error {
+	return c.CopyFileWithOptions(ctx, sourcePath, destPath, nil)
+}
+
+// CopyFileWithOptions copies an object server-side, keeping, extending or replacing its
+// metadata as opts says. A nil opts copies the metadata unchanged, like CopyFile.
+func (c *RustFSClient) CopyFileWithOptions(ctx context.Context, sourcePath, destPath string, opts *CopyOptions) error {
+	sourcePath, destPath = c.objectKey(sourcePath), c.objectKey(destPath)
+	if err := validateObjectKey(c.keyValidator, destPath); err != nil {
+		return err
+	}
+	normalized, err := normalizeCopyOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if normalized.MetadataDirective == MetadataDirectiveReplace || len(normalized.Metadata) > 0 || c.usesSideObjects() {
+		// S3 can't merge metadata on copy, so the merged set is written with a REPLACE copy. That
+		// copy also gives the destination side objects of its own.
+		source, err := c.GetFileInfo(ctx, sourcePath)
+		if err != nil {
+			return cancellationError(ctx, err)
+		}
+		metadata := mergeMetadata(source.Metadata, normalized.Metadata, normalized.MetadataDirective == MetadataDirectiveReplace)
+		return cancellationError(ctx, c.copyWithMetadata(ctx, source, destPath, metadata, source.ContentType, "COPY_FAILED"))
+	}
+
+	err = c.withRetry(ctx, func(ctx context.Context) error {
+		_, err := c.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(c.config.BucketName),
+			Key:        aws.String(destPath),
+			CopySource: aws.String(c.copySource(sourcePath)),
+		})
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "COPY_FAILED", err)
+		}
+		return nil
+	})
+	c.bodies.invalidate(destPath)
+	return cancellationError(ctx, err)
+}
+
+// BatchCopy copies objects server-side and reports each copy's outcome keyed by destination
+// path, nil for successful copies. Servers with a batch copy endpoint receive every copy in one
+// request; otherwise the copies are issued concurrently. The error reports failures of the
+// batch as a whole.
+func (c *RustFSClient) BatchCopy(ctx context.Context, copies []FileCopy) (map[string]error, error) {
+	if err := validateFileCopies(copies); err != nil {
+		return nil, err
+	}
+	if len(copies) == 0 {
+		return map[string]error{}, nil
+	}
+
+	if c.capabilities.supports(ctx, CapabilityBatchCopy) {
+		results, batched, err := c.batchCopyServer(ctx, copies)
+		if err != nil {
+			return nil, err
+		}
+		if batched {
+			return results, nil
+		}
+	}
+
+	log.Printf("[RUSTFS] batch copy endpoint unavailable, copying %d objects individually", len(copies))
+	return copyEach(ctx, c, copies, c.config.ConcurrentUploads), nil
+}
+
+// batchCopyServer sends copies to the batch copy endpoint. It reports false when the server has no such endpoint.
+func (c *RustFSClient) batchCopyServer(ctx context.Context, copies []FileCopy) (map[string]error, bool, error) {
+	payload, err := json.Marshal(batchCopyRequest{Copies: copies})
+	if err != nil {
+		return nil, false, apperror.NewAppError(500, "BATCH_COPY_FAILED", err)
+	}
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/copy", url.PathEscape(c.config.BucketName)))
+	resp, err := c.doSignedRequest(ctx, http.MethodPost, endpoint, payload)
+	if err != nil {
+		return nil, false, apperror.NewAppError(500, "BATCH_COPY_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, false, nil
+	default:
+		return nil, false, apperror.NewAppError(resp.StatusCode, "BATCH_COPY_FAILED",
+			fmt.Errorf("batch copy endpoint returned %s", resp.Status))
+	}
+
+	var body batchCopyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, false, apperror.NewAppError(502, "BATCH_COPY_FAILED", err)
+	}
+
+	results := make(map[string]error, len(copies))
+	for _, result := range body.Results {
+		if result.Error != "" {
+			results[result.Dest] = apperror.NewAppError(500, "COPY_FAILED", errors.New(result.Error))
+		} else {
+			results[result.Dest] = nil
+		}
+	}
+	for _, item := range copies {
+		if _, ok := results[item.DestPath]; !ok {
+			results[item.DestPath] = apperror.NewAppError(502, "COPY_FAILED", fmt.Errorf("batch copy endpoint returned no result"))
+		}
+	}
+
+	for _, item := range copies {
+		c.bodies.invalidate(item.DestPath)
+	}
+
+	return results, true, nil
+}
+
+// BatchCopy copies files within mock storage. Like a server batch endpoint, every copy is
+// applied in one step unless batch copying has been disabled with SetBatchCopySupported.
+func (m *MockRustFSClient) BatchCopy(ctx context.Context, copies []FileCopy) (map[string]error, error) {
+	if err := validateFileCopies(copies); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if m.noBatchCopy {
+		m.mu.Unlock()
+		return copyEach(ctx, m, copies, 0), nil
+	}
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	results := make(map[string]error, len(copies))
+	for _, item := range copies {
+		results[item.DestPath] = m.copyFileLocked(item.SourcePath, item.DestPath)
+	}
+	return results, nil
+}
+
+// SetBatchCopySupported sets whether BatchCopy simulates a server batch endpoint or falls back
+// to individual copies
+func (m *MockRustFSClient) SetBatchCopySupported(supported bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.noBatchCopy = !supported
+}
@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/audit"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestMockCopyKeepsHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		// copy copies or moves doc.txt and returns the path now holding it
+		copy func(m *MockRustFSClient) (string, error)
+	}{
+		{"CopyFile", func(m *MockRustFSClient) (string, error) {
+			return "copy.txt", m.CopyFile(context.Background(), "doc.txt", "copy.txt")
+		}},
+		{"CopyFileWithOptions with REPLACE", func(m *MockRustFSClient) (string, error) {
+			return "copy.txt", m.CopyFileWithOptions(context.Background(), "doc.txt", "copy.txt", &CopyOptions{
+				MetadataDirective: MetadataDirectiveReplace,
+				Metadata:          map[string]interface{}{"owner": "bob"},
+			})
+		}},
+		{"MoveFile", func(m *MockRustFSClient) (string, error) {
+			return "moved.txt", m.MoveFile(context.Background(), "doc.txt", "moved.txt")
+		}},
+		{"UpdateMetadata", func(m *MockRustFSClient) (string, error) {
+			_, err := m.UpdateMetadata(context.Background(), "doc.txt", map[string]interface{}{"owner": "bob"}, false)
+			return "doc.txt", err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMockRustFSClient()
+			req := uploadRequest("doc.txt", "content")
+			req.CacheControl = "public, max-age=3600"
+			req.ContentDisposition = `attachment; filename="doc.txt"`
+			req.ContentEncoding = "identity"
+			req.StorageClass = types.StorageClassGlacier
+			req.Metadata = map[string]interface{}{"owner": "alice"}
+			if _, err := m.UploadFile(context.Background(), req); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			path, err := tt.copy(m)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			info, err := m.GetFileInfo(context.Background(), path)
+			if err != nil {
+				t.Fatalf("GetFileInfo: %v", err)
+			}
+
+			if info.Path != path || info.ContentType != req.ContentType || info.CacheControl != req.CacheControl ||
+				info.ContentDisposition != req.ContentDisposition || info.ContentEncoding != req.ContentEncoding ||
+				info.StorageClass != req.StorageClass {
+				t.Errorf("headers after %s = %+v, want the source's", tt.name, info)
+			}
+			if info.Metadata["owner"] == nil {
+				t.Errorf("metadata after %s = %v", tt.name, info.Metadata)
+			}
+		})
+	}
+}
+
+func TestMockCopyDoesNotShareMetadata(t *testing.T) {
+	m := NewMockRustFSClient()
+	ctx := context.Background()
+	req := uploadRequest("doc.txt", "content")
+	req.Metadata = map[string]interface{}{"owner": "alice"}
+	if _, err := m.UploadFile(ctx, req); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if err := m.CopyFile(ctx, "doc.txt", "copy.txt"); err != nil {
+		t.Fatalf("CopyFile: %v", err)
+	}
+
+	copied, _ := m.GetFileInfo(ctx, "copy.txt")
+	copied.Metadata["owner"] = "mallory"
+	if source, _ := m.GetFileInfo(ctx, "doc.txt"); source.Metadata["owner"] != "alice" {
+		t.Errorf("source metadata changed through the copy: %v", source.Metadata)
+	}
+}
+
+// batchCopies copies a.txt and b.txt and, failing for lack of a source, missing.txt
+var batchCopies = []FileCopy{
+	{SourcePath: "src/a.txt", DestPath: "dst/a.txt"},
+	{SourcePath: "src/missing.txt", DestPath: "dst/missing.txt"},
+	{SourcePath: "src/b.txt", DestPath: "dst/b.txt"},
+}
+
+// checkBatchCopyResults checks that only the copy of the missing source failed
+func checkBatchCopyResults(t *testing.T, results map[string]error, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("BatchCopy: %v", err)
+	}
+	if len(results) != len(batchCopies) {
+		t.Fatalf("%d results for %d copies: %v", len(results), len(batchCopies), results)
+	}
+	for _, item := range batchCopies {
+		failed := results[item.DestPath] != nil
+		if want := item.SourcePath == "src/missing.txt"; failed != want {
+			t.Errorf("%s failed = %v (%v), want %v", item.DestPath, failed, results[item.DestPath], want)
+		}
+	}
+}
+
+func TestBatchCopy(t *testing.T) {
+	// newBatchClient returns a client over a fake holding the sources, whose server advertises
+	// and serves batch copies as told, and a counter of batch requests
+	newBatchClient := func(t *testing.T, advertised, served bool) (*RustFSClient, *fakeS3, func() int) {
+		c, fake := newFakeS3Client(t)
+		fake.put("src/a.txt", []byte("a"), nil)
+		fake.put("src/b.txt", []byte("b"), nil)
+
+		var mu sync.Mutex
+		batchRequests := 0
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			switch {
+			case r.URL.Path == apiPrefix+"capabilities" && advertised:
+				json.NewEncoder(w).Encode(capabilitiesResponse{Capabilities: []string{CapabilityBatchCopy}})
+				return true
+			case r.URL.Path == apiPrefix+"buckets/"+fake.bucket+"/copy" && served:
+				mu.Lock()
+				batchRequests++
+				mu.Unlock()
+
+				var req batchCopyRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				var resp batchCopyResponse
+				for _, item := range req.Copies {
+					result := struct {
+						Dest  string `json:"dest"`
+						Error string `json:"error,omitempty"`
+					}{Dest: item.DestPath}
+					if object, ok := fake.get(item.SourcePath); ok {
+						fake.put(item.DestPath, object.data, nil)
+					} else {
+						result.Error = "NoSuchKey"
+					}
+					resp.Results = append(resp.Results, result)
+				}
+				json.NewEncoder(w).Encode(resp)
+				return true
+			}
+			return false
+		}
+		return c, fake, func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return batchRequests
+		}
+	}
+
+	tests := []struct {
+		name             string
+		advertised       bool
+		served           bool
+		wantBatchRequest bool
+	}{
+		{"server batch endpoint", true, true, true},
+		{"no batch capability", false, true, false},
+		{"advertised endpoint missing", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake, batchRequests := newBatchClient(t, tt.advertised, tt.served)
+
+			results, err := c.BatchCopy(context.Background(), batchCopies)
+			checkBatchCopyResults(t, results, err)
+
+			for _, path := range []string{"dst/a.txt", "dst/b.txt"} {
+				if _, ok := fake.get(path); !ok {
+					t.Errorf("%s wasn't copied", path)
+				}
+			}
+			individual := fake.requests(http.MethodPut, "dst/")
+			if tt.wantBatchRequest {
+				if batchRequests() != 1 || len(individual) != 0 {
+					t.Errorf("%d batch requests and %d individual copies, want a single batch request", batchRequests(), len(individual))
+				}
+			} else if batchRequests() != 0 || len(individual) != len(batchCopies) {
+				t.Errorf("%d batch requests and individual copies %v, want one copy request per pair", batchRequests(), individual)
+			}
+		})
+	}
+
+	t.Run("invalid copies", func(t *testing.T) {
+		c, _, batchRequests := newBatchClient(t, true, true)
+		for _, copies := range [][]FileCopy{
+			{{SourcePath: "src/a.txt"}},
+			{{SourcePath: "src/a.txt", DestPath: "dst/x.txt"}, {SourcePath: "src/b.txt", DestPath: "dst/x.txt"}},
+		} {
+			if _, err := c.BatchCopy(context.Background(), copies); errorCode(err) != "INVALID_COPY" {
+				t.Errorf("BatchCopy(%v) = %v, want INVALID_COPY", copies, err)
+			}
+		}
+		if batchRequests() != 0 {
+			t.Errorf("invalid copies reached the server")
+		}
+	})
+}
+
+func TestMockBatchCopy(t *testing.T) {
+	for _, batched := range []bool{true, false} {
+		name := "server batch"
+		if !batched {
+			name = "per-item fallback"
+		}
+		t.Run(name, func(t *testing.T) {
+			m := NewMockRustFSClientBuilder().
+				WithFile("src/a.txt", 1, "text/plain").
+				WithFile("src/b.txt", 1, "text/plain").
+				Build()
+			m.SetBatchCopySupported(batched)
+
+			results, err := m.BatchCopy(context.Background(), batchCopies)
+			checkBatchCopyResults(t, results, err)
+			files := m.GetFiles()
+			if files["dst/a.txt"] == nil || files["dst/b.txt"] == nil || files["dst/missing.txt"] != nil {
+				t.Errorf("stored files after the copy: %v", files)
+			}
+		})
+	}
+}
+
+func TestAuditableBatchCopy(t *testing.T) {
+	m := NewMockRustFSClientBuilder().
+		WithFile("src/a.txt", 1, "text/plain").
+		WithFile("src/b.txt", 1, "text/plain").
+		Build()
+	sink := &recordingAuditSink{}
+	c := NewAuditableRustFSClient(m, audit.NewRustFSAuditLogger("test-service", sink, nil), testConfig(t, "http://localhost:9000"), "test-service")
+
+	results, err := c.BatchCopy(context.Background(), batchCopies)
+	checkBatchCopyResults(t, results, err)
+
+	copied, failed := map[string]bool{}, 0
+	var summary map[string]interface{}
+	for _, event := range sink.events {
+		switch {
+		case event.Metadata["operation"] == "batch_copy" && event.Metadata["target_path"] != nil:
+			copied[event.ResourceID] = true
+			if !event.Success {
+				failed++
+			}
+		case event.Action == "batch_copy":
+			summary = event.Metadata
+		}
+	}
+	if len(copied) != len(batchCopies) || failed != 1 {
+		t.Errorf("copy events for %v with %d failures, want one per pair and 1 failure", copied, failed)
+	}
+	if summary == nil || summary["copy_count"] != len(batchCopies) || summary["failed_count"] != 1 {
+		t.Errorf("batch summary = %v, want 3 copies and 1 failure", summary)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDeleteFileResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		exists      bool
+		failedHeads int
+		want        bool
+	}{
+		{"existing file", true, 0, true},
+		{"missing file", false, 0, false},
+		{"transient HEAD failure is retried", true, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			if tt.exists {
+				fake.put("doc.txt", []byte("content"), nil)
+			}
+
+			failures := tt.failedHeads
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method == http.MethodHead && failures > 0 {
+					failures--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return true
+				}
+				return false
+			}
+
+			deleted, err := c.DeleteFileResult(context.Background(), "/doc.txt")
+			if err != nil {
+				t.Fatalf("DeleteFileResult: %v", err)
+			}
+			if deleted != tt.want {
+				t.Errorf("deleted = %v, want %v", deleted, tt.want)
+			}
+			if _, ok := fake.get("doc.txt"); ok {
+				t.Errorf("file still stored after DeleteFileResult")
+			}
+		})
+	}
+}
+
+func TestMockDeleteFileResult(t *testing.T) {
+	m := NewMockRustFSClientBuilder().WithFile("doc.txt", 7, "text/plain").Build()
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"doc.txt", true},
+		{"doc.txt", false},
+		{"missing.txt", false},
+	}
+	for _, tt := range tests {
+		deleted, err := m.DeleteFileResult(context.Background(), tt.path)
+		if err != nil {
+			t.Fatalf("DeleteFileResult(%q): %v", tt.path, err)
+		}
+		if deleted != tt.want {
+			t.Errorf("DeleteFileResult(%q) = %v, want %v", tt.path, deleted, tt.want)
+		}
+	}
+}
+
+func TestMockDeleteFileResultIsTrackedAndCancellable(t *testing.T) {
+	m := NewMockRustFSClientBuilder().WithFile("doc.txt", 7, "text/plain").Build()
+
+	if _, err := m.DeleteFileResult(context.Background(), "doc.txt"); err != nil {
+		t.Fatalf("DeleteFileResult: %v", err)
+	}
+	if got := m.Summary().Deletes; got != 1 {
+		t.Errorf("summary counts %d deletes, want 1", got)
+	}
+
+	m.SetLatency(time.Minute)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := m.DeleteFileResult(context.Background(), "doc.txt")
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	m.CancelAll()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrClientCancelled) {
+			t.Errorf("DeleteFileResult = %v, want ErrClientCancelled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("DeleteFileResult still running after CancelAll")
+	}
+}
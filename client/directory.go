@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// directoryPrefix returns the prefix holding the children of path as a directory
+func directoryPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
+
+// directoryInfo builds the synthetic FileInfo of a directory that exists only as a prefix
+func directoryInfo(path string) *types.FileInfo {
+	return &types.FileInfo{
+		Path:  path,
+		IsDir: true,
+	}
+}
+
+// statDirectory falls back to a synthetic directory when info lookup failed with
+// ErrFileNotFound and objects exist under "path/". Any other outcome returns err unchanged.
+func (c *RustFSClient) statDirectory(ctx context.Context, path string, err error) (*types.FileInfo, error) {
+	if !c.config.SyntheticDirectories || !errors.Is(err, ErrFileNotFound) || strings.TrimSuffix(path, "/") == "" {
+		return nil, err
+	}
+
+	hasChildren, probeErr := c.hasChildren(ctx, path)
+	if probeErr != nil {
+		return nil, probeErr
+	}
+	if !hasChildren {
+		return nil, err
+	}
+	return directoryInfo(path), nil
+}
+
+// hasChildren reports whether any object is stored under "path/"
+func (c *RustFSClient) hasChildren(ctx context.Context, path string) (bool, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(c.config.BucketName),
+		Prefix:  aws.String(directoryPrefix(path)),
+		MaxKeys: aws.Int32(1),
+	}
+
+	var output *s3.ListObjectsV2Output
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = c.client.ListObjectsV2(ctx, input)
+		if err != nil {
+			return apperror.NewAppError(500, "LIST_FAILED", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(output.Contents) > 0, nil
+}
+
+// SetSyntheticDirectories sets whether GetFileInfo on a path with no file but with files
+// under "path/" returns a directory FileInfo instead of ErrFileNotFound
+func (m *MockRustFSClient) SetSyntheticDirectories(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syntheticDirectories = enabled
+}
+
+// statDirectory is the mock counterpart of RustFSClient.statDirectory; the caller must hold m.mu
+func (m *MockRustFSClient) statDirectory(path string, err error) (*types.FileInfo, error) {
+	if !m.syntheticDirectories || !errors.Is(err, ErrFileNotFound) || strings.TrimSuffix(path, "/") == "" {
+		return nil, err
+	}
+
+	prefix := directoryPrefix(path)
+	for filePath := range m.files {
+		if strings.HasPrefix(filePath, prefix) {
+			return directoryInfo(path), nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestSyntheticDirectories(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantDir bool
+		// wantFile is set for a path holding an object of its own
+		wantFile bool
+	}{
+		{path: "photos", wantDir: true},
+		{path: "photos/", wantDir: true},
+		{path: "photos/2024", wantDir: true},
+		{path: "photos/a.jpg", wantFile: true},
+		{path: "phot"},
+		{path: "videos"},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+			cfg.SyntheticDirectories = enabled
+		})
+		m := NewMockRustFSClient()
+		m.SetSyntheticDirectories(enabled)
+		for _, path := range []string{"photos/a.jpg", "photos/2024/b.jpg"} {
+			fake.put(path, []byte("content"), nil)
+			if _, err := m.UploadFile(context.Background(), uploadRequest(path, "content")); err != nil {
+				t.Fatalf("UploadFile(%s): %v", path, err)
+			}
+		}
+
+		for name, s := range map[string]interface {
+			GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error)
+		}{"RustFSClient": c, "MockRustFSClient": m} {
+			for _, tt := range tests {
+				t.Run(fmt.Sprintf("%s/enabled %v/%s", name, enabled, tt.path), func(t *testing.T) {
+					info, err := s.GetFileInfo(context.Background(), tt.path)
+					switch {
+					case tt.wantFile:
+						if err != nil || info.IsDir {
+							t.Fatalf("GetFileInfo = %+v, %v; want the file", info, err)
+						}
+					case tt.wantDir && enabled:
+						if err != nil {
+							t.Fatalf("GetFileInfo: %v", err)
+						}
+						if !info.IsDir || strings.TrimSuffix(info.Path, "/") != strings.TrimSuffix(tt.path, "/") || info.Size != 0 {
+							t.Errorf("GetFileInfo = %+v, want a synthetic directory", info)
+						}
+					default:
+						if !errors.Is(err, ErrFileNotFound) {
+							t.Fatalf("GetFileInfo = %+v, %v; want ErrFileNotFound", info, err)
+						}
+					}
+				})
+			}
+		}
+
+		// The child probe costs a listing, which is skipped when the flag is off
+		if listed := len(fake.requests("GET", "?list-type")); (listed > 0) != enabled {
+			t.Errorf("enabled %v: sent %d child probes", enabled, listed)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// DownloadFile streams a file from RustFS. The caller is responsible for closing the returned reader.
+// Small objects are served from the body cache when it is enabled.
+func (c *RustFSClient) DownloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	path = c.objectKey(path)
+
+	// The operation context lives until the caller closes the body
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finish := trackOperation(c.activity, c.metrics, OperationDownload)
+	body, info, err := c.downloadFile(ctx, path)
+	err = cancellationError(ctx, err)
+	finish(err)
+	if err != nil {
+		done()
+		return nil, nil, err
+	}
+	body = &cancelOnClose{ReadCloser: body, cancel: done}
+	return withDownloadHooks(ctx, body, info, c.downloadHooks), info, nil
+}
+
+func (c *RustFSClient) downloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	if data, info, ok := c.bodies.get(path); ok {
+		return io.NopCloser(bytes.NewReader(data)), info, nil
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+	}
+
+	// Only establishing the stream is retried; a stream broken midway may be resumed by downloadBody
+	var output *s3.GetObjectOutput
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			if apiErrorCode(err) == "InvalidObjectState" {
+				return newSentinelError(409, "OBJECT_ARCHIVED", ErrObjectArchived, err)
+			}
+			return apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, err := c.objectMetadata(ctx, output.Metadata)
+	if err != nil {
+		output.Body.Close()
+		return nil, nil, err
+	}
+	info := buildFileInfo(path, output.ContentLength, output.ContentType, output.ETag, output.LastModified,
+		metadata, output.StorageClass, output.Restore)
+	info.CacheControl = aws.ToString(output.CacheControl)
+	info.ContentDisposition = aws.ToString(output.ContentDisposition)
+	info.ContentEncoding = aws.ToString(output.ContentEncoding)
+
+	// Check encryption metadata before handing out any bytes
+	if err := validateEncryptionMetadata(info.Metadata, c.config); err != nil {
+		output.Body.Close()
+		return nil, nil, err
+	}
+
+	var body io.ReadCloser = utils.NewCountingReadCloser(c.downloadBody(ctx, input, output), transferProgress(ctx, c.bandwidth, OperationDownload, c.metrics, c.activity))
+	if c.config.EnableEncryption {
+		key, err := c.config.EncryptionKeyBytes()
+		if err != nil {
+			body.Close()
+			return nil, nil, newSentinelError(422, "DECRYPTION_FAILED", ErrDecryptionFailed, err)
+		}
+		if body, err = decryptBody(key, body, info.Metadata); err != nil {
+			return nil, nil, err
+		}
+		info.Size = decryptedSize(info.Size)
+	}
+
+	if c.bodies.cacheable(info.Size) {
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+		}
+		c.bodies.put(path, data, info)
+		return io.NopCloser(bytes.NewReader(data)), info, nil
+	}
+
+	return body, info, nil
+}
+
+// readFile downloads an object into memory, failing with ErrResponseTooLarge rather than
+// reading more than maxBytes
+func readFile(ctx context.Context, storage Downloader, path string, maxBytes int64) ([]byte, *types.FileInfo, error) {
+	if maxBytes <= 0 {
+		return nil, nil, apperror.NewAppError(400, "INVALID_MAX_BYTES", fmt.Errorf("maxBytes must be positive"))
+	}
+
+	body, info, err := storage.DownloadFile(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	tooLarge := func(size int64) error {
+		return newSentinelError(413, "RESPONSE_TOO_LARGE", ErrResponseTooLarge,
+			fmt.Errorf("%s is at least %d bytes, limit is %d", path, size, maxBytes))
+	}
+
+	// The reported size fails fast; the limited read guards against a missing or wrong size
+	if info.Size > maxBytes {
+		return nil, nil, tooLarge(info.Size)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, nil, apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, nil, tooLarge(int64(len(data)))
+	}
+
+	return data, info, nil
+}
+
+// ReadFile downloads a small object and returns its content. Objects larger than maxBytes fail
+// with ErrResponseTooLarge without being read into memory; use DownloadFile to stream them.
+func (c *RustFSClient) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, *types.FileInfo, error) {
+	return readFile(ctx, c, path, maxBytes)
+}
+
+// ReadFile returns the content of a file in mock storage, enforcing maxBytes like RustFSClient
+func (m *MockRustFSClient) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, *types.FileInfo, error) {
+	return readFile(ctx, m, path, maxBytes)
+}
+
+// readHead reads up to length leading bytes of an object with a ranged GET. The read is small,
+// so it is retried whole like the start of DownloadFile.
+func (c *RustFSClient) readHead(ctx context.Context, path string, length int64) ([]byte, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", length-1)),
+	}
+
+	var head []byte
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		output, err := c.client.GetObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+		}
+		defer output.Body.Close()
+
+		head, err = io.ReadAll(io.LimitReader(output.Body, length))
+		if err != nil {
+			return apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+// RestoreObject requests a temporary restored copy of an archived object, readable for the given number of days
+func (c *RustFSClient) RestoreObject(ctx context.Context, path string, days int) error {
+	path = c.objectKey(path)
+	if err := c.requireCapability(ctx, CapabilityRestore); err != nil {
+		return err
+	}
+
+	input := &s3.RestoreObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+		RestoreRequest: &s3types.RestoreRequest{
+			Days: aws.Int32(int32(days)),
+		},
+	}
+
+	return c.withRetry(ctx, func(ctx context.Context) error {
+		if _, err := c.client.RestoreObject(ctx, input); err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "RESTORE_FAILED", err)
+		}
+		return nil
+	})
+}
+
+// buildFileInfo assembles FileInfo from the object headers returned by HEAD and GET requests
+func buildFileInfo(path string, contentLength *int64, contentType, etag *string, lastModified *time.Time,
+	metadata map[string]interface{}, storageClass s3types.StorageClass, restore *string) *types.FileInfo {
+	class := string(storageClass)
+	if class == "" {
+		class = types.StorageClassStandard
+	}
+
+	return &types.FileInfo{
+		Path:         path,
+		Size:         aws.ToInt64(contentLength),
+		ContentType:  aws.ToString(contentType),
+		ETag:         aws.ToString(etag),
+		LastModified: aws.ToTime(lastModified),
+		Metadata:     metadata,
+		StorageClass: class,
+		// The Restore header reads ongoing-request="false" once the restored copy is available
+		Restored: strings.Contains(aws.ToString(restore), `ongoing-request="false"`),
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxBytes int64
+		wantErr  error
+		wantCode string
+	}{
+		{"small read", "hello", 1024, nil, ""},
+		{"exactly at the limit", "hello", 5, nil, ""},
+		{"over the limit", "hello world", 5, ErrResponseTooLarge, "RESPONSE_TOO_LARGE"},
+		{"non-positive limit", "hello", 0, nil, "INVALID_MAX_BYTES"},
+	}
+
+	storages := []struct {
+		name  string
+		setup func(t *testing.T, content string) FileReader
+	}{
+		{"RustFSClient", func(t *testing.T, content string) FileReader {
+			c, fake := newFakeS3Client(t)
+			fake.put("config.txt", []byte(content), nil)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T, content string) FileReader {
+			m := NewMockRustFSClient()
+			if _, err := m.UploadFile(context.Background(), uploadRequest("config.txt", content)); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			return m
+		}},
+	}
+
+	for _, storage := range storages {
+		for _, tt := range tests {
+			t.Run(storage.name+"/"+tt.name, func(t *testing.T) {
+				s := storage.setup(t, tt.content)
+
+				data, info, err := s.ReadFile(context.Background(), "config.txt", tt.maxBytes)
+				if tt.wantCode != "" {
+					if errorCode(err) != tt.wantCode {
+						t.Fatalf("error = %v, want %s", err, tt.wantCode)
+					}
+					if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+						t.Errorf("error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				if string(data) != tt.content {
+					t.Errorf("data = %q, want %q", data, tt.content)
+				}
+				if info.Size != int64(len(tt.content)) {
+					t.Errorf("info.Size = %d, want %d", info.Size, len(tt.content))
+				}
+			})
+		}
+	}
+}
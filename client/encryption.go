@@ -0,0 +1,174 @@
+package client
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// Object metadata keys describing client-side encryption
+const (
+	MetadataEncryptionAlgorithm = "encryption-algorithm"
+	MetadataEncryptionKeyID     = "encryption-key-id"
+	MetadataEncryptionNonce     = "encryption-nonce"
+)
+
+// validateEncryptionMetadata checks that an object's encryption metadata matches what the client
+// is configured to decrypt, so a key or algorithm change fails loudly instead of yielding garbage
+func validateEncryptionMetadata(metadata map[string]interface{}, cfg *config.RustFSConfig) error {
+	if !cfg.EnableEncryption {
+		return nil
+	}
+
+	mismatch := func(format string, args ...interface{}) error {
+		return newSentinelError(422, "DECRYPTION_METADATA_MISMATCH", ErrDecryptionMetadataMismatch, fmt.Errorf(format, args...))
+	}
+
+	algorithm, _ := metadata[MetadataEncryptionAlgorithm].(string)
+	switch {
+	case algorithm == "":
+		return mismatch("object has no %s metadata", MetadataEncryptionAlgorithm)
+	case algorithm != cfg.EncryptionAlgorithm:
+		return mismatch("object is encrypted with %s, client expects %s", algorithm, cfg.EncryptionAlgorithm)
+	}
+
+	if cfg.EncryptionKeyID != "" {
+		if keyID, _ := metadata[MetadataEncryptionKeyID].(string); keyID != cfg.EncryptionKeyID {
+			return mismatch("object is encrypted with key %q, client expects %q", keyID, cfg.EncryptionKeyID)
+		}
+	}
+
+	if nonce, _ := metadata[MetadataEncryptionNonce].(string); nonce == "" {
+		return mismatch("object has no %s metadata", MetadataEncryptionNonce)
+	}
+
+	return nil
+}
+
+// isEncrypted reports whether metadata marks an object as encrypted by the client
+func isEncrypted(metadata map[string]interface{}) bool {
+	algorithm, _ := metadata[MetadataEncryptionAlgorithm].(string)
+	return algorithm != ""
+}
+
+// newObjectCipher returns the AES-256-GCM cipher for key
+func newObjectCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptObject reads source and seals it with AES-256-GCM under key. The returned content is
+// the nonce followed by the ciphertext; the nonce is also returned for the object's metadata.
+// GCM authenticates the whole object at once, so source is read into memory.
+func encryptObject(key []byte, source io.Reader) ([]byte, []byte, error) {
+	aead, err := newObjectCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var plaintext []byte
+	if source != nil {
+		if plaintext, err = io.ReadAll(source); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nonce, nil
+}
+
+// withEncryptionMetadata returns a copy of metadata tagged with the encryption algorithm, key ID
+// and nonce of an encrypted object
+func withEncryptionMetadata(metadata map[string]interface{}, keyID string, nonce []byte) map[string]interface{} {
+	tagged := make(map[string]interface{}, len(metadata)+3)
+	for k, v := range metadata {
+		tagged[k] = v
+	}
+	tagged[MetadataEncryptionAlgorithm] = config.EncryptionAES256GCM
+	if keyID != "" {
+		tagged[MetadataEncryptionKeyID] = keyID
+	}
+	tagged[MetadataEncryptionNonce] = hex.EncodeToString(nonce)
+	return tagged
+}
+
+// withSourceEncryptionMetadata returns a copy of metadata carrying the encryption entries of
+// source, so rewritten metadata still describes how the content is encrypted
+func withSourceEncryptionMetadata(metadata, source map[string]interface{}) map[string]interface{} {
+	tagged := make(map[string]interface{}, len(metadata)+3)
+	for k, v := range metadata {
+		tagged[k] = v
+	}
+	for _, key := range []string{MetadataEncryptionAlgorithm, MetadataEncryptionKeyID, MetadataEncryptionNonce} {
+		if v, ok := source[key]; ok {
+			tagged[key] = v
+		}
+	}
+	return tagged
+}
+
+// decryptObject reads an object written by encryptObject from body and returns its plaintext.
+// The nonce prefix must match the object's nonce metadata.
+func decryptObject(key []byte, body io.Reader, metadata map[string]interface{}) ([]byte, error) {
+	failed := func(cause error) error {
+		return newSentinelError(422, "DECRYPTION_FAILED", ErrDecryptionFailed, cause)
+	}
+
+	aead, err := newObjectCipher(key)
+	if err != nil {
+		return nil, failed(err)
+	}
+	sealed, err := io.ReadAll(body)
+	if err != nil {
+		return nil, apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, failed(fmt.Errorf("object is shorter than the nonce and authentication tag"))
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	if tagged, _ := metadata[MetadataEncryptionNonce].(string); tagged != hex.EncodeToString(nonce) {
+		return nil, newSentinelError(422, "DECRYPTION_METADATA_MISMATCH", ErrDecryptionMetadataMismatch,
+			fmt.Errorf("object nonce does not match its %s metadata", MetadataEncryptionNonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, failed(err)
+	}
+	return plaintext, nil
+}
+
+// encryptionOverhead is the bytes encryptObject adds: a standard 12 byte GCM nonce and 16 byte tag
+const encryptionOverhead = 12 + 16
+
+// decryptedSize is the plaintext size of an encrypted object of size bytes
+func decryptedSize(size int64) int64 {
+	if size < encryptionOverhead {
+		return 0
+	}
+	return size - encryptionOverhead
+}
+
+// decryptBody replaces body with a reader over its decrypted content. body is always closed.
+func decryptBody(key []byte, body io.ReadCloser, metadata map[string]interface{}) (io.ReadCloser, error) {
+	defer body.Close()
+	plaintext, err := decryptObject(key, body, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(plaintext)), nil
+}
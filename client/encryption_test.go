@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+const testEncryptionKey = "0123456789abcdef0123456789abcdef"
+
+// encryptionConfig encrypts uploads with testEncryptionKey
+func encryptionConfig(cfg *config.RustFSConfig) {
+	cfg.EnableEncryption = true
+	cfg.EncryptionKey = testEncryptionKey
+}
+
+func TestEncryptedObjectSizes(t *testing.T) {
+	c, _ := newFakeS3Client(t, encryptionConfig)
+	m := NewMockRustFSClient()
+	if err := m.SetEncryptionKey([]byte(testEncryptionKey)); err != nil {
+		t.Fatalf("SetEncryptionKey: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		storage interface {
+			FileStorage
+			Downloader
+		}
+	}{
+		{"client", c},
+		{"mock", m},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			content := strings.Repeat("secret ", 100)
+
+			uploaded, err := tt.storage.UploadFile(ctx, &types.UploadRequest{
+				File:        strings.NewReader(content),
+				FileSize:    int64(len(content)),
+				ContentType: "text/plain",
+				BucketPath:  "secret.txt",
+			})
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			info, err := tt.storage.GetFileInfo(ctx, "secret.txt")
+			if err != nil {
+				t.Fatalf("GetFileInfo: %v", err)
+			}
+
+			body, downloaded, err := tt.storage.DownloadFile(ctx, "secret.txt")
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			data, err := io.ReadAll(body)
+			body.Close()
+			if err != nil || string(data) != content {
+				t.Fatalf("downloaded %d bytes, %v; want the plaintext", len(data), err)
+			}
+
+			want := int64(len(content))
+			for source, size := range map[string]int64{"upload": uploaded.Size, "GetFileInfo": info.Size, "DownloadFile": downloaded.Size} {
+				if size != want {
+					t.Errorf("%s size = %d, want the plaintext size %d", source, size, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptionMetadataMismatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		download  func(cfg *config.RustFSConfig)
+		tamper    func(metadata map[string]string)
+		wantError bool
+	}{
+		{name: "matching metadata"},
+		{
+			name:      "different algorithm",
+			tamper:    func(metadata map[string]string) { metadata[MetadataEncryptionAlgorithm] = "CHACHA20-POLY1305" },
+			wantError: true,
+		},
+		{
+			name:      "client expects another algorithm",
+			download:  func(cfg *config.RustFSConfig) { cfg.EncryptionAlgorithm = "CHACHA20-POLY1305" },
+			wantError: true,
+		},
+		{
+			name:      "different key ID",
+			download:  func(cfg *config.RustFSConfig) { cfg.EncryptionKeyID = "key-2" },
+			wantError: true,
+		},
+		{
+			name:      "missing nonce",
+			tamper:    func(metadata map[string]string) { delete(metadata, MetadataEncryptionNonce) },
+			wantError: true,
+		},
+		{
+			name:      "unencrypted object",
+			tamper:    func(metadata map[string]string) { delete(metadata, MetadataEncryptionAlgorithm) },
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, encryptionConfig, func(cfg *config.RustFSConfig) {
+				cfg.EncryptionKeyID = "key-1"
+			})
+			ctx := context.Background()
+			if _, err := c.UploadFile(ctx, uploadRequest("secret.txt", "secret")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			object, _ := fake.get("secret.txt")
+			if object.metadata[MetadataEncryptionKeyID] != "key-1" {
+				t.Fatalf("stored metadata = %v, want the key ID", object.metadata)
+			}
+			if tt.tamper != nil {
+				tt.tamper(object.metadata)
+			}
+			if tt.download != nil {
+				tt.download(c.config)
+			}
+
+			body, _, err := c.DownloadFile(ctx, "secret.txt")
+			if !tt.wantError {
+				if err != nil {
+					t.Fatalf("DownloadFile: %v", err)
+				}
+				data, _ := io.ReadAll(body)
+				body.Close()
+				if string(data) != "secret" {
+					t.Errorf("downloaded %q, want the plaintext", data)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrDecryptionMetadataMismatch) || errorCode(err) != "DECRYPTION_METADATA_MISMATCH" {
+				t.Errorf("DownloadFile = %v, want ErrDecryptionMetadataMismatch", err)
+			}
+			if body != nil {
+				body.Close()
+				t.Error("DownloadFile returned a body alongside the mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// Sentinel errors returned by RustFS clients. They are wrapped in *apperror.AppError,
+// so callers should match them with errors.Is.
+var (
+	ErrFileNotFound          = errors.New("file not found")
+	ErrObjectArchived        = errors.New("object is archived and must be restored before download")
+	ErrInvalidLimit          = errors.New("result limit cannot be negative")
+	ErrMetadataKeyCollision  = errors.New("metadata keys differ only in case")
+	ErrMetadataValueTooLarge = errors.New("metadata value exceeds the size limit")
+	ErrUnauthenticated       = errors.New("operation requires an authenticated user")
+	ErrUnsupportedOperation  = errors.New("operation is not supported by the server")
+	ErrClientCancelled       = errors.New("client cancelled")
+	ErrResponseTooLarge      = errors.New("object exceeds the maximum size to read into memory")
+	ErrMoveIncomplete        = errors.New("file was copied to the target but the source could not be deleted")
+	ErrAlreadyExists         = errors.New("object already exists")
+
+	ErrRangeNotSatisfiable = errors.New("requested range starts past the end of the object")
+	ErrRangeIgnored        = errors.New("server ignored the requested range")
+	ErrRangeMismatch       = errors.New("server returned a different range than requested")
+
+	ErrUploadVerificationFailed   = errors.New("uploaded object does not match the source content")
+	ErrDecryptionMetadataMismatch = errors.New("object encryption metadata does not match the client's encryption configuration")
+	ErrDecryptionFailed           = errors.New("object could not be decrypted")
+
+	ErrDownloadTokenNotFound  = errors.New("download token does not exist or was revoked")
+	ErrDownloadTokenExpired   = errors.New("download token has expired")
+	ErrDownloadTokenExhausted = errors.New("download token has no uses left")
+
+	ErrWebhookNotFound         = errors.New("no webhook is registered for the URL")
+	ErrInvalidWebhookSignature = errors.New("webhook signature does not match the payload")
+)
+
+// newSentinelError wraps a sentinel error in an AppError, keeping the underlying cause in the message
+func newSentinelError(status int, code string, sentinel, cause error) *apperror.AppError {
+	if cause == nil {
+		return apperror.NewAppError(status, code, sentinel)
+	}
+	return apperror.NewAppError(status, code, fmt.Errorf("%w: %v", sentinel, cause))
+}
+
+// conditionalUploadError maps the 412 an IfNotExists upload gets for an existing object to
+// ErrAlreadyExists, and any other failure to code
+func conditionalUploadError(err error, code string) error {
+	if status, ok := utils.HTTPStatusFromError(err); (ok && status == http.StatusPreconditionFailed) ||
+		apiErrorCode(err) == "PreconditionFailed" {
+		return newSentinelError(412, "ALREADY_EXISTS", ErrAlreadyExists, err)
+	}
+	return apperror.NewAppError(500, code, err)
+}
+
+// apiErrorCode returns the S3 API error code carried by err, if any
+func apiErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+// isNotFoundError checks if err is an S3 "object does not exist" error
+func isNotFoundError(err error) bool {
+	switch apiErrorCode(err) {
+	case "NotFound", "NoSuchKey":
+		return true
+	default:
+		return false
+	}
+}
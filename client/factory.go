@@ -4,19 +4,43 @@ import (
 	"context"
 	"fmt"
 
+	audittypes "github.com/garyjdn/go-auditlogger/types"
 	"github.com/garyjdn/go-rustfs/audit"
 	"github.com/garyjdn/go-rustfs/config"
 	"github.com/garyjdn/go-rustfs/types"
 )
 
 // ClientFactory creates different types of RustFS clients
-type ClientFactory struct{}
+type ClientFactory struct {
+	auditSink audittypes.AuditLogger
+}
 
 // NewClientFactory creates a new client factory
 func NewClientFactory() *ClientFactory {
 	return &ClientFactory{}
 }
 
+// WithAuditSink sets the logger audit events are written to. Events reach it through an
+// AsyncAuditLogger applying RUSTFS_AUDIT_OVERFLOW_POLICY, RUSTFS_AUDIT_OVERFLOW_TIMEOUT and
+// RUSTFS_AUDIT_WORKERS, which the client's Close drains.
+func (f *ClientFactory) WithAuditSink(sink audittypes.AuditLogger) *ClientFactory {
+	f.auditSink = sink
+	return f
+}
+
+// newAuditLogger creates the audit logger of a client, or nil when audit is disabled
+func (f *ClientFactory) newAuditLogger(cfg *config.RustFSConfig) *audit.RustFSAuditLogger {
+	if !cfg.EnableAudit {
+		return nil
+	}
+
+	var sink audittypes.AuditLogger
+	if f.auditSink != nil {
+		sink = audit.NewAsyncAuditLogger(f.auditSink, audit.AsyncAuditLoggerOptionsFromConfig(cfg))
+	}
+	return audit.NewRustFSAuditLogger(cfg.AuditService, sink, cfg.AuditMetadata)
+}
+
 // CreateProductionClient creates a production RustFS client with audit logging
 func (f *ClientFactory) CreateProductionClient(serviceName string) (*AuditableRustFSClient, error) {
 	// Load configuration
@@ -25,16 +49,8 @@ func (f *ClientFactory) CreateProductionClient(serviceName string) (*AuditableRu
 	// Create base client
 	baseClient := NewRustFSClient(cfg)
 
-	// Create audit logger if enabled
-	var auditLogger *audit.RustFSAuditLogger
-	if cfg.EnableAudit {
-		// For now, we'll create a simple audit logger
-		// In production, this should be replaced with actual audit logger implementation
-		auditLogger = audit.NewRustFSAuditLogger(cfg.AuditService, nil, cfg.AuditMetadata)
-	}
-
 	// Create auditable client
-	return NewAuditableRustFSClient(baseClient, auditLogger, cfg, serviceName), nil
+	return NewAuditableRustFSClient(baseClient, f.newAuditLogger(cfg), cfg, serviceName), nil
 }
 
 // CreateDevelopmentClient creates a development RustFS client (mock)
@@ -45,14 +61,8 @@ func (f *ClientFactory) CreateDevelopmentClient(serviceName string) (*AuditableR
 	// Create mock client
 	mockClient := NewMockRustFSClient()
 
-	// Create audit logger for development (console only)
-	var auditLogger *audit.RustFSAuditLogger
-	if cfg.EnableAudit {
-		auditLogger = audit.NewRustFSAuditLogger(cfg.AuditService, nil, cfg.AuditMetadata)
-	}
-
 	// Create auditable client
-	return NewAuditableRustFSClient(mockClient, auditLogger, cfg, serviceName), nil
+	return NewAuditableRustFSClient(mockClient, f.newAuditLogger(cfg), cfg, serviceName), nil
 }
 
 // CreateTestClient creates a test RustFS client (mock with predefined data)
@@ -70,14 +80,8 @@ func (f *ClientFactory) CreateTestClient(serviceName string, testData *TestData)
 		}
 	}
 
-	// Create audit logger for testing (console only, minimal)
-	var auditLogger *audit.RustFSAuditLogger
-	if cfg.EnableAudit {
-		auditLogger = audit.NewRustFSAuditLogger(cfg.AuditService, nil, cfg.AuditMetadata)
-	}
-
 	// Create auditable client
-	return NewAuditableRustFSClient(mockClient, auditLogger, cfg, serviceName), nil
+	return NewAuditableRustFSClient(mockClient, f.newAuditLogger(cfg), cfg, serviceName), nil
 }
 
 // CreateClientFromConfig creates a client from custom configuration
@@ -87,17 +91,15 @@ func (f *ClientFactory) CreateClientFromConfig(cfg *config.RustFSConfig, service
 	if useMock {
 		baseClient = NewMockRustFSClient()
 	} else {
-		baseClient = NewRustFSClient(cfg)
-	}
-
-	// Create audit logger if enabled
-	var auditLogger *audit.RustFSAuditLogger
-	if cfg.EnableAudit {
-		auditLogger = audit.NewRustFSAuditLogger(cfg.AuditService, nil, cfg.AuditMetadata)
+		rustfsClient, err := NewRustFSClientE(cfg)
+		if err != nil {
+			return nil, err
+		}
+		baseClient = rustfsClient
 	}
 
 	// Create auditable client
-	return NewAuditableRustFSClient(baseClient, auditLogger, cfg, serviceName), nil
+	return NewAuditableRustFSClient(baseClient, f.newAuditLogger(cfg), cfg, serviceName), nil
 }
 
 // TestData represents test data for mock client
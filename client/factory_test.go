@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	audittypes "github.com/garyjdn/go-auditlogger/types"
+	"github.com/garyjdn/go-rustfs/audit"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// recordingAuditSink records the events written to it
+type recordingAuditSink struct {
+	mu     sync.Mutex
+	events []*audittypes.AuditEvent
+}
+
+func (s *recordingAuditSink) LogEvent(ctx context.Context, event *audittypes.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingAuditSink) LogAuthEvent(ctx context.Context, eventType audittypes.AuditEventType, userID, reason string, success bool, metadata map[string]interface{}) error {
+	return nil
+}
+
+func (s *recordingAuditSink) LogAccessEvent(ctx context.Context, userID, resource, action, resourceID string, success bool, reason string) error {
+	return nil
+}
+
+func (s *recordingAuditSink) LogSecurityEvent(ctx context.Context, eventType audittypes.AuditEventType, details map[string]interface{}) error {
+	return nil
+}
+
+func (s *recordingAuditSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func TestClientFactoryAuditSink(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantPolicy audit.OverflowPolicy
+	}{
+		{"block", config.AuditOverflowBlock, audit.OverflowBlock},
+		{"drop oldest", config.AuditOverflowDropOldest, audit.OverflowDropOldest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost:9000")
+			cfg.EnableAudit = true
+			cfg.AuditOverflowPolicy = tt.policy
+			sink := &recordingAuditSink{}
+
+			c, err := NewClientFactory().WithAuditSink(sink).CreateClientFromConfig(cfg, "test-service", true)
+			if err != nil {
+				t.Fatalf("CreateClientFromConfig: %v", err)
+			}
+
+			async, ok := c.GetAuditLogger().GetAuditLogger().(*audit.AsyncAuditLogger)
+			if !ok {
+				t.Fatalf("audit logger is %T, want *audit.AsyncAuditLogger", c.GetAuditLogger().GetAuditLogger())
+			}
+			if got := async.Policy(); got != tt.wantPolicy {
+				t.Errorf("OverflowPolicy = %q, want %q", got, tt.wantPolicy)
+			}
+
+			if err := c.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if sink.count() == 0 {
+				t.Errorf("Close didn't drain the shutdown events into the sink")
+			}
+		})
+	}
+}
+
+func TestClientFactoryWithoutAudit(t *testing.T) {
+	cfg := testConfig(t, "http://localhost:9000")
+	cfg.EnableAudit = false
+
+	c, err := NewClientFactory().WithAuditSink(&recordingAuditSink{}).CreateClientFromConfig(cfg, "test-service", true)
+	if err != nil {
+		t.Fatalf("CreateClientFromConfig: %v", err)
+	}
+	if c.GetAuditLogger() != nil {
+		t.Errorf("audit logger created with audit disabled")
+	}
+}
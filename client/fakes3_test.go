@@ -0,0 +1,544 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// fakeObject is an object stored by fakeS3
+type fakeObject struct {
+	data            []byte
+	contentType     string
+	contentEncoding string
+	metadata        map[string]string
+	tags            map[string]string
+	modified        time.Time
+	// multipartETag is the ETag of an object completed from parts, in the S3 multipart format
+	multipartETag string
+}
+
+func (o *fakeObject) etag() string {
+	if o.multipartETag != "" {
+		return o.multipartETag
+	}
+	sum := md5.Sum(o.data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// fakeUpload is an incomplete multipart upload
+type fakeUpload struct {
+	key       string
+	object    *fakeObject
+	parts     map[int][]byte
+	initiated time.Time
+}
+
+// fakeS3 is an in-memory S3 server covering the requests RustFSClient sends: objects, ranged
+// reads, copies, listings and multipart uploads. The management API answers 404, like a plain
+// S3 server.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string]*fakeObject
+	uploads map[string]*fakeUpload
+	nextID  int
+
+	// intercept sees every request first and reports whether it wrote the response
+	intercept func(w http.ResponseWriter, r *http.Request) bool
+	// log records "METHOD key?query" of every request reaching the fake
+	log []string
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{bucket: bucket, objects: make(map[string]*fakeObject), uploads: make(map[string]*fakeUpload)}
+}
+
+// newFakeS3Client returns a client backed by a fakeS3, with the default configuration changed by configure
+func newFakeS3Client(t *testing.T, configure ...func(cfg *config.RustFSConfig)) (*RustFSClient, *fakeS3) {
+	t.Helper()
+	fake := newFakeS3("default")
+	server := newTestServer(t, fake.ServeHTTP)
+	cfg := testConfig(t, server.URL)
+	cfg.BucketName = fake.bucket
+	for _, fn := range configure {
+		fn(cfg)
+	}
+	return NewRustFSClient(cfg), fake
+}
+
+// put stores an object directly
+func (f *fakeS3) put(key string, data []byte, metadata map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = &fakeObject{data: data, contentType: "application/octet-stream", metadata: metadata, modified: time.Now()}
+}
+
+// tag replaces the tags of a stored object
+func (f *fakeS3) tag(key string, tags map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key].tags = tags
+}
+
+// startUpload starts an incomplete multipart upload of key initiated at the given time
+func (f *fakeS3) startUpload(key string, initiated time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	f.uploads[fmt.Sprintf("upload-%d", f.nextID)] = &fakeUpload{key: key, object: &fakeObject{}, parts: make(map[int][]byte), initiated: initiated}
+}
+
+// uploadKeys returns the keys of the incomplete multipart uploads, sorted
+func (f *fakeS3) uploadKeys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for _, upload := range f.uploads {
+		keys = append(keys, upload.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// get returns a stored object
+func (f *fakeS3) get(key string) (*fakeObject, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[key]
+	return object, ok
+}
+
+// requests returns the logged requests matching method whose key has prefix
+func (f *fakeS3) requests(method, prefix string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []string
+	for _, entry := range f.log {
+		if strings.HasPrefix(entry, method+" "+prefix) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.intercept != nil && f.intercept(w, r) {
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, apiPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+f.bucket), "/")
+	query := r.URL.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, r.Method+" "+key+"?"+r.URL.RawQuery)
+
+	switch {
+	case r.Method == http.MethodHead && key == "":
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet && key == "" && query.Has("uploads"):
+		f.listUploads(w)
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, query)
+	case r.Method == http.MethodPost && key == "" && query.Has("delete"):
+		f.deleteObjects(w, r)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.createUpload(w, r, key)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		f.uploadPart(w, r, query)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.completeUpload(w, r, key, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		f.copyObject(w, r, key)
+	case r.Method == http.MethodPut:
+		f.putObject(w, r, key)
+	case r.Method == http.MethodGet && query.Has("tagging"):
+		f.getTagging(w, key)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		f.getObject(w, r, key)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+// readBody returns the request payload, undoing aws-chunked framing
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeField, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeField, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// objectFromRequest builds an object from the headers of a PUT or multipart create
+func objectFromRequest(r *http.Request) *fakeObject {
+	object := &fakeObject{
+		contentType:     r.Header.Get("Content-Type"),
+		contentEncoding: strings.TrimSuffix(strings.TrimPrefix(strings.ReplaceAll(r.Header.Get("Content-Encoding"), "aws-chunked", ""), ","), ","),
+		metadata:        make(map[string]string),
+		modified:        time.Now(),
+	}
+	for name, values := range r.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-meta-") {
+			object.metadata[strings.TrimPrefix(lower, "x-amz-meta-")] = values[0]
+		}
+	}
+	return object
+}
+
+func (f *fakeS3) putObject(w http.ResponseWriter, r *http.Request, key string) {
+	if _, exists := f.objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+		writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	data, err := readBody(r)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+	object := objectFromRequest(r)
+	object.data = data
+	f.objects[key] = object
+	w.Header().Set("ETag", object.etag())
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeS3) getObject(w http.ResponseWriter, r *http.Request, key string) {
+	object, ok := f.objects[key]
+	if !ok {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != object.etag() {
+		writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", object.contentType)
+	header.Set("ETag", object.etag())
+	header.Set("Last-Modified", object.modified.UTC().Format(http.TimeFormat))
+	if object.contentEncoding != "" {
+		header.Set("Content-Encoding", object.contentEncoding)
+	}
+	for k, v := range object.metadata {
+		header.Set("X-Amz-Meta-"+k, v)
+	}
+
+	data, status := object.data, http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		size := int64(len(object.data))
+		start, end := int64(0), size-1
+		if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); err != nil && !strings.HasSuffix(rangeHeader, "-") {
+			writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+			return
+		}
+		if start >= size {
+			writeS3Error(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return
+		}
+		end = min(end, size-1)
+		data, status = object.data[start:end+1], http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+func (f *fakeS3) copyObject(w http.ResponseWriter, r *http.Request, key string) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+	sourceKey := strings.TrimPrefix(strings.TrimPrefix(source, "/"), f.bucket+"/")
+	original, ok := f.objects[sourceKey]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	copied := *original
+	copied.data = append([]byte(nil), original.data...)
+	copied.modified = time.Now()
+	copied.multipartETag = ""
+	if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		replaced := objectFromRequest(r)
+		copied.metadata, copied.contentType, copied.contentEncoding = replaced.metadata, replaced.contentType, replaced.contentEncoding
+	}
+	f.objects[key] = &copied
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CopyObjectResult"`
+		ETag    string
+	}{ETag: copied.etag()})
+}
+
+func (f *fakeS3) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Objects []struct{ Key string } `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeS3Error(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+
+	type deleted struct{ Key string }
+	result := struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Deleted []deleted `xml:"Deleted"`
+	}{}
+	for _, object := range request.Objects {
+		delete(f.objects, object.Key)
+		result.Deleted = append(result.Deleted, deleted{Key: object.Key})
+	}
+	writeXML(w, result)
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	maxKeys := 1000
+	if value, err := strconv.Atoi(query.Get("max-keys")); err == nil && value > 0 {
+		maxKeys = value
+	}
+	after := query.Get("continuation-token")
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key          string
+		Size         int64
+		ETag         string
+		LastModified string
+	}
+	type commonPrefix struct{ Prefix string }
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		KeyCount              int
+		MaxKeys               int
+		IsTruncated           bool
+		NextContinuationToken string         `xml:",omitempty"`
+		Contents              []content      `xml:"Contents"`
+		CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+	}{Name: f.bucket, Prefix: prefix, MaxKeys: maxKeys}
+
+	seenPrefixes := make(map[string]bool)
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || (after != "" && key <= after) {
+			continue
+		}
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				common := key[:len(prefix)+i+len(delimiter)]
+				if !seenPrefixes[common] {
+					seenPrefixes[common] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: common})
+					result.KeyCount++
+					result.NextContinuationToken = key
+				}
+				continue
+			}
+		}
+		object := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			Size:         int64(len(object.data)),
+			ETag:         object.etag(),
+			LastModified: object.modified.UTC().Format(time.RFC3339),
+		})
+		result.KeyCount++
+		result.NextContinuationToken = key
+	}
+	if !result.IsTruncated {
+		result.NextContinuationToken = ""
+	}
+	writeXML(w, result)
+}
+
+func (f *fakeS3) createUpload(w http.ResponseWriter, r *http.Request, key string) {
+	f.nextID++
+	id := fmt.Sprintf("upload-%d", f.nextID)
+	f.uploads[id] = &fakeUpload{key: key, object: objectFromRequest(r), parts: make(map[int][]byte), initiated: time.Now()}
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadId string
+	}{Bucket: f.bucket, Key: key, UploadId: id})
+}
+
+func (f *fakeS3) uploadPart(w http.ResponseWriter, r *http.Request, query url.Values) {
+	upload, ok := f.uploads[query.Get("uploadId")]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	number, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+	data, err := readBody(r)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+	upload.parts[number] = data
+	sum := md5.Sum(data)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeS3) completeUpload(w http.ResponseWriter, r *http.Request, key, id string) {
+	upload, ok := f.uploads[id]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	if _, exists := f.objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+		writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+
+	numbers := make([]int, 0, len(upload.parts))
+	for number := range upload.parts {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	var data bytes.Buffer
+	digests := md5.New()
+	for _, number := range numbers {
+		data.Write(upload.parts[number])
+		digest := md5.Sum(upload.parts[number])
+		digests.Write(digest[:])
+	}
+
+	object := upload.object
+	object.data = data.Bytes()
+	object.multipartETag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(digests.Sum(nil)), len(numbers))
+	f.objects[key] = object
+	delete(f.uploads, id)
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: f.bucket, Key: key, ETag: object.etag()})
+}
+
+func (f *fakeS3) getTagging(w http.ResponseWriter, key string) {
+	object, ok := f.objects[key]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	type tag struct {
+		Key   string
+		Value string
+	}
+	result := struct {
+		XMLName xml.Name `xml:"Tagging"`
+		TagSet  []tag    `xml:"TagSet>Tag"`
+	}{}
+	for k, v := range object.tags {
+		result.TagSet = append(result.TagSet, tag{Key: k, Value: v})
+	}
+	writeXML(w, result)
+}
+
+func (f *fakeS3) listUploads(w http.ResponseWriter) {
+	type upload struct {
+		Key       string
+		UploadId  string
+		Initiated string
+	}
+	result := struct {
+		XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+		Bucket  string
+		Uploads []upload `xml:"Upload"`
+	}{Bucket: f.bucket}
+	for id, u := range f.uploads {
+		result.Uploads = append(result.Uploads, upload{Key: u.key, UploadId: id, Initiated: u.initiated.UTC().Format(time.RFC3339)})
+	}
+	sort.Slice(result.Uploads, func(i, j int) bool { return result.Uploads[i].UploadId < result.Uploads[j].UploadId })
+	writeXML(w, result)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// DownloadQueryParam is the query parameter that forces an attachment download with the given filename
+const DownloadQueryParam = "download"
+
+// DownloadHandler serves stored files over HTTP, translating object attributes into response headers.
+// A "download" query parameter overrides the stored disposition with an attachment of that filename.
+type DownloadHandler struct {
+	storage Downloader
+	prefix  string
+}
+
+// NewDownloadHandler creates a handler serving files from storage. The prefix is stripped from the
+// request path to obtain the object path.
+func NewDownloadHandler(storage Downloader, prefix string) *DownloadHandler {
+	return &DownloadHandler{
+		storage: storage,
+		prefix:  prefix,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *DownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, h.prefix), "/")
+	if path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, info, err := h.storage.DownloadFile(r.Context(), path)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Code >= 400 && appErr.Code < 600 {
+			status = appErr.Code
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	defer body.Close()
+
+	header := w.Header()
+	etag := quoteETag(info.ETag)
+	if etag != "" {
+		header.Set("ETag", etag)
+	}
+	if !info.LastModified.IsZero() {
+		header.Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if info.CacheControl != "" {
+		header.Set("Cache-Control", info.CacheControl)
+	}
+	if info.ContentEncoding != "" {
+		header.Set("Content-Encoding", info.ContentEncoding)
+	}
+
+	if notModified(r, etag, info.LastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if info.ContentType != "" {
+		header.Set("Content-Type", info.ContentType)
+	}
+	if info.Size > 0 {
+		header.Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if filename := r.URL.Query().Get(DownloadQueryParam); filename != "" {
+		header.Set("Content-Disposition", utils.ContentDisposition("attachment", filename))
+	} else if info.ContentDisposition != "" {
+		header.Set("Content-Disposition", info.ContentDisposition)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = io.Copy(w, body)
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since per RFC 9110. If-None-Match takes
+// precedence: when present, If-Modified-Since is ignored.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagListMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether an If-None-Match list matches etag using weak comparison
+func etagListMatches(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteETag returns etag as a quoted entity tag, as required in ETag headers
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDownloadHandlerContentDisposition(t *testing.T) {
+	m := NewMockRustFSClient()
+	req := uploadRequest("docs/report.txt", "content")
+	req.ContentDisposition = `inline; filename="report.txt"`
+	if _, err := m.UploadFile(context.Background(), req); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	handler := NewDownloadHandler(m, "/files/")
+
+	tests := []struct {
+		name         string
+		download     string
+		wantHeader   string
+		wantType     string
+		wantFilename string
+	}{
+		{"stored disposition", "", `inline; filename="report.txt"`, "inline", "report.txt"},
+		{"ASCII override", "q3.txt", `attachment; filename="q3.txt"`, "attachment", "q3.txt"},
+		{
+			"non-ASCII override",
+			"Résumé 报告.txt",
+			`attachment; filename="R_sum_ __.txt"; filename*=UTF-8''R%C3%A9sum%C3%A9%20%E6%8A%A5%E5%91%8A.txt`,
+			"attachment",
+			"Résumé 报告.txt",
+		},
+		{
+			"quotes and separators",
+			`a "b"; c=d.txt`,
+			`attachment; filename="a _b_; c=d.txt"; filename*=UTF-8''a%20%22b%22%3B%20c%3Dd.txt`,
+			"attachment",
+			`a "b"; c=d.txt`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/files/docs/report.txt"
+			if tt.download != "" {
+				target += "?" + url.Values{DownloadQueryParam: {tt.download}}.Encode()
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			header := rec.Header().Get("Content-Disposition")
+			if header != tt.wantHeader {
+				t.Errorf("Content-Disposition = %s, want %s", header, tt.wantHeader)
+			}
+
+			// A conforming client decodes filename* back to the exact name
+			dispositionType, params, err := mime.ParseMediaType(header)
+			if err != nil {
+				t.Fatalf("Content-Disposition doesn't parse: %v", err)
+			}
+			if dispositionType != tt.wantType || params["filename"] != tt.wantFilename {
+				t.Errorf("parsed %s with filename %q, want %s with %q", dispositionType, params["filename"], tt.wantType, tt.wantFilename)
+			}
+		})
+	}
+}
+
+func TestDownloadHandlerConditionalGet(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMockRustFSClient()
+	if _, err := m.UploadFile(context.Background(), uploadRequest("docs/report.txt", "content")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	info := m.GetFiles()["docs/report.txt"]
+	info.ETag = "v1"
+	info.LastModified = modified.Add(500 * time.Millisecond)
+	handler := NewDownloadHandler(m, "/files/")
+
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	at := modified.Format(http.TimeFormat)
+
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"unconditional", http.MethodGet, nil, http.StatusOK},
+		{"matching ETag", http.MethodGet, map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified},
+		{"weak ETag in a list", http.MethodGet, map[string]string{"If-None-Match": `"v0", W/"v1"`}, http.StatusNotModified},
+		{"wildcard ETag", http.MethodGet, map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale ETag", http.MethodGet, map[string]string{"If-None-Match": `"v0"`}, http.StatusOK},
+		{"not modified since", http.MethodGet, map[string]string{"If-Modified-Since": at}, http.StatusNotModified},
+		{"modified since", http.MethodGet, map[string]string{"If-Modified-Since": before}, http.StatusOK},
+		{"unparseable date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{"stale ETag overrides a matching date", http.MethodGet, map[string]string{"If-None-Match": `"v0"`, "If-Modified-Since": at}, http.StatusOK},
+		{"matching ETag overrides a stale date", http.MethodGet, map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": before}, http.StatusNotModified},
+		{"HEAD with a matching ETag", http.MethodHead, map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/files/docs/report.txt", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != `"v1"` {
+				t.Errorf("ETag = %s, want \"v1\"", got)
+			}
+			if got := rec.Header().Get("Last-Modified"); got != at {
+				t.Errorf("Last-Modified = %s, want %s", got, at)
+			}
+
+			wantBody := "content"
+			if tt.wantStatus == http.StatusNotModified || tt.method == http.MethodHead {
+				wantBody = ""
+			}
+			if rec.Body.String() != wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// errorCode returns the code of the outermost AppError in err's chain
+func errorCode(err error) string {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return ""
+}
+
+// testConfig loads the default configuration pointed at baseURL with fast retries. The AWS SDK's
+// own retries are turned off, so only the client's retry policy is exercised.
+func testConfig(t *testing.T, baseURL string) *config.RustFSConfig {
+	t.Helper()
+	t.Setenv("AWS_MAX_ATTEMPTS", "1")
+	t.Setenv("RUSTFS_BASE_URL", baseURL)
+	t.Setenv("RUSTFS_ACCESS_KEY", "test-access")
+	t.Setenv("RUSTFS_SECRET_KEY", "test-secret")
+	t.Setenv("RUSTFS_RETRY_DELAY", "1ms")
+	return config.LoadConfig()
+}
+
+// testServer serves handler and counts the requests it receives
+type testServer struct {
+	*httptest.Server
+	requests atomic.Int64
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+	t.Helper()
+	server := &testServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClient returns a RustFS client sending every request to handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*RustFSClient, *testServer) {
+	t.Helper()
+	server := newTestServer(t, handler)
+	return NewRustFSClient(testConfig(t, server.URL)), server
+}
+
+// uploadRequest returns a request uploading content to path
+func uploadRequest(path, content string) *types.UploadRequest {
+	return &types.UploadRequest{
+		File:        strings.NewReader(content),
+		FileSize:    int64(len(content)),
+		ContentType: "text/plain",
+		BucketPath:  path,
+	}
+}
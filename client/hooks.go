@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// DownloadHook runs after a download has been read to completion, with the total bytes read.
+// Hook errors are logged and never affect the data returned to the caller.
+type DownloadHook func(ctx context.Context, info *types.FileInfo, bytesRead int64) error
+
+// hookReadCloser runs download hooks once the body reaches EOF, or on Close if every byte
+// of a known-size body was read. Partially read bodies don't fire hooks.
+type hookReadCloser struct {
+	io.ReadCloser
+	ctx   context.Context
+	info  *types.FileInfo
+	hooks []DownloadHook
+	read  int64
+	once  sync.Once
+}
+
+// withDownloadHooks wraps body so hooks fire after it has been fully consumed
+func withDownloadHooks(ctx context.Context, body io.ReadCloser, info *types.FileInfo, hooks []DownloadHook) io.ReadCloser {
+	if len(hooks) == 0 {
+		return body
+	}
+	return &hookReadCloser{ReadCloser: body, ctx: ctx, info: info, hooks: hooks}
+}
+
+// Read implements io.Reader
+func (r *hookReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err == io.EOF {
+		r.fire()
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (r *hookReadCloser) Close() error {
+	if r.info != nil && r.info.Size > 0 && r.read >= r.info.Size {
+		r.fire()
+	}
+	return r.ReadCloser.Close()
+}
+
+// fire runs each hook once, logging failures
+func (r *hookReadCloser) fire() {
+	r.once.Do(func() {
+		for _, hook := range r.hooks {
+			runDownloadHook(r.ctx, hook, r.info, r.read)
+		}
+	})
+}
+
+// runDownloadHook runs hook, logging its error or panic instead of propagating it
+func runDownloadHook(ctx context.Context, hook DownloadHook, info *types.FileInfo, bytesRead int64) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[RUSTFS] download hook for %s panicked: %v", info.Path, recovered)
+		}
+	}()
+
+	if err := hook(ctx, info, bytesRead); err != nil {
+		log.Printf("[RUSTFS] download hook for %s failed: %v", info.Path, err)
+	}
+}
+
+// AddDownloadHook registers a hook run after each download is fully read. Register hooks
+// before the client is shared between goroutines.
+func (c *RustFSClient) AddDownloadHook(hook DownloadHook) {
+	c.downloadHooks = append(c.downloadHooks, hook)
+}
+
+// AddDownloadHook registers a hook run after each mock download is fully read
+func (m *MockRustFSClient) AddDownloadHook(hook DownloadHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.downloadHooks = append(m.downloadHooks, hook)
+}
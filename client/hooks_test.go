@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// hookStorage is the surface of both clients used by the download hook tests
+type hookStorage interface {
+	FileStorage
+	Downloader
+	AddDownloadHook(hook DownloadHook)
+}
+
+// hookCall records one invocation of a download hook
+type hookCall struct {
+	path      string
+	bytesRead int64
+}
+
+func TestDownloadHooks(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+
+	storages := []struct {
+		name string
+		new  func(t *testing.T) hookStorage
+	}{
+		{"RustFSClient", func(t *testing.T) hookStorage {
+			c, _ := newFakeS3Client(t)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T) hookStorage { return NewMockRustFSClient() }},
+	}
+
+	tests := []struct {
+		name      string
+		consume   func(body io.Reader) error
+		wantCalls []hookCall
+	}{
+		{"read to EOF", func(body io.Reader) error {
+			_, err := io.ReadAll(body)
+			return err
+		}, []hookCall{{"data.txt", int64(len(content))}}},
+		{"every byte read without reaching EOF", func(body io.Reader) error {
+			_, err := io.ReadFull(body, make([]byte, len(content)))
+			return err
+		}, []hookCall{{"data.txt", int64(len(content))}}},
+		{"partial read", func(body io.Reader) error {
+			_, err := io.ReadFull(body, make([]byte, 10))
+			return err
+		}, nil},
+	}
+
+	for _, storage := range storages {
+		for _, tt := range tests {
+			t.Run(storage.name+"/"+tt.name, func(t *testing.T) {
+				s := storage.new(t)
+				ctx := context.Background()
+				if _, err := s.UploadFile(ctx, uploadRequest("data.txt", content)); err != nil {
+					t.Fatalf("UploadFile: %v", err)
+				}
+
+				var mu sync.Mutex
+				var calls []hookCall
+				s.AddDownloadHook(func(ctx context.Context, info *types.FileInfo, bytesRead int64) error {
+					panic("hook bug")
+				})
+				s.AddDownloadHook(func(ctx context.Context, info *types.FileInfo, bytesRead int64) error {
+					mu.Lock()
+					defer mu.Unlock()
+					calls = append(calls, hookCall{info.Path, bytesRead})
+					return errors.New("analytics unavailable")
+				})
+
+				body, _, err := s.DownloadFile(ctx, "data.txt")
+				if err != nil {
+					t.Fatalf("DownloadFile: %v", err)
+				}
+				if err := tt.consume(body); err != nil {
+					t.Fatalf("reading the body: %v", err)
+				}
+				// Reads past EOF and Close must not fire the hooks again
+				body.Read(make([]byte, 1))
+				if err := body.Close(); err != nil {
+					t.Errorf("Close: %v", err)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if len(calls) != len(tt.wantCalls) || (len(calls) > 0 && calls[0] != tt.wantCalls[0]) {
+					t.Errorf("hook calls = %+v, want %+v", calls, tt.wantCalls)
+				}
+			})
+		}
+	}
+}
+
+func TestDownloadHookLeavesDataIntact(t *testing.T) {
+	m := NewMockRustFSClient()
+	content := "hello, world"
+	if _, err := m.UploadFile(context.Background(), uploadRequest("data.txt", content)); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	m.AddDownloadHook(func(ctx context.Context, info *types.FileInfo, bytesRead int64) error {
+		return errors.New("cache warm-up failed")
+	})
+
+	body, _, err := m.DownloadFile(context.Background(), "data.txt")
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != content {
+		t.Errorf("downloaded %q, %v; want the content despite the failing hook", data, err)
+	}
+}
@@ -0,0 +1,318 @@
+// Package httprecord records the HTTP interactions of a RustFS client to a file and replays
+// them, so tests can run deterministically without a live server.
+//
+// In record mode every request is forwarded to the real transport and the exchange is kept;
+// Save writes the recording as JSON. In replay mode requests are answered from the file,
+// matched on method, URL, selected headers and a hash of the request body. Credentials and
+// request signatures never reach the file.
+package httprecord
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays interactions
+type Mode int
+
+const (
+	// ModeRecord forwards requests to the real transport and records each exchange
+	ModeRecord Mode = iota
+	// ModeReplay answers requests from a recording without any network access
+	ModeReplay
+)
+
+// RedactedValue replaces credentials in recordings
+const RedactedValue = "[REDACTED]"
+
+// ErrNoInteraction is returned in replay mode when no unused recorded interaction matches a request
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// DefaultMatchHeaders are the request headers that must match for a recorded interaction to be
+// replayed, besides method, URL and body. They change what the server does with a request.
+var DefaultMatchHeaders = []string{
+	"Content-Type",
+	"Range",
+	"If-Match",
+	"If-None-Match",
+	"X-Amz-Copy-Source",
+	"X-Amz-Metadata-Directive",
+}
+
+// redactedHeaders carry credentials or signatures and are never written to a recording
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+}
+
+// volatileQueryParams change on every request, such as presigned URL signatures, so they are
+// redacted and ignored when matching
+var volatileQueryParams = []string{
+	"X-Amz-Credential",
+	"X-Amz-Date",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+}
+
+// Interaction is one recorded request and the response it received
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request used to match it on replay
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	// BodySHA256 is the hex SHA-256 of the request body, empty for requests without one
+	BodySHA256 string `json:"body_sha256,omitempty"`
+}
+
+// RecordedResponse is a response as it is served back on replay
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions. It is safe for
+// concurrent use.
+type Recorder struct {
+	// MatchHeaders lists the request headers compared on replay; nil uses DefaultMatchHeaders
+	MatchHeaders []string
+
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// New creates a recorder for the recording at path. In record mode requests go through next,
+// or http.DefaultTransport when nil. In replay mode the recording is loaded from path.
+func New(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, next: next}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("failed to parse recording: %w", err)
+		}
+		r.replayed = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes the recorded interactions to the recorder's path. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	if err := os.WriteFile(r.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// recordRequest captures the matchable parts of req. The body is read to hash it, and req.Body
+// is replaced with an equivalent reader so it can still be sent.
+func (r *Recorder) recordRequest(req *http.Request) (RecordedRequest, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return RecordedRequest{}, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    canonicalURL(req.URL),
+		Header: make(http.Header),
+	}
+	for _, name := range r.matchHeaders() {
+		if values := req.Header.Values(name); len(values) > 0 {
+			recorded.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		recorded.BodySHA256 = hex.EncodeToString(sum[:])
+	}
+
+	return recorded, nil
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	redactHeader(header)
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       respBody,
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching recorded, so repeated identical
+// requests replay in the order they were recorded
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.replayed[i] = true
+
+		response := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+			StatusCode:    response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(response.Body)),
+			ContentLength: int64(len(response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+func (r *Recorder) matchHeaders() []string {
+	if r.MatchHeaders != nil {
+		return r.MatchHeaders
+	}
+	return DefaultMatchHeaders
+}
+
+// matches compares a recorded request with an incoming one
+func matches(recorded, incoming RecordedRequest) bool {
+	if recorded.Method != incoming.Method || recorded.URL != incoming.URL || recorded.BodySHA256 != incoming.BodySHA256 {
+		return false
+	}
+	if len(recorded.Header) != len(incoming.Header) {
+		return false
+	}
+	for name, values := range recorded.Header {
+		if strings.Join(values, ",") != strings.Join(incoming.Header[name], ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalURL renders u with sorted query parameters and volatile ones redacted, so
+// recordings don't depend on parameter order, signatures or timestamps
+func canonicalURL(u *url.URL) string {
+	canonical := *u
+	canonical.User = nil
+
+	query := canonical.Query()
+	for _, name := range volatileQueryParams {
+		if query.Has(name) {
+			query.Set(name, RedactedValue)
+		}
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	canonical.RawQuery = strings.Join(parts, "&")
+
+	return canonical.String()
+}
+
+// redactHeader masks credential headers in place
+func redactHeader(header http.Header) {
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, RedactedValue)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package httprecord
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exchange is a request sent through a Recorder in the tests
+type exchange struct {
+	method string
+	path   string
+	header map[string]string
+	body   string
+}
+
+func (e exchange) send(t *testing.T, client *http.Client, baseURL string) (int, string, error) {
+	t.Helper()
+	var body io.Reader
+	if e.body != "" {
+		body = strings.NewReader(e.body)
+	}
+	req, err := http.NewRequest(e.method, baseURL+e.path, body)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	for name, value := range e.header {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	return resp.StatusCode, string(data), nil
+}
+
+// newObjectServer stores PUT bodies and serves them back on GET, counting the requests it receives
+func newObjectServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	objects := make(map[string]string)
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(data)
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			io.WriteString(w, data)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestRecordReplay(t *testing.T) {
+	sequence := []exchange{
+		{method: http.MethodPut, path: "/bucket/a.txt", header: map[string]string{"Content-Type": "text/plain", "Authorization": "AWS4 secret"}, body: "first"},
+		{method: http.MethodGet, path: "/bucket/a.txt"},
+		{method: http.MethodPut, path: "/bucket/a.txt", header: map[string]string{"Content-Type": "text/plain"}, body: "second"},
+		{method: http.MethodGet, path: "/bucket/a.txt"},
+		{method: http.MethodGet, path: "/bucket/missing.txt?X-Amz-Signature=abc&X-Amz-Date=20260101T000000Z"},
+	}
+
+	server, hits := newObjectServer(t)
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	recorder, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	type result struct {
+		status int
+		body   string
+	}
+	var recorded []result
+	for _, e := range sequence {
+		status, body, err := e.send(t, &http.Client{Transport: recorder}, server.URL)
+		if err != nil {
+			t.Fatalf("recording %s %s: %v", e.method, e.path, err)
+		}
+		recorded = append(recorded, result{status, body})
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading recording: %v", err)
+	}
+	for _, secret := range []string{"AWS4 secret", "session=secret", "X-Amz-Signature=abc"} {
+		if strings.Contains(string(file), secret) {
+			t.Errorf("recording contains %q", secret)
+		}
+	}
+
+	recordedHits := *hits
+	replayer, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New replay: %v", err)
+	}
+	for i, e := range sequence {
+		// A fresh signature must still match the redacted recording
+		e.path = strings.Replace(e.path, "X-Amz-Signature=abc", "X-Amz-Signature=def", 1)
+		status, body, err := e.send(t, &http.Client{Transport: replayer}, server.URL)
+		if err != nil {
+			t.Fatalf("replaying %s %s: %v", e.method, e.path, err)
+		}
+		if got := (result{status, body}); got != recorded[i] {
+			t.Errorf("replayed %s %s = %+v, want %+v", e.method, e.path, got, recorded[i])
+		}
+	}
+	if *hits != recordedHits {
+		t.Errorf("replay reached the server %d times", *hits-recordedHits)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	server, _ := newObjectServer(t)
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	recorder, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	upload := exchange{method: http.MethodPut, path: "/bucket/a.txt", header: map[string]string{"Content-Type": "text/plain"}, body: "content"}
+	if _, _, err := upload.send(t, &http.Client{Transport: recorder}, server.URL); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		request exchange
+	}{
+		{"different body", exchange{method: http.MethodPut, path: "/bucket/a.txt", header: map[string]string{"Content-Type": "text/plain"}, body: "tampered"}},
+		{"different content type", exchange{method: http.MethodPut, path: "/bucket/a.txt", header: map[string]string{"Content-Type": "image/png"}, body: "content"}},
+		{"different path", exchange{method: http.MethodPut, path: "/bucket/b.txt", header: map[string]string{"Content-Type": "text/plain"}, body: "content"}},
+		{"different method", exchange{method: http.MethodGet, path: "/bucket/a.txt"}},
+		{"interaction already replayed", upload},
+	}
+
+	replayer, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("New replay: %v", err)
+	}
+	if _, _, err := upload.send(t, &http.Client{Transport: replayer}, server.URL); err != nil {
+		t.Fatalf("replaying the recorded upload: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.request.send(t, &http.Client{Transport: replayer}, server.URL)
+			if !errors.Is(err, ErrNoInteraction) {
+				t.Errorf("error = %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/client/httprecord"
+)
+
+func TestRecordReplayClientSequence(t *testing.T) {
+	fake := newFakeS3("default")
+	server := newTestServer(t, fake.ServeHTTP)
+	cfg := testConfig(t, server.URL)
+	cfg.BucketName = fake.bucket
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	// sequence uploads two files, reads one back and deletes the other, returning what it observed
+	sequence := func(c *RustFSClient) ([]string, error) {
+		ctx := context.Background()
+		var observed []string
+		for _, upload := range []struct{ path, content string }{{"docs/a.txt", "alpha"}, {"docs/b.txt", "bravo"}} {
+			resp, err := c.UploadFile(ctx, uploadRequest(upload.path, upload.content))
+			if err != nil {
+				return nil, err
+			}
+			observed = append(observed, resp.ETag)
+		}
+
+		body, info, err := c.DownloadFile(ctx, "docs/a.txt")
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		observed = append(observed, string(data), info.ContentType)
+
+		if err := c.DeleteFile(ctx, "docs/b.txt"); err != nil {
+			return nil, err
+		}
+		exists, err := c.FileExists(ctx, "docs/b.txt")
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			observed = append(observed, "b exists")
+		}
+		return observed, nil
+	}
+
+	tests := []struct {
+		name string
+		mode httprecord.Mode
+	}{
+		{"record", httprecord.ModeRecord},
+		{"replay", httprecord.ModeReplay},
+		{"replay again", httprecord.ModeReplay},
+	}
+
+	var want []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, err := httprecord.New(path, tt.mode, nil)
+			if err != nil {
+				t.Fatalf("httprecord.New: %v", err)
+			}
+			c := NewRustFSClientWithHTTPClient(cfg, &http.Client{Transport: recorder})
+
+			before := server.requests.Load()
+			observed, err := sequence(c)
+			if err != nil {
+				t.Fatalf("sequence: %v", err)
+			}
+			if err := recorder.Save(); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			if tt.mode == httprecord.ModeRecord {
+				want = observed
+				return
+			}
+			if server.requests.Load() != before {
+				t.Errorf("replay sent %d requests to the server", server.requests.Load()-before)
+			}
+			if len(observed) != len(want) {
+				t.Fatalf("observed %q, want %q", observed, want)
+			}
+			for i := range want {
+				if observed[i] != want[i] {
+					t.Errorf("observed[%d] = %q, want %q", i, observed[i], want[i])
+				}
+			}
+		})
+	}
+}
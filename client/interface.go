@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
 	"time"
 
@@ -35,10 +36,16 @@ type ProgressCallback func(progress *types.UploadProgress)
 
 // UploadOptions defines options for file upload
 type UploadOptions struct {
-	ProgressCallback  ProgressCallback
+	ProgressCallback ProgressCallback
+	// EnableCompression gzips the upload and stores it with Content-Encoding: gzip, unless its
+	// content type is an already-compressed format such as image/jpeg or application/zip
 	EnableCompression bool
-	EnableEncryption  bool
 	Metadata          map[string]interface{}
+	// IfNotExists uploads with If-None-Match: *, so an existing object fails the upload with
+	// ErrAlreadyExists instead of being overwritten. The check is atomic on the server.
+	IfNotExists bool
+	// VerifyAfterUpload reads the object back after upload and compares it with the source
+	VerifyAfterUpload VerificationScope
 }
 
 // ClientOptions defines options for client initialization
@@ -65,6 +72,107 @@ type HealthChecker interface {
 	GetCapabilities(ctx context.Context) ([]string, error)
 }
 
+// FileInfoReader defines reads of file information
+type FileInfoReader interface {
+	GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error)
+}
+
+// RangeDownloader defines reads of a byte window of stored file content
+type RangeDownloader interface {
+	DownloadRange(ctx context.Context, path string, start, end int64) (io.ReadCloser, error)
+}
+
+// ExistenceChecker defines cheap checks for whether an object exists
+type ExistenceChecker interface {
+	FileExists(ctx context.Context, path string) (bool, error)
+}
+
+// FileInfoBatchReader defines looking up the info of many files in one call, keyed by path
+type FileInfoBatchReader interface {
+	GetFileInfos(ctx context.Context, paths []string) (map[string]FileInfoResult, error)
+}
+
+// DeleteResultReporter defines a delete that reports whether an object was actually removed
+type DeleteResultReporter interface {
+	DeleteFileResult(ctx context.Context, path string) (bool, error)
+}
+
+// MetadataUpdater defines in-place metadata updates that do not re-upload object content
+type MetadataUpdater interface {
+	UpdateMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) (*types.FileInfo, error)
+}
+
+// Downloader defines streaming reads of stored file content
+type Downloader interface {
+	DownloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error)
+}
+
+// LargeFileUploader defines chunked uploads of files larger than the configured chunk size
+type LargeFileUploader interface {
+	UploadLargeFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error)
+}
+
+// FileReader defines reading a small object fully into memory
+type FileReader interface {
+	ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, *types.FileInfo, error)
+}
+
+// DownloadTokenIssuer defines opaque, time-bounded and use-limited download tokens
+type DownloadTokenIssuer interface {
+	CreateDownloadToken(ctx context.Context, path string, ttl time.Duration, maxUses int) (string, error)
+	RedeemDownloadToken(ctx context.Context, token string) (io.ReadCloser, *types.FileInfo, error)
+}
+
+// Copier defines server-side copies between paths
+type Copier interface {
+	CopyFile(ctx context.Context, sourcePath, destPath string) error
+}
+
+// OptionsCopier defines server-side copies that can extend or replace the copied metadata
+type OptionsCopier interface {
+	CopyFileWithOptions(ctx context.Context, sourcePath, destPath string, opts *CopyOptions) error
+}
+
+// Mover defines moving an object to a new path
+type Mover interface {
+	MoveFile(ctx context.Context, sourcePath, destPath string) error
+}
+
+// BatchCopier defines server-side copies of many objects, reporting each copy's outcome
+// keyed by destination path
+type BatchCopier interface {
+	BatchCopy(ctx context.Context, copies []FileCopy) (map[string]error, error)
+}
+
+// Tagger defines object tagging and tag-based lookup
+type Tagger interface {
+	GetTags(ctx context.Context, path string) (map[string]string, error)
+	SetTags(ctx context.Context, path string, tags map[string]string) error
+	ListFilesByTag(ctx context.Context, key, value string, opts *ListOptions) ([]*types.FileInfo, error)
+}
+
+// ContentTypeRepairer defines in-place correction of stored content types by sniffing content
+type ContentTypeRepairer interface {
+	FixContentType(ctx context.Context, path string) (*types.FileInfo, error)
+	FixContentTypePrefix(ctx context.Context, prefix string) ([]*types.FileInfo, error)
+}
+
+// ActivitySummarizer defines a lifetime activity report, emitted by the auditable client on Close
+type ActivitySummarizer interface {
+	Summary() ClientSummary
+}
+
+// Pinger defines a lightweight liveness check, cheaper than a full health check
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Canceller defines aborting every in-flight operation of a client
+type Canceller interface {
+	CancelAll()
+	ResetCancellation()
+}
+
 // BatchOperations defines batch operations interface
 type BatchOperations interface {
 	BatchUpload(ctx context.Context, requests []*types.UploadRequest) ([]*types.UploadResponse, error)
@@ -78,15 +186,33 @@ type FileMove struct {
 	TargetPath string
 }
 
+// DefaultPageSize is the number of results returned when a list or search limit is zero
+const DefaultPageSize = 1000
+
 // SearchOptions defines search options
 type SearchOptions struct {
-	Query      string
-	Prefix     string
+	Query  string
+	Prefix string
+	// MaxResults follows the same convention as ListOptions.Limit
 	MaxResults int
 	SortBy     string
 	SortOrder  string
 }
 
+// ListOptions defines options for listing files
+type ListOptions struct {
+	Prefix string
+	// Limit caps the number of results. Zero means DefaultPageSize and a negative value is
+	// rejected; use WalkFiles to visit every matching file.
+	Limit int
+	// DirectoryMode treats Prefix as a path segment boundary instead of a raw string prefix,
+	// so "img" matches "img/a.png" but not "images/b.png"
+	DirectoryMode bool
+	// IncludeMetadata returns each file's metadata map and content type. Listings are
+	// lightweight (path, size, ETag) by default, since metadata costs a lookup per file.
+	IncludeMetadata bool
+}
+
 // SearchResult defines search result
 type SearchResult struct {
 	Path         string                 `json:"path"`
@@ -126,3 +252,9 @@ type AdvancedStorage interface {
 	PresignedURL
 	Webhook
 }
+
+// MultipartMaintenance defines housekeeping operations for dangling multipart uploads
+type MultipartMaintenance interface {
+	ListIncompleteUploads(ctx context.Context, prefix string) ([]types.IncompleteUpload, error)
+	AbortIncompleteUploads(ctx context.Context, olderThan time.Duration) (int, error)
+}
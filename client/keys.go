@@ -0,0 +1,32 @@
+package client
+
+import (
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// validateObjectKey checks a key about to be written, using utils.DefaultKeyValidator when no
+// validator is set. Rejections wrap utils.ErrInvalidKey.
+func validateObjectKey(validator utils.KeyValidator, key string) error {
+	if validator == nil {
+		validator = utils.DefaultKeyValidator{}
+	}
+	if err := validator.Validate(key); err != nil {
+		return apperror.NewAppError(400, "INVALID_KEY", err)
+	}
+	return nil
+}
+
+// SetKeyValidator sets the rules object keys must pass before any upload or copy writes them;
+// nil restores utils.DefaultKeyValidator. Combine the default with stricter rules through
+// utils.ChainKeyValidators to keep its limits.
+func (c *RustFSClient) SetKeyValidator(validator utils.KeyValidator) {
+	c.keyValidator = validator
+}
+
+// SetKeyValidator sets the rules object keys must pass before mock uploads and copies
+func (m *MockRustFSClient) SetKeyValidator(validator utils.KeyValidator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keyValidator = validator
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// copyStorage is the surface of both clients used by the key validation tests
+type copyStorage interface {
+	FileStorage
+	CopyFile(ctx context.Context, sourcePath, destPath string) error
+}
+
+func TestCustomKeyValidator(t *testing.T) {
+	validator := utils.ChainKeyValidators(utils.DefaultKeyValidator{}, utils.RequireKeyPrefix("tenant-a/"))
+
+	c, fake := newFakeS3Client(t)
+	c.SetKeyValidator(validator)
+	var requests atomic.Int32
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		requests.Add(1)
+		return false
+	}
+	fake.put("tenant-a/source.txt", []byte("content"), nil)
+
+	m := NewMockRustFSClient()
+	if _, err := m.UploadFile(context.Background(), uploadRequest("tenant-a/source.txt", "content")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	m.SetKeyValidator(validator)
+
+	writes := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"required prefix", "tenant-a/ok.txt", false},
+		{"other tenant", "tenant-b/report.txt", true},
+		{"no prefix", "report.txt", true},
+		{"prefix not at the start", "archive/tenant-a/report.txt", true},
+		{"default rule still applies", "tenant-a/../tenant-b/report.txt", true},
+	}
+
+	for name, s := range map[string]copyStorage{"RustFSClient": c, "MockRustFSClient": m} {
+		for _, tt := range writes {
+			operations := map[string]func() error{
+				"upload": func() error {
+					_, err := s.UploadFile(context.Background(), uploadRequest(tt.key, "content"))
+					return err
+				},
+				"copy": func() error {
+					return s.CopyFile(context.Background(), "tenant-a/source.txt", tt.key)
+				},
+			}
+			for operation, write := range operations {
+				t.Run(name+"/"+operation+"/"+tt.name, func(t *testing.T) {
+					sent := requests.Load()
+					err := write()
+					if !tt.wantErr {
+						if err != nil {
+							t.Fatalf("%s to %q: %v", operation, tt.key, err)
+						}
+						return
+					}
+					if !errors.Is(err, utils.ErrInvalidKey) || errorCode(err) != "INVALID_KEY" {
+						t.Fatalf("%s to %q error = %v, want INVALID_KEY", operation, tt.key, err)
+					}
+					if name == "RustFSClient" {
+						if n := requests.Load() - sent; n != 0 {
+							t.Errorf("rejected key sent %d requests", n)
+						}
+					}
+					if _, err := s.GetFileInfo(context.Background(), tt.key); !errors.Is(err, ErrFileNotFound) {
+						t.Errorf("rejected key %q was stored: %v", tt.key, err)
+					}
+				})
+			}
+		}
+	}
+
+	// Clearing the validator restores the default rules, which don't require a prefix
+	c.SetKeyValidator(nil)
+	if _, err := c.UploadFile(context.Background(), uploadRequest("report.txt", "content")); err != nil {
+		t.Fatalf("UploadFile after clearing the validator: %v", err)
+	}
+	if _, err := c.UploadFile(context.Background(), uploadRequest("a/../b.txt", "content")); !errors.Is(err, utils.ErrInvalidKey) {
+		t.Fatalf("default validator accepted a traversal key: %v", err)
+	}
+}
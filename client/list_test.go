@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// listStorage is the surface of both clients used by the listing tests
+type listStorage interface {
+	FileStorage
+	ListFilesWithOptions(ctx context.Context, opts *ListOptions) ([]*types.FileInfo, error)
+}
+
+// listStorages returns both clients, each holding a file at every path
+func listStorages(t *testing.T, paths ...string) map[string]listStorage {
+	t.Helper()
+	c, fake := newFakeS3Client(t)
+	m := NewMockRustFSClient()
+	for _, path := range paths {
+		fake.put(path, []byte("content"), nil)
+		if _, err := m.UploadFile(context.Background(), uploadRequest(path, "content")); err != nil {
+			t.Fatalf("UploadFile(%s): %v", path, err)
+		}
+	}
+	return map[string]listStorage{"RustFSClient": c, "MockRustFSClient": m}
+}
+
+// listedPaths returns the paths of files
+func listedPaths(files []*types.FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestListFilesDirectoryMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefix        string
+		directoryMode bool
+		want          []string
+	}{
+		{"raw prefix crosses segments", "im", false, []string{"images/b.png", "img/a.png", "img/sub/c.png", "imgs/d.png"}},
+		{"raw prefix matches a longer directory", "img", false, []string{"img/a.png", "img/sub/c.png", "imgs/d.png"}},
+		{"directory prefix stops at the segment", "img", true, []string{"img/a.png", "img/sub/c.png"}},
+		{"trailing delimiter in directory mode", "img/", true, []string{"img/a.png", "img/sub/c.png"}},
+		{"trailing delimiter as a raw prefix", "img/", false, []string{"img/a.png", "img/sub/c.png"}},
+		{"other directory", "images/", true, []string{"images/b.png"}},
+		{"partial segment matches nothing", "im", true, []string{}},
+	}
+
+	for name, s := range listStorages(t, "img/a.png", "img/sub/c.png", "images/b.png", "imgs/d.png") {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				files, err := s.ListFilesWithOptions(context.Background(), &ListOptions{
+					Prefix:        tt.prefix,
+					DirectoryMode: tt.directoryMode,
+				})
+				if err != nil {
+					t.Fatalf("ListFilesWithOptions: %v", err)
+				}
+				got := listedPaths(files)
+				slices.Sort(got)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("listed %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestZeroLimitMeansDefaultPageSize(t *testing.T) {
+	files := DefaultPageSize + 1
+	paths := make([]string, files)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("docs/%04d.txt", i)
+	}
+
+	limits := []struct {
+		name    string
+		limit   int
+		want    int
+		wantErr bool
+	}{
+		{"zero is the default page size", 0, DefaultPageSize, false},
+		{"positive limit", 3, 3, false},
+		{"negative limit is rejected", -1, 0, true},
+	}
+
+	t.Run("listing", func(t *testing.T) {
+		c, fake := newFakeS3Client(t)
+		builder := NewMockRustFSClientBuilder()
+		for _, path := range paths {
+			fake.put(path, []byte("content"), nil)
+			builder.WithFile(path, 7, "text/plain")
+		}
+		storages := map[string]listStorage{"RustFSClient": c, "MockRustFSClient": builder.Build()}
+
+		for name, s := range storages {
+			for _, tt := range limits {
+				t.Run(name+"/"+tt.name, func(t *testing.T) {
+					listed, err := s.ListFilesWithOptions(context.Background(), &ListOptions{Prefix: "docs/", Limit: tt.limit})
+					if tt.wantErr {
+						if !errors.Is(err, ErrInvalidLimit) {
+							t.Errorf("ListFilesWithOptions = %v, want ErrInvalidLimit", err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("ListFilesWithOptions: %v", err)
+					}
+					if len(listed) != tt.want {
+						t.Errorf("listed %d files, want %d", len(listed), tt.want)
+					}
+				})
+			}
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		builder := NewMockRustFSClientBuilder()
+		for _, path := range paths {
+			builder.WithFile(path, 7, "text/plain")
+		}
+		m := builder.Build()
+		for _, info := range m.GetFiles() {
+			info.Metadata["owner"] = "alice"
+		}
+
+		// The server applies the limit, so the real client is checked for the one it sends
+		c, fake := newFakeS3Client(t)
+		var sent []string
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			switch r.URL.Path {
+			case apiPrefix + "capabilities":
+				json.NewEncoder(w).Encode(capabilitiesResponse{Capabilities: []string{CapabilitySearch}})
+				return true
+			case apiPrefix + "buckets/" + fake.bucket + "/search":
+				sent = append(sent, r.URL.Query().Get("max_results"))
+				json.NewEncoder(w).Encode(searchResponse{})
+				return true
+			}
+			return false
+		}
+
+		for _, tt := range limits {
+			t.Run(tt.name, func(t *testing.T) {
+				sent = nil
+				_, err := c.SearchFiles(context.Background(), &SearchOptions{Query: "docs", MaxResults: tt.limit})
+				if tt.wantErr {
+					if !errors.Is(err, ErrInvalidLimit) || len(sent) != 0 {
+						t.Errorf("SearchFiles = %v after %d requests, want ErrInvalidLimit without a request", err, len(sent))
+					}
+				} else if err != nil || len(sent) != 1 || sent[0] != strconv.Itoa(tt.want) {
+					t.Errorf("SearchFiles sent max_results %v, %v; want %d", sent, err, tt.want)
+				}
+
+				results, err := m.SearchFiles(context.Background(), &SearchOptions{Query: "docs", MaxResults: tt.limit})
+				if tt.wantErr {
+					if !errors.Is(err, ErrInvalidLimit) {
+						t.Errorf("mock SearchFiles = %v, want ErrInvalidLimit", err)
+					}
+				} else if err != nil || len(results) != tt.want {
+					t.Errorf("mock SearchFiles returned %d results, %v; want %d", len(results), err, tt.want)
+				}
+			})
+		}
+
+		results, err := m.SearchByMetadata(context.Background(), "owner", "alice")
+		if err != nil || len(results) != DefaultPageSize {
+			t.Errorf("mock SearchByMetadata returned %d results, %v; want %d", len(results), err, DefaultPageSize)
+		}
+		sent = nil
+		if _, err := c.SearchByMetadata(context.Background(), "owner", "alice"); err != nil || len(sent) != 1 || sent[0] != strconv.Itoa(DefaultPageSize) {
+			t.Errorf("SearchByMetadata sent max_results %v, %v; want %d", sent, err, DefaultPageSize)
+		}
+	})
+}
+
+func TestListFilesIncludeMetadata(t *testing.T) {
+	paths := []string{"docs/a.txt", "docs/b.txt", "docs/c.txt"}
+	c, fake := newFakeS3Client(t)
+	m := NewMockRustFSClient()
+	for _, path := range paths {
+		fake.put(path, []byte("content"), map[string]string{"owner": "alice"})
+		req := uploadRequest(path, "content")
+		req.Metadata = map[string]interface{}{"owner": "alice"}
+		if _, err := m.UploadFile(context.Background(), req); err != nil {
+			t.Fatalf("UploadFile(%s): %v", path, err)
+		}
+	}
+
+	for name, s := range map[string]listStorage{"RustFSClient": c, "MockRustFSClient": m} {
+		for _, include := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/include %v", name, include), func(t *testing.T) {
+				heads := len(fake.requests("HEAD", "docs/"))
+				files, err := s.ListFilesWithOptions(context.Background(), &ListOptions{
+					Prefix:          "docs/",
+					IncludeMetadata: include,
+				})
+				if err != nil {
+					t.Fatalf("ListFilesWithOptions: %v", err)
+				}
+				if len(files) != len(paths) {
+					t.Fatalf("listed %v, want %v", listedPaths(files), paths)
+				}
+				for _, file := range files {
+					if got := file.Metadata["owner"]; include && got != "alice" {
+						t.Errorf("%s metadata = %v, want owner alice", file.Path, file.Metadata)
+					}
+					if !include && (file.Metadata != nil || file.ContentType != "") {
+						t.Errorf("%s lightweight listing has metadata %v and content type %q", file.Path, file.Metadata, file.ContentType)
+					}
+					if file.Size != int64(len("content")) {
+						t.Errorf("%s size = %d, want %d", file.Path, file.Size, len("content"))
+					}
+				}
+
+				if name != "RustFSClient" {
+					return
+				}
+				// Metadata isn't returned inline by the listing, so it costs one lookup per file
+				wantHeads := 0
+				if include {
+					wantHeads = len(paths)
+				}
+				if got := len(fake.requests("HEAD", "docs/")) - heads; got != wantHeads {
+					t.Errorf("listing sent %d HEAD requests, want %d", got, wantHeads)
+				}
+			})
+		}
+	}
+
+	// The mock's lightweight listing must not strip metadata from the stored file
+	info, err := m.GetFileInfo(context.Background(), "docs/a.txt")
+	if err != nil || info.Metadata["owner"] != "alice" {
+		t.Fatalf("stored metadata after listing = %v, %v; want owner alice", info, err)
+	}
+}
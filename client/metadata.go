@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// CapabilityMetadataUpdate marks servers that can rewrite an object's metadata without copying it
+const CapabilityMetadataUpdate = "metadata_update"
+
+// metadataUpdateRequest is the body sent to the metadata endpoint
+type metadataUpdateRequest struct {
+	Path     string            `json:"path"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// UpdateMetadata changes an object's metadata without re-uploading its content. Servers with
+// CapabilityMetadataUpdate rewrite the headers through the metadata endpoint; others get a
+// server-side copy of the object onto itself. When replace is true the given metadata
+// overwrites all existing metadata; otherwise it is merged over the existing keys.
+func (c *RustFSClient) UpdateMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) (*types.FileInfo, error) {
+	path = c.objectKey(path)
+	current, err := c.GetFileInfo(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := mergeMetadata(current.Metadata, metadata, replace)
+	if !c.capabilities.supports(ctx, CapabilityMetadataUpdate) {
+		return c.rewriteObjectHeaders(ctx, current, updated, current.ContentType)
+	}
+
+	if err := c.putObjectMetadata(ctx, current, updated); err != nil {
+		return nil, err
+	}
+	return c.GetFileInfo(ctx, path)
+}
+
+// putObjectMetadata stores metadata for source through the metadata endpoint, keeping
+// client-side encryption metadata like copyWithMetadata
+func (c *RustFSClient) putObjectMetadata(ctx context.Context, source *types.FileInfo, metadata map[string]interface{}) error {
+	path := source.Path
+	if isEncrypted(source.Metadata) {
+		metadata = withSourceEncryptionMetadata(metadata, source.Metadata)
+	}
+	limited, sideObjects, err := c.limitMetadataValues(path, metadata)
+	if err != nil {
+		return err
+	}
+	encoded, err := c.metadataEncoder.Encode(limited)
+	if err != nil {
+		return apperror.NewAppError(400, "INVALID_METADATA", err)
+	}
+	payload, err := json.Marshal(metadataUpdateRequest{Path: path, Metadata: encoded})
+	if err != nil {
+		return apperror.NewAppError(400, "INVALID_METADATA", err)
+	}
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/metadata", url.PathEscape(c.config.BucketName)))
+	resp, err := c.doSignedRequest(ctx, http.MethodPut, endpoint, payload)
+	if err != nil {
+		return apperror.NewAppError(500, "UPDATE_METADATA_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return apperror.NewAppError(resp.StatusCode, "UPDATE_METADATA_FAILED",
+			fmt.Errorf("metadata endpoint returned %s", resp.Status))
+	}
+	c.bodies.invalidate(path)
+	return c.syncSideObjects(ctx, path, sideObjects)
+}
+
+// rewriteObjectHeaders replaces an object's metadata and content type in place with a
+// server-side copy onto itself, carrying over the other system headers, and returns the new info
+func (c *RustFSClient) rewriteObjectHeaders(ctx context.Context, current *types.FileInfo, metadata map[string]interface{}, contentType string) (*types.FileInfo, error) {
+	if err := c.copyWithMetadata(ctx, current, current.Path, metadata, contentType, "UPDATE_METADATA_FAILED"); err != nil {
+		return nil, err
+	}
+	return c.GetFileInfo(ctx, current.Path)
+}
+
+// copyWithMetadata copies source to destPath with a REPLACE metadata directive, storing metadata
+// and contentType and carrying over the other system headers. Client-side encryption metadata is
+// always kept, since the content can't be decrypted without it. A failed copy is reported with
+// failureCode.
+func (c *RustFSClient) copyWithMetadata(ctx context.Context, source *types.FileInfo, destPath string, metadata map[string]interface{}, contentType, failureCode string) error {
+	if isEncrypted(source.Metadata) {
+		metadata = withSourceEncryptionMetadata(metadata, source.Metadata)
+	}
+	metadata, sideObjects, err := c.limitMetadataValues(destPath, metadata)
+	if err != nil {
+		return err
+	}
+	encoded, err := c.metadataEncoder.Encode(metadata)
+	if err != nil {
+		return apperror.NewAppError(400, "INVALID_METADATA", err)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(c.config.BucketName),
+		Key:               aws.String(destPath),
+		CopySource:        aws.String(c.copySource(source.Path)),
+		MetadataDirective: s3types.MetadataDirectiveReplace,
+		Metadata:          encoded,
+	}
+	// A REPLACE copy resets system metadata, so carry it over explicitly
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if source.StorageClass != "" {
+		input.StorageClass = s3types.StorageClass(source.StorageClass)
+	}
+	if source.CacheControl != "" {
+		input.CacheControl = aws.String(source.CacheControl)
+	}
+	if source.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(source.ContentDisposition)
+	}
+	if source.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(source.ContentEncoding)
+	}
+
+	_, err = c.client.CopyObject(ctx, input)
+	c.bodies.invalidate(destPath)
+	if err != nil {
+		return apperror.NewAppError(500, failureCode, err)
+	}
+	return c.syncSideObjects(ctx, destPath, sideObjects)
+}
+
+// MetadataSchemaVersionKey is the metadata key holding an object's metadata schema version
+const MetadataSchemaVersionKey = "schema_version"
+
+// MetadataMigrator transforms metadata written under an older schema into the current
+// shape and returns the schema version the result conforms to
+type MetadataMigrator func(old map[string]interface{}) (map[string]interface{}, string)
+
+// MigratableStorage defines the operations needed to migrate object metadata
+type MigratableStorage interface {
+	GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error)
+	MetadataUpdater
+}
+
+// MigrateMetadata reads an object's metadata and, if its schema version differs from
+// currentVersion, applies migrator and writes the result back, replacing the old metadata.
+// Objects already at currentVersion are returned unchanged, as is every object when
+// currentVersion is empty, since there is no version to migrate to.
+func MigrateMetadata(ctx context.Context, storage MigratableStorage, path, currentVersion string, migrator MetadataMigrator) (*types.FileInfo, error) {
+	info, err := storage.GetFileInfo(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentVersion == "" {
+		return info, nil
+	}
+	if version, ok := info.Metadata[MetadataSchemaVersionKey]; ok && fmt.Sprintf("%v", version) == currentVersion {
+		return info, nil
+	}
+
+	old := make(map[string]interface{}, len(info.Metadata))
+	for k, v := range info.Metadata {
+		old[k] = v
+	}
+
+	migrated, newVersion := migrator(old)
+	if migrated == nil {
+		migrated = make(map[string]interface{})
+	}
+	migrated[MetadataSchemaVersionKey] = newVersion
+
+	return storage.UpdateMetadata(ctx, path, migrated, true)
+}
+
+// MigrateMetadata migrates an object's metadata to the configured schema version
+func (c *RustFSClient) MigrateMetadata(ctx context.Context, path string, migrator MetadataMigrator) (*types.FileInfo, error) {
+	return MigrateMetadata(ctx, c, path, c.config.MetadataSchemaVersion, migrator)
+}
+
+// withSchemaVersion returns a copy of metadata tagged with version, leaving an explicit
+// caller-supplied version untouched. The caller's map is never mutated.
+func withSchemaVersion(metadata map[string]interface{}, version string) map[string]interface{} {
+	if version == "" {
+		return metadata
+	}
+	if _, exists := metadata[MetadataSchemaVersionKey]; exists {
+		return metadata
+	}
+
+	tagged := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		tagged[k] = v
+	}
+	tagged[MetadataSchemaVersionKey] = version
+	return tagged
+}
+
+// withDefaultMetadata returns a copy of metadata with the defaults added for keys the caller
+// didn't set, compared case-insensitively as S3 does, and the sorted keys that were added. The
+// caller's map is never mutated.
+func withDefaultMetadata(metadata map[string]interface{}, defaults map[string]string) (map[string]interface{}, []string) {
+	if len(defaults) == 0 {
+		return metadata, nil
+	}
+
+	set := make(map[string]bool, len(metadata))
+	for k := range metadata {
+		set[strings.ToLower(k)] = true
+	}
+
+	merged := make(map[string]interface{}, len(metadata)+len(defaults))
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	var applied []string
+	for k, v := range defaults {
+		if set[strings.ToLower(k)] {
+			continue
+		}
+		merged[k] = v
+		applied = append(applied, k)
+	}
+	sort.Strings(applied)
+	return merged, applied
+}
+
+// copySource returns the URL-encoded bucket/key copy source for path
+func (c *RustFSClient) copySource(path string) string {
+	return c.config.BucketName + "/" + utils.EscapePath(path)
+}
+
+// mergeMetadata returns a new map holding updates merged over current, or only updates when replace is true.
+// An update replaces a current key that differs from it only in case.
+func mergeMetadata(current, updates map[string]interface{}, replace bool) map[string]interface{} {
+	merged := make(map[string]interface{})
+	if !replace {
+		updated := make(map[string]bool, len(updates))
+		for k := range updates {
+			updated[strings.ToLower(k)] = true
+		}
+		for k, v := range current {
+			if !updated[strings.ToLower(k)] {
+				merged[k] = v
+			}
+		}
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+	return merged
+}
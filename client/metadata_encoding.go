@@ -0,0 +1,245 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// Metadata wire encodings selectable with RUSTFS_METADATA_ENCODING
+const (
+	MetadataEncodingPerKey     = "per-key"
+	MetadataEncodingJSON       = "json"
+	MetadataEncodingBase64JSON = "base64-json"
+)
+
+// encodedMetadataKey is the single metadata key used by the JSON-based encodings
+const encodedMetadataKey = "metadata"
+
+// MetadataEncoder converts upload metadata to and from the object metadata headers sent to the server
+type MetadataEncoder interface {
+	Encode(metadata map[string]interface{}) (map[string]string, error)
+	Decode(headers map[string]string) (map[string]interface{}, error)
+}
+
+// newConfiguredMetadataEncoder returns the encoder selected by the configuration
+func newConfiguredMetadataEncoder(cfg *config.RustFSConfig) (MetadataEncoder, error) {
+	encoder, err := NewMetadataEncoder(cfg.MetadataEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := encoder.(PerKeyMetadataEncoder); ok {
+		encoder = PerKeyMetadataEncoder{MergeCollisions: cfg.MetadataKeyCollision == config.MetadataKeyCollisionMerge}
+	}
+	return encoder, nil
+}
+
+// NewMetadataEncoder returns the encoder for an encoding name; empty selects per-key
+func NewMetadataEncoder(encoding string) (MetadataEncoder, error) {
+	switch encoding {
+	case "", MetadataEncodingPerKey:
+		return PerKeyMetadataEncoder{}, nil
+	case MetadataEncodingJSON:
+		return JSONMetadataEncoder{}, nil
+	case MetadataEncodingBase64JSON:
+		return Base64JSONMetadataEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown metadata encoding %q", encoding)
+	}
+}
+
+// PerKeyMetadataEncoder sends each entry as its own X-Amz-Meta-* header. Values that are not
+// plain printable ASCII are RFC 2047 encoded so they survive as header values.
+//
+// Header names are case-insensitive, so keys are lowercased. Keys that become equal are
+// rejected with ErrMetadataKeyCollision unless MergeCollisions is set, in which case the
+// all-lowercase spelling wins, or else the first spelling in byte order. The JSON-based
+// encodings keep keys in their original case.
+type PerKeyMetadataEncoder struct {
+	MergeCollisions bool
+}
+
+// Encode implements MetadataEncoder
+func (e PerKeyMetadataEncoder) Encode(metadata map[string]interface{}) (map[string]string, error) {
+	canonical, err := canonicalMetadataKeys(metadata, e.MergeCollisions)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(canonical))
+	for k, v := range canonical {
+		value := fmt.Sprintf("%v", v)
+		if needsHeaderEncoding(value) {
+			value = mime.BEncoding.Encode("UTF-8", value)
+		}
+		headers[k] = value
+	}
+	return headers, nil
+}
+
+// Decode implements MetadataEncoder
+func (PerKeyMetadataEncoder) Decode(headers map[string]string) (map[string]interface{}, error) {
+	decoder := new(mime.WordDecoder)
+	metadata := make(map[string]interface{}, len(headers))
+	for k, v := range headers {
+		if decoded, err := decoder.DecodeHeader(v); err == nil {
+			v = decoded
+		}
+		metadata[k] = v
+	}
+	return metadata, nil
+}
+
+// canonicalMetadataKeys lowercases metadata keys, resolving keys that collide as described
+// on PerKeyMetadataEncoder
+func canonicalMetadataKeys(metadata map[string]interface{}, merge bool) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	canonical := make(map[string]interface{}, len(metadata))
+	spelling := make(map[string]string, len(metadata))
+	for _, k := range keys {
+		lower := strings.ToLower(k)
+		previous, collides := spelling[lower]
+		if !collides {
+			canonical[lower] = metadata[k]
+			spelling[lower] = k
+			continue
+		}
+
+		if !merge {
+			return nil, fmt.Errorf("%w: %q and %q", ErrMetadataKeyCollision, previous, k)
+		}
+		if k == lower {
+			canonical[lower] = metadata[k]
+			spelling[lower] = k
+		}
+	}
+	return canonical, nil
+}
+
+// JSONMetadataEncoder sends the whole metadata map as one JSON document in a single header,
+// preserving value types. Non-ASCII characters are \u-escaped.
+type JSONMetadataEncoder struct{}
+
+// Encode implements MetadataEncoder
+func (JSONMetadataEncoder) Encode(metadata map[string]interface{}) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return map[string]string{}, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return map[string]string{encodedMetadataKey: asciiJSON(string(data))}, nil
+}
+
+// Decode implements MetadataEncoder
+func (JSONMetadataEncoder) Decode(headers map[string]string) (map[string]interface{}, error) {
+	return decodeJSONMetadata(headers, func(value string) ([]byte, error) {
+		return []byte(value), nil
+	})
+}
+
+// Base64JSONMetadataEncoder sends the metadata map as base64-wrapped JSON in a single header,
+// for servers that mangle quotes or other punctuation in header values
+type Base64JSONMetadataEncoder struct{}
+
+// Encode implements MetadataEncoder
+func (Base64JSONMetadataEncoder) Encode(metadata map[string]interface{}) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return map[string]string{}, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return map[string]string{encodedMetadataKey: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// Decode implements MetadataEncoder
+func (Base64JSONMetadataEncoder) Decode(headers map[string]string) (map[string]interface{}, error) {
+	return decodeJSONMetadata(headers, base64.StdEncoding.DecodeString)
+}
+
+// decodeJSONMetadata unwraps the single metadata header written by the JSON-based encoders
+func decodeJSONMetadata(headers map[string]string, unwrap func(string) ([]byte, error)) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+
+	value, ok := headers[encodedMetadataKey]
+	if !ok {
+		return metadata, nil
+	}
+
+	data, err := unwrap(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// needsHeaderEncoding reports whether a value contains characters unsafe in an HTTP header
+func needsHeaderEncoding(value string) bool {
+	for _, r := range value {
+		if r < 0x20 || r >= 0x7f {
+			return true
+		}
+	}
+	// Values that already look like encoded-words must be encoded so they decode back verbatim
+	return strings.HasPrefix(value, "=?") && strings.HasSuffix(value, "?=")
+}
+
+// asciiJSON escapes non-ASCII characters in a JSON document as \u sequences
+func asciiJSON(data string) string {
+	var b strings.Builder
+	for _, r := range data {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, `\u%04x\u%04x`, r1, r2)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	return b.String()
+}
+
+// decodeMetadata decodes object metadata headers, falling back to raw per-key values for
+// objects written with a different encoding: a JSON-based encoder finds no metadata header on
+// objects written per key before the encoding was switched
+func (c *RustFSClient) decodeMetadata(headers map[string]string) map[string]interface{} {
+	_, encoded := headers[encodedMetadataKey]
+	if encoded || !usesEncodedMetadataKey(c.metadataEncoder) {
+		if metadata, err := c.metadataEncoder.Decode(headers); err == nil {
+			return metadata
+		}
+	}
+	metadata, _ := PerKeyMetadataEncoder{}.Decode(headers)
+	return metadata
+}
+
+// usesEncodedMetadataKey reports whether encoder stores all metadata under encodedMetadataKey
+func usesEncodedMetadataKey(encoder MetadataEncoder) bool {
+	switch encoder.(type) {
+	case JSONMetadataEncoder, Base64JSONMetadataEncoder:
+		return true
+	}
+	return false
+}
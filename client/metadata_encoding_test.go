@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestMetadataEncodersRoundTrip(t *testing.T) {
+	metadata := map[string]interface{}{"owner": "alice", "title": "Café ☕", "note": "=?looks-encoded?="}
+
+	for _, encoding := range []string{MetadataEncodingPerKey, MetadataEncodingJSON, MetadataEncodingBase64JSON} {
+		t.Run(encoding, func(t *testing.T) {
+			encoder, err := NewMetadataEncoder(encoding)
+			if err != nil {
+				t.Fatalf("NewMetadataEncoder: %v", err)
+			}
+			headers, err := encoder.Encode(metadata)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := encoder.Decode(headers)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			for k, want := range metadata {
+				if got := decoded[k]; got != want {
+					t.Errorf("decoded[%q] = %#v, want %#v", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMetadataWrittenUnderPreviousEncoding(t *testing.T) {
+	for _, encoding := range []string{MetadataEncodingJSON, MetadataEncodingBase64JSON} {
+		t.Run(encoding, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				cfg.MetadataEncoding = encoding
+			})
+			fake.put("old.txt", []byte("written per key"), map[string]string{"owner": "alice"})
+			ctx := context.Background()
+
+			info, err := c.GetFileInfo(ctx, "old.txt")
+			if err != nil {
+				t.Fatalf("GetFileInfo: %v", err)
+			}
+			if info.Metadata["owner"] != "alice" {
+				t.Fatalf("metadata = %v, want owner alice", info.Metadata)
+			}
+
+			// A merge must keep the metadata written under the old encoding
+			if _, err := c.UpdateMetadata(ctx, "old.txt", map[string]interface{}{"reviewed": "yes"}, false); err != nil {
+				t.Fatalf("UpdateMetadata: %v", err)
+			}
+			info, err = c.GetFileInfo(ctx, "old.txt")
+			if err != nil {
+				t.Fatalf("GetFileInfo after update: %v", err)
+			}
+			if info.Metadata["owner"] != "alice" || info.Metadata["reviewed"] != "yes" {
+				t.Fatalf("metadata after merge = %v, want owner and reviewed", info.Metadata)
+			}
+		})
+	}
+}
+
+func TestMetadataKeyCollision(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		metadata map[string]interface{}
+		want     map[string]string // nil when the collision is rejected
+	}{
+		{
+			name:     "reject",
+			policy:   config.MetadataKeyCollisionReject,
+			metadata: map[string]interface{}{"Owner": "alice", "owner": "bob"},
+		},
+		{
+			name:     "default rejects",
+			policy:   "",
+			metadata: map[string]interface{}{"OWNER": "alice", "Owner": "bob"},
+		},
+		{
+			name:     "merge prefers lowercase",
+			policy:   config.MetadataKeyCollisionMerge,
+			metadata: map[string]interface{}{"Owner": "alice", "owner": "bob", "OWNER": "carol"},
+			want:     map[string]string{"owner": "bob"},
+		},
+		{
+			name:     "merge without lowercase takes first in byte order",
+			policy:   config.MetadataKeyCollisionMerge,
+			metadata: map[string]interface{}{"Owner": "alice", "OWNER": "carol", "Team": "storage"},
+			want:     map[string]string{"owner": "carol", "team": "storage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				cfg.MetadataEncoding = MetadataEncodingPerKey
+				cfg.MetadataKeyCollision = tt.policy
+			})
+
+			encoder, err := newConfiguredMetadataEncoder(c.config)
+			if err != nil {
+				t.Fatalf("newConfiguredMetadataEncoder: %v", err)
+			}
+			headers, err := encoder.Encode(tt.metadata)
+			if tt.want == nil {
+				if !errors.Is(err, ErrMetadataKeyCollision) {
+					t.Fatalf("Encode error = %v, want ErrMetadataKeyCollision", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			for k, want := range tt.want {
+				if headers[k] != want {
+					t.Errorf("headers[%q] = %q, want %q", k, headers[k], want)
+				}
+			}
+
+			req := uploadRequest("collide.txt", "content")
+			req.Metadata = tt.metadata
+			_, err = c.UploadFile(context.Background(), req)
+			if tt.want == nil {
+				if code := errorCode(err); code != "INVALID_METADATA" {
+					t.Fatalf("UploadFile error = %v, want INVALID_METADATA", err)
+				}
+				if puts := fake.requests("PUT", "collide.txt"); len(puts) != 0 {
+					t.Fatalf("rejected upload sent %v", puts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			object, ok := fake.get("collide.txt")
+			if !ok {
+				t.Fatal("object not stored")
+			}
+			for k, want := range tt.want {
+				if object.metadata[k] != want {
+					t.Errorf("stored metadata[%q] = %q, want %q", k, object.metadata[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONMetadataEncodingKeepsKeyCase(t *testing.T) {
+	metadata := map[string]interface{}{"Owner": "alice", "owner": "bob"}
+	for _, encoding := range []string{MetadataEncodingJSON, MetadataEncodingBase64JSON} {
+		t.Run(encoding, func(t *testing.T) {
+			encoder, err := NewMetadataEncoder(encoding)
+			if err != nil {
+				t.Fatalf("NewMetadataEncoder: %v", err)
+			}
+			headers, err := encoder.Encode(metadata)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := encoder.Decode(headers)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if decoded["Owner"] != "alice" || decoded["owner"] != "bob" {
+				t.Fatalf("decoded = %v, want both spellings kept", decoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// sideObjectConfig stores metadata values over 16 bytes in side objects
+func sideObjectConfig(cfg *config.RustFSConfig) {
+	cfg.MetadataValueLimit = 16
+	cfg.MetadataOversizePolicy = config.MetadataOversizeSideObject
+}
+
+func TestIfNotExistsUploadKeepsSideObjectsOfExistingObject(t *testing.T) {
+	c, fake := newFakeS3Client(t, sideObjectConfig)
+	ctx := context.Background()
+	winner := strings.Repeat("winner ", 10)
+	loser := strings.Repeat("loser ", 10)
+
+	upload := func(notes string) error {
+		_, err := c.UploadFile(ctx, &types.UploadRequest{
+			File:        strings.NewReader("content"),
+			ContentType: "text/plain",
+			BucketPath:  "doc.txt",
+			Metadata:    map[string]interface{}{"notes": notes},
+			IfNotExists: true,
+		})
+		return err
+	}
+
+	if err := upload(winner); err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	if err := upload(loser); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("second upload error = %v, want ErrAlreadyExists", err)
+	}
+
+	side, ok := fake.get("doc.txt.metadata/notes")
+	if !ok {
+		t.Fatalf("side object of the first upload is missing")
+	}
+	if string(side.data) != winner {
+		t.Fatalf("side object = %q, want the first upload's value", side.data)
+	}
+}
+
+func TestSideObjectLifecycle(t *testing.T) {
+	c, fake := newFakeS3Client(t, sideObjectConfig)
+	ctx := context.Background()
+	notes := strings.Repeat("long note ", 10)
+
+	// The first side object PUT fails once and must be retried
+	failed := false
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, ".metadata/notes") && !failed {
+			failed = true
+			writeS3Error(w, http.StatusServiceUnavailable, "SlowDown")
+			return true
+		}
+		return false
+	}
+
+	_, err := c.UploadFile(ctx, &types.UploadRequest{
+		File:        strings.NewReader("content"),
+		ContentType: "text/plain",
+		BucketPath:  "doc.txt",
+		Metadata:    map[string]interface{}{"notes": notes, "owner": "alice"},
+	})
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if !failed {
+		t.Fatalf("side object was never written")
+	}
+
+	stored, _ := fake.get("doc.txt")
+	if ref := stored.metadata["notes"]; ref != MetadataSideObjectRef+"doc.txt.metadata/notes" {
+		t.Fatalf("stored metadata value = %q, want a side object reference", ref)
+	}
+
+	tests := []struct {
+		name string
+		read func() (map[string]interface{}, error)
+	}{
+		{"GetFileInfo", func() (map[string]interface{}, error) {
+			info, err := c.GetFileInfo(ctx, "doc.txt")
+			if err != nil {
+				return nil, err
+			}
+			return info.Metadata, nil
+		}},
+		{"DownloadFile", func() (map[string]interface{}, error) {
+			body, info, err := c.DownloadFile(ctx, "doc.txt")
+			if err != nil {
+				return nil, err
+			}
+			body.Close()
+			return info.Metadata, nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" resolves references", func(t *testing.T) {
+			metadata, err := tt.read()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if metadata["notes"] != notes || metadata["owner"] != "alice" {
+				t.Fatalf("metadata = %v, want the side object's value", metadata)
+			}
+		})
+	}
+
+	if err := c.MoveFile(ctx, "doc.txt", "moved.txt"); err != nil {
+		t.Fatalf("MoveFile: %v", err)
+	}
+	if _, ok := fake.get("doc.txt.metadata/notes"); ok {
+		t.Errorf("side object of the moved source was left behind")
+	}
+	info, err := c.GetFileInfo(ctx, "moved.txt")
+	if err != nil {
+		t.Fatalf("GetFileInfo after move: %v", err)
+	}
+	if info.Metadata["notes"] != notes {
+		t.Errorf("metadata after move = %v, want the side object's value", info.Metadata)
+	}
+
+	// Shrinking the value below the limit drops its side object
+	if _, err := c.UpdateMetadata(ctx, "moved.txt", map[string]interface{}{"notes": "short"}, false); err != nil {
+		t.Fatalf("UpdateMetadata: %v", err)
+	}
+	if _, ok := fake.get("moved.txt.metadata/notes"); ok {
+		t.Errorf("stale side object kept after the value shrank")
+	}
+
+	if _, err := c.UpdateMetadata(ctx, "moved.txt", map[string]interface{}{"notes": notes}, false); err != nil {
+		t.Fatalf("UpdateMetadata: %v", err)
+	}
+	if err := c.DeleteFile(ctx, "moved.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, ok := fake.get("moved.txt.metadata/notes"); ok {
+		t.Errorf("side object kept after its object was deleted")
+	}
+}
+
+// metadataStorage is the surface of both clients used by the metadata update tests
+type metadataStorage interface {
+	FileStorage
+	MetadataUpdater
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		updates map[string]interface{}
+		replace bool
+		want    map[string]string
+	}{
+		{
+			name:    "merge keeps untouched keys",
+			updates: map[string]interface{}{"reviewer": "bob"},
+			want:    map[string]string{"owner": "alice", "stage": "draft", "reviewer": "bob"},
+		},
+		{
+			name:    "merge overrides keys in any case",
+			updates: map[string]interface{}{"Stage": "final"},
+			want:    map[string]string{"owner": "alice", "stage": "final"},
+		},
+		{
+			name:    "replace drops existing keys",
+			updates: map[string]interface{}{"reviewer": "bob"},
+			replace: true,
+			want:    map[string]string{"reviewer": "bob"},
+		},
+	}
+
+	storages := []struct {
+		name string
+		new  func(t *testing.T) metadataStorage
+	}{
+		{"RustFSClient", func(t *testing.T) metadataStorage {
+			c, _ := newFakeS3Client(t)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T) metadataStorage { return NewMockRustFSClient() }},
+	}
+
+	for _, storage := range storages {
+		for _, tt := range tests {
+			t.Run(storage.name+"/"+tt.name, func(t *testing.T) {
+				s := storage.new(t)
+				ctx := context.Background()
+				req := uploadRequest("doc.txt", "content")
+				req.Metadata = map[string]interface{}{"owner": "alice", "stage": "draft"}
+				if _, err := s.UploadFile(ctx, req); err != nil {
+					t.Fatalf("UploadFile: %v", err)
+				}
+
+				info, err := s.UpdateMetadata(ctx, "doc.txt", tt.updates, tt.replace)
+				if err != nil {
+					t.Fatalf("UpdateMetadata: %v", err)
+				}
+				// The mock keeps the caller's key case while S3 lower-cases it
+				got := make(map[string]string, len(info.Metadata))
+				for k, v := range info.Metadata {
+					got[strings.ToLower(k)] = fmt.Sprint(v)
+				}
+				if !maps.Equal(got, tt.want) {
+					t.Errorf("metadata = %v, want %v", got, tt.want)
+				}
+			})
+		}
+
+		t.Run(storage.name+"/missing file", func(t *testing.T) {
+			s := storage.new(t)
+			_, err := s.UpdateMetadata(context.Background(), "missing.txt", map[string]interface{}{"a": "b"}, false)
+			if !errors.Is(err, ErrFileNotFound) {
+				t.Errorf("UpdateMetadata = %v, want ErrFileNotFound", err)
+			}
+		})
+	}
+}
+
+func TestUpdateMetadataPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities []string
+		status       int
+		wantCode     string
+		wantOwner    string
+		wantEndpoint bool
+		wantCopy     bool
+	}{
+		{"endpoint updates metadata", []string{CapabilityMetadataUpdate}, http.StatusNoContent, "", "bob", true, false},
+		{"server without the capability copies onto itself", []string{CapabilityMultipart}, http.StatusNoContent, "", "bob", false, true},
+		{"endpoint failure is not masked by a copy", []string{CapabilityMetadataUpdate}, http.StatusNotFound, "UPDATE_METADATA_FAILED", "alice", true, false},
+	}
+
+	encryption := map[string]string{
+		MetadataEncryptionAlgorithm: "AES-256-GCM",
+		MetadataEncryptionNonce:     "00112233445566778899aabb",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			stored := map[string]string{"owner": "alice"}
+			maps.Copy(stored, encryption)
+			fake.put("doc.txt", []byte("content"), stored)
+
+			endpointCalls := 0
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				switch r.URL.Path {
+				case apiPrefix + "capabilities":
+					json.NewEncoder(w).Encode(capabilitiesResponse{Capabilities: tt.capabilities})
+					return true
+				case apiPrefix + "buckets/" + fake.bucket + "/metadata":
+					endpointCalls++
+					if tt.status == http.StatusNoContent {
+						var body metadataUpdateRequest
+						json.NewDecoder(r.Body).Decode(&body)
+						fake.put(body.Path, []byte("content"), body.Metadata)
+					}
+					w.WriteHeader(tt.status)
+					return true
+				}
+				return false
+			}
+
+			// Replacing the metadata still keeps what the content needs to be decrypted
+			_, err := c.UpdateMetadata(context.Background(), "/doc.txt", map[string]interface{}{"owner": "bob"}, true)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("UpdateMetadata error = %v, want code %q", err, tt.wantCode)
+			}
+			if (endpointCalls > 0) != tt.wantEndpoint {
+				t.Errorf("metadata endpoint called %d times, want called = %v", endpointCalls, tt.wantEndpoint)
+			}
+			if copied := len(fake.requests(http.MethodPut, "doc.txt")) > 0; copied != tt.wantCopy {
+				t.Errorf("copied onto itself = %v, want %v", copied, tt.wantCopy)
+			}
+
+			info, err := c.GetFileInfo(context.Background(), "doc.txt")
+			if err != nil {
+				t.Fatalf("GetFileInfo: %v", err)
+			}
+			if info.Metadata["owner"] != tt.wantOwner {
+				t.Errorf("owner = %v, want %q", info.Metadata["owner"], tt.wantOwner)
+			}
+			for k, v := range encryption {
+				if info.Metadata[k] != v {
+					t.Errorf("encryption metadata %s = %v, want %s", k, info.Metadata[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateMetadata(t *testing.T) {
+	tests := []struct {
+		name           string
+		metadata       map[string]interface{}
+		currentVersion string
+		wantMigrated   bool
+	}{
+		{"no configured version", map[string]interface{}{"owner": "alice"}, "", false},
+		{"unversioned object", map[string]interface{}{"owner": "alice"}, "2", true},
+		{"older version", map[string]interface{}{"owner": "alice", MetadataSchemaVersionKey: "1"}, "2", true},
+		{"current version", map[string]interface{}{"owner": "alice", MetadataSchemaVersionKey: "2"}, "2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMockRustFSClient()
+			ctx := context.Background()
+			if _, err := m.UploadFile(ctx, &types.UploadRequest{
+				File:       strings.NewReader("content"),
+				BucketPath: "doc.txt",
+				Metadata:   tt.metadata,
+			}); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			migrated := false
+			info, err := MigrateMetadata(ctx, m, "doc.txt", tt.currentVersion, func(old map[string]interface{}) (map[string]interface{}, string) {
+				migrated = true
+				return map[string]interface{}{"owners": old["owner"]}, tt.currentVersion
+			})
+			if err != nil {
+				t.Fatalf("MigrateMetadata: %v", err)
+			}
+			if migrated != tt.wantMigrated {
+				t.Fatalf("migrated = %v, want %v", migrated, tt.wantMigrated)
+			}
+			if tt.wantMigrated && (info.Metadata["owners"] != "alice" || info.Metadata[MetadataSchemaVersionKey] != tt.currentVersion) {
+				t.Errorf("metadata = %v, want the migrated metadata at version %q", info.Metadata, tt.currentVersion)
+			}
+		})
+	}
+}
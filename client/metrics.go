@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// Operation names reported to a MetricsRecorder
+const (
+	OperationUpload   = "upload"
+	OperationDownload = "download"
+)
+
+// MetricsRecorder receives incremental byte-transfer counts as data flows, so counters
+// such as bytes-transferred advance in near real time rather than only at completion
+type MetricsRecorder interface {
+	AddBytes(operation string, n int64)
+}
+
+// OperationMetricsRecorder is a MetricsRecorder that is also told when uploads, downloads and
+// deletes start and finish, for in-flight gauges, latency histograms and error counters. A
+// download finishes once its stream is open; reading the body is reported through AddBytes.
+type OperationMetricsRecorder interface {
+	MetricsRecorder
+	OperationStarted(operation string)
+	OperationFinished(operation string, duration time.Duration, err error)
+}
+
+// trackOperation reports the start of an operation to the recorder, if it tracks operations,
+// and returns the function that records its outcome in the activity counters and the recorder
+func trackOperation(activity *activityCounters, recorder MetricsRecorder, operation string) func(err error) {
+	tracker, _ := recorder.(OperationMetricsRecorder)
+	if tracker != nil {
+		tracker.OperationStarted(operation)
+	}
+	start := time.Now()
+
+	return func(err error) {
+		activity.recordOperation(operation, err)
+		if tracker != nil {
+			tracker.OperationFinished(operation, time.Since(start), err)
+		}
+	}
+}
+
+// transferProgress returns a progress callback that feeds the metrics recorders and throttles
+// the transfer through the bandwidth limiter; the limiter and any recorder may be nil
+func transferProgress(ctx context.Context, limiter *utils.BandwidthLimiter, operation string, recorders ...MetricsRecorder) utils.ProgressFunc {
+	return func(n int64) {
+		for _, recorder := range recorders {
+			if recorder != nil {
+				recorder.AddBytes(operation, n)
+			}
+		}
+		if limiter != nil {
+			// A cancelled context surfaces through the request itself
+			_ = limiter.WaitN(ctx, n)
+		}
+	}
+}
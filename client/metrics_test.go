@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+)
+
+// transferStorage is the surface of both clients used by the byte metrics test
+type transferStorage interface {
+	FileStorage
+	Downloader
+}
+
+// byteRecorder captures every incremental byte count by operation
+type byteRecorder struct {
+	mu     sync.Mutex
+	counts map[string][]int64
+}
+
+func (r *byteRecorder) AddBytes(operation string, n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.counts == nil {
+		r.counts = make(map[string][]int64)
+	}
+	r.counts[operation] = append(r.counts[operation], n)
+}
+
+// total returns the number of reports for operation and their sum
+func (r *byteRecorder) total(operation string) (int, int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sum int64
+	for _, n := range r.counts[operation] {
+		sum += n
+	}
+	return len(r.counts[operation]), sum
+}
+
+func TestIncrementalByteMetrics(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024) // 1 MiB
+
+	storages := []struct {
+		name string
+		new  func(t *testing.T, recorder MetricsRecorder) transferStorage
+	}{
+		{"RustFSClient", func(t *testing.T, recorder MetricsRecorder) transferStorage {
+			c, _ := newFakeS3Client(t)
+			c.SetMetricsRecorder(recorder)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T, recorder MetricsRecorder) transferStorage {
+			m := NewMockRustFSClient()
+			m.SetMetricsRecorder(recorder)
+			return m
+		}},
+	}
+
+	for _, storage := range storages {
+		t.Run(storage.name, func(t *testing.T) {
+			recorder := &byteRecorder{}
+			s := storage.new(t, recorder)
+			ctx := context.Background()
+
+			req := uploadRequest("big.bin", "")
+			req.File, req.FileSize = bytes.NewReader(content), int64(len(content))
+			if _, err := s.UploadFile(ctx, req); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			reports, sum := recorder.total(OperationUpload)
+			if sum != int64(len(content)) || reports < 2 {
+				t.Errorf("upload reported %d bytes in %d increments, want %d bytes in several", sum, reports, len(content))
+			}
+
+			body, _, err := s.DownloadFile(ctx, "big.bin")
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			// Bytes are reported as the body is read, not when the stream opens
+			if _, early := recorder.total(OperationDownload); early == int64(len(content)) {
+				t.Errorf("download reported every byte before the body was read")
+			}
+			if _, err := io.Copy(io.Discard, body); err != nil {
+				t.Fatalf("reading body: %v", err)
+			}
+			body.Close()
+			reports, sum = recorder.total(OperationDownload)
+			if sum != int64(len(content)) || reports < 2 {
+				t.Errorf("download reported %d bytes in %d increments, want %d bytes in several", sum, reports, len(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// MirrorPolicy controls how a MirroringFileStorage reacts to secondary write failures
+type MirrorPolicy string
+
+const (
+	// MirrorPolicyIgnore records secondary failures without reporting them
+	MirrorPolicyIgnore MirrorPolicy = "ignore"
+	// MirrorPolicyLog records and logs secondary failures
+	MirrorPolicyLog MirrorPolicy = "log"
+	// MirrorPolicyFail returns an error when any secondary write fails
+	MirrorPolicyFail MirrorPolicy = "fail"
+)
+
+// maxMirrorFailures bounds the failure history kept by a MirroringFileStorage
+const maxMirrorFailures = 100
+
+// MirrorFailure records a write that failed on one backend
+type MirrorFailure struct {
+	Backend   string    `json:"backend"`
+	Operation string    `json:"operation"`
+	Path      string    `json:"path"`
+	Error     string    `json:"error"`
+	Time      time.Time `json:"time"`
+}
+
+// MirroringFileStorage writes every upload, delete and copy to a primary and one or more
+// secondary backends. Reads are served by the primary only.
+type MirroringFileStorage struct {
+	primary     FileStorage
+	secondaries []FileStorage
+	policy      MirrorPolicy
+
+	mu       sync.Mutex
+	failures []MirrorFailure
+	counts   map[string]int
+}
+
+// NewMirroringFileStorage creates a mirroring decorator over primary and secondaries
+func NewMirroringFileStorage(primary FileStorage, policy MirrorPolicy, secondaries ...FileStorage) *MirroringFileStorage {
+	return &MirroringFileStorage{
+		primary:     primary,
+		secondaries: secondaries,
+		policy:      policy,
+		counts:      make(map[string]int),
+	}
+}
+
+// UploadFile uploads to the primary, then sends the same content to each secondary. A seekable
+// source is rewound for each secondary and any other source is read back from the primary, so
+// memory use doesn't grow with the file. Only when the primary can't be read back is the content
+// buffered.
+func (s *MirroringFileStorage) UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	defer closeUploadSource(req)
+
+	var start int64
+	seeker, rewindable := req.File.(io.Seeker)
+	if rewindable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			rewindable = false
+		}
+	}
+	downloader, rereadable := s.primary.(Downloader)
+
+	var content []byte
+	if req.File != nil && !rewindable && !rereadable {
+		data, err := io.ReadAll(req.File)
+		if err != nil {
+			return nil, apperror.NewAppError(500, "FILE_READ_ERROR", err)
+		}
+		content = data
+	}
+
+	// The source is closed here once every backend has read it
+	withBody := func(body io.Reader) *types.UploadRequest {
+		clone := *req
+		clone.File = body
+		clone.CloseSource = false
+		return &clone
+	}
+
+	primaryReq := withBody(req.File)
+	if content != nil {
+		primaryReq.File = bytes.NewReader(content)
+	}
+	result, err := s.primary.UploadFile(ctx, primaryReq)
+	if err != nil {
+		s.recordFailure("primary", "upload", req.BucketPath, err)
+		return nil, err
+	}
+
+	if err := s.mirror("upload", req.BucketPath, func(backend FileStorage) error {
+		switch {
+		case req.File == nil:
+			_, err := backend.UploadFile(ctx, withBody(nil))
+			return err
+		case content != nil:
+			_, err := backend.UploadFile(ctx, withBody(bytes.NewReader(content)))
+			return err
+		case rewindable:
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return apperror.NewAppError(500, "FILE_READ_ERROR", err)
+			}
+			_, err := backend.UploadFile(ctx, withBody(req.File))
+			return err
+		default:
+			body, info, err := downloader.DownloadFile(ctx, result.Path)
+			if err != nil {
+				return err
+			}
+			defer body.Close()
+
+			mirrored := withBody(body)
+			mirrored.FileSize = info.Size
+			_, err = backend.UploadFile(ctx, mirrored)
+			return err
+		}
+	}); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// DeleteFile deletes from the primary and every secondary
+func (s *MirroringFileStorage) DeleteFile(ctx context.Context, path string) error {
+	if err := s.primary.DeleteFile(ctx, path); err != nil {
+		s.recordFailure("primary", "delete", path, err)
+		return err
+	}
+
+	return s.mirror("delete", path, func(backend FileStorage) error {
+		return backend.DeleteFile(ctx, path)
+	})
+}
+
+// CopyFile copies on the primary and every secondary; all backends must support copying
+func (s *MirroringFileStorage) CopyFile(ctx context.Context, sourcePath, destPath string) error {
+	copier, ok := s.primary.(Copier)
+	if !ok {
+		return fmt.Errorf("primary storage does not support copy")
+	}
+
+	if err := copier.CopyFile(ctx, sourcePath, destPath); err != nil {
+		s.recordFailure("primary", "copy", destPath, err)
+		return err
+	}
+
+	return s.mirror("copy", destPath, func(backend FileStorage) error {
+		copier, ok := backend.(Copier)
+		if !ok {
+			return fmt.Errorf("storage does not support copy")
+		}
+		return copier.CopyFile(ctx, sourcePath, destPath)
+	})
+}
+
+// GetFileURL returns the primary's URL for path
+func (s *MirroringFileStorage) GetFileURL(path string) string {
+	return s.primary.GetFileURL(path)
+}
+
+// GetFileInfo reads file information from the primary
+func (s *MirroringFileStorage) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	return s.primary.GetFileInfo(ctx, path)
+}
+
+// Failures returns the most recent backend write failures, oldest first
+func (s *MirroringFileStorage) Failures() []MirrorFailure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := make([]MirrorFailure, len(s.failures))
+	copy(failures, s.failures)
+	return failures
+}
+
+// FailureCounts returns the number of failed writes per backend
+func (s *MirroringFileStorage) FailureCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(s.counts))
+	for backend, n := range s.counts {
+		counts[backend] = n
+	}
+	return counts
+}
+
+// mirror applies write to every secondary, handling failures according to the policy
+func (s *MirroringFileStorage) mirror(operation, path string, write func(backend FileStorage) error) error {
+	var errs []error
+	for i, backend := range s.secondaries {
+		name := fmt.Sprintf("secondary-%d", i+1)
+		if err := write(backend); err != nil {
+			s.recordFailure(name, operation, path, err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	switch s.policy {
+	case MirrorPolicyFail:
+		return apperror.NewAppError(502, "MIRROR_WRITE_FAILED", errors.Join(errs...))
+	case MirrorPolicyLog:
+		log.Printf("[RUSTFS] mirrored %s of %s failed: %v", operation, path, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// recordFailure stores a failed write for later reporting
+func (s *MirroringFileStorage) recordFailure(backend, operation, path string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, MirrorFailure{
+		Backend:   backend,
+		Operation: operation,
+		Path:      path,
+		Error:     err.Error(),
+		Time:      time.Now(),
+	})
+	if len(s.failures) > maxMirrorFailures {
+		s.failures = s.failures[len(s.failures)-maxMirrorFailures:]
+	}
+	s.counts[backend]++
+}
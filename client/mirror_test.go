@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// onlyFileStorage hides every method of a storage beyond FileStorage
+type onlyFileStorage struct {
+	FileStorage
+}
+
+// recordingStorage records the source of the last upload it received
+type recordingStorage struct {
+	*MockRustFSClient
+	source io.Reader
+}
+
+func (r *recordingStorage) UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	r.source = req.File
+	return r.MockRustFSClient.UploadFile(ctx, req)
+}
+
+// onceReader is a source that can't be rewound and fails if read again after its end
+type onceReader struct {
+	r    io.Reader
+	done bool
+}
+
+func (o *onceReader) Read(p []byte) (int, error) {
+	if o.done {
+		return 0, errors.New("source read after its end")
+	}
+	n, err := o.r.Read(p)
+	if err == io.EOF {
+		o.done = true
+	}
+	return n, err
+}
+
+func TestMirroringUpload(t *testing.T) {
+	const content = "mirrored content"
+
+	tests := []struct {
+		name      string
+		source    func() io.Reader
+		writeOnly bool
+		streamed  bool
+	}{
+		{"seekable source", func() io.Reader { return strings.NewReader(content) }, false, true},
+		{"stream read back from the primary", func() io.Reader { return &onceReader{r: strings.NewReader(content)} }, false, true},
+		{"stream buffered for a write-only primary", func() io.Reader { return &onceReader{r: strings.NewReader(content)} }, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary := &recordingStorage{MockRustFSClient: NewMockRustFSClient()}, NewMockRustFSClient()
+			var backend FileStorage = primary
+			if tt.writeOnly {
+				backend = onlyFileStorage{primary}
+			}
+			mirror := NewMirroringFileStorage(backend, MirrorPolicyFail, secondary)
+
+			source := tt.source()
+			_, err := mirror.UploadFile(context.Background(), &types.UploadRequest{
+				File:        source,
+				FileSize:    int64(len(content)),
+				ContentType: "text/plain",
+				BucketPath:  "doc.txt",
+			})
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			if streamed := primary.source == source; streamed != tt.streamed {
+				t.Errorf("primary read the caller's source directly = %v, want %v", streamed, tt.streamed)
+			}
+
+			for name, backend := range map[string]*MockRustFSClient{"primary": primary.MockRustFSClient, "secondary": secondary} {
+				body, _, err := backend.DownloadFile(context.Background(), "doc.txt")
+				if err != nil {
+					t.Fatalf("%s DownloadFile: %v", name, err)
+				}
+				data, _ := io.ReadAll(body)
+				if string(data) != content {
+					t.Errorf("%s holds %q, want %q", name, data, content)
+				}
+			}
+		})
+	}
+}
+
+func TestMirroringFailingSecondary(t *testing.T) {
+	tests := []struct {
+		policy  MirrorPolicy
+		wantErr bool
+	}{
+		{MirrorPolicyIgnore, false},
+		{MirrorPolicyLog, false},
+		{MirrorPolicyFail, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			primary, secondary := NewMockRustFSClient(), NewMockRustFSClient()
+			secondary.SetFailureMode(true, errors.New("secondary down"))
+			mirror := NewMirroringFileStorage(primary, tt.policy, secondary)
+
+			_, err := mirror.UploadFile(context.Background(), uploadRequest("doc.txt", "content"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadFile error = %v, want error %v", err, tt.wantErr)
+			}
+			if exists, _ := primary.FileExists(context.Background(), "doc.txt"); !exists {
+				t.Errorf("primary write lost")
+			}
+			if counts := mirror.FailureCounts(); counts["secondary-1"] != 1 {
+				t.Errorf("failure counts = %v, want one secondary-1 failure", counts)
+			}
+		})
+	}
+}
@@ -1,32 +1,67 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"maps"
 	"mime/multipart"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/config"
 	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
 )
 
 // MockRustFSClient is a mock implementation of FileStorage interface for testing
 type MockRustFSClient struct {
-	files      map[string]*types.FileInfo
-	uploads    []*types.UploadResponse
-	deletes    []string
-	mu         sync.RWMutex
-	shouldFail bool
-	failError  error
+	files         map[string]*types.FileInfo
+	contents      map[string][]byte
+	uploads       []*types.UploadResponse
+	deletes       []string
+	multipart     map[string]*types.IncompleteUpload
+	aborted       []string
+	tokens        map[string]*mockDownloadToken
+	tags          map[string]map[string]string
+	directoryMode bool
+	noBatchCopy   bool
+	metrics       MetricsRecorder
+	activity      *activityCounters
+	downloadHooks []DownloadHook
+	canceller     *canceller
+	latency       time.Duration
+	mu            sync.RWMutex
+	shouldFail    bool
+	failError     error
+
+	syntheticDirectories bool
+	keyValidator         utils.KeyValidator
+	defaultMetadata      map[string]string
+	webhooks             map[string][]string
+	webhookEvents        []types.WebhookEvent
+	encryptionKey        []byte
 }
 
 // NewMockRustFSClient creates a new mock RustFS client
 func NewMockRustFSClient() *MockRustFSClient {
 	return &MockRustFSClient{
-		files:   make(map[string]*types.FileInfo),
-		uploads: make([]*types.UploadResponse, 0),
-		deletes: make([]string, 0),
+		files:     make(map[string]*types.FileInfo),
+		contents:  make(map[string][]byte),
+		uploads:   make([]*types.UploadResponse, 0),
+		deletes:   make([]string, 0),
+		multipart: make(map[string]*types.IncompleteUpload),
+		aborted:   make([]string, 0),
+		tokens:    make(map[string]*mockDownloadToken),
+		tags:      make(map[string]map[string]string),
+		activity:  newActivityCounters(),
+		canceller: newCanceller(),
 	}
 }
 
@@ -38,8 +73,45 @@ func (m *MockRustFSClient) SetFailureMode(shouldFail bool, err error) {
 	m.failError = err
 }
 
+// SetDefaultMetadata sets metadata merged into every upload, like config.DefaultMetadata
+func (m *MockRustFSClient) SetDefaultMetadata(metadata map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultMetadata = metadata
+}
+
+// SetEncryptionKey makes the mock encrypt uploads with key like a client with encryption enabled,
+// and decrypt them on download. A nil key disables encryption.
+func (m *MockRustFSClient) SetEncryptionKey(key []byte) error {
+	if key != nil && len(key) != config.EncryptionKeySize {
+		return apperror.NewAppError(400, "INVALID_ENCRYPTION_KEY",
+			fmt.Errorf("encryption key must be %d bytes", config.EncryptionKeySize))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.encryptionKey = key
+	return nil
+}
+
 // UploadFile uploads a file to mock storage
 func (m *MockRustFSClient) UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	defer closeUploadSource(req)
+
+	ctx, done, err := m.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	finish := trackOperation(m.activity, m.metricsRecorder(), OperationUpload)
+	response, err := m.uploadFile(ctx, req)
+	err = cancellationError(ctx, err)
+	finish(err)
+	return response, err
+}
+
+func (m *MockRustFSClient) uploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -48,29 +120,90 @@ func (m *MockRustFSClient) UploadFile(ctx context.Context, req *types.UploadRequ
 		return nil, m.failError
 	}
 
+	if err := validateObjectKey(m.keyValidator, req.BucketPath); err != nil {
+		return nil, err
+	}
+	if err := validateUploadHeaders(req); err != nil {
+		return nil, err
+	}
+	if _, exists := m.files[req.BucketPath]; exists && req.IfNotExists {
+		return nil, newSentinelError(412, "ALREADY_EXISTS", ErrAlreadyExists, fmt.Errorf("%s", req.BucketPath))
+	}
+
 	// Simulate upload delay
-	time.Sleep(10 * time.Millisecond)
+	if err := m.simulateDelay(ctx, 10*time.Millisecond); err != nil {
+		return nil, err
+	}
+
+	// Read content so it can be served back and checksummed
+	var content []byte
+	if req.File != nil {
+		data, err := io.ReadAll(utils.NewCountingReader(req.File, transferProgress(ctx, nil, OperationUpload, m.metrics, m.activity)))
+		if err != nil {
+			return nil, err
+		}
+		content = data
+	}
+
+	size := req.FileSize
+	if size == 0 {
+		size = int64(len(content))
+	}
+
+	requestMetadata := req.Metadata
+	if m.encryptionKey != nil {
+		sealed, nonce, err := encryptObject(m.encryptionKey, bytes.NewReader(content))
+		if err != nil {
+			return nil, apperror.NewAppError(500, "ENCRYPTION_FAILED", err)
+		}
+		content, size = sealed, int64(len(sealed))
+		requestMetadata = withEncryptionMetadata(req.Metadata, "", nonce)
+	}
+
+	checksum := sha256.Sum256(content)
+	metadata, defaultKeys := withDefaultMetadata(requestMetadata, m.defaultMetadata)
 
 	// Create upload response
 	response := &types.UploadResponse{
-		Path:     req.BucketPath,
-		ETag:     fmt.Sprintf("etag-%d", time.Now().UnixNano()),
-		Size:     req.FileSize,
-		URL:      fmt.Sprintf("http://mock-storage.com/%s", req.BucketPath),
-		Metadata: req.Metadata,
+		Path:              req.BucketPath,
+		ETag:              fmt.Sprintf("etag-%d", time.Now().UnixNano()),
+		Size:              size,
+		URL:               m.GetFileURL(req.BucketPath),
+		ContentType:       req.ContentType,
+		LastModified:      time.Now(),
+		Metadata:          metadata,
+		Checksum:          hex.EncodeToString(checksum[:]),
+		ChecksumAlgorithm: "sha256",
+
+		DefaultMetadataKeys: defaultKeys,
+		ContentEncoding:     req.ContentEncoding,
+	}
+	if m.encryptionKey != nil {
+		response.Size = decryptedSize(size)
 	}
 
+	storageClass := req.StorageClass
+	if storageClass == "" {
+		storageClass = types.StorageClassStandard
+	}
+	response.StorageClass = storageClass
+
 	// Store file info
 	fileInfo := &types.FileInfo{
-		Path:         req.BucketPath,
-		Size:         req.FileSize,
-		ContentType:  req.ContentType,
-		ETag:         response.ETag,
-		LastModified: time.Now(),
-		Metadata:     req.Metadata,
+		Path:               req.BucketPath,
+		Size:               size,
+		ContentType:        req.ContentType,
+		ETag:               response.ETag,
+		LastModified:       time.Now(),
+		Metadata:           metadata,
+		StorageClass:       storageClass,
+		CacheControl:       req.CacheControl,
+		ContentDisposition: req.ContentDisposition,
+		ContentEncoding:    req.ContentEncoding,
 	}
 
 	m.files[req.BucketPath] = fileInfo
+	m.contents[req.BucketPath] = content
 	m.uploads = append(m.uploads, response)
 
 	return response, nil
@@ -78,33 +211,79 @@ func (m *MockRustFSClient) UploadFile(ctx context.Context, req *types.UploadRequ
 
 // DeleteFile deletes a file from mock storage
 func (m *MockRustFSClient) DeleteFile(ctx context.Context, path string) error {
+	ctx, done, err := m.canceller.derive(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	finish := trackOperation(m.activity, m.metricsRecorder(), OperationDelete)
+	_, err = m.deleteFile(ctx, path)
+	err = cancellationError(ctx, err)
+	finish(err)
+	return err
+}
+
+// deleteFile removes path from mock storage and reports whether it existed
+func (m *MockRustFSClient) deleteFile(ctx context.Context, path string) (bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.shouldFail {
 		m.shouldFail = false // Reset failure mode
-		return m.failError
+		return false, m.failError
 	}
 
 	// Simulate delete delay
-	time.Sleep(5 * time.Millisecond)
+	if err := m.simulateDelay(ctx, 5*time.Millisecond); err != nil {
+		return false, err
+	}
 
 	// Remove file if exists
-	if _, exists := m.files[path]; exists {
+	_, exists := m.files[path]
+	if exists {
 		delete(m.files, path)
+		delete(m.contents, path)
+		delete(m.tags, path)
 	}
 
 	m.deletes = append(m.deletes, path)
-	return nil
+	return exists, nil
+}
+
+// DeleteFileResult deletes a file from mock storage and reports whether it existed
+func (m *MockRustFSClient) DeleteFileResult(ctx context.Context, path string) (bool, error) {
+	ctx, done, err := m.canceller.derive(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	finish := trackOperation(m.activity, m.metricsRecorder(), OperationDelete)
+	deleted, err := m.deleteFile(ctx, path)
+	err = cancellationError(ctx, err)
+	finish(err)
+	return deleted, err
 }
 
 // GetFileURL returns mock URL for a file
 func (m *MockRustFSClient) GetFileURL(path string) string {
-	return fmt.Sprintf("http://mock-storage.com/%s", path)
+	return "http://mock-storage.com/" + utils.EscapePath(path)
 }
 
 // GetFileInfo retrieves file information from mock storage
 func (m *MockRustFSClient) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	ctx, done, err := m.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	info, err := m.getFileInfo(ctx, path)
+	return info, cancellationError(ctx, err)
+}
+
+func (m *MockRustFSClient) getFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -114,16 +293,196 @@ func (m *MockRustFSClient) GetFileInfo(ctx context.Context, path string) (*types
 	}
 
 	// Simulate get info delay
-	time.Sleep(2 * time.Millisecond)
+	if err := m.simulateDelay(ctx, 2*time.Millisecond); err != nil {
+		return nil, err
+	}
 
 	fileInfo, exists := m.files[path]
 	if !exists {
-		return nil, fmt.Errorf("file not found: %s", path)
+		return m.statDirectory(path, fmt.Errorf("%w: %s", ErrFileNotFound, path))
+	}
+	if isEncrypted(fileInfo.Metadata) {
+		info := *fileInfo
+		info.Size = decryptedSize(info.Size)
+		return &info, nil
 	}
 
 	return fileInfo, nil
 }
 
+// FileExists reports whether a file is stored at path in mock storage
+func (m *MockRustFSClient) FileExists(ctx context.Context, path string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return false, m.failError
+	}
+
+	_, exists := m.files[path]
+	return exists, nil
+}
+
+// DownloadFile returns the stored content of a file in mock storage
+func (m *MockRustFSClient) DownloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	// The operation context lives until the caller closes the body
+	ctx, done, err := m.canceller.derive(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finish := trackOperation(m.activity, m.metricsRecorder(), OperationDownload)
+	body, info, err := m.downloadFile(ctx, path)
+	err = cancellationError(ctx, err)
+	finish(err)
+	if err != nil {
+		done()
+		return nil, nil, err
+	}
+	return &cancelOnClose{ReadCloser: body, cancel: done}, info, nil
+}
+
+func (m *MockRustFSClient) downloadFile(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, nil, m.failError
+	}
+
+	return m.downloadFileLocked(ctx, path)
+}
+
+// downloadFileLocked opens a file's content, decrypted and wrapped in the download hooks; the
+// caller must hold m.mu
+func (m *MockRustFSClient) downloadFileLocked(ctx context.Context, path string) (io.ReadCloser, *types.FileInfo, error) {
+	if err := m.simulateDelay(ctx, 0); err != nil {
+		return nil, nil, err
+	}
+
+	fileInfo, exists := m.files[path]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+
+	if types.IsArchivedStorageClass(fileInfo.StorageClass) && !fileInfo.Restored {
+		return nil, nil, newSentinelError(409, "OBJECT_ARCHIVED", ErrObjectArchived, nil)
+	}
+
+	var body io.ReadCloser = utils.NewCountingReadCloser(io.NopCloser(bytes.NewReader(m.contents[path])),
+		transferProgress(ctx, nil, OperationDownload, m.metrics, m.activity))
+	if m.encryptionKey != nil && isEncrypted(fileInfo.Metadata) {
+		decrypted, err := decryptBody(m.encryptionKey, body, fileInfo.Metadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		info := *fileInfo
+		info.Size = decryptedSize(info.Size)
+		body, fileInfo = decrypted, &info
+	}
+	return withDownloadHooks(ctx, body, fileInfo, m.downloadHooks), fileInfo, nil
+}
+
+// RestoreObject marks an archived file in mock storage as restored
+func (m *MockRustFSClient) RestoreObject(ctx context.Context, path string, days int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	fileInfo, exists := m.files[path]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+
+	fileInfo.Restored = true
+	return nil
+}
+
+// UpdateMetadata merges or replaces the metadata of a file in mock storage
+func (m *MockRustFSClient) UpdateMetadata(ctx context.Context, path string, metadata map[string]interface{}, replace bool) (*types.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	fileInfo, exists := m.files[path]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+
+	updated := *fileInfo
+	updated.Metadata = mergeMetadata(fileInfo.Metadata, metadata, replace)
+	if isEncrypted(fileInfo.Metadata) {
+		updated.Metadata = withSourceEncryptionMetadata(updated.Metadata, fileInfo.Metadata)
+	}
+	updated.ETag = fmt.Sprintf("etag-%d", time.Now().UnixNano())
+	updated.LastModified = time.Now()
+	m.files[path] = &updated
+
+	return &updated, nil
+}
+
+// FixContentType sniffs a mock file's content type from its stored content and corrects it if needed
+func (m *MockRustFSClient) FixContentType(ctx context.Context, path string) (*types.FileInfo, error) {
+	info, _, err := m.fixContentType(ctx, path)
+	return info, err
+}
+
+// FixContentTypePrefix repairs the content type of every mock file under prefix
+func (m *MockRustFSClient) FixContentTypePrefix(ctx context.Context, prefix string) ([]*types.FileInfo, error) {
+	return fixContentTypePrefix(ctx, m, prefix)
+}
+
+func (m *MockRustFSClient) fixContentType(ctx context.Context, path string) (*types.FileInfo, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, false, m.failError
+	}
+
+	fileInfo, exists := m.files[path]
+	if !exists {
+		return nil, false, fmt.Errorf("file not found: %s", path)
+	}
+	if len(m.contents[path]) == 0 {
+		return fileInfo, false, nil
+	}
+
+	head := m.contents[path]
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+
+	contentType, ok := correctedContentType(fileInfo.ContentType, head)
+	if !ok {
+		return fileInfo, false, nil
+	}
+
+	updated := *fileInfo
+	updated.ContentType = contentType
+	updated.ETag = fmt.Sprintf("etag-%d", time.Now().UnixNano())
+	updated.LastModified = time.Now()
+	m.files[path] = &updated
+
+	return &updated, true, nil
+}
+
+// Summary reports the mock client's activity since it was created
+func (m *MockRustFSClient) Summary() ClientSummary {
+	return m.activity.summary()
+}
+
 // GetFiles returns all files in mock storage
 func (m *MockRustFSClient) GetFiles() map[string]*types.FileInfo {
 	m.mu.RLock()
@@ -162,10 +521,16 @@ func (m *MockRustFSClient) Reset() {
 	defer m.mu.Unlock()
 
 	m.files = make(map[string]*types.FileInfo)
+	m.contents = make(map[string][]byte)
 	m.uploads = make([]*types.UploadResponse, 0)
 	m.deletes = make([]string, 0)
+	m.multipart = make(map[string]*types.IncompleteUpload)
+	m.aborted = make([]string, 0)
+	m.tokens = make(map[string]*mockDownloadToken)
+	m.tags = make(map[string]map[string]string)
 	m.shouldFail = false
 	m.failError = nil
+	m.canceller.reset()
 }
 
 // UploadSnapshot implements SnapshotStorage interface
@@ -234,35 +599,304 @@ func (m *MockRustFSClient) CheckHealth(ctx context.Context) error {
 	return nil
 }
 
+// Ping performs liveness check on mock storage
+func (m *MockRustFSClient) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	return nil
+}
+
+// mockDownloadToken is the mock token store's record of an issued download token
+type mockDownloadToken struct {
+	path      string
+	expiresAt time.Time
+	remaining int
+}
+
+// CreateDownloadToken issues a download token from the in-memory token store
+func (m *MockRustFSClient) CreateDownloadToken(ctx context.Context, path string, ttl time.Duration, maxUses int) (string, error) {
+	if err := validateTokenOptions(path, ttl, maxUses); err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return "", m.failError
+	}
+
+	if _, exists := m.files[path]; !exists {
+		return "", fmt.Errorf("file not found: %s", path)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	m.tokens[token] = &mockDownloadToken{
+		path:      path,
+		expiresAt: time.Now().Add(ttl),
+		remaining: maxUses,
+	}
+
+	return token, nil
+}
+
+// RedeemDownloadToken consumes one use of a token and returns the file it grants access to
+func (m *MockRustFSClient) RedeemDownloadToken(ctx context.Context, token string) (io.ReadCloser, *types.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, nil, m.failError
+	}
+
+	record, exists := m.tokens[token]
+	if !exists {
+		return nil, nil, newSentinelError(404, "TOKEN_NOT_FOUND", ErrDownloadTokenNotFound, nil)
+	}
+	if time.Now().After(record.expiresAt) {
+		return nil, nil, newSentinelError(410, "TOKEN_EXPIRED", ErrDownloadTokenExpired, nil)
+	}
+	if record.remaining <= 0 {
+		return nil, nil, newSentinelError(410, "TOKEN_EXHAUSTED", ErrDownloadTokenExhausted, nil)
+	}
+
+	// A use is only spent on a download that could start
+	body, fileInfo, err := m.downloadFileLocked(ctx, record.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	record.remaining--
+
+	return body, fileInfo, nil
+}
+
 // ListFiles lists files in mock storage (additional method for testing)
 func (m *MockRustFSClient) ListFiles(ctx context.Context, prefix string, limit int) ([]*types.FileInfo, error) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	directoryMode := m.directoryMode
+	m.mu.RUnlock()
+
+	return m.ListFilesWithOptions(ctx, &ListOptions{
+		Prefix:        prefix,
+		Limit:         limit,
+		DirectoryMode: directoryMode,
+	})
+}
+
+// ListFilesWithOptions lists files in mock storage matching the given options
+func (m *MockRustFSClient) ListFilesWithOptions(ctx context.Context, opts *ListOptions) ([]*types.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	if m.shouldFail {
 		m.shouldFail = false // Reset failure mode
 		return nil, m.failError
 	}
 
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
 	var files []*types.FileInfo
-	count := 0
+	for _, path := range m.sortedPaths() {
+		if !utils.MatchPrefix(path, opts.Prefix, opts.DirectoryMode) {
+			continue
+		}
+		if len(files) >= limit {
+			break
+		}
+		files = append(files, listedFileInfo(m.files[path], opts.IncludeMetadata))
+	}
+
+	return files, nil
+}
 
-	for path, fileInfo := range m.files {
-		if prefix != "" && !containsPath(path, prefix) {
+// listedFileInfo returns the listing view of a stored file: a copy without metadata and content
+// type unless includeMetadata is set, matching the server's lightweight listing
+func listedFileInfo(info *types.FileInfo, includeMetadata bool) *types.FileInfo {
+	if includeMetadata {
+		return info
+	}
+	listed := *info
+	listed.Metadata = nil
+	listed.ContentType = ""
+	listed.CacheControl = ""
+	listed.ContentDisposition = ""
+	listed.ContentEncoding = ""
+	return &listed
+}
+
+// GetTags returns the tags attached to a file in mock storage
+func (m *MockRustFSClient) GetTags(ctx context.Context, path string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	if _, exists := m.files[path]; !exists {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	tags := make(map[string]string, len(m.tags[path]))
+	for k, v := range m.tags[path] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags attached to a file in mock storage
+func (m *MockRustFSClient) SetTags(ctx context.Context, path string, tags map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	if _, exists := m.files[path]; !exists {
+		return fmt.Errorf("file not found: %s", path)
+	}
+
+	stored := make(map[string]string, len(tags))
+	for k, v := range tags {
+		stored[k] = v
+	}
+	m.tags[path] = stored
+	return nil
+}
+
+// ListFilesByTag lists files in mock storage carrying the tag key=value
+func (m *MockRustFSClient) ListFilesByTag(ctx context.Context, key, value string, opts *ListOptions) ([]*types.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*types.FileInfo, 0)
+	for _, path := range m.sortedPaths() {
+		if !utils.MatchPrefix(path, opts.Prefix, opts.DirectoryMode) {
 			continue
 		}
-		if limit > 0 && count >= limit {
+		if tagValue, ok := m.tags[path][key]; !ok || tagValue != value {
+			continue
+		}
+		if len(files) >= limit {
 			break
 		}
-		files = append(files, fileInfo)
-		count++
+		files = append(files, listedFileInfo(m.files[path], opts.IncludeMetadata))
 	}
 
 	return files, nil
 }
 
+// WalkFiles visits every file in mock storage matching opts, ignoring opts.Limit
+func (m *MockRustFSClient) WalkFiles(ctx context.Context, opts *ListOptions, fn func(file *types.FileInfo) error) error {
+	m.mu.Lock()
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		m.mu.Unlock()
+		return m.failError
+	}
+
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	var files []*types.FileInfo
+	for _, path := range m.sortedPaths() {
+		if utils.MatchPrefix(path, opts.Prefix, opts.DirectoryMode) {
+			files = append(files, listedFileInfo(m.files[path], opts.IncludeMetadata))
+		}
+	}
+	m.mu.Unlock()
+
+	for _, file := range files {
+		if err := fn(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedPaths returns stored paths in lexical order, matching server listing order
+func (m *MockRustFSClient) sortedPaths() []string {
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SetMetricsRecorder sets the recorder that receives incremental byte-transfer counts. A
+// recorder implementing OperationMetricsRecorder also sees each operation start and finish.
+func (m *MockRustFSClient) SetMetricsRecorder(recorder MetricsRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = recorder
+}
+
+// metricsRecorder returns the configured metrics recorder
+func (m *MockRustFSClient) metricsRecorder() MetricsRecorder {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.metrics
+}
+
+// SetDirectoryMode sets whether ListFiles treats its prefix as a path segment boundary
+func (m *MockRustFSClient) SetDirectoryMode(directoryMode bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.directoryMode = directoryMode
+}
+
 // CopyFile copies a file within mock storage (additional method for testing)
 func (m *MockRustFSClient) CopyFile(ctx context.Context, sourcePath, destPath string) error {
+	return m.CopyFileWithOptions(ctx, sourcePath, destPath, nil)
+}
+
+// CopyFileWithOptions copies a file within mock storage, applying opts' metadata directive like
+// RustFSClient.CopyFileWithOptions
+func (m *MockRustFSClient) CopyFileWithOptions(ctx context.Context, sourcePath, destPath string, opts *CopyOptions) error {
+	normalized, err := normalizeCopyOptions(opts)
+	if err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -271,25 +905,115 @@ func (m *MockRustFSClient) CopyFile(ctx context.Context, sourcePath, destPath st
 		return m.failError
 	}
 
+	if err := m.copyFileLocked(sourcePath, destPath); err != nil {
+		return err
+	}
+	if normalized.MetadataDirective == MetadataDirectiveReplace || len(normalized.Metadata) > 0 {
+		dest := m.files[destPath]
+		metadata := mergeMetadata(dest.Metadata, normalized.Metadata, normalized.MetadataDirective == MetadataDirectiveReplace)
+		if isEncrypted(dest.Metadata) {
+			metadata = withSourceEncryptionMetadata(metadata, dest.Metadata)
+		}
+		dest.Metadata = metadata
+	}
+	return nil
+}
+
+// copyFileLocked copies a file within mock storage; the caller must hold m.mu
+func (m *MockRustFSClient) copyFileLocked(sourcePath, destPath string) error {
+	if err := validateObjectKey(m.keyValidator, destPath); err != nil {
+		return err
+	}
+
 	sourceFile, exists := m.files[sourcePath]
 	if !exists {
 		return fmt.Errorf("source file not found: %s", sourcePath)
 	}
 
-	// Create copy
-	destFile := &types.FileInfo{
-		Path:         destPath,
-		Size:         sourceFile.Size,
-		ContentType:  sourceFile.ContentType,
-		ETag:         fmt.Sprintf("etag-%d", time.Now().UnixNano()),
-		LastModified: time.Now(),
-		Metadata:     sourceFile.Metadata,
-	}
+	// A copy keeps every header of the source, like a server-side COPY
+	destFile := *sourceFile
+	destFile.Path = destPath
+	destFile.ETag = fmt.Sprintf("etag-%d", time.Now().UnixNano())
+	destFile.LastModified = time.Now()
+	destFile.Metadata = maps.Clone(sourceFile.Metadata)
 
-	m.files[destPath] = destFile
+	m.files[destPath] = &destFile
+	m.contents[destPath] = m.contents[sourcePath]
 	return nil
 }
 
+// InitiateMultipartUpload starts a simulated multipart upload session and returns its upload ID
+func (m *MockRustFSClient) InitiateMultipartUpload(ctx context.Context, path string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return "", m.failError
+	}
+
+	uploadID := fmt.Sprintf("upload-%d", time.Now().UnixNano())
+	m.multipart[uploadID] = &types.IncompleteUpload{
+		Path:      path,
+		UploadID:  uploadID,
+		Initiated: time.Now(),
+	}
+	return uploadID, nil
+}
+
+// ListIncompleteUploads lists simulated multipart uploads that have not been completed or aborted
+func (m *MockRustFSClient) ListIncompleteUploads(ctx context.Context, prefix string) ([]types.IncompleteUpload, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	uploads := make([]types.IncompleteUpload, 0)
+	for _, upload := range m.multipart {
+		if prefix != "" && !containsPath(upload.Path, prefix) {
+			continue
+		}
+		uploads = append(uploads, *upload)
+	}
+	return uploads, nil
+}
+
+// AbortIncompleteUploads aborts simulated multipart uploads initiated more than olderThan ago
+func (m *MockRustFSClient) AbortIncompleteUploads(ctx context.Context, olderThan time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return 0, m.failError
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	aborted := 0
+	for uploadID, upload := range m.multipart {
+		if upload.Initiated.After(cutoff) {
+			continue
+		}
+		delete(m.multipart, uploadID)
+		m.aborted = append(m.aborted, uploadID)
+		aborted++
+	}
+	return aborted, nil
+}
+
+// GetAbortedUploads returns the upload IDs of all aborted multipart uploads
+func (m *MockRustFSClient) GetAbortedUploads() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aborted := make([]string, len(m.aborted))
+	copy(aborted, m.aborted)
+	return aborted
+}
+
 // containsPath checks if path contains prefix
 func containsPath(path, prefix string) bool {
 	if len(prefix) > len(path) {
@@ -324,6 +1048,17 @@ func (b *MockRustFSClientBuilder) WithFile(path string, size int64, contentType
 	return b
 }
 
+// WithIncompleteUpload adds a predefined incomplete multipart upload to the mock client
+func (b *MockRustFSClientBuilder) WithIncompleteUpload(path string, initiated time.Time) *MockRustFSClientBuilder {
+	uploadID := fmt.Sprintf("upload-%d", len(b.client.multipart))
+	b.client.multipart[uploadID] = &types.IncompleteUpload{
+		Path:      path,
+		UploadID:  uploadID,
+		Initiated: initiated,
+	}
+	return b
+}
+
 // WithFailure sets the mock client to fail
 func (b *MockRustFSClientBuilder) WithFailure(err error) *MockRustFSClientBuilder {
 	b.client.shouldFail = true
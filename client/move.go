@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// CapabilityMove marks servers that can move an object in a single request
+const CapabilityMove = "move"
+
+// moveRequest is the body sent to the move endpoint
+type moveRequest struct {
+	Bucket      string `json:"bucket"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// moveFiler is the storage surface needed to move objects by copying and deleting them
+type moveFiler interface {
+	copyFiler
+	DeleteFile(ctx context.Context, path string) error
+}
+
+// validateFileMoves rejects moves without paths, moves onto their own source, which would
+// delete the object, and moves sharing a target
+func validateFileMoves(moves []FileMove) error {
+	seen := make(map[string]bool, len(moves))
+	for i, move := range moves {
+		if move.SourcePath == "" || move.TargetPath == "" {
+			return apperror.NewAppError(400, "INVALID_MOVE", fmt.Errorf("move %d is missing a source or target path", i))
+		}
+		if move.SourcePath == move.TargetPath {
+			return apperror.NewAppError(400, "INVALID_MOVE", fmt.Errorf("move %d has the same source and target %s", i, move.SourcePath))
+		}
+		if seen[move.TargetPath] {
+			return apperror.NewAppError(400, "INVALID_MOVE", fmt.Errorf("target %s appears more than once", move.TargetPath))
+		}
+		seen[move.TargetPath] = true
+	}
+	return nil
+}
+
+// copyThenDelete moves an object by copying it and deleting the source. A failed copy leaves the
+// source untouched; a failed delete after the copy is reported as ErrMoveIncomplete.
+func copyThenDelete(ctx context.Context, storage moveFiler, sourcePath, targetPath string) error {
+	if err := storage.CopyFile(ctx, sourcePath, targetPath); err != nil {
+		return err
+	}
+	if err := storage.DeleteFile(ctx, sourcePath); err != nil {
+		return newSentinelError(500, "MOVE_INCOMPLETE", ErrMoveIncomplete, err)
+	}
+	return nil
+}
+
+// batchMove moves concurrently, at most limit at a time, continuing past failures and
+// reporting every failed move in a *BatchError keyed by source path
+func batchMove(ctx context.Context, move func(ctx context.Context, sourcePath, targetPath string) error, moves []FileMove, limit int) error {
+	if err := validateFileMoves(moves); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(moves))
+
+	// Tasks never fail, so one bad move doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, limit)
+	for i, item := range moves {
+		i, item := i, item
+		group.Go(func() error {
+			errs[i] = move(groupCtx, item.SourcePath, item.TargetPath)
+			return nil
+		})
+	}
+	group.Wait()
+
+	var failures []BatchItemError
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, BatchItemError{Index: i, Path: moves[i].SourcePath, Err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &BatchError{Operation: "move", Total: len(moves), Failures: failures}
+	}
+	return nil
+}
+
+// BatchMove moves objects concurrently by copying each to its target and deleting the source.
+// Failures are reported together in a *BatchError. A failure matching ErrMoveIncomplete means
+// the object now exists at both paths; any other failure leaves the source untouched.
+func (c *RustFSClient) BatchMove(ctx context.Context, moves []FileMove) error {
+	// Validate the keys the moves act on, so "a" -> "/a" is caught as a move onto itself
+	normalized := make([]FileMove, len(moves))
+	for i, move := range moves {
+		normalized[i] = FileMove{SourcePath: c.objectKey(move.SourcePath), TargetPath: c.objectKey(move.TargetPath)}
+	}
+	moves = normalized
+
+	return batchMove(ctx, func(ctx context.Context, sourcePath, targetPath string) error {
+		return copyThenDelete(ctx, c, sourcePath, targetPath)
+	}, moves, c.config.ConcurrentUploads)
+}
+
+// BatchMove moves files within mock storage, reporting failures like RustFSClient.BatchMove
+func (m *MockRustFSClient) BatchMove(ctx context.Context, moves []FileMove) error {
+	return batchMove(ctx, func(ctx context.Context, sourcePath, targetPath string) error {
+		return copyThenDelete(ctx, m, sourcePath, targetPath)
+	}, moves, 0)
+}
+
+// MoveFile moves an object to destPath. It uses the server's move endpoint when available and
+// otherwise copies the object and deletes the source; if that delete fails the error matches
+// ErrMoveIncomplete and the object exists at both paths.
+func (c *RustFSClient) MoveFile(ctx context.Context, sourcePath, destPath string) error {
+	sourcePath, destPath = c.objectKey(sourcePath), c.objectKey(destPath)
+	if err := validateFileMoves([]FileMove{{SourcePath: sourcePath, TargetPath: destPath}}); err != nil {
+		return err
+	}
+	if err := validateObjectKey(c.keyValidator, destPath); err != nil {
+		return err
+	}
+
+	// A server-side move would leave side objects behind at the source path
+	if !c.usesSideObjects() && c.capabilities.supports(ctx, CapabilityMove) {
+		moved, err := c.moveServer(ctx, sourcePath, destPath)
+		if err != nil {
+			return err
+		}
+		if moved {
+			return nil
+		}
+	}
+
+	return copyThenDelete(ctx, c, sourcePath, destPath)
+}
+
+// moveServer sends a move to the move endpoint. It reports false when the server has no such endpoint.
+func (c *RustFSClient) moveServer(ctx context.Context, sourcePath, destPath string) (bool, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	payload, err := json.Marshal(moveRequest{Bucket: c.config.BucketName, Source: sourcePath, Destination: destPath})
+	if err != nil {
+		return false, apperror.NewAppError(500, "MOVE_FAILED", err)
+	}
+
+	resp, err := c.doSignedRequest(ctx, http.MethodPost, c.apiURL("files/move"), payload)
+	if err != nil {
+		return false, cancellationError(ctx, apperror.NewAppError(500, "MOVE_FAILED", err))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return false, nil
+	default:
+		return false, apperror.NewAppError(resp.StatusCode, "MOVE_FAILED",
+			fmt.Errorf("move endpoint returned %s", resp.Status))
+	}
+
+	c.bodies.invalidate(sourcePath)
+	c.bodies.invalidate(destPath)
+	return true, nil
+}
+
+// MoveFile moves a file within mock storage in one step, like a server move endpoint
+func (m *MockRustFSClient) MoveFile(ctx context.Context, sourcePath, destPath string) error {
+	if err := validateFileMoves([]FileMove{{SourcePath: sourcePath, TargetPath: destPath}}); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	if err := m.copyFileLocked(sourcePath, destPath); err != nil {
+		return err
+	}
+	delete(m.files, sourcePath)
+	delete(m.contents, sourcePath)
+	delete(m.tags, sourcePath)
+	return nil
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestBatchMoveRejectsMovesOntoThemselvesAfterNormalization(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []FileMove
+	}{
+		{"leading slash", []FileMove{{SourcePath: "a", TargetPath: "/a"}}},
+		{"trailing slash", []FileMove{{SourcePath: "dir/a/", TargetPath: "dir/a"}}},
+		{"doubled slash", []FileMove{{SourcePath: "dir//a", TargetPath: "dir/a"}}},
+		{"duplicate target", []FileMove{{SourcePath: "a", TargetPath: "c"}, {SourcePath: "b", TargetPath: "/c"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			err := c.BatchMove(context.Background(), tt.moves)
+			if errorCode(err) != "INVALID_MOVE" {
+				t.Fatalf("BatchMove error = %v, want INVALID_MOVE", err)
+			}
+			if n := server.requests.Load(); n != 0 {
+				t.Fatalf("BatchMove sent %d requests, want none", n)
+			}
+		})
+	}
+}
+
+func TestMockBatchMove(t *testing.T) {
+	m := NewMockRustFSClient()
+	ctx := context.Background()
+	for _, path := range []string{"a", "b"} {
+		if _, err := m.UploadFile(ctx, &types.UploadRequest{File: strings.NewReader(path), BucketPath: path}); err != nil {
+			t.Fatalf("UploadFile(%s): %v", path, err)
+		}
+	}
+
+	err := m.BatchMove(ctx, []FileMove{{SourcePath: "a", TargetPath: "moved/a"}, {SourcePath: "b", TargetPath: "moved/b"}})
+	if err != nil {
+		t.Fatalf("BatchMove: %v", err)
+	}
+	for _, path := range []string{"moved/a", "moved/b"} {
+		if _, err := m.GetFileInfo(ctx, path); err != nil {
+			t.Errorf("GetFileInfo(%s): %v", path, err)
+		}
+	}
+	for _, path := range []string{"a", "b"} {
+		if _, err := m.GetFileInfo(ctx, path); err == nil {
+			t.Errorf("GetFileInfo(%s) found the moved source", path)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// ListIncompleteUploads lists multipart uploads under prefix that were never completed or aborted
+func (c *RustFSClient) ListIncompleteUploads(ctx context.Context, prefix string) ([]types.IncompleteUpload, error) {
+	if err := c.requireCapability(ctx, CapabilityMultipart); err != nil {
+		return nil, err
+	}
+
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(c.config.BucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	uploads := make([]types.IncompleteUpload, 0)
+	paginator := s3.NewListMultipartUploadsPaginator(c.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, apperror.NewAppError(500, "LIST_MULTIPART_FAILED", err)
+		}
+
+		for _, upload := range page.Uploads {
+			uploads = append(uploads, types.IncompleteUpload{
+				Path:      aws.ToString(upload.Key),
+				UploadID:  aws.ToString(upload.UploadId),
+				Initiated: aws.ToTime(upload.Initiated),
+			})
+		}
+	}
+
+	return uploads, nil
+}
+
+// AbortIncompleteUploads aborts every incomplete multipart upload initiated more than olderThan ago
+// and returns the number of uploads that were aborted. Up to ConcurrentUploads aborts run at once,
+// each retried on its own; failures don't stop the other aborts and are reported together in a
+// *BatchError keyed by path.
+func (c *RustFSClient) AbortIncompleteUploads(ctx context.Context, olderThan time.Duration) (int, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	uploads, err := c.ListIncompleteUploads(ctx, "")
+	if err != nil {
+		return 0, cancellationError(ctx, err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var stale []types.IncompleteUpload
+	for _, upload := range uploads {
+		if !upload.Initiated.After(cutoff) {
+			stale = append(stale, upload)
+		}
+	}
+
+	errs := make([]error, len(stale))
+
+	// Tasks never fail, so one failed abort doesn't cancel the rest
+	group, groupCtx := utils.NewGroup(ctx, c.config.ConcurrentUploads)
+	for i, upload := range stale {
+		i, upload := i, upload
+		group.Go(func() error {
+			errs[i] = c.abortIncompleteUpload(groupCtx, upload)
+			return nil
+		})
+	}
+	group.Wait()
+
+	var failures []BatchItemError
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, BatchItemError{Index: i, Path: stale[i].Path, Err: cancellationError(ctx, err)})
+		}
+	}
+
+	aborted := len(stale) - len(failures)
+	if len(failures) > 0 {
+		return aborted, &BatchError{Operation: "abort", Total: len(stale), Failures: failures}
+	}
+	return aborted, nil
+}
+
+// abortIncompleteUpload aborts one multipart upload. An upload that is already gone, e.g.
+// because an earlier attempt aborted it before its response was lost, counts as aborted.
+func (c *RustFSClient) abortIncompleteUpload(ctx context.Context, upload types.IncompleteUpload) error {
+	return c.withRetry(ctx, func(ctx context.Context) error {
+		_, err := c.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(c.config.BucketName),
+			Key:      aws.String(upload.Path),
+			UploadId: aws.String(upload.UploadID),
+		})
+		if err != nil && apiErrorCode(err) != "NoSuchUpload" {
+			return apperror.NewAppError(500, "ABORT_MULTIPART_FAILED", err)
+		}
+		return nil
+	})
+}
+
+// UploadLargeFile uploads a file in parts of the configured PartSize using a multipart upload
+// when its FileSize exceeds a part, and as a single request otherwise. Up to ConcurrentUploads parts are in
+// flight at once, and a failed part is retried on its own rather than restarting the upload.
+// Encrypted uploads are sealed as a whole and always sent as a single request.
+// The response carries a composite checksum of the parts (see utils.CompositeChecksum).
+func (c *RustFSClient) UploadLargeFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	if req.FileSize <= c.config.PartSize() || c.config.EnableEncryption {
+		return c.UploadFile(ctx, req)
+	}
+
+	defer closeUploadSource(req)
+
+	if key := c.uploadKey(req); key != req.BucketPath {
+		normalized := *req
+		normalized.BucketPath = key
+		req = &normalized
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	finish := trackOperation(c.activity, c.metrics, OperationUpload)
+	response, err := c.uploadMultipart(ctx, req)
+	err = cancellationError(ctx, err)
+	finish(err)
+	return response, err
+}
+
+func (c *RustFSClient) uploadMultipart(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	if err := validateObjectKey(c.keyValidator, req.BucketPath); err != nil {
+		return nil, err
+	}
+	if err := validateUploadHeaders(req); err != nil {
+		return nil, err
+	}
+	if err := c.requireCapability(ctx, CapabilityMultipart); err != nil {
+		return nil, err
+	}
+
+	source, err := utils.NewPartSource(req.File, req.FileSize, c.config.PartSize())
+	if err != nil {
+		return nil, apperror.NewAppError(400, "INVALID_CHUNK_SIZE", err)
+	}
+
+	contentType := "application/octet-stream"
+	if req.ContentType != "" {
+		contentType = req.ContentType
+	}
+
+	withDefaults, defaultKeys := withDefaultMetadata(req.Metadata, c.config.DefaultMetadata)
+	requestMetadata, sideObjects, err := c.limitMetadataValues(req.BucketPath, withSchemaVersion(withDefaults, c.config.MetadataSchemaVersion))
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := c.metadataEncoder.Encode(requestMetadata)
+	if err != nil {
+		return nil, apperror.NewAppError(400, "INVALID_METADATA", err)
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(c.config.BucketName),
+		Key:         aws.String(req.BucketPath),
+		ContentType: aws.String(contentType),
+		Metadata:    metadata,
+	}
+	if req.StorageClass != "" {
+		input.StorageClass = s3types.StorageClass(req.StorageClass)
+	}
+	if req.CacheControl != "" {
+		input.CacheControl = aws.String(req.CacheControl)
+	}
+	if req.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(req.ContentDisposition)
+	}
+	if req.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(req.ContentEncoding)
+	}
+
+	created, err := c.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, apperror.NewAppError(500, "UPLOAD_FAILED", err)
+	}
+	uploadID := created.UploadId
+
+	parts, size, checksum, err := c.uploadParts(ctx, req.BucketPath, uploadID, source)
+	if err != nil {
+		c.abortMultipartUpload(req.BucketPath, uploadID)
+		return nil, err
+	}
+
+	// The existence check of an IfNotExists upload happens when it completes
+	complete := &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(c.config.BucketName),
+		Key:             aws.String(req.BucketPath),
+		UploadId:        uploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	}
+	if req.IfNotExists {
+		complete.IfNoneMatch = aws.String("*")
+	}
+	completed, err := c.client.CompleteMultipartUpload(ctx, complete)
+	c.bodies.invalidate(req.BucketPath)
+	if err != nil {
+		c.abortMultipartUpload(req.BucketPath, uploadID)
+		return nil, conditionalUploadError(err, "UPLOAD_FAILED")
+	}
+	if err := c.syncSideObjects(ctx, req.BucketPath, sideObjects); err != nil {
+		return nil, err
+	}
+
+	return &types.UploadResponse{
+		Path:              req.BucketPath,
+		URL:               c.GetFileURL(req.BucketPath),
+		Size:              size,
+		ContentType:       contentType,
+		ETag:              aws.ToString(completed.ETag),
+		LastModified:      time.Now(),
+		Metadata:          requestMetadata,
+		StorageClass:      req.StorageClass,
+		Checksum:          checksum,
+		ChecksumAlgorithm: utils.CompositeChecksumAlgorithm,
+
+		DefaultMetadataKeys: defaultKeys,
+		ContentEncoding:     req.ContentEncoding,
+	}, nil
+}
+
+// uploadParts uploads every part of source concurrently and returns them in part order along
+// with their total size and composite checksum. At most ConcurrentUploads parts are read ahead, which bounds
+// the memory buffered for sequential sources.
+func (c *RustFSClient) uploadParts(ctx context.Context, key string, uploadID *string, source utils.PartSource) ([]s3types.CompletedPart, int64, string, error) {
+	limit := c.config.ConcurrentUploads
+	if limit <= 0 {
+		limit = 1
+	}
+	slots := make(chan struct{}, limit)
+
+	var mu sync.Mutex
+	var completed []s3types.CompletedPart
+	var size int64
+	checksum := utils.NewCompositeChecksum()
+
+	group, groupCtx := utils.NewGroup(ctx, 0)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-groupCtx.Done():
+		}
+		// A failed part cancels the group, so stop reading more parts
+		if groupCtx.Err() != nil {
+			break
+		}
+
+		part, err := source.NextPart()
+		if err == io.EOF {
+			<-slots
+			break
+		}
+		if err != nil {
+			<-slots
+			group.Go(func() error { return partSourceError(err) })
+			break
+		}
+
+		group.Go(func() error {
+			defer func() { <-slots }()
+
+			digest, err := utils.PartMD5(part.Body)
+			if err != nil {
+				return apperror.NewAppError(500, "FILE_READ_ERROR", err)
+			}
+			checksum.AddPart(part.Number, digest)
+
+			etag, err := c.uploadPart(groupCtx, key, uploadID, part)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			completed = append(completed, s3types.CompletedPart{
+				ETag:       aws.String(etag),
+				PartNumber: aws.Int32(int32(part.Number)),
+			})
+			size += part.Size
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, 0, "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, 0, "", err
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+	sum, err := checksum.Sum()
+	if err != nil {
+		return nil, 0, "", apperror.NewAppError(500, "CHECKSUM_FAILED", err)
+	}
+
+	return completed, size, sum, nil
+}
+
+// partSourceError maps a failure to read the next part to an AppError
+func partSourceError(err error) error {
+	switch {
+	case errors.Is(err, utils.ErrTooManyParts):
+		return apperror.NewAppError(400, "TOO_MANY_PARTS", err)
+	case errors.Is(err, utils.ErrSizeMismatch):
+		return apperror.NewAppError(400, "SIZE_MISMATCH", err)
+	default:
+		return apperror.NewAppError(500, "FILE_READ_ERROR", err)
+	}
+}
+
+// uploadPart uploads one part, retrying it on its own with the client's backoff
+func (c *RustFSClient) uploadPart(ctx context.Context, key string, uploadID *string, part *utils.UploadPart) (string, error) {
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	// Retries rewind the counting reader, which only reports bytes past the furthest offset read
+	body := utils.WrapCountingReader(part.Body, transferProgress(ctx, c.bandwidth, OperationUpload, c.metrics, c.activity)).(io.ReadSeeker)
+
+	var etag string
+	err = c.withRetry(ctx, func(ctx context.Context) error {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		output, err := c.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(c.config.BucketName),
+			Key:           aws.String(key),
+			UploadId:      uploadID,
+			PartNumber:    aws.Int32(int32(part.Number)),
+			ContentLength: aws.Int64(part.Size),
+			Body:          body,
+		})
+		if err != nil {
+			return err
+		}
+		etag = aws.ToString(output.ETag)
+		return nil
+	})
+	if err != nil {
+		return "", apperror.NewAppError(500, "UPLOAD_PART_FAILED", fmt.Errorf("part %d: %w", part.Number, err))
+	}
+
+	return etag, nil
+}
+
+// abortMultipartUpload discards the parts of a failed upload. It runs detached from the
+// upload's context, which may already be cancelled, and only logs failures; leftovers are
+// reclaimed by AbortIncompleteUploads.
+func (c *RustFSClient) abortMultipartUpload(key string, uploadID *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	_, err := c.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.config.BucketName),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if err != nil {
+		log.Printf("[RUSTFS] failed to abort multipart upload %s of %s: %v", aws.ToString(uploadID), key, err)
+	}
+}
+
+// UploadLargeFile stores a file in mock storage; the mock has no multipart protocol, so
+// large files are stored in one piece
+func (m *MockRustFSClient) UploadLargeFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	return m.UploadFile(ctx, req)
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+func TestUploadLargeFileWithDefaultConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		multipart bool
+	}{
+		{"below a chunk", 512 * 1024, false},
+		{"above the default chunk size but within a part", 2 * 1024 * 1024, false},
+		{"several parts", 2*utils.MinPartSize + 1024, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			data := bytes.Repeat([]byte("0123456789abcdef"), int(tt.size/16))
+
+			resp, err := c.UploadLargeFile(context.Background(), &types.UploadRequest{
+				File:        bytes.NewReader(data),
+				FileSize:    int64(len(data)),
+				ContentType: "application/octet-stream",
+				BucketPath:  "large.bin",
+			})
+			if err != nil {
+				t.Fatalf("UploadLargeFile: %v", err)
+			}
+			if resp.Size != int64(len(data)) {
+				t.Errorf("response size = %d, want %d", resp.Size, len(data))
+			}
+
+			stored, ok := fake.get("large.bin")
+			if !ok || !bytes.Equal(stored.data, data) {
+				t.Fatalf("stored object doesn't match the upload")
+			}
+			if created := len(fake.requests("POST", "large.bin?uploads")); (created > 0) != tt.multipart {
+				t.Errorf("multipart uploads created = %d, want multipart %v", created, tt.multipart)
+			}
+		})
+	}
+}
+
+func TestMultipartUploadChecksum(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		concurrency int
+		wantParts   int
+	}{
+		{"two parts", utils.MinPartSize + 1024, 1, 2},
+		{"three parts uploaded concurrently", 2*utils.MinPartSize + 1024, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				cfg.ConcurrentUploads = tt.concurrency
+			})
+			data := bytes.Repeat([]byte("0123456789abcdef"), tt.size/16)
+
+			resp, err := c.UploadLargeFile(context.Background(), &types.UploadRequest{
+				File:        bytes.NewReader(data),
+				FileSize:    int64(len(data)),
+				ContentType: "application/octet-stream",
+				BucketPath:  "large.bin",
+			})
+			if err != nil {
+				t.Fatalf("UploadLargeFile: %v", err)
+			}
+
+			digests := md5.New()
+			for offset := 0; offset < len(data); offset += utils.MinPartSize {
+				part := md5.Sum(data[offset:min(offset+utils.MinPartSize, len(data))])
+				digests.Write(part[:])
+			}
+			want := fmt.Sprintf("%x-%d", digests.Sum(nil), tt.wantParts)
+
+			if resp.Checksum != want || resp.ChecksumAlgorithm != utils.CompositeChecksumAlgorithm {
+				t.Errorf("checksum = %s %q, want %s %q", resp.ChecksumAlgorithm, resp.Checksum, utils.CompositeChecksumAlgorithm, want)
+			}
+			stored, _ := fake.get("large.bin")
+			if !utils.MatchesETag(resp.Checksum, stored.etag()) {
+				t.Errorf("checksum %q doesn't match the server ETag %s", resp.Checksum, stored.etag())
+			}
+		})
+	}
+}
+
+func TestAbortIncompleteUploads(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    map[string]int // abort failures per key before it succeeds; -1 fails every attempt
+		wantAborted int
+		wantFailed  []string
+		wantLeft    []string
+	}{
+		{name: "stale uploads are aborted", wantAborted: 3, wantLeft: []string{"fresh.bin"}},
+		{name: "transient failure is retried", failures: map[string]int{"b.bin": 1}, wantAborted: 3, wantLeft: []string{"fresh.bin"}},
+		{
+			name: "failure doesn't stop the other aborts", failures: map[string]int{"b.bin": -1},
+			wantAborted: 2, wantFailed: []string{"b.bin"}, wantLeft: []string{"b.bin", "fresh.bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			old := time.Now().Add(-2 * time.Hour)
+			for _, key := range []string{"a.bin", "b.bin", "c.bin"} {
+				fake.startUpload(key, old)
+			}
+			fake.startUpload("fresh.bin", time.Now())
+
+			failures := tt.failures
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method != http.MethodDelete || !r.URL.Query().Has("uploadId") {
+					return false
+				}
+				key := strings.TrimPrefix(r.URL.Path, "/"+fake.bucket+"/")
+				fake.mu.Lock()
+				defer fake.mu.Unlock()
+				if failures[key] == 0 {
+					return false
+				}
+				if failures[key] > 0 {
+					failures[key]--
+				}
+				writeS3Error(w, http.StatusServiceUnavailable, "SlowDown")
+				return true
+			}
+
+			aborted, err := c.AbortIncompleteUploads(context.Background(), time.Hour)
+			if aborted != tt.wantAborted {
+				t.Errorf("aborted = %d, want %d", aborted, tt.wantAborted)
+			}
+
+			var failed []string
+			var batchErr *BatchError
+			if errors.As(err, &batchErr) {
+				for _, failure := range batchErr.Failures {
+					failed = append(failed, failure.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("AbortIncompleteUploads: %v", err)
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+			if left := fake.uploadKeys(); !reflect.DeepEqual(left, tt.wantLeft) {
+				t.Errorf("uploads left = %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestUploadLargeFileSizeMismatch(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 2*utils.MinPartSize+1024)
+
+	tests := []struct {
+		name     string
+		declared int64
+	}{
+		{"source shorter than declared", 3 * utils.MinPartSize},
+		{"source longer than declared", 2*utils.MinPartSize + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+
+			// An unseekable source is streamed through buffered parts
+			_, err := c.UploadLargeFile(context.Background(), &types.UploadRequest{
+				File:        &onceReader{r: bytes.NewReader(data)},
+				FileSize:    tt.declared,
+				ContentType: "application/octet-stream",
+				BucketPath:  "large.bin",
+			})
+			if errorCode(err) != "SIZE_MISMATCH" || !errors.Is(err, utils.ErrSizeMismatch) {
+				t.Fatalf("UploadLargeFile = %v, want SIZE_MISMATCH", err)
+			}
+			if _, ok := fake.get("large.bin"); ok {
+				t.Errorf("mismatched upload was stored")
+			}
+			if keys := fake.uploadKeys(); len(keys) != 0 {
+				t.Errorf("multipart uploads left open: %v", keys)
+			}
+		})
+	}
+}
+
+func TestUploadLargeFileTooManyParts(t *testing.T) {
+	c, fake := newFakeS3Client(t)
+
+	// The declared size alone shows the upload can't fit, so nothing is read or sent
+	source := &countingSource{r: strings.NewReader("content")}
+	_, err := c.UploadLargeFile(context.Background(), &types.UploadRequest{
+		File:        source,
+		FileSize:    utils.MaxUploadParts*c.config.PartSize() + 1,
+		ContentType: "application/octet-stream",
+		BucketPath:  "huge.bin",
+	})
+	if errorCode(err) != "INVALID_CHUNK_SIZE" {
+		t.Fatalf("UploadLargeFile = %v, want INVALID_CHUNK_SIZE", err)
+	}
+	if source.read != 0 {
+		t.Errorf("%d bytes read before failing", source.read)
+	}
+	if created := fake.requests(http.MethodPost, "huge.bin"); len(created) != 0 {
+		t.Errorf("multipart upload started: %v", created)
+	}
+}
+
+func TestUploadLargeFileFromOSFile(t *testing.T) {
+	c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+		cfg.ConcurrentUploads = 3
+	})
+	// The first part finishes last, so completion order differs from part order
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodPut && r.URL.Query().Get("partNumber") == "1" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+
+	// Every part holds a different byte, so a misplaced part changes the object
+	var data []byte
+	for _, b := range []byte("abc") {
+		data = append(data, bytes.Repeat([]byte{b}, utils.MinPartSize)...)
+	}
+	data = data[:len(data)-1024]
+
+	path := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	resp, err := c.UploadLargeFile(context.Background(), &types.UploadRequest{
+		File:        file,
+		FileSize:    int64(len(data)),
+		ContentType: "application/octet-stream",
+		BucketPath:  "large.bin",
+	})
+	if err != nil {
+		t.Fatalf("UploadLargeFile: %v", err)
+	}
+	if resp.Size != int64(len(data)) {
+		t.Errorf("response size = %d, want %d", resp.Size, len(data))
+	}
+	if !strings.HasSuffix(resp.Checksum, "-3") {
+		t.Errorf("checksum %q isn't a composite of 3 parts", resp.Checksum)
+	}
+
+	stored, ok := fake.get("large.bin")
+	if !ok || !bytes.Equal(stored.data, data) {
+		t.Fatalf("stored object doesn't match the file")
+	}
+	if parts := fake.requests("PUT", "large.bin?partNumber"); len(parts) != 3 {
+		t.Errorf("uploaded %d parts, want 3", len(parts))
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/garyjdn/go-apperror"
+)
+
+// MaxPresignExpiry is the longest lifetime of a presigned URL, matching the SigV4 limit
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// validatePresignOptions checks the arguments shared by every presign implementation
+func validatePresignOptions(path string, expiresIn time.Duration) error {
+	if path == "" {
+		return apperror.NewAppError(400, "INVALID_PRESIGN_REQUEST", fmt.Errorf("path is required"))
+	}
+	if expiresIn <= 0 {
+		return apperror.NewAppError(400, "INVALID_PRESIGN_REQUEST", fmt.Errorf("expiresIn must be positive"))
+	}
+	if expiresIn > MaxPresignExpiry {
+		return apperror.NewAppError(400, "INVALID_PRESIGN_REQUEST",
+			fmt.Errorf("expiresIn must be at most %s", MaxPresignExpiry))
+	}
+	return nil
+}
+
+// GenerateDownloadURL returns a SigV4 query-signed URL granting GET access to path until
+// expiresIn has passed. The URL carries its expiry and signature, so it works without an
+// Authorization header.
+func (c *RustFSClient) GenerateDownloadURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	path = c.objectKey(path)
+	if err := validatePresignOptions(path, expiresIn); err != nil {
+		return "", err
+	}
+	if err := c.requireSigV4("presigning URLs"); err != nil {
+		return "", err
+	}
+
+	request, err := s3.NewPresignClient(c.client).PresignGetObject(c.presignContext(ctx, path), &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+	}, s3.WithPresignExpires(expiresIn))
+	if err != nil {
+		return "", apperror.NewAppError(500, "PRESIGN_FAILED", err)
+	}
+	return request.URL, nil
+}
+
+// GenerateUploadURL returns a SigV4 query-signed URL letting a client such as a browser PUT
+// path directly to storage until expiresIn has passed. contentType is part of the signature,
+// so the upload must send exactly that Content-Type header or storage rejects it.
+func (c *RustFSClient) GenerateUploadURL(ctx context.Context, path, contentType string, expiresIn time.Duration) (string, error) {
+	path = c.objectKey(path)
+	if err := validatePresignOptions(path, expiresIn); err != nil {
+		return "", err
+	}
+	if contentType == "" {
+		return "", apperror.NewAppError(400, "INVALID_PRESIGN_REQUEST", fmt.Errorf("contentType is required"))
+	}
+	if err := validateObjectKey(c.keyValidator, path); err != nil {
+		return "", err
+	}
+	if err := c.requireSigV4("presigning URLs"); err != nil {
+		return "", err
+	}
+
+	request, err := s3.NewPresignClient(c.client).PresignPutObject(c.presignContext(ctx, path), &s3.PutObjectInput{
+		Bucket:      aws.String(c.config.BucketName),
+		Key:         aws.String(path),
+		ContentType: aws.String(contentType),
+	}, s3.WithPresignExpires(expiresIn), s3.WithPresignClientFromClientOptions(s3.WithAPIOptions(signContentType(contentType))))
+	if err != nil {
+		return "", apperror.NewAppError(500, "PRESIGN_FAILED", err)
+	}
+	return request.URL, nil
+}
+
+// signContentType restores the Content-Type header the SDK drops from presigned requests without
+// a body, so the content type becomes part of the signature
+func signContentType(contentType string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		restore := middleware.BuildMiddlewareFunc("RustFSSignContentType", func(
+			ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+		) (middleware.BuildOutput, middleware.Metadata, error) {
+			if req, ok := in.Request.(*smithyhttp.Request); ok {
+				req.Header.Set("Content-Type", contentType)
+			}
+			return next.HandleBuild(ctx, in)
+		})
+		return stack.Build.Insert(restore, "RemoveContentTypeHeader", middleware.After)
+	}
+}
+
+// presignContext pins the endpoint of a presigned URL for path when requests are spread across
+// several endpoints, so every URL for an object points at the same server
+func (c *RustFSClient) presignContext(ctx context.Context, path string) context.Context {
+	if c.balancer == nil {
+		return ctx
+	}
+	return withPinnedEndpoint(ctx, c.balancer.endpointFor(path))
+}
+
+// GenerateDownloadURL returns a fake presigned URL for a file in mock storage. The URL carries
+// the same expiry and signature query parameters as a real one.
+func (m *MockRustFSClient) GenerateDownloadURL(ctx context.Context, path string, expiresIn time.Duration) (string, error) {
+	if err := validatePresignOptions(path, expiresIn); err != nil {
+		return "", err
+	}
+	return mockPresignedURL(m.GetFileURL(path), expiresIn, nil), nil
+}
+
+// GenerateUploadURL returns a fake presigned upload URL for mock storage, signing the content
+// type like a real one
+func (m *MockRustFSClient) GenerateUploadURL(ctx context.Context, path, contentType string, expiresIn time.Duration) (string, error) {
+	if err := validatePresignOptions(path, expiresIn); err != nil {
+		return "", err
+	}
+	if contentType == "" {
+		return "", apperror.NewAppError(400, "INVALID_PRESIGN_REQUEST", fmt.Errorf("contentType is required"))
+	}
+
+	m.mu.RLock()
+	validator := m.keyValidator
+	m.mu.RUnlock()
+	if err := validateObjectKey(validator, path); err != nil {
+		return "", err
+	}
+
+	return mockPresignedURL(m.GetFileURL(path), expiresIn, url.Values{"X-Amz-SignedHeaders": {"content-type;host"}}), nil
+}
+
+// mockPresignedURL appends SigV4-style presign query parameters and extra to fileURL
+func mockPresignedURL(fileURL string, expiresIn time.Duration, extra url.Values) string {
+	query := url.Values{}
+	for k, v := range extra {
+		query[k] = v
+	}
+	query.Set("X-Amz-Date", time.Now().UTC().Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expiresIn/time.Second), 10))
+	query.Set("X-Amz-Signature", "mock-signature")
+	return fileURL + "?" + query.Encode()
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateUploadURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		expiresIn   time.Duration
+		wantCode    string
+		wantExpires string
+	}{
+		{name: "fifteen minutes", path: "uploads/photo.png", contentType: "image/png", expiresIn: 15 * time.Minute, wantExpires: "900"},
+		{name: "maximum expiry", path: "uploads/photo.png", contentType: "image/png", expiresIn: MaxPresignExpiry, wantExpires: "604800"},
+		{name: "escaped key", path: "uploads/my photo (1).png", contentType: "image/png", expiresIn: time.Minute, wantExpires: "60"},
+		{name: "missing content type", path: "uploads/photo.png", expiresIn: time.Minute, wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "missing path", contentType: "image/png", expiresIn: time.Minute, wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "expiry past the SigV4 limit", path: "uploads/photo.png", contentType: "image/png", expiresIn: MaxPresignExpiry + time.Second, wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "non-positive expiry", path: "uploads/photo.png", contentType: "image/png", wantCode: "INVALID_PRESIGN_REQUEST"},
+	}
+
+	storages := []struct {
+		name string
+		new  func(t *testing.T) PresignedURL
+	}{
+		{"RustFSClient", func(t *testing.T) PresignedURL {
+			c, _ := newFakeS3Client(t)
+			return c
+		}},
+		{"MockRustFSClient", func(t *testing.T) PresignedURL { return NewMockRustFSClient() }},
+	}
+
+	for _, storage := range storages {
+		for _, tt := range tests {
+			t.Run(storage.name+"/"+tt.name, func(t *testing.T) {
+				signed, err := storage.new(t).GenerateUploadURL(context.Background(), tt.path, tt.contentType, tt.expiresIn)
+				if tt.wantCode != "" {
+					if errorCode(err) != tt.wantCode {
+						t.Fatalf("error = %v, want %s", err, tt.wantCode)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("GenerateUploadURL: %v", err)
+				}
+
+				parsed, err := url.Parse(signed)
+				if err != nil {
+					t.Fatalf("parsing %q: %v", signed, err)
+				}
+				if unescaped, _ := url.PathUnescape(parsed.EscapedPath()); !strings.HasSuffix(unescaped, "/"+tt.path) {
+					t.Errorf("URL path = %s, want it to end in %s", unescaped, tt.path)
+				}
+
+				query := parsed.Query()
+				if got := query.Get("X-Amz-Expires"); got != tt.wantExpires {
+					t.Errorf("X-Amz-Expires = %q, want %q", got, tt.wantExpires)
+				}
+				for _, param := range []string{"X-Amz-Signature", "X-Amz-Date"} {
+					if query.Get(param) == "" {
+						t.Errorf("URL has no %s: %s", param, signed)
+					}
+				}
+				if signedHeaders := strings.Split(query.Get("X-Amz-SignedHeaders"), ";"); !slices.Contains(signedHeaders, "content-type") {
+					t.Errorf("X-Amz-SignedHeaders = %v, want content-type signed", signedHeaders)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateDownloadURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		expiresIn   time.Duration
+		wantCode    string
+		wantExpires string
+	}{
+		{name: "one second", path: "docs/report.pdf", expiresIn: time.Second, wantExpires: "1"},
+		{name: "one hour", path: "docs/report.pdf", expiresIn: time.Hour, wantExpires: "3600"},
+		{name: "maximum expiry", path: "docs/report.pdf", expiresIn: MaxPresignExpiry, wantExpires: "604800"},
+		{name: "escaped key", path: "docs/my report+final #2 (ü).pdf", expiresIn: time.Minute, wantExpires: "60"},
+		{name: "expiry past the SigV4 limit", path: "docs/report.pdf", expiresIn: MaxPresignExpiry + time.Second, wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "zero expiry", path: "docs/report.pdf", wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "negative expiry", path: "docs/report.pdf", expiresIn: -time.Minute, wantCode: "INVALID_PRESIGN_REQUEST"},
+		{name: "missing path", expiresIn: time.Minute, wantCode: "INVALID_PRESIGN_REQUEST"},
+	}
+
+	c, fake := newFakeS3Client(t)
+	for _, tt := range tests {
+		if tt.path != "" {
+			fake.put(tt.path, []byte("content of "+tt.path), nil)
+		}
+	}
+	storages := map[string]PresignedURL{"RustFSClient": c, "MockRustFSClient": NewMockRustFSClient()}
+
+	for name, storage := range storages {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				signed, err := storage.GenerateDownloadURL(context.Background(), tt.path, tt.expiresIn)
+				if tt.wantCode != "" {
+					if errorCode(err) != tt.wantCode {
+						t.Fatalf("error = %v, want %s", err, tt.wantCode)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("GenerateDownloadURL: %v", err)
+				}
+
+				parsed, err := url.Parse(signed)
+				if err != nil {
+					t.Fatalf("parsing %q: %v", signed, err)
+				}
+				// The key is escaped in the URL, so reserved characters can't end the path early
+				if unescaped, _ := url.PathUnescape(parsed.EscapedPath()); !strings.HasSuffix(unescaped, "/"+tt.path) {
+					t.Errorf("URL path = %s, want it to end in %s", unescaped, tt.path)
+				}
+				if strings.ContainsAny(parsed.EscapedPath(), " #") {
+					t.Errorf("URL path %s is not escaped", parsed.EscapedPath())
+				}
+
+				query := parsed.Query()
+				if got := query.Get("X-Amz-Expires"); got != tt.wantExpires {
+					t.Errorf("X-Amz-Expires = %q, want %q", got, tt.wantExpires)
+				}
+				for _, param := range []string{"X-Amz-Signature", "X-Amz-Date"} {
+					if query.Get(param) == "" {
+						t.Errorf("URL has no %s: %s", param, signed)
+					}
+				}
+				if name != "RustFSClient" {
+					return
+				}
+
+				if query.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+					t.Errorf("X-Amz-Algorithm = %q, want AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+				}
+				if credential := query.Get("X-Amz-Credential"); !strings.HasPrefix(credential, "test-access/") {
+					t.Errorf("X-Amz-Credential = %q, want the access key", credential)
+				}
+
+				// The URL works on its own, without an Authorization header
+				resp, err := http.Get(signed)
+				if err != nil {
+					t.Fatalf("GET %s: %v", signed, err)
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				if resp.StatusCode != http.StatusOK || string(body) != "content of "+tt.path {
+					t.Errorf("GET presigned URL = %s %q, want the object", resp.Status, body)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateDownloadURLNormalizesPath(t *testing.T) {
+	c, _ := newFakeS3Client(t)
+	messy, err := c.GenerateDownloadURL(context.Background(), "/docs//report.pdf", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateDownloadURL: %v", err)
+	}
+	parsed, err := url.Parse(messy)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", messy, err)
+	}
+	if !strings.HasSuffix(parsed.Path, "/default/docs/report.pdf") {
+		t.Errorf("URL path = %s, want the normalized key", parsed.Path)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// ProgressInterval is how many bytes an upload transfers between progress callbacks
+const ProgressInterval = 256 * 1024
+
+// progressReporter turns byte counts into throttled ProgressCallback calls. Calls are
+// serialized, so the callback never runs concurrently with itself.
+type progressReporter struct {
+	callback ProgressCallback
+	total    int64
+	start    time.Time
+
+	mu          sync.Mutex
+	transferred int64
+	reported    int64
+}
+
+// newProgressReporter reports progress towards total bytes; a total of zero is unknown
+func newProgressReporter(callback ProgressCallback, total int64) *progressReporter {
+	return &progressReporter{callback: callback, total: total, start: time.Now()}
+}
+
+// add records n more bytes, reporting every ProgressInterval bytes and when the total is reached.
+// It is a utils.ProgressFunc.
+func (p *progressReporter) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.transferred += n
+	if p.transferred-p.reported >= ProgressInterval || (p.total > 0 && p.transferred >= p.total) {
+		p.report()
+	}
+}
+
+// finish reports the bytes transferred since the last callback, such as the tail of an upload
+// whose size was unknown
+func (p *progressReporter) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.transferred > p.reported {
+		p.report()
+	}
+}
+
+// report calls the callback with the current progress; the caller must hold p.mu
+func (p *progressReporter) report() {
+	p.reported = p.transferred
+	p.callback(utils.CalculateUploadProgress(p.transferred, p.total, p.start))
+}
@@ -0,0 +1,121 @@
+// Package prommetrics exposes RustFS client metrics to Prometheus.
+//
+// It lives in its own package so that only services importing it take on the
+// Prometheus dependency. Register a Collector and install it on a client:
+//
+//	collector := prommetrics.NewCollector("")
+//	prometheus.MustRegister(collector)
+//	rustfsClient.SetMetricsRecorder(collector)
+package prommetrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/client"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace prefixes metric names when NewCollector is given an empty namespace
+const DefaultNamespace = "rustfs"
+
+// UnknownErrorCode labels errors that carry no application error code
+const UnknownErrorCode = "unknown"
+
+// Collector is a prometheus.Collector fed through the client's metrics hooks. It implements
+// client.OperationMetricsRecorder and is safe for concurrent use.
+type Collector struct {
+	uploads          prometheus.Counter
+	errors           *prometheus.CounterVec
+	duration         *prometheus.HistogramVec
+	bytesTransferred *prometheus.HistogramVec
+	inflight         *prometheus.GaugeVec
+}
+
+// NewCollector creates a collector whose metric names start with namespace, or with
+// DefaultNamespace when it is empty
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	return &Collector{
+		uploads: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uploads_total",
+			Help:      "Number of uploads attempted, successful or not.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of failed operations by operation and error code.",
+		}, []string{"operation", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of uploads, deletes and download requests until the stream opens.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		bytesTransferred: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "bytes_transferred",
+			Help:      "Bytes moved per transferred chunk; the _sum series is the byte total.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+		}, []string{"operation"}),
+		inflight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "inflight_requests",
+			Help:      "Number of operations in progress.",
+		}, []string{"operation"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.uploads.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.bytesTransferred.Describe(ch)
+	c.inflight.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.uploads.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.bytesTransferred.Collect(ch)
+	c.inflight.Collect(ch)
+}
+
+// AddBytes implements client.MetricsRecorder
+func (c *Collector) AddBytes(operation string, n int64) {
+	c.bytesTransferred.WithLabelValues(operation).Observe(float64(n))
+}
+
+// OperationStarted implements client.OperationMetricsRecorder
+func (c *Collector) OperationStarted(operation string) {
+	c.inflight.WithLabelValues(operation).Inc()
+	if operation == client.OperationUpload {
+		c.uploads.Inc()
+	}
+}
+
+// OperationFinished implements client.OperationMetricsRecorder
+func (c *Collector) OperationFinished(operation string, duration time.Duration, err error) {
+	c.inflight.WithLabelValues(operation).Dec()
+	c.duration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		c.errors.WithLabelValues(operation, errorCode(err)).Inc()
+	}
+}
+
+// errorCode returns the application error code carried by err, such as FILE_NOT_FOUND
+func errorCode(err error) string {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) && appErr.Message != "" {
+		return appErr.Message
+	}
+	return UnknownErrorCode
+}
@@ -0,0 +1,149 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/client"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// uploadRequest returns a request uploading content to path
+func uploadRequest(path, content string) *types.UploadRequest {
+	return &types.UploadRequest{
+		File:        strings.NewReader(content),
+		FileSize:    int64(len(content)),
+		ContentType: "text/plain",
+		BucketPath:  path,
+	}
+}
+
+func TestCollectorRecordsOperations(t *testing.T) {
+	collector := NewCollector("")
+	m := client.NewMockRustFSClient()
+	m.SetMetricsRecorder(collector)
+	ctx := context.Background()
+
+	for _, path := range []string{"a.txt", "b.txt"} {
+		if _, err := m.UploadFile(ctx, uploadRequest(path, "0123456789")); err != nil {
+			t.Fatalf("UploadFile(%s): %v", path, err)
+		}
+	}
+	// The mock reports a missing file without an application error code
+	if _, _, err := m.DownloadFile(ctx, "missing.txt"); err == nil {
+		t.Fatal("DownloadFile of a missing file succeeded")
+	}
+
+	expected := `
+# HELP rustfs_uploads_total Number of uploads attempted, successful or not.
+# TYPE rustfs_uploads_total counter
+rustfs_uploads_total 2
+# HELP rustfs_errors_total Number of failed operations by operation and error code.
+# TYPE rustfs_errors_total counter
+rustfs_errors_total{code="unknown",operation="download"} 1
+# HELP rustfs_inflight_requests Number of operations in progress.
+# TYPE rustfs_inflight_requests gauge
+rustfs_inflight_requests{operation="download"} 0
+rustfs_inflight_requests{operation="upload"} 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"rustfs_uploads_total", "rustfs_errors_total", "rustfs_inflight_requests"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Durations vary, so only the number of observations is compared
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	histograms := map[string]struct {
+		count uint64
+		sum   float64
+	}{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if h := metric.GetHistogram(); h != nil {
+				key := family.GetName() + "/" + metric.GetLabel()[0].GetValue()
+				histograms[key] = struct {
+					count uint64
+					sum   float64
+				}{h.GetSampleCount(), h.GetSampleSum()}
+			}
+		}
+	}
+	if got := histograms["rustfs_operation_duration_seconds/upload"].count; got != 2 {
+		t.Errorf("upload durations observed = %d, want 2", got)
+	}
+	if got := histograms["rustfs_operation_duration_seconds/download"].count; got != 1 {
+		t.Errorf("download durations observed = %d, want 1", got)
+	}
+	if got := histograms["rustfs_bytes_transferred/upload"].sum; got != 20 {
+		t.Errorf("upload bytes = %v, want 20", got)
+	}
+
+	if problems, err := testutil.CollectAndLint(collector); err != nil || len(problems) > 0 {
+		t.Errorf("lint: %v, %v", problems, err)
+	}
+}
+
+func TestCollectorInflight(t *testing.T) {
+	collector := NewCollector("media")
+	m := client.NewMockRustFSClient()
+	m.SetMetricsRecorder(collector)
+	m.SetLatency(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.UploadFile(ctx, uploadRequest("slow.txt", "content"))
+		done <- err
+	}()
+
+	inflight := collector.inflight.WithLabelValues(client.OperationUpload)
+	deadline := time.Now().Add(2 * time.Second)
+	for testutil.ToFloat64(inflight) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("upload never reported in flight")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err == nil {
+		t.Fatal("cancelled upload succeeded")
+	}
+	if got := testutil.ToFloat64(inflight); got != 0 {
+		t.Errorf("inflight after the upload finished = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(collector.errors.WithLabelValues(client.OperationUpload, UnknownErrorCode)); got != 1 {
+		t.Errorf("cancelled uploads counted as errors = %v, want 1", got)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"application error", apperror.NewAppError(404, "FILE_NOT_FOUND", client.ErrFileNotFound), "FILE_NOT_FOUND"},
+		{"wrapped application error", fmt.Errorf("download: %w", apperror.NewAppError(503, "SLOW_DOWN", errors.New("throttled"))), "SLOW_DOWN"},
+		{"plain error", client.ErrFileNotFound, UnknownErrorCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// validateRange checks the inclusive byte window requested from DownloadRange
+func validateRange(start, end int64) error {
+	if start < 0 || end < start {
+		return apperror.NewAppError(400, "INVALID_RANGE", fmt.Errorf("invalid byte range %d-%d", start, end))
+	}
+	return nil
+}
+
+// checkContentRange verifies that a Content-Range header covers the requested window and returns
+// the length of the window served. The server may cut the end short when it lies past the last
+// byte of the object.
+func checkContentRange(contentRange string, start, end int64) (int64, error) {
+	var gotStart, gotEnd int64
+	var total string
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%s", &gotStart, &gotEnd, &total); err != nil {
+		return 0, newSentinelError(502, "RANGE_MISMATCH", ErrRangeMismatch,
+			fmt.Errorf("malformed Content-Range %q", contentRange))
+	}
+
+	truncated := false
+	var size int64
+	if _, err := fmt.Sscanf(total, "%d", &size); err == nil {
+		truncated = end >= size && gotEnd == size-1
+	}
+	if gotStart != start || (gotEnd != end && !truncated) {
+		return 0, newSentinelError(502, "RANGE_MISMATCH", ErrRangeMismatch,
+			fmt.Errorf("requested bytes %d-%d, got %q", start, end, contentRange))
+	}
+	return gotEnd - gotStart + 1, nil
+}
+
+// DownloadRange streams the inclusive byte window start-end of a file. A window running past
+// the end of the object is cut short, like an HTTP range request. Servers ignoring the range
+// fail with ErrRangeIgnored and a window starting past the end with ErrRangeNotSatisfiable.
+// Encrypted objects can only be decrypted whole, so ranges of them aren't supported.
+func (c *RustFSClient) DownloadRange(ctx context.Context, path string, start, end int64) (io.ReadCloser, error) {
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+	path = c.objectKey(path)
+
+	// The operation context lives until the caller closes the body
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	finish := trackOperation(c.activity, c.metrics, OperationDownload)
+	body, err := c.downloadRange(ctx, path, start, end)
+	err = cancellationError(ctx, err)
+	finish(err)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	return &cancelOnClose{ReadCloser: body, cancel: done}, nil
+}
+
+func (c *RustFSClient) downloadRange(ctx context.Context, path string, start, end int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+	}
+
+	var output *s3.GetObjectOutput
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			if status, ok := utils.HTTPStatusFromError(err); (ok && status == http.StatusRequestedRangeNotSatisfiable) ||
+				apiErrorCode(err) == "InvalidRange" {
+				return newSentinelError(416, "RANGE_NOT_SATISFIABLE", ErrRangeNotSatisfiable, err)
+			}
+			if apiErrorCode(err) == "InvalidObjectState" {
+				return newSentinelError(409, "OBJECT_ARCHIVED", ErrObjectArchived, err)
+			}
+			return apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if isEncrypted(c.decodeMetadata(output.Metadata)) {
+		output.Body.Close()
+		return nil, newSentinelError(501, "UNSUPPORTED_OPERATION", ErrUnsupportedOperation,
+			fmt.Errorf("range downloads of encrypted objects"))
+	}
+
+	// A 200 response carries the whole object and no Content-Range
+	contentRange := aws.ToString(output.ContentRange)
+	if contentRange == "" {
+		output.Body.Close()
+		return nil, newSentinelError(502, "RANGE_IGNORED", ErrRangeIgnored,
+			fmt.Errorf("server returned the whole object for bytes %d-%d", start, end))
+	}
+	length, err := checkContentRange(contentRange, start, end)
+	if err != nil {
+		output.Body.Close()
+		return nil, err
+	}
+
+	// A connection cut mid-window must not read as a clean end of the range
+	body := utils.NewLengthCheckingReadCloser(output.Body, length)
+	return utils.NewCountingReadCloser(body, transferProgress(ctx, c.bandwidth, OperationDownload, c.metrics, c.activity)), nil
+}
+
+// DownloadRange returns the inclusive byte window start-end of a file in mock storage
+func (m *MockRustFSClient) DownloadRange(ctx context.Context, path string, start, end int64) (io.ReadCloser, error) {
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	if err := m.simulateDelay(ctx, 0); err != nil {
+		return nil, err
+	}
+
+	fileInfo, exists := m.files[path]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+	if isEncrypted(fileInfo.Metadata) {
+		return nil, newSentinelError(501, "UNSUPPORTED_OPERATION", ErrUnsupportedOperation,
+			fmt.Errorf("range downloads of encrypted objects"))
+	}
+
+	content := m.contents[path]
+	if start >= int64(len(content)) {
+		return nil, newSentinelError(416, "RANGE_NOT_SATISFIABLE", ErrRangeNotSatisfiable,
+			fmt.Errorf("bytes %d-%d of a %d byte object", start, end, len(content)))
+	}
+	if end >= int64(len(content)) {
+		end = int64(len(content)) - 1
+	}
+
+	window := append([]byte(nil), content[start:end+1]...)
+	return io.NopCloser(bytes.NewReader(window)), nil
+}
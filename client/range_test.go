@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCheckContentRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentRange string
+		start, end   int64
+		wantLength   int64
+		wantErr      error
+	}{
+		{"exact window", "bytes 10-19/100", 10, 19, 10, nil},
+		{"end cut at the object size", "bytes 90-99/100", 90, 150, 10, nil},
+		{"unknown total", "bytes 0-9/*", 0, 9, 10, nil},
+		{"different start", "bytes 5-19/100", 10, 19, 0, ErrRangeMismatch},
+		{"short end inside the object", "bytes 10-15/100", 10, 19, 0, ErrRangeMismatch},
+		{"malformed", "items 0-9", 0, 9, 0, ErrRangeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, err := checkContentRange(tt.contentRange, tt.start, tt.end)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if length != tt.wantLength {
+				t.Errorf("length = %d, want %d", length, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+
+	tests := []struct {
+		name        string
+		start, end  int64
+		intercept   func(w http.ResponseWriter, r *http.Request) bool
+		want        string
+		wantErr     error
+		wantReadErr error
+	}{
+		{name: "window", start: 2, end: 5, want: "2345"},
+		{name: "window past the end", start: 15, end: 100, want: "fghij"},
+		{name: "start past the end", start: 50, end: 60, wantErr: ErrRangeNotSatisfiable},
+		{
+			name: "server ignores the range", start: 2, end: 5, wantErr: ErrRangeIgnored,
+			intercept: func(w http.ResponseWriter, r *http.Request) bool {
+				w.Write(content)
+				return true
+			},
+		},
+		{
+			name: "connection cut mid-window", start: 0, end: 9, wantReadErr: io.ErrUnexpectedEOF,
+			intercept: func(w http.ResponseWriter, r *http.Request) bool {
+				// Without a Content-Length the short body ends like a complete one
+				w.Header().Set("Content-Range", "bytes 0-9/20")
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write(content[:4])
+				w.(http.Flusher).Flush()
+				return true
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			fake.put("doc.txt", content, nil)
+			if tt.intercept != nil {
+				fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+					return r.Method == http.MethodGet && r.URL.Path == "/"+fake.bucket+"/doc.txt" && tt.intercept(w, r)
+				}
+			}
+
+			body, err := c.DownloadRange(context.Background(), "doc.txt", tt.start, tt.end)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DownloadRange error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer body.Close()
+
+			data, err := io.ReadAll(body)
+			if !errors.Is(err, tt.wantReadErr) {
+				t.Fatalf("read error = %v, want %v", err, tt.wantReadErr)
+			}
+			if err == nil && string(data) != tt.want {
+				t.Errorf("data = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockDownloadRange(t *testing.T) {
+	m := NewMockRustFSClient()
+	if _, err := m.UploadFile(context.Background(), uploadRequest("doc.txt", "0123456789")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	tests := []struct {
+		start, end int64
+		want       string
+		wantErr    error
+	}{
+		{0, 3, "0123", nil},
+		{8, 20, "89", nil},
+		{10, 12, "", ErrRangeNotSatisfiable},
+	}
+	for _, tt := range tests {
+		body, err := m.DownloadRange(context.Background(), "doc.txt", tt.start, tt.end)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("DownloadRange(%d, %d) error = %v, want %v", tt.start, tt.end, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(body)
+		if string(data) != tt.want {
+			t.Errorf("DownloadRange(%d, %d) = %q, want %q", tt.start, tt.end, data, tt.want)
+		}
+	}
+}
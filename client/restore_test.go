@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestMockArchiveRestoreDownload(t *testing.T) {
+	m := NewMockRustFSClient()
+	ctx := context.Background()
+
+	req := uploadRequest("archive/report.txt", "quarterly")
+	req.StorageClass = types.StorageClassGlacier
+	if _, err := m.UploadFile(ctx, req); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	if _, _, err := m.DownloadFile(ctx, "archive/report.txt"); !errors.Is(err, ErrObjectArchived) {
+		t.Fatalf("DownloadFile before restore = %v, want ErrObjectArchived", err)
+	}
+
+	if err := m.RestoreObject(ctx, "archive/report.txt", 1); err != nil {
+		t.Fatalf("RestoreObject: %v", err)
+	}
+	body, _, err := m.DownloadFile(ctx, "archive/report.txt")
+	if err != nil {
+		t.Fatalf("DownloadFile after restore: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "quarterly" {
+		t.Errorf("downloaded %q, want the archived content", data)
+	}
+
+	if err := m.RestoreObject(ctx, "archive/missing.txt", 1); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("RestoreObject of a missing file = %v, want ErrFileNotFound", err)
+	}
+}
+
+func TestRestoreObject(t *testing.T) {
+	t.Run("archived object is readable after a retried restore", func(t *testing.T) {
+		c, fake := newFakeS3Client(t)
+		fake.put("archive/report.txt", []byte("quarterly"), nil)
+
+		var mu sync.Mutex
+		restored, restoreCalls := false, 0
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case r.Method == http.MethodPost && r.URL.Query().Has("restore"):
+				restoreCalls++
+				if restoreCalls == 1 {
+					writeS3Error(w, http.StatusServiceUnavailable, "SlowDown")
+					return true
+				}
+				restored = true
+				w.WriteHeader(http.StatusAccepted)
+				return true
+			case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/archive/report.txt") && !restored:
+				writeS3Error(w, http.StatusForbidden, "InvalidObjectState")
+				return true
+			}
+			return false
+		}
+
+		ctx := context.Background()
+		if _, _, err := c.DownloadFile(ctx, "archive/report.txt"); !errors.Is(err, ErrObjectArchived) {
+			t.Fatalf("DownloadFile before restore = %v, want ErrObjectArchived", err)
+		}
+		if err := c.RestoreObject(ctx, "archive/report.txt", 1); err != nil {
+			t.Fatalf("RestoreObject: %v", err)
+		}
+		if restoreCalls != 2 {
+			t.Errorf("restore was sent %d times, want a retry after the transient failure", restoreCalls)
+		}
+
+		body, _, err := c.DownloadFile(ctx, "archive/report.txt")
+		if err != nil {
+			t.Fatalf("DownloadFile after restore: %v", err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		if string(data) != "quarterly" {
+			t.Errorf("downloaded %q, want the archived content", data)
+		}
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		c, fake := newFakeS3Client(t)
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method == http.MethodPost && r.URL.Query().Has("restore") {
+				writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+				return true
+			}
+			return false
+		}
+
+		err := c.RestoreObject(context.Background(), "archive/missing.txt", 1)
+		if !errors.Is(err, ErrFileNotFound) || errorCode(err) != "FILE_NOT_FOUND" {
+			t.Errorf("RestoreObject = %v, want FILE_NOT_FOUND", err)
+		}
+	})
+
+	t.Run("restore capability disabled", func(t *testing.T) {
+		c, fake := newFakeS3Client(t)
+		restoreCalls := 0
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			switch {
+			case r.URL.Path == apiPrefix+"capabilities":
+				json.NewEncoder(w).Encode(capabilitiesResponse{Capabilities: []string{CapabilityMultipart}})
+				return true
+			case r.URL.Query().Has("restore"):
+				restoreCalls++
+			}
+			return false
+		}
+
+		err := c.RestoreObject(context.Background(), "archive/report.txt", 1)
+		if !errors.Is(err, ErrUnsupportedOperation) {
+			t.Errorf("RestoreObject = %v, want ErrUnsupportedOperation", err)
+		}
+		if restoreCalls != 0 {
+			t.Errorf("restore was sent %d times without the capability", restoreCalls)
+		}
+	})
+}
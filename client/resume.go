@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// downloadBody guards a GetObject body against truncation. Reading fails with
+// io.ErrUnexpectedEOF when fewer bytes arrive than the response advertised. When resumes are
+// configured and the object has an ETag, a broken stream is first continued with a Range
+// request pinned to that ETag, so the caller never sees bytes of a different version.
+func (c *RustFSClient) downloadBody(ctx context.Context, input *s3.GetObjectInput, output *s3.GetObjectOutput) io.ReadCloser {
+	size := int64(-1)
+	if output.ContentLength != nil {
+		size = *output.ContentLength
+	}
+	body := utils.NewLengthCheckingReadCloser(output.Body, size)
+
+	if c.config.DownloadResumeAttempts <= 0 || size < 0 || aws.ToString(output.ETag) == "" {
+		return body
+	}
+
+	resumeInput := *input
+	resumeInput.IfMatch = output.ETag
+	return &resumingBody{
+		ctx:     ctx,
+		client:  c.client,
+		input:   resumeInput,
+		body:    body,
+		size:    size,
+		resumes: c.config.DownloadResumeAttempts,
+	}
+}
+
+// resumingBody re-requests the rest of an object when its stream breaks midway
+type resumingBody struct {
+	ctx     context.Context
+	client  *s3.Client
+	input   s3.GetObjectInput
+	body    io.ReadCloser
+	offset  int64
+	size    int64
+	resumes int
+	// err is the read error that ended the stream once resuming failed
+	err error
+}
+
+// Read implements io.Reader. The original read error is returned when resuming fails.
+func (b *resumingBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	if err == nil || err == io.EOF || b.resumes == 0 || b.ctx.Err() != nil {
+		return n, err
+	}
+
+	if !b.resume() {
+		b.err = err
+		return n, err
+	}
+	if n == 0 {
+		return b.Read(p)
+	}
+	return n, nil
+}
+
+// resume replaces the broken body with one starting at the first byte not yet delivered and
+// reports whether that succeeded
+func (b *resumingBody) resume() bool {
+	b.resumes--
+
+	input := b.input
+	input.Range = aws.String(fmt.Sprintf("bytes=%d-", b.offset))
+	output, err := b.client.GetObject(b.ctx, &input)
+	if err != nil {
+		return false
+	}
+
+	b.body.Close()
+	b.body = utils.NewLengthCheckingReadCloser(output.Body, b.size-b.offset)
+	return true
+}
+
+// Close implements io.Closer
+func (b *resumingBody) Close() error {
+	return b.body.Close()
+}
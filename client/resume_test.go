@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// truncatingWriter passes on the headers of a response but only the first limit body bytes,
+// like a connection reset midway
+type truncatingWriter struct {
+	http.ResponseWriter
+	limit int
+}
+
+func (w *truncatingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+	w.limit -= len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func TestDownloadFileTruncation(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+
+	tests := []struct {
+		name      string
+		resumes   int
+		cuts      int  // number of leading GETs cut short after 8 bytes
+		replace   bool // replace the object after the first cut
+		want      string
+		wantErr   error
+		wantGets  int
+		wantRange string
+	}{
+		{name: "complete stream", want: string(content), wantGets: 1},
+		{name: "truncated without resumes", cuts: 1, wantErr: io.ErrUnexpectedEOF, wantGets: 1},
+		{name: "resumed after truncation", resumes: 1, cuts: 1, want: string(content), wantGets: 2, wantRange: "bytes=8-"},
+		{name: "truncated again after the last resume", resumes: 1, cuts: 2, wantErr: io.ErrUnexpectedEOF, wantGets: 2},
+		{name: "object replaced before the resume", resumes: 1, cuts: 1, replace: true, wantErr: io.ErrUnexpectedEOF, wantGets: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				cfg.DownloadResumeAttempts = tt.resumes
+			})
+			fake.put("doc.txt", content, nil)
+
+			gets, lastRange := 0, ""
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method != http.MethodGet || r.URL.Path != "/"+fake.bucket+"/doc.txt" {
+					return false
+				}
+				gets++
+				lastRange = r.Header.Get("Range")
+				if gets > tt.cuts {
+					return false
+				}
+
+				fake.mu.Lock()
+				fake.getObject(&truncatingWriter{ResponseWriter: w, limit: 8}, r, "doc.txt")
+				fake.mu.Unlock()
+				if tt.replace {
+					fake.put("doc.txt", []byte("a different version"), nil)
+				}
+				return true
+			}
+
+			body, _, err := c.DownloadFile(context.Background(), "doc.txt")
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			data, err := io.ReadAll(body)
+			body.Close()
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("read error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(data) != tt.want {
+				t.Errorf("data = %q, want %q", data, tt.want)
+			}
+			if err != nil && string(data) != string(content[:len(data)]) {
+				t.Errorf("partial data %q isn't a prefix of the original object", data)
+			}
+			if gets != tt.wantGets {
+				t.Errorf("GET requests = %d, want %d", gets, tt.wantGets)
+			}
+			if tt.wantRange != "" && lastRange != tt.wantRange {
+				t.Errorf("resume Range = %q, want %q", lastRange, tt.wantRange)
+			}
+		})
+	}
+}
@@ -3,14 +3,20 @@ package client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/garyjdn/go-apperror"
 	"github.com/garyjdn/go-rustfs/config"
 	"github.com/garyjdn/go-rustfs/types"
@@ -19,15 +25,79 @@ import (
 
 // RustFSClient implements the FileStorage interface using AWS SDK for Go v2
 type RustFSClient struct {
-	client *s3.Client
-	config *config.RustFSConfig
+	client     *s3.Client
+	config     *config.RustFSConfig
+	limiter    *utils.PrioritySemaphore
+	pingClient *http.Client
+	httpClient *http.Client
+	metrics    MetricsRecorder
+	bandwidth  *utils.BandwidthLimiter
+
+	capabilities    *capabilityCache
+	metadataEncoder MetadataEncoder
+	bodies          *bodyCache
+	activity        *activityCounters
+	downloadHooks   []DownloadHook
+	canceller       *canceller
+	keyValidator    utils.KeyValidator
+	balancer        *endpointBalancer
+	webhooks        *webhookRegistry
+}
+
+// NewRustFSClientE validates cfg and creates a new RustFS client, so missing endpoints,
+// credentials or bucket names fail at construction instead of on the first request
+func NewRustFSClientE(cfg *config.RustFSConfig) (*RustFSClient, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("invalid RustFS configuration: config is nil")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RustFS configuration: %w", err)
+	}
+
+	return NewRustFSClient(cfg), nil
 }
 
-// NewRustFSClient creates a new RustFS client
+// NewRustFSClient creates a new RustFS client without validating the configuration
 func NewRustFSClient(cfg *config.RustFSConfig) *RustFSClient {
+	return NewRustFSClientWithHTTPClient(cfg, nil)
+}
+
+// NewRustFSClientWithHTTPClient creates a new RustFS client that sends every request through
+// httpClient, e.g. to tune connection pooling or stub the transport in tests. The configuration
+// isn't validated, and ExpectContinueTimeout and the TLS settings are left to httpClient's
+// transport. A nil httpClient uses the default client of NewRustFSClient.
+func NewRustFSClientWithHTTPClient(cfg *config.RustFSConfig, httpClient *http.Client) *RustFSClient {
+	configureTransport := transportOptions(cfg)
+	// The transport waits ExpectContinueTimeout for the server's interim response before sending a body
+	defaultHTTPClient := awshttp.NewBuildableClient().WithTransportOptions(configureTransport, func(t *http.Transport) {
+		t.ExpectContinueTimeout = cfg.ExpectContinueTimeout
+	})
+	transport := newTransport(configureTransport)
+	apiClient := &http.Client{Transport: transport}
+	pingClient := &http.Client{
+		Transport: transport,
+		// Never follow redirects: any response proves the server process is up
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if httpClient != nil {
+		apiClient = httpClient
+		pingClient.Transport = httpClient.Transport
+	}
+
+	var balancer *endpointBalancer
+	if len(cfg.BaseURLs) > 0 {
+		balancer = newEndpointBalancer(cfg, func(ctx context.Context, endpoint string) error {
+			return pingURL(ctx, pingClient, endpoint, cfg.PingTimeout)
+		})
+	}
+
 	// Load AWS configuration
 	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
 		awsconfig.WithRegion(cfg.Region),
+		awsconfig.WithHTTPClient(defaultHTTPClient),
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKey,
 			cfg.SecretKey,
@@ -43,24 +113,234 @@ func NewRustFSClient(cfg *config.RustFSConfig) *RustFSClient {
 	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(cfg.BaseURL)
 		o.UsePathStyle = true // Required for MinIO/RustFS
+		o.ContinueHeaderThresholdBytes = expectContinueThreshold(cfg.ExpectContinueThreshold)
+		o.APIOptions = append(o.APIOptions, expectContinueProto)
+		// Set here rather than in the AWS config, which only accepts its own client type when
+		// loading a custom CA bundle
+		if httpClient != nil {
+			o.HTTPClient = httpClient
+		}
+		if usesBearerAuth(cfg) {
+			o.Credentials = aws.AnonymousCredentials{}
+			o.APIOptions = append(o.APIOptions, bearerAuth(cfg.APIKey))
+		}
+		if balancer != nil {
+			o.EndpointResolverV2 = balancer
+			o.HTTPClient = balancer.httpClient(o.HTTPClient)
+			o.APIOptions = append(o.APIOptions, balancer.addPickMiddleware)
+		}
 	})
 
-	return &RustFSClient{
-		client: client,
-		config: cfg,
+	c := &RustFSClient{
+		client:     client,
+		config:     cfg,
+		limiter:    utils.NewPrioritySemaphore(cfg.ConcurrentUploads),
+		bandwidth:  utils.NewBandwidthLimiter(cfg.BandwidthLimit),
+		pingClient: pingClient,
+		httpClient: apiClient,
+		activity:   newActivityCounters(),
+		canceller:  newCanceller(),
+		balancer:   balancer,
+		webhooks:   newWebhookRegistry(),
+	}
+	if c.metadataEncoder, err = newConfiguredMetadataEncoder(cfg); err != nil {
+		// Validate rejects unknown encodings; unvalidated configs keep the historical format
+		c.metadataEncoder = PerKeyMetadataEncoder{}
+	}
+	if cfg.CacheEnabled {
+		c.bodies = newBodyCache(cfg.BodyCacheMaxObjectSize, cfg.BodyCacheMaxBytes, cfg.CacheTTL)
+	}
+	c.capabilities = newCapabilityCache(c.fetchCapabilities, cfg.CapabilityRefresh,
+		cfg.EnabledCapabilities, cfg.DisabledCapabilities)
+
+	return c
+}
+
+// retryConfig returns the configured backoff, or one derived from RetryCount when none is set
+func (c *RustFSClient) retryConfig() *types.RetryConfig {
+	if c.config.RetryConfig != nil {
+		return c.config.RetryConfig
+	}
+	return utils.NewRetryConfigBuilder().
+		WithMaxAttempts(c.config.RetryCount + 1).
+		WithDelay(100 * time.Millisecond).
+		Build()
+}
+
+// withRetry runs fn with the configured backoff and returns its last error. Only errors that
+// utils.IsRetryableError accepts are retried; a 4xx response such as ErrFileNotFound ends the
+// retries at once, since repeating the request can't succeed.
+func (c *RustFSClient) withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	return c.withRetryConfig(ctx, c.retryConfig(), fn)
+}
+
+func (c *RustFSClient) withRetryConfig(ctx context.Context, retryConfig *types.RetryConfig, fn func(ctx context.Context) error) error {
+	result := utils.RetryRetryableWithContext(ctx, fn, retryConfig)
+
+	if result.Attempts > 1 {
+		c.activity.recordRetries(int64(result.Attempts - 1))
+	}
+	if !result.Success {
+		return result.LastError
+	}
+	return nil
+}
+
+// objectKey returns the storage key for a caller-supplied path, normalized unless disabled
+func (c *RustFSClient) objectKey(path string) string {
+	if !c.config.NormalizePaths {
+		return path
+	}
+	return utils.NormalizePath(path)
+}
+
+// uploadKey returns the storage key for an upload. Empty uploads to a path ending in a slash
+// are directory markers and keep their trailing slash.
+func (c *RustFSClient) uploadKey(req *types.UploadRequest) string {
+	if !c.config.NormalizePaths {
+		return req.BucketPath
+	}
+	if req.File == nil && strings.HasSuffix(req.BucketPath, utils.PathDelimiter) {
+		return utils.NormalizeDirectoryPath(req.BucketPath)
+	}
+	return utils.NormalizePath(req.BucketPath)
+}
+
+// closeUploadSource closes the request's source reader when the caller handed over ownership with CloseSource
+func closeUploadSource(req *types.UploadRequest) {
+	if req == nil || !req.CloseSource {
+		return
+	}
+	if closer, ok := req.File.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("[RUSTFS] failed to close upload source for %s: %v", req.BucketPath, err)
+		}
+	}
+}
+
+// expectContinueThreshold maps the configured threshold onto the SDK option, where -1 disables
+// Expect: 100-continue and 0 selects the SDK default
+func expectContinueThreshold(threshold int64) int64 {
+	if threshold <= 0 {
+		return -1
 	}
+	return threshold
+}
+
+// SetMetricsRecorder sets the recorder that receives incremental byte-transfer counts. A
+// recorder implementing OperationMetricsRecorder also sees each operation start and finish.
+func (c *RustFSClient) SetMetricsRecorder(recorder MetricsRecorder) {
+	c.metrics = recorder
+}
+
+// Summary reports the client's activity since it was created
+func (c *RustFSClient) Summary() ClientSummary {
+	return c.activity.summary()
+}
+
+// Close releases idle connections. The client remains usable; call Summary for an activity report.
+func (c *RustFSClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	c.pingClient.CloseIdleConnections()
+	return nil
+}
+
+// SetBandwidthLimit changes the aggregate transfer ceiling in bytes per second; zero disables it
+func (c *RustFSClient) SetBandwidthLimit(bytesPerSecond int64) {
+	c.bandwidth.SetRate(bytesPerSecond)
+}
+
+// acquireSlot waits for a concurrency slot honoring the priority carried by ctx
+func (c *RustFSClient) acquireSlot(ctx context.Context) (func(), error) {
+	if err := c.limiter.Acquire(ctx, int(PriorityFromContext(ctx))); err != nil {
+		return nil, apperror.NewAppError(503, "CONCURRENCY_WAIT_CANCELLED", err)
+	}
+	return c.limiter.Release, nil
 }
 
 // UploadFile uploads a file to RustFS
 func (c *RustFSClient) UploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	defer closeUploadSource(req)
+
+	if key := c.uploadKey(req); key != req.BucketPath {
+		normalized := *req
+		normalized.BucketPath = key
+		req = &normalized
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	finish := trackOperation(c.activity, c.metrics, OperationUpload)
+	response, err := c.uploadFile(ctx, req)
+	err = cancellationError(ctx, err)
+	finish(err)
+	return response, err
+}
+
+func (c *RustFSClient) uploadFile(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+	if err := validateObjectKey(c.keyValidator, req.BucketPath); err != nil {
+		return nil, err
+	}
+	if err := validateUploadHeaders(req); err != nil {
+		return nil, err
+	}
+
+	// A source of unknown size, such as a compressed stream, is buffered up to one part; a longer
+	// one streams through a multipart upload rather than being held in memory whole
+	if req.FileSize == 0 && req.File != nil && !c.config.EnableEncryption && c.capabilities.supports(ctx, CapabilityMultipart) {
+		buffered, multipart, err := c.bufferFirstPart(req)
+		if err != nil {
+			return nil, err
+		}
+		if multipart {
+			return c.uploadMultipart(ctx, buffered)
+		}
+		req = buffered
+	}
+
+	// A source that can't be rewound and is too large to buffer streams in parts instead, each
+	// buffered and retried on its own
+	if _, rewindable := req.File.(io.Seeker); !rewindable && req.File != nil && req.FileSize > int64(c.config.ChunkSize) &&
+		!c.config.EnableEncryption && c.capabilities.supports(ctx, CapabilityMultipart) {
+		return c.uploadMultipart(ctx, req)
+	}
+
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Create a buffer to store the file content if it's not already a Seekable reader
 	// S3 PutObject requires a ReadSeeker for optimal performance and to calculate content length automatically
 	var body io.Reader = req.File
 	var size int64 = req.FileSize
 
+	// Encrypted content replaces the source, so the checksum below covers the stored bytes
+	requestMetadata := req.Metadata
+	if c.config.EnableEncryption {
+		key, err := c.config.EncryptionKeyBytes()
+		if err != nil {
+			return nil, apperror.NewAppError(500, "ENCRYPTION_FAILED", err)
+		}
+		sealed, nonce, err := encryptObject(key, req.File)
+		if err != nil {
+			return nil, apperror.NewAppError(500, "ENCRYPTION_FAILED", err)
+		}
+		body, size = bytes.NewReader(sealed), int64(len(sealed))
+		requestMetadata = withEncryptionMetadata(req.Metadata, c.config.EncryptionKeyID, nonce)
+	}
+
 	// If size is unknown (0) or we need to ensure we can read it, read into buffer
-	// optimized: if it's already bytes.Reader, we can use it directly, but here we keep it safe
-	if size == 0 {
+	// optimized: if it's already bytes.Reader, we can use it directly, but here we keep it safe.
+	// A source that can't be rewound is also buffered up to ChunkSize, so a retry resends the
+	// whole payload. Larger ones only get here without multipart support and are sent once.
+	_, rewindable := body.(io.Seeker)
+	if !c.config.EnableEncryption && (size == 0 || (!rewindable && size <= int64(c.config.ChunkSize))) {
 		buf := new(bytes.Buffer)
 		n, err := io.Copy(buf, req.File)
 		if err != nil {
@@ -70,17 +350,33 @@ func (c *RustFSClient) UploadFile(ctx context.Context, req *types.UploadRequest)
 		size = n
 	}
 
+	// Compute checksum of the content if enabled
+	var checksum string
+	if c.config.ChecksumAlgorithm != "" {
+		checksumBody, sum, err := c.checksumBody(body)
+		if err != nil {
+			return nil, apperror.NewAppError(500, "FILE_READ_ERROR", err)
+		}
+		body = checksumBody
+		checksum = sum
+	}
+
+	body = utils.WrapCountingReader(body, transferProgress(ctx, c.bandwidth, OperationUpload, c.metrics, c.activity))
+
 	contentType := "application/octet-stream"
 	if req.ContentType != "" {
 		contentType = req.ContentType
 	}
 
 	// Prepare metadata
-	metadata := make(map[string]string)
-	if req.Metadata != nil {
-		for k, v := range req.Metadata {
-			metadata[k] = fmt.Sprintf("%v", v)
-		}
+	withDefaults, defaultKeys := withDefaultMetadata(requestMetadata, c.config.DefaultMetadata)
+	requestMetadata, sideObjects, err := c.limitMetadataValues(req.BucketPath, withSchemaVersion(withDefaults, c.config.MetadataSchemaVersion))
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := c.metadataEncoder.Encode(requestMetadata)
+	if err != nil {
+		return nil, apperror.NewAppError(400, "INVALID_METADATA", err)
 	}
 
 	// Create PutObject input
@@ -91,42 +387,214 @@ func (c *RustFSClient) UploadFile(ctx context.Context, req *types.UploadRequest)
 		ContentType: aws.String(contentType),
 		Metadata:    metadata,
 	}
+	if req.StorageClass != "" {
+		input.StorageClass = s3types.StorageClass(req.StorageClass)
+	}
+	if req.CacheControl != "" {
+		input.CacheControl = aws.String(req.CacheControl)
+	}
+	if req.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(req.ContentDisposition)
+	}
+	if req.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(req.ContentEncoding)
+	}
+	if req.IfNotExists {
+		input.IfNoneMatch = aws.String("*")
+	}
 
-	// Upload to S3
-	_, err := c.client.PutObject(ctx, input)
+	// Upload to S3. Each attempt rewinds the body to where it started; a body that still can't
+	// be rewound is sent once rather than retried with whatever is left of it.
+	retryConfig := c.retryConfig()
+	var start int64
+	seeker, seekable := body.(io.Seeker)
+	if seekable {
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+	if !seekable {
+		retryConfig = &types.RetryConfig{MaxAttempts: 1}
+	}
+	attempted := false
+	var output *s3.PutObjectOutput
+	err = c.withRetryConfig(ctx, retryConfig, func(ctx context.Context) error {
+		if attempted {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return apperror.NewAppError(500, "FILE_READ_ERROR", err)
+			}
+		}
+		attempted = true
+		var err error
+		output, err = c.client.PutObject(ctx, input)
+		if err != nil {
+			return conditionalUploadError(err, "UPLOAD_FAILED")
+		}
+		return nil
+	})
+	c.bodies.invalidate(req.BucketPath)
 	if err != nil {
-		return nil, apperror.NewAppError(500, "UPLOAD_FAILED", err)
+		return nil, err
+	}
+	if err := c.syncSideObjects(ctx, req.BucketPath, sideObjects); err != nil {
+		return nil, err
 	}
 
-	// Return response
-	return &types.UploadResponse{
+	response := &types.UploadResponse{
 		Path:         req.BucketPath,
 		URL:          c.GetFileURL(req.BucketPath),
 		Size:         size,
 		ContentType:  contentType,
-		ETag:         "", // AWS SDK doesn't always return ETag in output struct easily without pointers
+		ETag:         aws.ToString(output.ETag),
 		LastModified: time.Now(),
-		Metadata:     req.Metadata,
-	}, nil
+		Metadata:     requestMetadata,
+		StorageClass: req.StorageClass,
+
+		DefaultMetadataKeys: defaultKeys,
+		ContentEncoding:     req.ContentEncoding,
+	}
+
+	if checksum != "" {
+		response.Checksum = checksum
+		response.ChecksumAlgorithm = strings.ToLower(c.config.ChecksumAlgorithm)
+	} else if output.ChecksumSHA256 != nil {
+		// Echo the server-computed checksum when no local one was requested
+		response.Checksum = aws.ToString(output.ChecksumSHA256)
+		response.ChecksumAlgorithm = "sha256"
+	}
+	// Report the plaintext size of encrypted objects, like GetFileInfo
+	if c.config.EnableEncryption {
+		response.Size = decryptedSize(size)
+	}
+
+	return response, nil
+}
+
+// bufferFirstPart reads up to one part of an upload of unknown size. A source ending within it
+// is returned as a request of known size; otherwise the returned request streams the buffered
+// part followed by the rest of the source, and multipart is true.
+func (c *RustFSClient) bufferFirstPart(req *types.UploadRequest) (*types.UploadRequest, bool, error) {
+	partSize := c.config.PartSize()
+	head := new(bytes.Buffer)
+	n, err := io.CopyN(head, req.File, partSize+1)
+	if err != nil && err != io.EOF {
+		return nil, false, apperror.NewAppError(500, "FILE_READ_ERROR", err)
+	}
+
+	buffered := *req
+	if n <= partSize {
+		buffered.File, buffered.FileSize = bytes.NewReader(head.Bytes()), n
+		return &buffered, false, nil
+	}
+	buffered.File = io.MultiReader(bytes.NewReader(head.Bytes()), req.File)
+	return &buffered, true, nil
+}
+
+// validateUploadHeaders checks the HTTP header values an upload request stores with the object
+func validateUploadHeaders(req *types.UploadRequest) error {
+	if req.CacheControl != "" {
+		if err := utils.ValidateCacheControl(req.CacheControl); err != nil {
+			return apperror.NewAppError(400, "INVALID_CACHE_CONTROL", err)
+		}
+	}
+	if req.ContentDisposition != "" {
+		if err := utils.ValidateContentDisposition(req.ContentDisposition); err != nil {
+			return apperror.NewAppError(400, "INVALID_CONTENT_DISPOSITION", err)
+		}
+	}
+	return nil
+}
+
+// checksumBody computes the configured checksum of body and returns a reader positioned at its start
+func (c *RustFSClient) checksumBody(body io.Reader) (io.Reader, string, error) {
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, "", err
+		}
+
+		checksumReader, err := utils.NewChecksumReader(seeker, c.config.ChecksumAlgorithm)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(io.Discard, checksumReader); err != nil {
+			return nil, "", err
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, "", err
+		}
+		return seeker, checksumReader.Sum(), nil
+	}
+
+	// Non-seekable source: buffer while digesting
+	checksumReader, err := utils.NewChecksumReader(body, c.config.ChecksumAlgorithm)
+	if err != nil {
+		return nil, "", err
+	}
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, checksumReader); err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(buf.Bytes()), checksumReader.Sum(), nil
 }
 
 // DeleteFile deletes a file from RustFS
 func (c *RustFSClient) DeleteFile(ctx context.Context, path string) error {
+	path = c.objectKey(path)
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	finish := trackOperation(c.activity, c.metrics, OperationDelete)
+	err = c.deleteFile(ctx, path)
+	if err == nil && c.usesSideObjects() {
+		err = c.deleteSideObjects(ctx, path, nil)
+	}
+	err = cancellationError(ctx, err)
+	finish(err)
+	return err
+}
+
+func (c *RustFSClient) deleteFile(ctx context.Context, path string) error {
 	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(c.config.BucketName),
 		Key:    aws.String(path),
 	}
 
-	_, err := c.client.DeleteObject(ctx, input)
-	if err != nil {
-		return apperror.NewAppError(500, "DELETE_FAILED", err)
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		if _, err := c.client.DeleteObject(ctx, input); err != nil {
+			return apperror.NewAppError(500, "DELETE_FAILED", err)
+		}
+		return nil
+	})
+	c.bodies.invalidate(path)
+	return err
+}
+
+// DeleteFileResult deletes a file and reports whether an object actually existed and was removed.
+// Deleting an absent file returns false with a nil error. S3 acknowledges a delete the same way
+// whether or not the key existed, so existence is checked with a HEAD before the DELETE; a file
+// deleted concurrently between the two requests can be reported as removed by both callers.
+func (c *RustFSClient) DeleteFileResult(ctx context.Context, path string) (bool, error) {
+	path = c.objectKey(path)
+	exists, err := c.FileExists(ctx, path)
+	if err != nil || !exists {
+		return false, err
 	}
 
-	return nil
+	if err := c.DeleteFile(ctx, path); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 // GetFileURL returns the public URL for a file
 func (c *RustFSClient) GetFileURL(path string) string {
+	path = c.objectKey(path)
 	// Construct URL manually as S3 doesn't return it directly
 	// Format: BaseURL/BucketName/Path
 	baseURL := c.config.BaseURL
@@ -134,34 +602,95 @@ func (c *RustFSClient) GetFileURL(path string) string {
 	if len(baseURL) > 0 && baseURL[len(baseURL)-1] == '/' {
 		baseURL = baseURL[:len(baseURL)-1]
 	}
-	return fmt.Sprintf("%s/%s/%s", baseURL, c.config.BucketName, path)
+	return fmt.Sprintf("%s/%s/%s", baseURL, c.config.BucketName, utils.EscapePath(path))
 }
 
 // GetFileInfo retrieves file information from RustFS
 func (c *RustFSClient) GetFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	key := c.objectKey(path)
+	info, err := c.getFileInfo(ctx, key)
+	if err != nil {
+		info, err = c.statDirectory(ctx, key, err)
+	}
+	return info, cancellationError(ctx, err)
+}
+
+// FileExists reports whether an object is stored at path using a single HEAD request, cheaper
+// than GetFileInfo since nothing is decoded. Only failures other than a missing object are
+// returned as errors; directories synthesized by GetFileInfo don't count as existing.
+func (c *RustFSClient) FileExists(ctx context.Context, path string) (bool, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(c.objectKey(path)),
+	}
+	err = c.withRetry(ctx, func(ctx context.Context) error {
+		_, err := c.client.HeadObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "GET_INFO_FAILED", err)
+		}
+		return nil
+	})
+	if errors.Is(err, ErrFileNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, cancellationError(ctx, err)
+	}
+	return true, nil
+}
+
+func (c *RustFSClient) getFileInfo(ctx context.Context, path string) (*types.FileInfo, error) {
 	input := &s3.HeadObjectInput{
 		Bucket: aws.String(c.config.BucketName),
 		Key:    aws.String(path),
 	}
 
-	output, err := c.client.HeadObject(ctx, input)
+	var output *s3.HeadObjectOutput
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = c.client.HeadObject(ctx, input)
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "GET_INFO_FAILED", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, apperror.NewAppError(404, "FILE_NOT_FOUND", err)
+		return nil, err
 	}
 
-	metadata := make(map[string]interface{})
-	for k, v := range output.Metadata {
-		metadata[k] = v
+	metadata, err := c.objectMetadata(ctx, output.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	info := buildFileInfo(path, output.ContentLength, output.ContentType, output.ETag, output.LastModified,
+		metadata, output.StorageClass, output.Restore)
+	info.CacheControl = aws.ToString(output.CacheControl)
+	info.ContentDisposition = aws.ToString(output.ContentDisposition)
+	info.ContentEncoding = aws.ToString(output.ContentEncoding)
+	// Report the plaintext size of encrypted objects, like DownloadFile
+	if isEncrypted(metadata) {
+		info.Size = decryptedSize(info.Size)
 	}
 
-	return &types.FileInfo{
-		Path:         path,
-		Size:         *output.ContentLength,
-		ContentType:  *output.ContentType,
-		ETag:         *output.ETag,
-		LastModified: *output.LastModified,
-		Metadata:     metadata,
-	}, nil
+	return info, nil
 }
 
 // UploadSnapshot uploads a snapshot (specific implementation for interface compliance)
@@ -228,3 +757,198 @@ func (c *RustFSClient) CheckHealth(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Ping performs a lightweight liveness check: a single HEAD request to BaseURL with a short timeout.
+// Any HTTP response, regardless of status, means the server process is up. Use CheckHealth for readiness.
+func (c *RustFSClient) Ping(ctx context.Context) error {
+	return pingURL(ctx, c.pingClient, c.config.BaseURL, c.config.PingTimeout)
+}
+
+// pingURL sends a HEAD request to target, succeeding on any HTTP response
+func pingURL(ctx context.Context, client *http.Client, target string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// ListFiles lists files under prefix using the configured prefix matching mode
+func (c *RustFSClient) ListFiles(ctx context.Context, prefix string, limit int) ([]*types.FileInfo, error) {
+	return c.ListFilesWithOptions(ctx, &ListOptions{
+		Prefix:        prefix,
+		Limit:         limit,
+		DirectoryMode: c.config.ListDirectoryMode,
+	})
+}
+
+// ListFilesWithOptions lists files matching the given options
+func (c *RustFSClient) ListFilesWithOptions(ctx context.Context, opts *ListOptions) ([]*types.FileInfo, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	files := make([]*types.FileInfo, 0)
+	errStop := errors.New("limit reached")
+	err = c.walkFiles(ctx, opts, func(file *types.FileInfo) error {
+		files = append(files, file)
+		if len(files) >= limit {
+			return errStop
+		}
+		return nil
+	})
+	if err != nil && err != errStop {
+		return nil, cancellationError(ctx, err)
+	}
+
+	return files, nil
+}
+
+// WalkFiles visits every file matching opts, ignoring opts.Limit, paging through the
+// listing until it is exhausted or fn returns an error
+func (c *RustFSClient) WalkFiles(ctx context.Context, opts *ListOptions, fn func(file *types.FileInfo) error) error {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return cancellationError(ctx, c.walkFiles(ctx, opts, fn))
+}
+
+func (c *RustFSClient) walkFiles(ctx context.Context, opts *ListOptions, fn func(file *types.FileInfo) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(c.config.BucketName),
+		MaxKeys: aws.Int32(DefaultPageSize),
+	}
+	if opts.Prefix != "" {
+		input.Prefix = aws.String(opts.Prefix)
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(c.client, input)
+	for paginator.HasMorePages() {
+		var page *s3.ListObjectsV2Output
+		err := c.withRetry(ctx, func(ctx context.Context) error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			if err != nil {
+				return apperror.NewAppError(500, "LIST_FAILED", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		files := make([]*types.FileInfo, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if !utils.MatchPrefix(key, opts.Prefix, opts.DirectoryMode) {
+				continue
+			}
+
+			files = append(files, &types.FileInfo{
+				Path:         key,
+				Size:         aws.ToInt64(object.Size),
+				ETag:         aws.ToString(object.ETag),
+				LastModified: aws.ToTime(object.LastModified),
+				StorageClass: string(object.StorageClass),
+			})
+		}
+
+		if opts.IncludeMetadata {
+			if files, err = c.enrichFileInfos(ctx, files); err != nil {
+				return err
+			}
+		}
+
+		for _, file := range files {
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// enrichFileInfos fills in metadata and content type for listed files, which the S3 listing
+// doesn't return inline. Lookups run concurrently, bounded by the configured concurrency
+// limit. Files deleted since they were listed are dropped.
+func (c *RustFSClient) enrichFileInfos(ctx context.Context, files []*types.FileInfo) ([]*types.FileInfo, error) {
+	found := make([]bool, len(files))
+
+	group, groupCtx := utils.NewGroup(ctx, c.config.ConcurrentUploads)
+	for i, file := range files {
+		i, file := i, file
+		group.Go(func() error {
+			info, err := c.getFileInfo(groupCtx, file.Path)
+			if errors.Is(err, ErrFileNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			file.Metadata = info.Metadata
+			file.ContentType = info.ContentType
+			file.CacheControl = info.CacheControl
+			file.ContentDisposition = info.ContentDisposition
+			file.ContentEncoding = info.ContentEncoding
+			found[i] = true
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	enriched := files[:0]
+	for i, file := range files {
+		if found[i] {
+			enriched = append(enriched, file)
+		}
+	}
+	return enriched, nil
+}
+
+// normalizeLimit applies the list/search limit convention: zero means DefaultPageSize
+// and negative values are rejected
+func normalizeLimit(limit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, newSentinelError(400, "INVALID_LIMIT", ErrInvalidLimit, nil)
+	case limit == 0:
+		return DefaultPageSize, nil
+	default:
+		return limit, nil
+	}
+}
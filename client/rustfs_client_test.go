@@ -0,0 +1,525 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/audit"
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+func TestUploadRetryResendsBody(t *testing.T) {
+	const content = "payload that must survive a retry"
+
+	tests := []struct {
+		name          string
+		source        func() io.Reader
+		size          int64
+		chunkSize     int
+		wantAttempts  int
+		wantMultipart bool
+	}{
+		{"seekable source", func() io.Reader { return strings.NewReader(content) }, int64(len(content)), 0, 2, false},
+		{"unseekable source", func() io.Reader { return &onceReader{r: strings.NewReader(content)} }, int64(len(content)), 0, 2, false},
+		{"unknown size", func() io.Reader { return &onceReader{r: strings.NewReader(content)} }, 0, 0, 2, false},
+		// Past ChunkSize an unseekable source is sent in parts, each retried on its own
+		{"unseekable source above the chunk size", func() io.Reader { return &onceReader{r: strings.NewReader(content)} }, int64(len(content)), 8, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				if tt.chunkSize > 0 {
+					cfg.ChunkSize = tt.chunkSize
+				}
+			})
+
+			var attempts []string
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method != http.MethodPut {
+					return false
+				}
+				raw, _ := io.ReadAll(r.Body)
+				r.Body = io.NopCloser(bytes.NewReader(raw))
+				body, err := readBody(r)
+				if err != nil {
+					t.Errorf("reading attempt %d: %v", len(attempts)+1, err)
+				}
+				attempts = append(attempts, string(body))
+				if len(attempts) == 1 {
+					writeS3Error(w, http.StatusServiceUnavailable, "SlowDown")
+					return true
+				}
+				r.Body = io.NopCloser(bytes.NewReader(raw))
+				return false
+			}
+
+			_, err := c.UploadFile(context.Background(), &types.UploadRequest{
+				File:        tt.source(),
+				FileSize:    tt.size,
+				ContentType: "text/plain",
+				BucketPath:  "doc.txt",
+			})
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("PUT attempts = %d, want %d", len(attempts), tt.wantAttempts)
+			}
+			for i, body := range attempts {
+				if body != content {
+					t.Errorf("attempt %d sent %q, want %q", i+1, body, content)
+				}
+			}
+			if multipart := len(fake.requests(http.MethodPost, "doc.txt?uploads")) > 0; multipart != tt.wantMultipart {
+				t.Errorf("multipart = %v, want %v", multipart, tt.wantMultipart)
+			}
+			if stored, ok := fake.get("doc.txt"); !ok || string(stored.data) != content {
+				t.Errorf("stored object doesn't match the upload")
+			}
+		})
+	}
+}
+
+func TestFileURLsEscapeKeys(t *testing.T) {
+	keys := []string{
+		"folder/my file (1).png",
+		"a/b+c#d.txt",
+		"reports/q1?draft=1.pdf",
+		"unicode/résumé.pdf",
+	}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			ctx := context.Background()
+
+			resp, err := c.UploadFile(ctx, uploadRequest(key, "content"))
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			if _, ok := fake.get(key); !ok {
+				t.Fatalf("object not stored under %q", key)
+			}
+
+			m := NewMockRustFSClient()
+			mockResp, err := m.UploadFile(ctx, uploadRequest(key, "content"))
+			if err != nil {
+				t.Fatalf("mock UploadFile: %v", err)
+			}
+
+			for name, fileURL := range map[string]string{
+				"GetFileURL":         c.GetFileURL(key),
+				"upload response":    resp.URL,
+				"mock GetFileURL":    m.GetFileURL(key),
+				"mock upload result": mockResp.URL,
+			} {
+				parsed, err := url.Parse(fileURL)
+				if err != nil {
+					t.Errorf("%s %q doesn't parse: %v", name, fileURL, err)
+					continue
+				}
+				if !strings.HasSuffix(parsed.Path, "/"+key) || parsed.RawQuery != "" || parsed.Fragment != "" {
+					t.Errorf("%s %q parses to path %q, query %q, fragment %q", name, fileURL, parsed.Path, parsed.RawQuery, parsed.Fragment)
+				}
+			}
+
+			if info, err := c.GetFileInfo(ctx, key); err != nil || info.Path != key {
+				t.Fatalf("GetFileInfo = %+v, %v", info, err)
+			}
+			body, _, err := c.DownloadFile(ctx, key)
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if string(data) != "content" {
+				t.Errorf("downloaded %q", data)
+			}
+
+			if err := c.CopyFile(ctx, key, "copies/"+key); err != nil {
+				t.Fatalf("CopyFile: %v", err)
+			}
+			if _, ok := fake.get("copies/" + key); !ok {
+				t.Errorf("copy not stored under %q", "copies/"+key)
+			}
+
+			if err := c.DeleteFile(ctx, key); err != nil {
+				t.Fatalf("DeleteFile: %v", err)
+			}
+			if _, ok := fake.get(key); ok {
+				t.Errorf("%q still stored after DeleteFile", key)
+			}
+		})
+	}
+}
+
+func TestUploadChecksum(t *testing.T) {
+	content := strings.Repeat("checksummed content ", 50)
+	sum := sha256.Sum256([]byte(content))
+	want := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name   string
+		upload func(t *testing.T, req *types.UploadRequest) (*types.UploadResponse, error)
+		source func() io.Reader
+	}{
+		{"RustFSClient seekable source", func(t *testing.T, req *types.UploadRequest) (*types.UploadResponse, error) {
+			c, _ := newFakeS3Client(t, func(cfg *config.RustFSConfig) { cfg.ChecksumAlgorithm = "SHA256" })
+			return c.UploadFile(context.Background(), req)
+		}, func() io.Reader { return strings.NewReader(content) }},
+		{"RustFSClient unseekable source", func(t *testing.T, req *types.UploadRequest) (*types.UploadResponse, error) {
+			c, _ := newFakeS3Client(t, func(cfg *config.RustFSConfig) { cfg.ChecksumAlgorithm = "SHA256" })
+			return c.UploadFile(context.Background(), req)
+		}, func() io.Reader { return &onceReader{r: strings.NewReader(content)} }},
+		{"MockRustFSClient", func(t *testing.T, req *types.UploadRequest) (*types.UploadResponse, error) {
+			return NewMockRustFSClient().UploadFile(context.Background(), req)
+		}, func() io.Reader { return strings.NewReader(content) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := uploadRequest("sum.txt", content)
+			req.File = tt.source()
+
+			resp, err := tt.upload(t, req)
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			if resp.ChecksumAlgorithm != "sha256" || resp.Checksum != want {
+				t.Errorf("checksum = %s:%s, want sha256:%s", resp.ChecksumAlgorithm, resp.Checksum, want)
+			}
+		})
+	}
+}
+
+func TestPingTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		status  int
+		wantErr bool
+	}{
+		{"healthy server", 0, http.StatusOK, false},
+		{"any HTTP response is reachable", 0, http.StatusForbidden, false},
+		{"server slower than the timeout", time.Second, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(tt.status)
+			})
+			cfg := testConfig(t, server.URL)
+			cfg.PingTimeout = 50 * time.Millisecond
+			c := NewRustFSClient(cfg)
+
+			start := time.Now()
+			err := c.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ping = %v, want error %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Ping took %v, want it bounded by the 50ms timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestNewRustFSClientE(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(cfg *config.RustFSConfig)
+		wantErr string
+	}{
+		{name: "valid config"},
+		{"missing base URL", func(cfg *config.RustFSConfig) { cfg.BaseURL = "" }, "RUSTFS_BASE_URL is required"},
+		{"missing access key", func(cfg *config.RustFSConfig) { cfg.AccessKey = "" }, "RUSTFS_ACCESS_KEY is required"},
+		{"missing secret key", func(cfg *config.RustFSConfig) { cfg.SecretKey = "" }, "RUSTFS_SECRET_KEY is required"},
+		{"missing bucket", func(cfg *config.RustFSConfig) { cfg.BucketName = "" }, "RUSTFS_BUCKET_NAME is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost:9000")
+			if tt.mutate != nil {
+				tt.mutate(cfg)
+			}
+
+			c, err := NewRustFSClientE(cfg)
+			if tt.wantErr == "" {
+				if err != nil || c == nil {
+					t.Fatalf("NewRustFSClientE = %v, %v; want a client", c, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRustFSClientE error = %v, want %q", err, tt.wantErr)
+			}
+			if c != nil {
+				t.Error("NewRustFSClientE returned a client alongside the error")
+			}
+			// The non-validating constructor still accepts the config for advanced setups
+			if NewRustFSClient(cfg) == nil {
+				t.Error("NewRustFSClient rejected the config")
+			}
+		})
+	}
+
+	if _, err := NewRustFSClientE(nil); err == nil {
+		t.Error("NewRustFSClientE(nil) succeeded")
+	}
+}
+
+// countingConn counts the bytes written to a connection
+type countingConn struct {
+	net.Conn
+	written *atomic.Int64
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+func TestExpectContinueRejection(t *testing.T) {
+	content := strings.Repeat("x", 8*1024*1024)
+
+	tests := []struct {
+		name       string
+		threshold  int64
+		wantExpect bool
+	}{
+		{"upload above the threshold waits for the server", 1024, true},
+		{"upload below the threshold", 16 * 1024 * 1024, false},
+		{"disabled", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written atomic.Int64
+			var expected atomic.Bool
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				// Reject without reading the body, as a server failing auth or size checks would
+				expected.Store(r.Header.Get("Expect") == "100-continue")
+				writeS3Error(w, http.StatusForbidden, "AccessDenied")
+			})
+
+			cfg := testConfig(t, server.URL)
+			cfg.ExpectContinueThreshold = tt.threshold
+			cfg.ExpectContinueTimeout = 5 * time.Second
+
+			// A transport configured like the client's own counts the bytes put on the wire
+			dialer := &net.Dialer{}
+			c := NewRustFSClientWithHTTPClient(cfg, &http.Client{Transport: &http.Transport{
+				ExpectContinueTimeout: cfg.ExpectContinueTimeout,
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					conn, err := dialer.DialContext(ctx, network, addr)
+					if err != nil {
+						return nil, err
+					}
+					return &countingConn{Conn: conn, written: &written}, nil
+				},
+			}})
+
+			req := uploadRequest("big.bin", content)
+			req.ContentType = "application/octet-stream"
+			if _, err := c.UploadFile(context.Background(), req); err == nil {
+				t.Fatal("UploadFile succeeded, want the server's rejection")
+			}
+			if expected.Load() != tt.wantExpect {
+				t.Errorf("Expect: 100-continue sent = %v, want %v", expected.Load(), tt.wantExpect)
+			}
+
+			// Without the interim response the client never streams the body
+			sentBody := written.Load() >= int64(len(content))/4
+			if sentBody == tt.wantExpect {
+				t.Errorf("client wrote %d bytes of a rejected %d-byte upload; body sent = %v, want %v",
+					written.Load(), len(content), sentBody, !tt.wantExpect)
+			}
+		})
+	}
+}
+
+// closeCountingReader counts how often an upload source is closed
+type closeCountingReader struct {
+	*bytes.Reader
+	closes atomic.Int32
+}
+
+func (r *closeCountingReader) Close() error {
+	r.closes.Add(1)
+	return nil
+}
+
+func TestUploadCloseSource(t *testing.T) {
+	small := []byte("owned by the client")
+	large := bytes.Repeat([]byte("0123456789abcdef"), (utils.MinPartSize+1024)/16)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		closeSource bool
+		failPut     bool
+		audited     bool
+		upload      func(c *RustFSClient) func(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error)
+		wantErr     bool
+		wantCloses  int32
+	}{
+		{name: "caller owns the source", data: small, wantCloses: 0},
+		{name: "closed after upload", data: small, closeSource: true, wantCloses: 1},
+		{name: "closed after failed upload", data: small, closeSource: true, failPut: true, wantErr: true, wantCloses: 1},
+		{name: "closed after multipart upload", data: large, closeSource: true, wantCloses: 1,
+			upload: func(c *RustFSClient) func(context.Context, *types.UploadRequest) (*types.UploadResponse, error) {
+				return c.UploadLargeFile
+			}},
+		{name: "caller owns the multipart source", data: large, wantCloses: 0,
+			upload: func(c *RustFSClient) func(context.Context, *types.UploadRequest) (*types.UploadResponse, error) {
+				return c.UploadLargeFile
+			}},
+		{name: "closed once through the audit wrapper", data: small, closeSource: true, audited: true, wantCloses: 1},
+		{name: "closed once after failed validation", data: small, contentType: "application/x-msdownload",
+			closeSource: true, audited: true, wantErr: true, wantCloses: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			if tt.failPut {
+				fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+					if r.Method != http.MethodPut {
+						return false
+					}
+					writeS3Error(w, http.StatusInternalServerError, "InternalError")
+					return true
+				}
+			}
+			upload := c.UploadFile
+			if tt.upload != nil {
+				upload = tt.upload(c)
+			}
+			if tt.audited {
+				cfg := *c.config
+				cfg.AllowedTypes = []string{"text/plain"}
+				audited := NewAuditableRustFSClient(c, audit.NewRustFSAuditLogger("test-service", &recordingAuditSink{}, nil), &cfg, "test-service")
+				upload = func(ctx context.Context, req *types.UploadRequest) (*types.UploadResponse, error) {
+					return audited.UploadFileWithAudit(ctx, req, "user-1")
+				}
+			}
+
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "text/plain"
+			}
+			source := &closeCountingReader{Reader: bytes.NewReader(tt.data)}
+			_, err := upload(context.Background(), &types.UploadRequest{
+				File:        source,
+				FileSize:    int64(len(tt.data)),
+				ContentType: contentType,
+				Filename:    "owned.txt",
+				BucketPath:  "owned.txt",
+				CloseSource: tt.closeSource,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("upload error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := source.closes.Load(); got != tt.wantCloses {
+				t.Errorf("source closed %d times, want %d", got, tt.wantCloses)
+			}
+		})
+	}
+}
+
+func TestMockUploadCloseSource(t *testing.T) {
+	for _, closeSource := range []bool{false, true} {
+		m := NewMockRustFSClient()
+		source := &closeCountingReader{Reader: bytes.NewReader([]byte("content"))}
+		if _, err := m.UploadFile(context.Background(), &types.UploadRequest{
+			File:        source,
+			FileSize:    7,
+			ContentType: "text/plain",
+			BucketPath:  "owned.txt",
+			CloseSource: closeSource,
+		}); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		want := int32(0)
+		if closeSource {
+			want = 1
+		}
+		if got := source.closes.Load(); got != want {
+			t.Errorf("CloseSource %v: source closed %d times, want %d", closeSource, got, want)
+		}
+	}
+}
+
+func TestMessyPathsNormalized(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("normalized", func(t *testing.T) {
+		c, fake := newFakeS3Client(t)
+		if _, err := c.UploadFile(ctx, uploadRequest("/./docs//report.txt", "content")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		if _, ok := fake.get("docs/report.txt"); !ok {
+			t.Fatal("messy upload path not stored under docs/report.txt")
+		}
+
+		for _, path := range []string{"docs/report.txt", "/docs/report.txt", "docs//report.txt", "./docs/./report.txt"} {
+			info, err := c.GetFileInfo(ctx, path)
+			if err != nil {
+				t.Fatalf("GetFileInfo(%q): %v", path, err)
+			}
+			if info.Path != "docs/report.txt" {
+				t.Errorf("GetFileInfo(%q).Path = %q, want docs/report.txt", path, info.Path)
+			}
+			body, _, err := c.DownloadFile(ctx, path)
+			if err != nil {
+				t.Fatalf("DownloadFile(%q): %v", path, err)
+			}
+			body.Close()
+		}
+
+		if err := c.DeleteFile(ctx, "//docs/report.txt/"); err != nil {
+			t.Fatalf("DeleteFile: %v", err)
+		}
+		if _, ok := fake.get("docs/report.txt"); ok {
+			t.Fatal("object still stored after deleting a messy path")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+			cfg.NormalizePaths = false
+		})
+		if _, err := c.UploadFile(ctx, uploadRequest("docs//report.txt", "content")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		if _, ok := fake.get("docs//report.txt"); !ok {
+			t.Fatal("key with doubled slash not stored verbatim")
+		}
+		if _, err := c.GetFileInfo(ctx, "docs/report.txt"); err == nil {
+			t.Fatal("GetFileInfo found the object under a different key")
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// Fields search results can be sorted by
+const (
+	SearchSortByPath         = "path"
+	SearchSortBySize         = "size"
+	SearchSortByLastModified = "last_modified"
+)
+
+// Search result orders
+const (
+	SearchSortAsc  = "asc"
+	SearchSortDesc = "desc"
+)
+
+// searchResponse is the body returned by the search endpoint
+type searchResponse struct {
+	Results []*SearchResult `json:"results"`
+}
+
+// normalizeSearchOptions validates opts and returns a copy with the defaults filled in: sorted
+// by path in ascending order, at most DefaultPageSize results
+func normalizeSearchOptions(opts *SearchOptions) (SearchOptions, error) {
+	var normalized SearchOptions
+	if opts != nil {
+		normalized = *opts
+	}
+
+	limit, err := normalizeLimit(normalized.MaxResults)
+	if err != nil {
+		return normalized, err
+	}
+	normalized.MaxResults = limit
+
+	switch normalized.SortBy {
+	case "":
+		normalized.SortBy = SearchSortByPath
+	case SearchSortByPath, SearchSortBySize, SearchSortByLastModified:
+	default:
+		return normalized, apperror.NewAppError(400, "INVALID_SEARCH",
+			fmt.Errorf("unknown sort field %q: must be path, size or last_modified", normalized.SortBy))
+	}
+
+	switch normalized.SortOrder {
+	case "":
+		normalized.SortOrder = SearchSortAsc
+	case SearchSortAsc, SearchSortDesc:
+	default:
+		return normalized, apperror.NewAppError(400, "INVALID_SEARCH",
+			fmt.Errorf("sort order %q must be asc or desc", normalized.SortOrder))
+	}
+
+	return normalized, nil
+}
+
+// SearchFiles finds files whose path contains opts.Query using the server's search endpoint.
+// It fails with ErrUnsupportedOperation on servers without search.
+func (c *RustFSClient) SearchFiles(ctx context.Context, opts *SearchOptions) ([]*SearchResult, error) {
+	normalized, err := normalizeSearchOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.requireCapability(ctx, CapabilitySearch); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("q", normalized.Query)
+	if normalized.Prefix != "" {
+		query.Set("prefix", c.objectKey(normalized.Prefix))
+	}
+	query.Set("max_results", strconv.Itoa(normalized.MaxResults))
+	query.Set("sort_by", normalized.SortBy)
+	query.Set("sort_order", normalized.SortOrder)
+
+	return c.search(ctx, query)
+}
+
+// SearchByMetadata finds files whose metadata has key set to value. The key must match exactly;
+// a value ending in "*" matches any value starting with the text before it. At most
+// DefaultPageSize results are returned, sorted by path.
+func (c *RustFSClient) SearchByMetadata(ctx context.Context, key, value string) ([]*SearchResult, error) {
+	if key == "" {
+		return nil, apperror.NewAppError(400, "INVALID_SEARCH", fmt.Errorf("metadata key is required"))
+	}
+	if err := c.requireCapability(ctx, CapabilitySearch); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("metadata_key", key)
+	query.Set("metadata_value", value)
+	query.Set("max_results", strconv.Itoa(DefaultPageSize))
+	query.Set("sort_by", SearchSortByPath)
+	query.Set("sort_order", SearchSortAsc)
+
+	return c.search(ctx, query)
+}
+
+// matchMetadataValue reports whether value matches pattern, where a trailing "*" in pattern
+// matches any suffix
+func matchMetadataValue(pattern, value string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return value == pattern
+}
+
+// search sends query to the search endpoint and decodes the results
+func (c *RustFSClient) search(ctx context.Context, query url.Values) ([]*SearchResult, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/search", url.PathEscape(c.config.BucketName))) + "?" + query.Encode()
+	resp, err := c.doSignedRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, cancellationError(ctx, apperror.NewAppError(500, "SEARCH_FAILED", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperror.NewAppError(resp.StatusCode, "SEARCH_FAILED",
+			fmt.Errorf("search endpoint returned %s", resp.Status))
+	}
+
+	var body searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, apperror.NewAppError(502, "SEARCH_FAILED", fmt.Errorf("invalid search endpoint response: %v", err))
+	}
+	return body.Results, nil
+}
+
+// SearchFiles finds files in mock storage under opts.Prefix whose path contains opts.Query,
+// ignoring case
+func (m *MockRustFSClient) SearchFiles(ctx context.Context, opts *SearchOptions) ([]*SearchResult, error) {
+	normalized, err := normalizeSearchOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(normalized.Query)
+
+	return m.search(normalized, func(info *types.FileInfo) bool {
+		return utils.MatchPrefix(info.Path, normalized.Prefix, false) &&
+			strings.Contains(strings.ToLower(info.Path), query)
+	})
+}
+
+// SearchByMetadata finds files in mock storage whose metadata has key set to value, matching
+// like RustFSClient.SearchByMetadata
+func (m *MockRustFSClient) SearchByMetadata(ctx context.Context, key, value string) ([]*SearchResult, error) {
+	if key == "" {
+		return nil, apperror.NewAppError(400, "INVALID_SEARCH", fmt.Errorf("metadata key is required"))
+	}
+
+	opts := SearchOptions{MaxResults: DefaultPageSize, SortBy: SearchSortByPath, SortOrder: SearchSortAsc}
+	return m.search(opts, func(info *types.FileInfo) bool {
+		stored, ok := info.Metadata[key]
+		return ok && matchMetadataValue(value, fmt.Sprint(stored))
+	})
+}
+
+// search returns the sorted, limited results for the stored files matching match
+func (m *MockRustFSClient) search(opts SearchOptions, match func(info *types.FileInfo) bool) ([]*SearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return nil, m.failError
+	}
+
+	var matched []*types.FileInfo
+	for _, path := range m.sortedPaths() {
+		if match(m.files[path]) {
+			matched = append(matched, m.files[path])
+		}
+	}
+	sortFileInfos(matched, opts.SortBy, opts.SortOrder == SearchSortDesc)
+
+	if len(matched) > opts.MaxResults {
+		matched = matched[:opts.MaxResults]
+	}
+	results := make([]*SearchResult, len(matched))
+	for i, info := range matched {
+		results[i] = &SearchResult{
+			Path:         info.Path,
+			Size:         info.Size,
+			ContentType:  info.ContentType,
+			LastModified: info.LastModified.Format(time.RFC3339),
+			Metadata:     info.Metadata,
+		}
+	}
+	return results, nil
+}
+
+// sortFileInfos sorts files by a search sort field, keeping path order among equal values
+func sortFileInfos(files []*types.FileInfo, sortBy string, descending bool) {
+	less := func(a, b *types.FileInfo) bool {
+		switch sortBy {
+		case SearchSortBySize:
+			return a.Size < b.Size
+		case SearchSortByLastModified:
+			return a.LastModified.Before(b.LastModified)
+		default:
+			return a.Path < b.Path
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if descending {
+			return less(files[j], files[i])
+		}
+		return less(files[i], files[j])
+	})
+}
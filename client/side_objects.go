@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/config"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// MetadataSideObjectRef prefixes metadata values that were moved to a side object under the
+// side-object oversize policy; the rest of the value is the side object's path. GetFileInfo and
+// DownloadFile resolve references back to the stored values.
+const MetadataSideObjectRef = "rustfs-side-object:"
+
+// sideObject is an oversized metadata value to be stored beside its object
+type sideObject struct {
+	path  string
+	value string
+}
+
+// sideObjectPrefix returns the prefix the side objects of path are stored under
+func sideObjectPrefix(path string) string {
+	return path + ".metadata/"
+}
+
+// usesSideObjects reports whether oversized metadata values are moved to side objects, which
+// are then copied, moved and deleted together with their object
+func (c *RustFSClient) usesSideObjects() bool {
+	return c.config.MetadataValueLimit > 0 && c.config.MetadataOversizePolicy == config.MetadataOversizeSideObject
+}
+
+// limitMetadataValues applies the configured oversize policy to metadata values longer than
+// MetadataValueLimit, so they are never truncated at the HTTP layer. Under the side-object
+// policy each oversized value is replaced by a reference to "<path>.metadata/<key>" and returned
+// as a side object, which the caller stores with syncSideObjects once the object itself has been
+// written. The caller's map is never mutated.
+func (c *RustFSClient) limitMetadataValues(path string, metadata map[string]interface{}) (map[string]interface{}, []sideObject, error) {
+	limit := c.config.MetadataValueLimit
+	if limit <= 0 {
+		return metadata, nil, nil
+	}
+
+	var limited map[string]interface{}
+	var sideObjects []sideObject
+	for k, v := range metadata {
+		value := fmt.Sprintf("%v", v)
+		if len(value) <= limit {
+			continue
+		}
+
+		if c.config.MetadataOversizePolicy != config.MetadataOversizeSideObject {
+			return nil, nil, newSentinelError(400, "METADATA_VALUE_TOO_LARGE", ErrMetadataValueTooLarge,
+				fmt.Errorf("value of %q is %d bytes, limit is %d", k, len(value), limit))
+		}
+
+		sidePath := sideObjectPrefix(path) + url.PathEscape(k)
+		if err := validateObjectKey(c.keyValidator, sidePath); err != nil {
+			return nil, nil, err
+		}
+		sideObjects = append(sideObjects, sideObject{path: sidePath, value: value})
+
+		if limited == nil {
+			limited = make(map[string]interface{}, len(metadata))
+			for key, value := range metadata {
+				limited[key] = value
+			}
+		}
+		limited[k] = MetadataSideObjectRef + sidePath
+	}
+
+	if limited == nil {
+		return metadata, nil, nil
+	}
+	return limited, sideObjects, nil
+}
+
+// syncSideObjects stores the side objects returned by limitMetadataValues for path and deletes
+// those left over from its previous metadata. It runs only after the object referencing them was
+// written, so a rejected write, such as an IfNotExists upload losing to an existing object,
+// never touches the side objects of the object it lost to.
+func (c *RustFSClient) syncSideObjects(ctx context.Context, path string, sideObjects []sideObject) error {
+	if !c.usesSideObjects() {
+		return nil
+	}
+
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	keep := make(map[string]bool, len(sideObjects))
+	for _, side := range sideObjects {
+		keep[side.path] = true
+		if err := c.putSideObject(ctx, side); err != nil {
+			return cancellationError(ctx, err)
+		}
+	}
+	return cancellationError(ctx, c.deleteSideObjects(ctx, path, keep))
+}
+
+// putSideObject stores one side object, retrying like any upload
+func (c *RustFSClient) putSideObject(ctx context.Context, side sideObject) error {
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		_, err := c.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(c.config.BucketName),
+			Key:         aws.String(side.path),
+			Body:        strings.NewReader(side.value),
+			ContentType: aws.String("text/plain; charset=utf-8"),
+		})
+		if err != nil {
+			return apperror.NewAppError(500, "METADATA_SIDE_OBJECT_FAILED", err)
+		}
+		return nil
+	})
+	c.bodies.invalidate(side.path)
+	return err
+}
+
+// deleteSideObjects deletes the side objects stored for path, except those in keep
+func (c *RustFSClient) deleteSideObjects(ctx context.Context, path string, keep map[string]bool) error {
+	var stale []string
+	err := c.walkFiles(ctx, &ListOptions{Prefix: sideObjectPrefix(path)}, func(file *types.FileInfo) error {
+		if !keep[file.Path] {
+			stale = append(stale, file.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sidePath := range stale {
+		if err := c.deleteFile(ctx, sidePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSideObjects replaces side object references in metadata with the values they point
+// to. A reference whose side object no longer exists is left in place.
+func (c *RustFSClient) resolveSideObjects(ctx context.Context, metadata map[string]interface{}) error {
+	for k, v := range metadata {
+		ref, ok := v.(string)
+		if !ok || !strings.HasPrefix(ref, MetadataSideObjectRef) {
+			continue
+		}
+
+		value, err := c.readSideObject(ctx, strings.TrimPrefix(ref, MetadataSideObjectRef))
+		if errors.Is(err, ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		metadata[k] = value
+	}
+	return nil
+}
+
+// readSideObject returns the value stored in a side object
+func (c *RustFSClient) readSideObject(ctx context.Context, sidePath string) (string, error) {
+	var value []byte
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		output, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(c.config.BucketName),
+			Key:    aws.String(sidePath),
+		})
+		if err != nil {
+			if isNotFoundError(err) {
+				return newSentinelError(404, "FILE_NOT_FOUND", ErrFileNotFound, err)
+			}
+			return apperror.NewAppError(500, "METADATA_SIDE_OBJECT_FAILED", err)
+		}
+		defer output.Body.Close()
+
+		if value, err = io.ReadAll(output.Body); err != nil {
+			return apperror.NewAppError(500, "METADATA_SIDE_OBJECT_FAILED", err)
+		}
+		return nil
+	})
+	return string(value), err
+}
+
+// objectMetadata decodes the metadata headers of an object and resolves its side objects
+func (c *RustFSClient) objectMetadata(ctx context.Context, headers map[string]string) (map[string]interface{}, error) {
+	metadata := c.decodeMetadata(headers)
+	if err := c.resolveSideObjects(ctx, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
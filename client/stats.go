@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// statsResponse is the body returned by the bucket stats endpoint
+type statsResponse struct {
+	TotalFiles     int64     `json:"total_files"`
+	TotalSize      int64     `json:"total_size"`
+	UsedSpace      int64     `json:"used_space"`
+	AvailableSpace int64     `json:"available_space"`
+	LastUpdated    time.Time `json:"last_updated"`
+}
+
+// GetStorageStats reports bucket usage from the server's stats endpoint, which also knows the
+// available space. Servers without the endpoint get stats computed by listing the bucket,
+// leaving AvailableSpace zero.
+func (c *RustFSClient) GetStorageStats(ctx context.Context) (*types.StorageStats, error) {
+	stats, err := c.serverStorageStats(ctx)
+	if err != nil || stats != nil {
+		return stats, err
+	}
+	return c.listStorageStats(ctx)
+}
+
+// serverStorageStats fetches stats from the stats endpoint. It returns nil stats and no error
+// when the server has no such endpoint.
+func (c *RustFSClient) serverStorageStats(ctx context.Context) (*types.StorageStats, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/stats", url.PathEscape(c.config.BucketName)))
+	resp, err := c.doSignedRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, cancellationError(ctx, apperror.NewAppError(500, "STATS_FAILED", err))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, nil
+	default:
+		return nil, apperror.NewAppError(resp.StatusCode, "STATS_FAILED",
+			fmt.Errorf("stats endpoint returned %s", resp.Status))
+	}
+
+	var body statsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, apperror.NewAppError(502, "STATS_FAILED", fmt.Errorf("invalid stats endpoint response: %v", err))
+	}
+	if body.LastUpdated.IsZero() {
+		body.LastUpdated = time.Now()
+	}
+
+	return &types.StorageStats{
+		TotalFiles:     body.TotalFiles,
+		TotalSize:      body.TotalSize,
+		UsedSpace:      body.UsedSpace,
+		AvailableSpace: body.AvailableSpace,
+		LastUpdated:    body.LastUpdated,
+	}, nil
+}
+
+// listStorageStats computes bucket usage by listing objects. Each top-level prefix is summed
+// by an independent sub-query; sub-queries run concurrently up to the configured concurrency
+// limit and the first failure cancels the rest.
+func (c *RustFSClient) listStorageStats(ctx context.Context) (*types.StorageStats, error) {
+	var totalFiles, totalSize atomic.Int64
+
+	group, groupCtx := utils.NewGroup(ctx, c.config.ConcurrentUploads)
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(c.config.BucketName),
+		Delimiter: aws.String(utils.PathDelimiter),
+		MaxKeys:   aws.Int32(DefaultPageSize),
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(c.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(groupCtx)
+		if err != nil {
+			// Route the failure through the group so running sub-queries are cancelled
+			group.Go(func() error { return err })
+			break
+		}
+
+		// Objects at the bucket root are counted from the delimiter listing itself
+		for _, object := range page.Contents {
+			totalFiles.Add(1)
+			totalSize.Add(aws.ToInt64(object.Size))
+		}
+
+		for _, prefix := range page.CommonPrefixes {
+			prefix := aws.ToString(prefix.Prefix)
+			group.Go(func() error {
+				return c.walkFiles(groupCtx, &ListOptions{Prefix: prefix}, func(file *types.FileInfo) error {
+					totalFiles.Add(1)
+					totalSize.Add(file.Size)
+					return nil
+				})
+			})
+		}
+	}
+
+	if err := group.Wait(); err != nil {
+		if _, ok := err.(*apperror.AppError); ok {
+			return nil, err
+		}
+		return nil, apperror.NewAppError(500, "STATS_FAILED", err)
+	}
+
+	return &types.StorageStats{
+		TotalFiles:  totalFiles.Load(),
+		TotalSize:   totalSize.Load(),
+		UsedSpace:   totalSize.Load(),
+		LastUpdated: time.Now(),
+	}, nil
+}
+
+// GetStorageStats computes usage from the files in mock storage
+func (m *MockRustFSClient) GetStorageStats(ctx context.Context) (*types.StorageStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &types.StorageStats{LastUpdated: time.Now()}
+	for _, info := range m.files {
+		stats.TotalFiles++
+		stats.TotalSize += info.Size
+	}
+	stats.UsedSpace = stats.TotalSize
+	return stats, nil
+}
+
+// OwnerMetadataKey is the metadata key naming the user who owns an object, used by
+// GetUsageByUser
+const OwnerMetadataKey = "user_id"
+
+// usageResponse is the body returned by the usage endpoint
+type usageResponse struct {
+	Bytes int64 `json:"bytes"`
+}
+
+// validateUsageFilter rejects an empty usage filter
+func validateUsageFilter(name, value string) error {
+	if value == "" {
+		return apperror.NewAppError(400, "INVALID_USAGE_QUERY", fmt.Errorf("%s is required", name))
+	}
+	return nil
+}
+
+// GetUsageByUser returns the total size of the objects whose OwnerMetadataKey metadata is
+// userID. The server's usage endpoint is used when available; otherwise the bucket is listed
+// with metadata, costing a lookup per object.
+func (c *RustFSClient) GetUsageByUser(ctx context.Context, userID string) (int64, error) {
+	if err := validateUsageFilter("userID", userID); err != nil {
+		return 0, err
+	}
+	return c.usage(ctx, url.Values{"user_id": {userID}}, func(file *types.FileInfo) bool {
+		return fmt.Sprint(file.Metadata[OwnerMetadataKey]) == userID
+	})
+}
+
+// GetUsageByType returns the total size of the objects whose content type matches contentType,
+// which may be a wildcard such as "image/*". Like GetUsageByUser it prefers the server's
+// usage endpoint.
+func (c *RustFSClient) GetUsageByType(ctx context.Context, contentType string) (int64, error) {
+	if err := validateUsageFilter("contentType", contentType); err != nil {
+		return 0, err
+	}
+	return c.usage(ctx, url.Values{"content_type": {contentType}}, func(file *types.FileInfo) bool {
+		return utils.MatchContentType(contentType, file.ContentType)
+	})
+}
+
+// usage asks the usage endpoint for the bytes matching query, falling back to summing the
+// listed objects that match
+func (c *RustFSClient) usage(ctx context.Context, query url.Values, match func(file *types.FileInfo) bool) (int64, error) {
+	total, ok, err := c.serverUsage(ctx, query)
+	if err != nil || ok {
+		return total, err
+	}
+
+	err = c.walkFiles(ctx, &ListOptions{IncludeMetadata: true}, func(file *types.FileInfo) error {
+		if match(file) {
+			total += file.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// serverUsage fetches aggregated usage from the usage endpoint. It reports false when the
+// server has no such endpoint.
+func (c *RustFSClient) serverUsage(ctx context.Context, query url.Values) (int64, bool, error) {
+	ctx, done, err := c.canceller.derive(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer done()
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/usage", url.PathEscape(c.config.BucketName))) + "?" + query.Encode()
+	resp, err := c.doSignedRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, false, cancellationError(ctx, apperror.NewAppError(500, "USAGE_FAILED", err))
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return 0, false, nil
+	default:
+		return 0, false, apperror.NewAppError(resp.StatusCode, "USAGE_FAILED",
+			fmt.Errorf("usage endpoint returned %s", resp.Status))
+	}
+
+	var body usageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, false, apperror.NewAppError(502, "USAGE_FAILED", fmt.Errorf("invalid usage endpoint response: %v", err))
+	}
+	return body.Bytes, true, nil
+}
+
+// GetUsageByUser sums the sizes of files in mock storage owned by userID
+func (m *MockRustFSClient) GetUsageByUser(ctx context.Context, userID string) (int64, error) {
+	if err := validateUsageFilter("userID", userID); err != nil {
+		return 0, err
+	}
+	return m.usage(func(info *types.FileInfo) bool {
+		return fmt.Sprint(info.Metadata[OwnerMetadataKey]) == userID
+	}), nil
+}
+
+// GetUsageByType sums the sizes of files in mock storage whose content type matches contentType
+func (m *MockRustFSClient) GetUsageByType(ctx context.Context, contentType string) (int64, error) {
+	if err := validateUsageFilter("contentType", contentType); err != nil {
+		return 0, err
+	}
+	return m.usage(func(info *types.FileInfo) bool {
+		return utils.MatchContentType(contentType, info.ContentType)
+	}), nil
+}
+
+// usage sums the sizes of the stored files matching match
+func (m *MockRustFSClient) usage(match func(info *types.FileInfo) bool) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var total int64
+	for _, info := range m.files {
+		if match(info) {
+			total += info.Size
+		}
+	}
+	return total
+}
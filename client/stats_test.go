@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestListStorageStatsParallel(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	prefixes := []string{"a/", "b/", "c/", "d/"}
+
+	// newStatsClient returns a client whose prefix listings each take delay, and a function
+	// reporting the peak number of listings in flight
+	newStatsClient := func(t *testing.T, limit int, failPrefix string) (*RustFSClient, func() int) {
+		c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) { cfg.ConcurrentUploads = limit })
+		fake.put("root.txt", []byte("1"), nil)
+		for _, prefix := range prefixes {
+			fake.put(prefix+"file.txt", []byte("12345"), nil)
+		}
+
+		var mu sync.Mutex
+		inFlight, peak := 0, 0
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			prefix := r.URL.Query().Get("prefix")
+			if r.Method != http.MethodGet || prefix == "" {
+				return false
+			}
+			if prefix == failPrefix {
+				writeS3Error(w, http.StatusForbidden, "AccessDenied")
+				return true
+			}
+
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+			}
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return false
+		}
+		return c, func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return peak
+		}
+	}
+
+	t.Run("sub-queries overlap", func(t *testing.T) {
+		c, peak := newStatsClient(t, len(prefixes), "")
+
+		start := time.Now()
+		stats, err := c.GetStorageStats(context.Background())
+		elapsed := time.Since(start)
+		if err != nil {
+			t.Fatalf("GetStorageStats: %v", err)
+		}
+		if stats.TotalFiles != 5 || stats.TotalSize != 21 {
+			t.Errorf("stats = %d files, %d bytes; want 5 files, 21 bytes", stats.TotalFiles, stats.TotalSize)
+		}
+		if elapsed >= 2*delay {
+			t.Errorf("GetStorageStats took %v, want the %v sub-queries to run in parallel", elapsed, delay)
+		}
+		if got := peak(); got != len(prefixes) {
+			t.Errorf("%d sub-queries ran at once, want %d", got, len(prefixes))
+		}
+	})
+
+	t.Run("concurrency limit", func(t *testing.T) {
+		c, peak := newStatsClient(t, 2, "")
+
+		start := time.Now()
+		if _, err := c.GetStorageStats(context.Background()); err != nil {
+			t.Fatalf("GetStorageStats: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 2*delay {
+			t.Errorf("GetStorageStats took %v with a limit of 2, want at least %v", elapsed, 2*delay)
+		}
+		if got := peak(); got > 2 {
+			t.Errorf("%d sub-queries ran at once, want at most 2", got)
+		}
+	})
+
+	t.Run("failure cancels the other sub-queries", func(t *testing.T) {
+		c, _ := newStatsClient(t, len(prefixes), "c/")
+
+		start := time.Now()
+		stats, err := c.GetStorageStats(context.Background())
+		if err == nil {
+			t.Fatalf("GetStorageStats = %+v, want the sub-query error", stats)
+		}
+		if elapsed := time.Since(start); elapsed >= delay {
+			t.Errorf("GetStorageStats took %v, want the failure to cancel the delayed sub-queries", elapsed)
+		}
+	})
+}
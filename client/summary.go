@@ -0,0 +1,100 @@
+package client
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// OperationDelete is the operation name for deletes in activity summaries
+const OperationDelete = "delete"
+
+// ClientSummary reports what a client did over its lifetime
+type ClientSummary struct {
+	Uploads         int64         `json:"uploads"`
+	Downloads       int64         `json:"downloads"`
+	Deletes         int64         `json:"deletes"`
+	BytesUploaded   int64         `json:"bytes_uploaded"`
+	BytesDownloaded int64         `json:"bytes_downloaded"`
+	Errors          int64         `json:"errors"`
+	Retries         int64         `json:"retries"`
+	StartedAt       time.Time     `json:"started_at"`
+	Lifetime        time.Duration `json:"lifetime"`
+}
+
+// ToMap converts the summary into audit event details
+func (s ClientSummary) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"uploads":          s.Uploads,
+		"downloads":        s.Downloads,
+		"deletes":          s.Deletes,
+		"bytes_uploaded":   s.BytesUploaded,
+		"bytes_downloaded": s.BytesDownloaded,
+		"errors":           s.Errors,
+		"retries":          s.Retries,
+		"started_at":       s.StartedAt.Format(time.RFC3339),
+		"lifetime":         s.Lifetime.String(),
+	}
+}
+
+// activityCounters accumulates a client's lifetime activity. It is safe for concurrent use
+// and doubles as a MetricsRecorder for byte counts.
+type activityCounters struct {
+	startedAt       time.Time
+	uploads         atomic.Int64
+	downloads       atomic.Int64
+	deletes         atomic.Int64
+	bytesUploaded   atomic.Int64
+	bytesDownloaded atomic.Int64
+	errors          atomic.Int64
+	retries         atomic.Int64
+}
+
+// newActivityCounters creates counters starting now
+func newActivityCounters() *activityCounters {
+	return &activityCounters{startedAt: time.Now()}
+}
+
+// AddBytes implements MetricsRecorder
+func (a *activityCounters) AddBytes(operation string, n int64) {
+	switch operation {
+	case OperationUpload:
+		a.bytesUploaded.Add(n)
+	case OperationDownload:
+		a.bytesDownloaded.Add(n)
+	}
+}
+
+// recordOperation counts one completed operation and whether it failed
+func (a *activityCounters) recordOperation(operation string, err error) {
+	switch operation {
+	case OperationUpload:
+		a.uploads.Add(1)
+	case OperationDownload:
+		a.downloads.Add(1)
+	case OperationDelete:
+		a.deletes.Add(1)
+	}
+	if err != nil {
+		a.errors.Add(1)
+	}
+}
+
+// recordRetries counts retried attempts
+func (a *activityCounters) recordRetries(n int64) {
+	a.retries.Add(n)
+}
+
+// summary snapshots the counters
+func (a *activityCounters) summary() ClientSummary {
+	return ClientSummary{
+		Uploads:         a.uploads.Load(),
+		Downloads:       a.downloads.Load(),
+		Deletes:         a.deletes.Load(),
+		BytesUploaded:   a.bytesUploaded.Load(),
+		BytesDownloaded: a.bytesDownloaded.Load(),
+		Errors:          a.errors.Load(),
+		Retries:         a.retries.Load(),
+		StartedAt:       a.startedAt,
+		Lifetime:        time.Since(a.startedAt),
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/audit"
+)
+
+func TestCloseSummary(t *testing.T) {
+	c, fake := newFakeS3Client(t)
+	fake.put("existing.txt", []byte("0123456789"), nil)
+
+	// The first GET of existing.txt is throttled, so the download costs one retry
+	var mu sync.Mutex
+	throttled := false
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet && r.URL.Path == "/"+fake.bucket+"/existing.txt" && !throttled {
+			throttled = true
+			writeS3Error(w, http.StatusServiceUnavailable, "SlowDown")
+			return true
+		}
+		return false
+	}
+
+	sink := &recordingAuditSink{}
+	ac := NewAuditableRustFSClient(c, audit.NewRustFSAuditLogger("test-service", sink, nil), c.config, "test-service")
+	ctx := context.Background()
+
+	for _, path := range []string{"a.txt", "b.txt"} {
+		if _, err := c.UploadFile(ctx, uploadRequest(path, "hello")); err != nil {
+			t.Fatalf("UploadFile(%s): %v", path, err)
+		}
+	}
+	body, _, err := c.DownloadFile(ctx, "existing.txt")
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	io.Copy(io.Discard, body)
+	body.Close()
+	if _, _, err := c.DownloadFile(ctx, "missing.txt"); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("DownloadFile(missing) = %v, want ErrFileNotFound", err)
+	}
+	if err := c.DeleteFile(ctx, "a.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	want := ClientSummary{
+		Uploads:         2,
+		Downloads:       2,
+		Deletes:         1,
+		BytesUploaded:   10,
+		BytesDownloaded: 10,
+		Errors:          1,
+		Retries:         1,
+	}
+	got := c.Summary()
+	got.StartedAt, got.Lifetime = want.StartedAt, want.Lifetime
+	if got != want {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+
+	if err := ac.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	var summary map[string]interface{}
+	for _, event := range sink.events {
+		if event.EventType == audit.AuditEventStorageMaintenance && event.Action == "client_summary" {
+			summary = event.Metadata
+		}
+	}
+	if summary == nil {
+		t.Fatal("Close logged no client_summary event")
+	}
+	for key, value := range want.ToMap() {
+		if key == "started_at" || key == "lifetime" {
+			continue
+		}
+		if summary[key] != value {
+			t.Errorf("client_summary %s = %v, want %v", key, summary[key], value)
+		}
+	}
+}
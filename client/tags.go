@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// CapabilityTagIndex marks servers that can list objects by tag without per-object lookups
+const CapabilityTagIndex = "tag_index"
+
+// tagIndexResponse is the body returned by the tag index endpoint
+type tagIndexResponse struct {
+	Files []*types.FileInfo `json:"files"`
+}
+
+// GetTags returns the tags attached to a file
+func (c *RustFSClient) GetTags(ctx context.Context, path string) (map[string]string, error) {
+	path = c.objectKey(path)
+	if err := c.requireCapability(ctx, CapabilityTagging); err != nil {
+		return nil, err
+	}
+
+	output, err := c.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(c.config.BucketName),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, apperror.NewAppError(500, "GET_TAGS_FAILED", err)
+	}
+
+	tags := make(map[string]string, len(output.TagSet))
+	for _, tag := range output.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags attached to a file
+func (c *RustFSClient) SetTags(ctx context.Context, path string, tags map[string]string) error {
+	path = c.objectKey(path)
+	if err := c.requireCapability(ctx, CapabilityTagging); err != nil {
+		return err
+	}
+
+	tagSet := make([]s3types.Tag, 0, len(tags))
+	for key, value := range tags {
+		tagSet = append(tagSet, s3types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	_, err := c.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(c.config.BucketName),
+		Key:     aws.String(path),
+		Tagging: &s3types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return apperror.NewAppError(500, "SET_TAGS_FAILED", err)
+	}
+	return nil
+}
+
+// ListFilesByTag lists files carrying the tag key=value. It queries the server's tag index when
+// available and otherwise falls back to listing and fetching each object's tags, which costs one
+// request per object; the fallback is logged.
+func (c *RustFSClient) ListFilesByTag(ctx context.Context, key, value string, opts *ListOptions) ([]*types.FileInfo, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.capabilities.supports(ctx, CapabilityTagIndex) {
+		files, indexed, err := c.listFilesByTagIndex(ctx, key, value, opts, limit)
+		if err != nil {
+			return nil, err
+		}
+		if indexed {
+			return files, nil
+		}
+	}
+
+	log.Printf("[RUSTFS] tag index unavailable, filtering %s=%s by per-object tag lookups", key, value)
+	return c.listFilesByTagScan(ctx, key, value, opts, limit)
+}
+
+// listFilesByTagIndex queries the tag index endpoint. It reports false when the server has no index.
+func (c *RustFSClient) listFilesByTagIndex(ctx context.Context, key, value string, opts *ListOptions, limit int) ([]*types.FileInfo, bool, error) {
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("value", value)
+	query.Set("prefix", opts.Prefix)
+	query.Set("limit", fmt.Sprint(limit))
+
+	endpoint := c.apiURL(fmt.Sprintf("buckets/%s/tags", url.PathEscape(c.config.BucketName))) + "?" + query.Encode()
+	resp, err := c.doSignedRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, apperror.NewAppError(500, "LIST_BY_TAG_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, false, nil
+	default:
+		return nil, false, apperror.NewAppError(resp.StatusCode, "LIST_BY_TAG_FAILED",
+			fmt.Errorf("tag index endpoint returned %s", resp.Status))
+	}
+
+	var body tagIndexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, false, apperror.NewAppError(502, "LIST_BY_TAG_FAILED", err)
+	}
+
+	files := make([]*types.FileInfo, 0, len(body.Files))
+	for _, file := range body.Files {
+		if utils.MatchPrefix(file.Path, opts.Prefix, opts.DirectoryMode) {
+			files = append(files, file)
+		}
+	}
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	return files, true, nil
+}
+
+// listFilesByTagScan walks the candidates page by page and checks their tags concurrently. The
+// walk waits for a free slot before dispatching each lookup, so at most the configured
+// concurrency limit of candidates is in flight, and it stops once limit matches are found.
+func (c *RustFSClient) listFilesByTagScan(ctx context.Context, key, value string, opts *ListOptions, limit int) ([]*types.FileInfo, error) {
+	var mu sync.Mutex
+	matches := make([]*types.FileInfo, 0)
+	found := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(matches)
+	}
+
+	slots := c.config.ConcurrentUploads
+	if slots <= 0 {
+		slots = 1
+	}
+	inFlight := make(chan struct{}, slots)
+
+	group, groupCtx := utils.NewGroup(ctx, slots)
+	errStop := errors.New("limit reached")
+	walkErr := c.walkFiles(groupCtx, opts, func(file *types.FileInfo) error {
+		select {
+		case inFlight <- struct{}{}:
+		case <-groupCtx.Done():
+			return groupCtx.Err()
+		}
+		// Candidates arrive in key order, so once limit matches are known every later
+		// candidate would be truncated away
+		if found() >= limit {
+			<-inFlight
+			return errStop
+		}
+
+		group.Go(func() error {
+			defer func() { <-inFlight }()
+			tags, err := c.GetTags(groupCtx, file.Path)
+			if err != nil {
+				return err
+			}
+			if tagValue, ok := tags[key]; ok && tagValue == value {
+				mu.Lock()
+				matches = append(matches, file)
+				mu.Unlock()
+			}
+			return nil
+		})
+		return nil
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	if walkErr != nil && walkErr != errStop {
+		return nil, walkErr
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
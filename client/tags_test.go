@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+func TestListFilesByTagScan(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		concurrency int
+		want        []string
+		maxLookups  int
+	}{
+		{"all matches", 100, 3, []string{"file-00", "file-03", "file-06", "file-09", "file-12", "file-15", "file-18"}, 20},
+		{"limit stops the walk", 2, 1, []string{"file-00", "file-03"}, 5},
+		{"limit with concurrent lookups", 3, 4, []string{"file-00", "file-03", "file-06"}, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t, func(cfg *config.RustFSConfig) {
+				cfg.ConcurrentUploads = tt.concurrency
+			})
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("file-%02d", i)
+				fake.put(key, []byte("content"), nil)
+				if i%3 == 0 {
+					fake.tag(key, map[string]string{"team": "blue"})
+				} else {
+					fake.tag(key, map[string]string{"team": "red"})
+				}
+			}
+
+			var mu sync.Mutex
+			inFlight, peak := 0, 0
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if !r.URL.Query().Has("tagging") {
+					return false
+				}
+				mu.Lock()
+				inFlight++
+				if inFlight > peak {
+					peak = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return false
+			}
+
+			files, err := c.ListFilesByTag(context.Background(), "team", "blue", &ListOptions{Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("ListFilesByTag: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				got = append(got, file.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			if peak > tt.concurrency {
+				t.Errorf("%d concurrent tag lookups, want at most %d", peak, tt.concurrency)
+			}
+			if lookups := len(fake.requests(http.MethodGet, "file-")); lookups > tt.maxLookups {
+				t.Errorf("%d tag lookups, want at most %d", lookups, tt.maxLookups)
+			}
+		})
+	}
+}
+
+func TestListFilesByTagScanLookupFailure(t *testing.T) {
+	c, fake := newFakeS3Client(t)
+	for i := 0; i < 5; i++ {
+		fake.put(fmt.Sprintf("file-%02d", i), []byte("content"), nil)
+	}
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Query().Has("tagging") && strings.HasSuffix(r.URL.Path, "file-02") {
+			writeS3Error(w, http.StatusForbidden, "AccessDenied")
+			return true
+		}
+		return false
+	}
+
+	if _, err := c.ListFilesByTag(context.Background(), "team", "blue", nil); errorCode(err) != "GET_TAGS_FAILED" {
+		t.Fatalf("error = %v, want GET_TAGS_FAILED", err)
+	}
+}
+
+func TestMockTagsDontOutliveTheirFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(m *MockRustFSClient) error
+	}{
+		{"DeleteFile", func(m *MockRustFSClient) error { return m.DeleteFile(context.Background(), "doc.txt") }},
+		{"DeleteFileResult", func(m *MockRustFSClient) error {
+			_, err := m.DeleteFileResult(context.Background(), "doc.txt")
+			return err
+		}},
+		{"Reset", func(m *MockRustFSClient) error {
+			m.Reset()
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMockRustFSClient()
+			ctx := context.Background()
+			if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "content")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			if err := m.SetTags(ctx, "doc.txt", map[string]string{"team": "blue"}); err != nil {
+				t.Fatalf("SetTags: %v", err)
+			}
+
+			if err := tt.remove(m); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "content")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			if tags, err := m.GetTags(ctx, "doc.txt"); err != nil || len(tags) != 0 {
+				t.Errorf("GetTags = %v, %v; want no tags on the new file", tags, err)
+			}
+			if files, err := m.ListFilesByTag(ctx, "team", "blue", nil); err != nil || len(files) != 0 {
+				t.Errorf("ListFilesByTag = %d files, %v; want none", len(files), err)
+			}
+		})
+	}
+}
+
+func TestMockResetClearsDownloadTokens(t *testing.T) {
+	m := NewMockRustFSClient()
+	ctx := context.Background()
+	if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "content")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	token, err := m.CreateDownloadToken(ctx, "doc.txt", time.Minute, 1)
+	if err != nil {
+		t.Fatalf("CreateDownloadToken: %v", err)
+	}
+
+	m.Reset()
+	if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "content")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if body, _, err := m.RedeemDownloadToken(ctx, token); err == nil {
+		body.Close()
+		t.Error("token issued before Reset still redeems")
+	}
+}
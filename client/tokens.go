@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// createTokenRequest is the body sent to the token endpoint to mint a download token
+type createTokenRequest struct {
+	Bucket     string `json:"bucket"`
+	Path       string `json:"path"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+	MaxUses    int    `json:"max_uses"`
+}
+
+// createTokenResponse is the token endpoint's reply to a mint request
+type createTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// tokenErrorResponse is returned by the token endpoint when a token can no longer be redeemed
+type tokenErrorResponse struct {
+	Reason string `json:"reason"`
+}
+
+// validateTokenOptions checks the arguments shared by every CreateDownloadToken implementation
+func validateTokenOptions(path string, ttl time.Duration, maxUses int) error {
+	if path == "" {
+		return apperror.NewAppError(400, "INVALID_TOKEN_REQUEST", fmt.Errorf("path is required"))
+	}
+	if ttl <= 0 {
+		return apperror.NewAppError(400, "INVALID_TOKEN_REQUEST", fmt.Errorf("ttl must be positive"))
+	}
+	if maxUses < 1 {
+		return apperror.NewAppError(400, "INVALID_TOKEN_REQUEST", fmt.Errorf("maxUses must be at least 1"))
+	}
+	return nil
+}
+
+// CreateDownloadToken mints an opaque token redeemable for path at most maxUses times within ttl.
+// Expiry and use counting are enforced by the token endpoint.
+func (c *RustFSClient) CreateDownloadToken(ctx context.Context, path string, ttl time.Duration, maxUses int) (string, error) {
+	path = c.objectKey(path)
+	if err := validateTokenOptions(path, ttl, maxUses); err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(createTokenRequest{
+		Bucket:     c.config.BucketName,
+		Path:       path,
+		TTLSeconds: int64(ttl.Round(time.Second) / time.Second),
+		MaxUses:    maxUses,
+	})
+	if err != nil {
+		return "", apperror.NewAppError(500, "TOKEN_CREATE_FAILED", err)
+	}
+
+	resp, err := c.doTokenRequest(ctx, http.MethodPost, "", payload)
+	if err != nil {
+		return "", apperror.NewAppError(500, "TOKEN_CREATE_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", apperror.NewAppError(resp.StatusCode, "TOKEN_CREATE_FAILED",
+			fmt.Errorf("token endpoint returned %s", resp.Status))
+	}
+
+	var created createTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || created.Token == "" {
+		return "", apperror.NewAppError(502, "TOKEN_CREATE_FAILED", fmt.Errorf("invalid token endpoint response: %v", err))
+	}
+
+	return created.Token, nil
+}
+
+// RedeemDownloadToken exchanges a download token for the file content, consuming one use.
+// The caller is responsible for closing the returned reader.
+func (c *RustFSClient) RedeemDownloadToken(ctx context.Context, token string) (io.ReadCloser, *types.FileInfo, error) {
+	if token == "" {
+		return nil, nil, newSentinelError(404, "TOKEN_NOT_FOUND", ErrDownloadTokenNotFound, nil)
+	}
+
+	resp, err := c.doTokenRequest(ctx, http.MethodGet, url.PathEscape(token), nil)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(500, "TOKEN_REDEEM_FAILED", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil, newSentinelError(404, "TOKEN_NOT_FOUND", ErrDownloadTokenNotFound, nil)
+	case http.StatusGone:
+		var reason tokenErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&reason)
+		resp.Body.Close()
+		if reason.Reason == "exhausted" {
+			return nil, nil, newSentinelError(410, "TOKEN_EXHAUSTED", ErrDownloadTokenExhausted, nil)
+		}
+		return nil, nil, newSentinelError(410, "TOKEN_EXPIRED", ErrDownloadTokenExpired, nil)
+	default:
+		resp.Body.Close()
+		return nil, nil, apperror.NewAppError(resp.StatusCode, "TOKEN_REDEEM_FAILED",
+			fmt.Errorf("token endpoint returned %s", resp.Status))
+	}
+
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+
+	info := &types.FileInfo{
+		Path:         resp.Header.Get("X-Rustfs-Path"),
+		Size:         size,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: lastModified,
+		CacheControl: resp.Header.Get("Cache-Control"),
+		Metadata:     make(map[string]interface{}),
+
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+	}
+
+	return resp.Body, info, nil
+}
+
+// doTokenRequest sends a request to the configured token endpoint
+func (c *RustFSClient) doTokenRequest(ctx context.Context, method, token string, payload []byte) (*http.Response, error) {
+	if c.config.TokenEndpoint == "" {
+		return nil, fmt.Errorf("RUSTFS_TOKEN_ENDPOINT is not configured")
+	}
+
+	endpoint := strings.TrimSuffix(c.config.TokenEndpoint, "/")
+	if token != "" {
+		endpoint += "/" + token
+	}
+
+	return c.doSignedRequest(ctx, method, endpoint, payload)
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestMockDownloadTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		maxUses int
+		// wait passes before the token is redeemed
+		wait time.Duration
+		// wantErrs lists the error expected from each redemption, nil for a successful one
+		wantErrs []error
+	}{
+		{"single use", time.Minute, 1, 0, []error{nil, ErrDownloadTokenExhausted}},
+		{"several uses", time.Minute, 3, 0, []error{nil, nil, nil, ErrDownloadTokenExhausted}},
+		{"expired", 5 * time.Millisecond, 3, 20 * time.Millisecond, []error{ErrDownloadTokenExpired}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMockRustFSClient()
+			ctx := context.Background()
+			if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "content")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			token, err := m.CreateDownloadToken(ctx, "doc.txt", tt.ttl, tt.maxUses)
+			if err != nil {
+				t.Fatalf("CreateDownloadToken: %v", err)
+			}
+			time.Sleep(tt.wait)
+
+			for i, wantErr := range tt.wantErrs {
+				body, _, err := m.RedeemDownloadToken(ctx, token)
+				if wantErr != nil {
+					if !errors.Is(err, wantErr) {
+						t.Fatalf("redemption %d error = %v, want %v", i+1, err, wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("redemption %d: %v", i+1, err)
+				}
+				data, _ := io.ReadAll(body)
+				body.Close()
+				if string(data) != "content" {
+					t.Errorf("redemption %d read %q", i+1, data)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		if _, _, err := NewMockRustFSClient().RedeemDownloadToken(context.Background(), "missing"); !errors.Is(err, ErrDownloadTokenNotFound) {
+			t.Errorf("error = %v, want ErrDownloadTokenNotFound", err)
+		}
+	})
+}
+
+func TestMockRedeemDownloadTokenReadsLikeDownloadFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("encrypted object is decrypted and fires hooks", func(t *testing.T) {
+		m := NewMockRustFSClient()
+		if err := m.SetEncryptionKey([]byte(strings.Repeat("k", 32))); err != nil {
+			t.Fatalf("SetEncryptionKey: %v", err)
+		}
+		var hooked []int64
+		m.AddDownloadHook(func(ctx context.Context, info *types.FileInfo, bytesRead int64) error {
+			hooked = append(hooked, bytesRead)
+			return nil
+		})
+		if _, err := m.UploadFile(ctx, uploadRequest("doc.txt", "secret content")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		token, err := m.CreateDownloadToken(ctx, "doc.txt", time.Minute, 1)
+		if err != nil {
+			t.Fatalf("CreateDownloadToken: %v", err)
+		}
+
+		body, info, err := m.RedeemDownloadToken(ctx, token)
+		if err != nil {
+			t.Fatalf("RedeemDownloadToken: %v", err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		if string(data) != "secret content" || info.Size != int64(len(data)) {
+			t.Errorf("redeemed %q with size %d, want the plaintext", data, info.Size)
+		}
+		if len(hooked) != 1 || hooked[0] != int64(len(data)) {
+			t.Errorf("hooks fired with %v, want once with %d bytes", hooked, len(data))
+		}
+	})
+
+	t.Run("archived object keeps its use", func(t *testing.T) {
+		m := NewMockRustFSClient()
+		req := uploadRequest("cold.txt", "content")
+		req.StorageClass = types.StorageClassGlacier
+		if _, err := m.UploadFile(ctx, req); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		token, err := m.CreateDownloadToken(ctx, "cold.txt", time.Minute, 1)
+		if err != nil {
+			t.Fatalf("CreateDownloadToken: %v", err)
+		}
+
+		if _, _, err := m.RedeemDownloadToken(ctx, token); !errors.Is(err, ErrObjectArchived) {
+			t.Fatalf("error = %v, want ErrObjectArchived", err)
+		}
+		if err := m.RestoreObject(ctx, "cold.txt", 1); err != nil {
+			t.Fatalf("RestoreObject: %v", err)
+		}
+		body, _, err := m.RedeemDownloadToken(ctx, token)
+		if err != nil {
+			t.Fatalf("RedeemDownloadToken after restore: %v", err)
+		}
+		body.Close()
+	})
+}
+
+func TestRedeemDownloadTokenStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"redeemed", http.StatusOK, "content", nil},
+		{"unknown token", http.StatusNotFound, "", ErrDownloadTokenNotFound},
+		{"expired token", http.StatusGone, `{"reason":"expired"}`, ErrDownloadTokenExpired},
+		{"exhausted token", http.StatusGone, `{"reason":"exhausted"}`, ErrDownloadTokenExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Rustfs-Path", "doc.txt")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+			cfg := testConfig(t, server.URL)
+			cfg.TokenEndpoint = server.URL + "/tokens"
+			c := NewRustFSClient(cfg)
+
+			body, info, err := c.RedeemDownloadToken(context.Background(), "abc")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RedeemDownloadToken: %v", err)
+			}
+			data, _ := io.ReadAll(body)
+			body.Close()
+			if string(data) != tt.body || info.Path != "doc.txt" {
+				t.Errorf("redeemed %q for %s", data, info.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/garyjdn/go-rustfs/config"
+)
+
+// transportOptions returns the connection settings of cfg, applied to every transport the client creates
+func transportOptions(cfg *config.RustFSConfig) func(t *http.Transport) {
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		// Validate rejects unusable certificate files; unvalidated configs keep the default TLS settings
+		tlsConfig = nil
+	}
+
+	proxy := http.ProxyFromEnvironment
+	// Validate rejects malformed proxy URLs as well; they fall back to the environment
+	if proxyURL, err := cfg.Proxy(); err == nil && proxyURL != nil {
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	return func(t *http.Transport) {
+		if tlsConfig != nil {
+			t.TLSClientConfig = tlsConfig.Clone()
+		}
+		t.Proxy = proxy
+	}
+}
+
+// newTransport returns a copy of http.DefaultTransport with options applied
+func newTransport(options func(t *http.Transport)) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	options(t)
+	return t
+}
+
+// expectContinueProto marks requests carrying Expect: 100-continue as HTTP/1.1. The SDK builds
+// requests without a protocol version, and net/http only waits for the interim response of
+// HTTP/1.1 requests, so without this the body is streamed at once.
+func expectContinueProto(stack *middleware.Stack) error {
+	mark := middleware.FinalizeMiddlewareFunc("RustFSExpectContinueProto", func(
+		ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+	) (middleware.FinalizeOutput, middleware.Metadata, error) {
+		if req, ok := in.Request.(*smithyhttp.Request); ok && req.Header.Get("Expect") != "" && req.ProtoMajor == 0 {
+			req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.1", 1, 1
+		}
+		return next.HandleFinalize(ctx, in)
+	})
+	return stack.Finalize.Add(mark, middleware.After)
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// VerificationScope selects how thoroughly an upload is read back and compared with its source
+type VerificationScope string
+
+const (
+	// VerifyNone skips verification
+	VerifyNone VerificationScope = ""
+	// VerifyChecksum compares the stored size and ETag with the source's MD5. Objects whose ETag
+	// is not a plain MD5, such as multipart uploads, are downloaded and hashed instead.
+	VerifyChecksum VerificationScope = "checksum"
+	// VerifyRange compares the stored size and the leading verifyRangeLength bytes
+	VerifyRange VerificationScope = "range"
+	// VerifyFull downloads the whole object and compares its SHA-256 with the source's
+	VerifyFull VerificationScope = "full"
+)
+
+// verifyRangeLength is the number of leading bytes compared by VerifyRange
+const verifyRangeLength = 64 * 1024
+
+// sourceDigest summarizes upload source content for verification
+type sourceDigest struct {
+	size   int64
+	md5    string
+	sha256 []byte
+	head   []byte
+}
+
+// uploadVerifier is the storage surface needed to read an upload back
+type uploadVerifier interface {
+	FileInfoReader
+	Downloader
+	readHead(ctx context.Context, path string, length int64) ([]byte, error)
+}
+
+// digestUploadSource returns a request reading the content of req.File from the start and a
+// function returning the content's digest once the upload has read it. Seekable sources are
+// hashed up front and rewound. Others, such as compressed streams, are hashed as the upload
+// reads them rather than buffered: uploads read an unseekable source exactly once.
+func digestUploadSource(req *types.UploadRequest) (*types.UploadRequest, func() *sourceDigest, error) {
+	var source io.Reader = req.File
+	if source == nil {
+		source = bytes.NewReader(nil)
+	}
+
+	digester := newDigestWriter()
+	rewound := *req
+	seeker, seekable := source.(io.ReadSeeker)
+	if !seekable {
+		rewound.File = io.TeeReader(source, digester)
+		return &rewound, digester.digest, nil
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.Copy(digester, seeker); err != nil {
+		return nil, nil, err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	rewound.File = seeker
+
+	return &rewound, digester.digest, nil
+}
+
+// digestWriter accumulates the digest of the bytes written to it
+type digestWriter struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+	head   []byte
+	size   int64
+}
+
+// newDigestWriter returns an empty digestWriter
+func newDigestWriter() *digestWriter {
+	return &digestWriter{md5: md5.New(), sha256: sha256.New()}
+}
+
+// Write implements io.Writer, keeping the leading verifyRangeLength bytes
+func (d *digestWriter) Write(p []byte) (int, error) {
+	d.md5.Write(p)
+	d.sha256.Write(p)
+	if remaining := verifyRangeLength - len(d.head); remaining > 0 {
+		if remaining > len(p) {
+			remaining = len(p)
+		}
+		d.head = append(d.head, p[:remaining]...)
+	}
+	d.size += int64(len(p))
+	return len(p), nil
+}
+
+// digest returns the digest of everything written so far
+func (d *digestWriter) digest() *sourceDigest {
+	return &sourceDigest{
+		size:   d.size,
+		md5:    hex.EncodeToString(d.md5.Sum(nil)),
+		sha256: d.sha256.Sum(nil),
+		head:   d.head,
+	}
+}
+
+// verifyUpload reads the uploaded object at path back with the given scope and compares it
+// with the source digest, returning ErrUploadVerificationFailed on mismatch
+func verifyUpload(ctx context.Context, storage uploadVerifier, path string, scope VerificationScope, digest *sourceDigest) error {
+	info, err := storage.GetFileInfo(ctx, path)
+	if err != nil {
+		return err
+	}
+	// Stored encrypted content can only be compared once decrypted
+	if isEncrypted(info.Metadata) {
+		return verifyFullContent(ctx, storage, path, digest)
+	}
+	if info.Size != digest.size {
+		return verificationError(path, fmt.Errorf("stored size %d, source size %d", info.Size, digest.size))
+	}
+
+	switch scope {
+	case VerifyChecksum:
+		etag := strings.Trim(info.ETag, `"`)
+		if isMD5ETag(etag) {
+			if !strings.EqualFold(etag, digest.md5) {
+				return verificationError(path, fmt.Errorf("stored ETag %s, source MD5 %s", etag, digest.md5))
+			}
+			return nil
+		}
+		return verifyFullContent(ctx, storage, path, digest)
+
+	case VerifyRange:
+		if digest.size == 0 {
+			return nil
+		}
+		head, err := storage.readHead(ctx, path, int64(len(digest.head)))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(head, digest.head) {
+			return verificationError(path, fmt.Errorf("leading %d bytes differ", len(digest.head)))
+		}
+		return nil
+
+	case VerifyFull:
+		return verifyFullContent(ctx, storage, path, digest)
+
+	default:
+		return apperror.NewAppError(400, "INVALID_VERIFICATION_SCOPE", fmt.Errorf("unknown verification scope %q", scope))
+	}
+}
+
+// verifyFullContent downloads the object and compares its SHA-256 with the source's
+func verifyFullContent(ctx context.Context, storage uploadVerifier, path string, digest *sourceDigest) error {
+	body, _, err := storage.DownloadFile(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return apperror.NewAppError(500, "DOWNLOAD_FAILED", err)
+	}
+	if !bytes.Equal(hash.Sum(nil), digest.sha256) {
+		return verificationError(path, fmt.Errorf("content checksum differs"))
+	}
+	return nil
+}
+
+// isMD5ETag reports whether etag is a plain hex MD5 digest, as produced by single-part uploads
+func isMD5ETag(etag string) bool {
+	if len(etag) != 2*md5.Size {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
+
+// verificationError wraps ErrUploadVerificationFailed with the mismatch found at path
+func verificationError(path string, cause error) error {
+	return newSentinelError(502, "UPLOAD_VERIFICATION_FAILED", ErrUploadVerificationFailed, fmt.Errorf("%s: %w", path, cause))
+}
+
+// readHead reads up to length leading bytes of a file in mock storage
+func (m *MockRustFSClient) readHead(ctx context.Context, path string, length int64) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.files[path]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+
+	content := m.contents[path]
+	if int64(len(content)) > length {
+		content = content[:length]
+	}
+	return append([]byte(nil), content...), nil
+}
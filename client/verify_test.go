@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestVerifyAfterUploadMismatch(t *testing.T) {
+	content := strings.Repeat("0123456789", verifyRangeLength/5)
+
+	// corruptReads serves reads of path with the first byte flipped, for ranged reads, full
+	// reads or both
+	corruptReads := func(fake *fakeS3, path string, ranged, full bool) {
+		fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method != http.MethodGet || r.URL.Path != "/"+fake.bucket+"/"+path {
+				return false
+			}
+			rangeHeader := r.Header.Get("Range")
+			if (rangeHeader != "" && !ranged) || (rangeHeader == "" && !full) {
+				return false
+			}
+			object, _ := fake.get(path)
+			data := append([]byte{object.data[0] ^ 0xff}, object.data[1:]...)
+			status := http.StatusOK
+			if rangeHeader != "" {
+				var start, end int
+				fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+				end = min(end, len(data)-1)
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+				data, status = data[start:end+1], http.StatusPartialContent
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.WriteHeader(status)
+			w.Write(data)
+			return true
+		}
+	}
+
+	tests := []struct {
+		name         string
+		scope        VerificationScope
+		ranged, full bool
+		wantMismatch bool
+	}{
+		{"range scope with a corrupt range", VerifyRange, true, false, true},
+		{"range scope with intact leading bytes", VerifyRange, false, true, false},
+		{"full scope with a corrupt body", VerifyFull, false, true, true},
+		{"full scope ignores ranged reads", VerifyFull, true, false, false},
+		{"no verification", VerifyNone, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newFakeS3Client(t)
+			corruptReads(fake, "data.txt", tt.ranged, tt.full)
+
+			_, err := c.UploadFileWithOptions(context.Background(), uploadRequest("data.txt", content),
+				&UploadOptions{VerifyAfterUpload: tt.scope})
+			if tt.wantMismatch {
+				if !errors.Is(err, ErrUploadVerificationFailed) || errorCode(err) != "UPLOAD_VERIFICATION_FAILED" {
+					t.Errorf("UploadFileWithOptions = %v, want ErrUploadVerificationFailed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("UploadFileWithOptions: %v", err)
+			}
+		})
+	}
+
+	t.Run("truncated object fails every scope", func(t *testing.T) {
+		for _, scope := range []VerificationScope{VerifyChecksum, VerifyRange, VerifyFull} {
+			c, fake := newFakeS3Client(t)
+			fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Method == http.MethodPut && r.URL.Path == "/"+fake.bucket+"/data.txt" {
+					fake.put("data.txt", []byte(content[:len(content)-1]), nil)
+					w.WriteHeader(http.StatusOK)
+					return true
+				}
+				return false
+			}
+
+			_, err := c.UploadFileWithOptions(context.Background(), uploadRequest("data.txt", content),
+				&UploadOptions{VerifyAfterUpload: scope})
+			if !errors.Is(err, ErrUploadVerificationFailed) {
+				t.Errorf("%s scope: UploadFileWithOptions = %v, want ErrUploadVerificationFailed", scope, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// WebhookSignatureHeader is the request header carrying a webhook callback's signature
+const WebhookSignatureHeader = "X-RustFS-Signature"
+
+// webhookSignaturePrefix optionally precedes the hex digest in the signature header
+const webhookSignaturePrefix = "sha256="
+
+// VerifyWebhookSignature checks that payload, the raw body of a webhook callback, was signed with
+// secret. signatureHeader is the hex HMAC-SHA256 of the body, optionally prefixed with "sha256=".
+// A missing or wrong signature fails with ErrInvalidWebhookSignature, which carries status 401.
+func VerifyWebhookSignature(payload []byte, signatureHeader string, secret string) error {
+	if secret == "" {
+		return newSentinelError(401, "INVALID_WEBHOOK_SIGNATURE", ErrInvalidWebhookSignature, fmt.Errorf("secret is empty"))
+	}
+
+	signature := strings.TrimPrefix(strings.TrimSpace(signatureHeader), webhookSignaturePrefix)
+	provided, err := hex.DecodeString(signature)
+	if err != nil || len(provided) != sha256.Size {
+		return newSentinelError(401, "INVALID_WEBHOOK_SIGNATURE", ErrInvalidWebhookSignature, fmt.Errorf("malformed signature"))
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(provided, mac.Sum(nil)) {
+		return newSentinelError(401, "INVALID_WEBHOOK_SIGNATURE", ErrInvalidWebhookSignature, nil)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-apperror"
+)
+
+const (
+	testWebhookSecret  = "whsec_test"
+	testWebhookPayload = `{"event":"object.created","path":"docs/a.txt"}`
+	// testWebhookSignature is the HMAC-SHA256 of testWebhookPayload under testWebhookSecret
+	testWebhookSignature = "6b021d47ba1c58182d6d1a76b6c0705ae5697893a919bb7c4103150e64b71938"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		signature string
+		secret    string
+		wantErr   bool
+	}{
+		{"known-good signature", testWebhookPayload, testWebhookSignature, testWebhookSecret, false},
+		{"prefixed signature", testWebhookPayload, "sha256=" + testWebhookSignature, testWebhookSecret, false},
+		{"uppercase hex", testWebhookPayload, strings.ToUpper(testWebhookSignature), testWebhookSecret, false},
+		{"surrounding whitespace", testWebhookPayload, " " + testWebhookSignature + "\n", testWebhookSecret, false},
+		{"tampered payload", strings.Replace(testWebhookPayload, "a.txt", "b.txt", 1), testWebhookSignature, testWebhookSecret, true},
+		{"wrong secret", testWebhookPayload, testWebhookSignature, "whsec_other", true},
+		{"empty secret", testWebhookPayload, testWebhookSignature, "", true},
+		{"missing signature", testWebhookPayload, "", testWebhookSecret, true},
+		{"truncated signature", testWebhookPayload, testWebhookSignature[:32], testWebhookSecret, true},
+		{"not hex", testWebhookPayload, strings.Repeat("zz", 32), testWebhookSecret, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature([]byte(tt.payload), tt.signature, tt.secret)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("VerifyWebhookSignature: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Fatalf("error = %v, want ErrInvalidWebhookSignature", err)
+			}
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != http.StatusUnauthorized {
+				t.Errorf("error = %#v, want status 401", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhookSignatureHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifyWebhookSignature(body, r.Header.Get(WebhookSignatureHeader), testWebhookSecret); err != nil {
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) {
+				w.WriteHeader(appErr.Code)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name       string
+		payload    string
+		signature  string
+		wantStatus int
+	}{
+		{"signed callback", testWebhookPayload, testWebhookSignature, http.StatusNoContent},
+		{"tampered callback", testWebhookPayload + " ", testWebhookSignature, http.StatusUnauthorized},
+		{"unsigned callback", testWebhookPayload, "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte(tt.payload)))
+			if tt.signature != "" {
+				req.Header.Set(WebhookSignatureHeader, tt.signature)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
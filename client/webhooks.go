@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/garyjdn/go-apperror"
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+// Webhook event types
+const (
+	WebhookEventUpload = "upload"
+	WebhookEventDelete = "delete"
+)
+
+// registerWebhookRequest is the body sent to the webhooks endpoint to register a callback
+type registerWebhookRequest struct {
+	Bucket string   `json:"bucket"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// registerWebhookResponse is the webhooks endpoint's reply to a registration
+type registerWebhookResponse struct {
+	ID string `json:"id"`
+}
+
+// webhookRegistry maps callback URLs to the IDs the server assigned them
+type webhookRegistry struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// newWebhookRegistry creates an empty registry
+func newWebhookRegistry() *webhookRegistry {
+	return &webhookRegistry{ids: make(map[string]string)}
+}
+
+// validateWebhook checks the arguments shared by every RegisterUploadWebhook implementation
+func validateWebhook(callbackURL string, events []string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperror.NewAppError(400, "INVALID_WEBHOOK", fmt.Errorf("url must be an absolute http or https URL"))
+	}
+	if len(events) == 0 {
+		return apperror.NewAppError(400, "INVALID_WEBHOOK", fmt.Errorf("at least one event is required"))
+	}
+	for _, event := range events {
+		if event == "" {
+			return apperror.NewAppError(400, "INVALID_WEBHOOK", fmt.Errorf("events cannot be empty"))
+		}
+	}
+	return nil
+}
+
+// newWebhookEvent builds a synthetic event from data, taking the path and ETag from its
+// "path" and "etag" entries when present
+func newWebhookEvent(bucket, event string, data map[string]interface{}) types.WebhookEvent {
+	path, _ := data["path"].(string)
+	etag, _ := data["etag"].(string)
+	return types.WebhookEvent{
+		Type:      event,
+		Bucket:    bucket,
+		Path:      path,
+		ETag:      etag,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// RegisterUploadWebhook asks RustFS to call url for the given events on the bucket. Registering
+// a URL again replaces its previous registration.
+func (c *RustFSClient) RegisterUploadWebhook(ctx context.Context, url string, events []string) error {
+	if err := validateWebhook(url, events); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(registerWebhookRequest{Bucket: c.config.BucketName, URL: url, Events: events})
+	if err != nil {
+		return apperror.NewAppError(500, "WEBHOOK_REGISTER_FAILED", err)
+	}
+
+	resp, err := c.doSignedRequest(ctx, http.MethodPost, c.apiURL("webhooks"), payload)
+	if err != nil {
+		return apperror.NewAppError(500, "WEBHOOK_REGISTER_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return apperror.NewAppError(resp.StatusCode, "WEBHOOK_REGISTER_FAILED",
+			fmt.Errorf("webhooks endpoint returned %s", resp.Status))
+	}
+
+	var registered registerWebhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&registered); err != nil || registered.ID == "" {
+		return apperror.NewAppError(502, "WEBHOOK_REGISTER_FAILED", fmt.Errorf("invalid webhooks endpoint response: %v", err))
+	}
+
+	c.webhooks.mu.Lock()
+	previous := c.webhooks.ids[url]
+	c.webhooks.ids[url] = registered.ID
+	c.webhooks.mu.Unlock()
+
+	if previous != "" && previous != registered.ID {
+		// The new registration is already active; a failure here leaves a stale duplicate
+		return c.deleteWebhook(ctx, previous)
+	}
+	return nil
+}
+
+// UnregisterWebhook removes the webhook registered for url by this client. It fails with
+// ErrWebhookNotFound when this client never registered url.
+func (c *RustFSClient) UnregisterWebhook(ctx context.Context, url string) error {
+	c.webhooks.mu.Lock()
+	id, ok := c.webhooks.ids[url]
+	c.webhooks.mu.Unlock()
+	if !ok {
+		return newSentinelError(404, "WEBHOOK_NOT_FOUND", ErrWebhookNotFound, nil)
+	}
+
+	if err := c.deleteWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	c.webhooks.mu.Lock()
+	if c.webhooks.ids[url] == id {
+		delete(c.webhooks.ids, url)
+	}
+	c.webhooks.mu.Unlock()
+	return nil
+}
+
+// deleteWebhook deletes a registration by ID; one already gone on the server counts as deleted
+func (c *RustFSClient) deleteWebhook(ctx context.Context, id string) error {
+	resp, err := c.doSignedRequest(ctx, http.MethodDelete, c.apiURL("webhooks/"+url.PathEscape(id)), nil)
+	if err != nil {
+		return apperror.NewAppError(500, "WEBHOOK_UNREGISTER_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return apperror.NewAppError(resp.StatusCode, "WEBHOOK_UNREGISTER_FAILED",
+			fmt.Errorf("webhooks endpoint returned %s", resp.Status))
+	}
+}
+
+// TriggerWebhook asks RustFS to deliver a synthetic event to the webhooks registered for it,
+// for checking that receivers are reachable. The event's path and ETag are taken from the
+// "path" and "etag" entries of data.
+func (c *RustFSClient) TriggerWebhook(ctx context.Context, event string, data map[string]interface{}) error {
+	if event == "" {
+		return apperror.NewAppError(400, "INVALID_WEBHOOK", fmt.Errorf("event is required"))
+	}
+
+	payload, err := json.Marshal(newWebhookEvent(c.config.BucketName, event, data))
+	if err != nil {
+		return apperror.NewAppError(400, "WEBHOOK_TRIGGER_FAILED", err)
+	}
+
+	resp, err := c.doSignedRequest(ctx, http.MethodPost, c.apiURL("webhooks/trigger"), payload)
+	if err != nil {
+		return apperror.NewAppError(500, "WEBHOOK_TRIGGER_FAILED", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	default:
+		return apperror.NewAppError(resp.StatusCode, "WEBHOOK_TRIGGER_FAILED",
+			fmt.Errorf("webhooks endpoint returned %s", resp.Status))
+	}
+}
+
+// RegisterUploadWebhook records a webhook registration in mock storage
+func (m *MockRustFSClient) RegisterUploadWebhook(ctx context.Context, url string, events []string) error {
+	if err := validateWebhook(url, events); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	if m.webhooks == nil {
+		m.webhooks = make(map[string][]string)
+	}
+	m.webhooks[url] = append([]string(nil), events...)
+	return nil
+}
+
+// UnregisterWebhook removes a webhook registration from mock storage
+func (m *MockRustFSClient) UnregisterWebhook(ctx context.Context, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.webhooks[url]; !ok {
+		return newSentinelError(404, "WEBHOOK_NOT_FOUND", ErrWebhookNotFound, nil)
+	}
+	delete(m.webhooks, url)
+	return nil
+}
+
+// TriggerWebhook records a synthetic event; see GetTriggeredWebhooks
+func (m *MockRustFSClient) TriggerWebhook(ctx context.Context, event string, data map[string]interface{}) error {
+	if event == "" {
+		return apperror.NewAppError(400, "INVALID_WEBHOOK", fmt.Errorf("event is required"))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shouldFail {
+		m.shouldFail = false // Reset failure mode
+		return m.failError
+	}
+
+	m.webhookEvents = append(m.webhookEvents, newWebhookEvent("", event, data))
+	return nil
+}
+
+// GetWebhooks returns the registered webhook URLs and their events
+func (m *MockRustFSClient) GetWebhooks() map[string][]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	webhooks := make(map[string][]string, len(m.webhooks))
+	for url, events := range m.webhooks {
+		webhooks[url] = append([]string(nil), events...)
+	}
+	return webhooks
+}
+
+// GetTriggeredWebhooks returns the events passed to TriggerWebhook
+func (m *MockRustFSClient) GetTriggeredWebhooks() []types.WebhookEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := make([]types.WebhookEvent, len(m.webhookEvents))
+	copy(events, m.webhookEvents)
+	return events
+}
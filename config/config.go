@@ -1,11 +1,74 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// MaxMultipartParts is the maximum number of parts a multipart upload may consist of
+const MaxMultipartParts = utils.MaxUploadParts
+
+// Policies for operations whose context carries no user_id
+const (
+	MissingUserPolicySystem    = "system"    // attribute to the "system" user
+	MissingUserPolicyAnonymous = "anonymous" // attribute to AnonymousPrincipal
+	MissingUserPolicyReject    = "reject"    // refuse the operation as unauthenticated
+)
+
+// Handling of per-key metadata keys that differ only in case
+const (
+	MetadataKeyCollisionReject = "reject" // fail the request
+	MetadataKeyCollisionMerge  = "merge"  // keep one value per key, see PerKeyMetadataEncoder
+)
+
+// Handling of metadata values longer than MetadataValueLimit
+const (
+	MetadataOversizeReject     = "reject"      // fail the request
+	MetadataOversizeSideObject = "side-object" // store the value in a separate object referenced from metadata
+)
+
+// Async audit queue overflow policies, see audit.OverflowPolicy
+const (
+	AuditOverflowBlock            = "block"              // wait for space, never lose events
+	AuditOverflowDropNewest       = "drop_newest"        // discard the event being logged
+	AuditOverflowDropOldest       = "drop_oldest"        // discard the oldest queued event
+	AuditOverflowBlockWithTimeout = "block_with_timeout" // wait up to AuditOverflowTimeout, then fail
+)
+
+// Audit event modes
+const (
+	AuditEventModeMulti    = "multi"    // one event per signal, e.g. an upload event and a slow-upload event
+	AuditEventModeCombined = "combined" // one enriched event per logical operation
+)
+
+// EncryptionAES256GCM is the only client-side encryption algorithm
+const EncryptionAES256GCM = "AES-256-GCM"
+
+// EncryptionKeySize is the length in bytes of an AES-256 key
+const EncryptionKeySize = 32
+
+// Ways of authenticating requests to RustFS
+const (
+	AuthSchemeSigV4  = "sigv4"  // AWS Signature Version 4 with AccessKey, SecretKey and Region
+	AuthSchemeBearer = "bearer" // Authorization: Bearer APIKey
+)
+
+// Strategies for choosing among BaseURLs
+const (
+	LoadBalanceRoundRobin       = "round-robin"       // rotate through healthy endpoints in order
+	LoadBalanceLeastOutstanding = "least-outstanding" // pick the healthy endpoint with the fewest requests in flight
 )
 
 // RustFSConfig represents configuration for RustFS client
@@ -16,25 +79,77 @@ type RustFSConfig struct {
 	SecretKey  string `json:"secret_key" env:"RUSTFS_SECRET_KEY"`
 	Region     string `json:"region" env:"RUSTFS_REGION"`
 	BucketName string `json:"bucket_name" env:"RUSTFS_BUCKET_NAME"`
+	// AuthScheme selects how requests are authenticated; empty means AuthSchemeSigV4. APIKey is
+	// only used by AuthSchemeBearer.
+	AuthScheme string `json:"auth_scheme" env:"RUSTFS_AUTH_SCHEME"`
+	APIKey     string `json:"api_key" env:"RUSTFS_API_KEY"`
+
+	// BaseURLs spreads S3 requests across several endpoints of one cluster; when empty only
+	// BaseURL is used. BaseURL still serves the management API and public file URLs.
+	BaseURLs            []string `json:"base_urls" env:"RUSTFS_BASE_URLS"`
+	LoadBalanceStrategy string   `json:"load_balance_strategy" env:"RUSTFS_LOAD_BALANCE_STRATEGY"`
+	// An endpoint failing EndpointFailureThreshold requests in a row leaves the rotation until a
+	// probe, sent every EndpointProbeInterval, gets a response; zero never removes endpoints
+	EndpointFailureThreshold int           `json:"endpoint_failure_threshold" env:"RUSTFS_ENDPOINT_FAILURE_THRESHOLD"`
+	EndpointProbeInterval    time.Duration `json:"endpoint_probe_interval" env:"RUSTFS_ENDPOINT_PROBE_INTERVAL"`
 
 	// Performance settings
 	Timeout    time.Duration `json:"timeout" env:"RUSTFS_TIMEOUT"`
 	RetryCount int           `json:"retry_count" env:"RUSTFS_RETRY_COUNT"`
+	// RetryConfig is the backoff for retried requests, built by LoadConfig from RetryCount,
+	// RUSTFS_RETRY_DELAY and RUSTFS_RETRY_BACKOFF. When nil it is derived from RetryCount.
+	RetryConfig *types.RetryConfig `json:"retry_config,omitempty"`
+	PingTimeout time.Duration      `json:"ping_timeout" env:"RUSTFS_PING_TIMEOUT"`
+
+	// Uploads at least ExpectContinueThreshold bytes send Expect: 100-continue and wait up to
+	// ExpectContinueTimeout for the server to accept them before streaming the body
+	ExpectContinueThreshold int64         `json:"expect_continue_threshold" env:"RUSTFS_EXPECT_CONTINUE_THRESHOLD"`
+	ExpectContinueTimeout   time.Duration `json:"expect_continue_timeout" env:"RUSTFS_EXPECT_CONTINUE_TIMEOUT"`
 
 	// File validation settings
 	MaxFileSize    int64    `json:"max_file_size" env:"RUSTFS_MAX_FILE_SIZE"`
 	AllowedTypes   []string `json:"allowed_types" env:"RUSTFS_ALLOWED_TYPES"`
 	ScanForMalware bool     `json:"scan_for_malware" env:"RUSTFS_SCAN_MALWARE"`
 
+	// Integrity settings
+	ChecksumAlgorithm string `json:"checksum_algorithm" env:"RUSTFS_CHECKSUM_ALGORITHM"`
+
 	// Audit settings
 	EnableAudit   bool                   `json:"enable_audit" env:"RUSTFS_ENABLE_AUDIT"`
 	AuditService  string                 `json:"audit_service" env:"RUSTFS_AUDIT_SERVICE"`
 	AuditMetadata map[string]interface{} `json:"audit_metadata"`
+	// AuditOverflowPolicy applies when the async audit queue is full
+	AuditOverflowPolicy  string        `json:"audit_overflow_policy" env:"RUSTFS_AUDIT_OVERFLOW_POLICY"`
+	AuditOverflowTimeout time.Duration `json:"audit_overflow_timeout" env:"RUSTFS_AUDIT_OVERFLOW_TIMEOUT"`
+	// AuditWorkers is the number of goroutines writing queued audit events; more than one may reorder them
+	AuditWorkers int `json:"audit_workers" env:"RUSTFS_AUDIT_WORKERS"`
+	// AuditEventMode selects separate events per signal or one combined event per operation
+	AuditEventMode string `json:"audit_event_mode" env:"RUSTFS_AUDIT_EVENT_MODE"`
+
+	// Identity settings
+	MissingUserPolicy  string `json:"missing_user_policy" env:"RUSTFS_MISSING_USER_POLICY"`
+	AnonymousPrincipal string `json:"anonymous_principal" env:"RUSTFS_ANONYMOUS_PRINCIPAL"`
 
 	// Security settings
-	EnableEncryption bool     `json:"enable_encryption" env:"RUSTFS_ENABLE_ENCRYPTION"`
-	EncryptionKey    string   `json:"encryption_key" env:"RUSTFS_ENCRYPTION_KEY"`
-	AllowedOrigins   []string `json:"allowed_origins" env:"RUSTFS_ALLOWED_ORIGINS"`
+	EnableEncryption bool `json:"enable_encryption" env:"RUSTFS_ENABLE_ENCRYPTION"`
+	// EncryptionKey is 32 raw bytes, 64 hex characters or the standard base64 encoding of 32 bytes
+	EncryptionKey string `json:"encryption_key" env:"RUSTFS_ENCRYPTION_KEY"`
+	// EncryptionAlgorithm and EncryptionKeyID must match the metadata of encrypted objects on download
+	EncryptionAlgorithm string   `json:"encryption_algorithm" env:"RUSTFS_ENCRYPTION_ALGORITHM"`
+	EncryptionKeyID     string   `json:"encryption_key_id" env:"RUSTFS_ENCRYPTION_KEY_ID"`
+	AllowedOrigins      []string `json:"allowed_origins" env:"RUSTFS_ALLOWED_ORIGINS"`
+	TokenEndpoint       string   `json:"token_endpoint" env:"RUSTFS_TOKEN_ENDPOINT"`
+
+	// TLS settings. CACertFile is a PEM bundle trusted in addition to the system roots;
+	// ClientCertFile and ClientKeyFile are a PEM key pair presented for mutual TLS.
+	CACertFile         string `json:"ca_cert_file" env:"RUSTFS_CA_CERT_FILE"`
+	ClientCertFile     string `json:"client_cert_file" env:"RUSTFS_CLIENT_CERT_FILE"`
+	ClientKeyFile      string `json:"client_key_file" env:"RUSTFS_CLIENT_KEY_FILE"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" env:"RUSTFS_INSECURE_SKIP_VERIFY"`
+
+	// ProxyURL routes all requests through an http, https or socks5 proxy; when empty the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply
+	ProxyURL string `json:"proxy_url" env:"RUSTFS_PROXY_URL"`
 
 	// Performance tuning
 	ConcurrentUploads int           `json:"concurrent_uploads" env:"RUSTFS_CONCURRENT_UPLOADS"`
@@ -42,27 +157,100 @@ type RustFSConfig struct {
 	CompressionLevel  int           `json:"compression_level" env:"RUSTFS_COMPRESSION_LEVEL"`
 	CacheEnabled      bool          `json:"cache_enabled" env:"RUSTFS_CACHE_ENABLED"`
 	CacheTTL          time.Duration `json:"cache_ttl" env:"RUSTFS_CACHE_TTL"`
+	BandwidthLimit    int64         `json:"bandwidth_limit" env:"RUSTFS_BANDWIDTH_LIMIT"`
+
+	// Small-object body cache, active when CacheEnabled and BodyCacheMaxObjectSize > 0
+	BodyCacheMaxObjectSize int64 `json:"body_cache_max_object_size" env:"RUSTFS_BODY_CACHE_MAX_OBJECT_SIZE"`
+	BodyCacheMaxBytes      int64 `json:"body_cache_max_bytes" env:"RUSTFS_BODY_CACHE_MAX_BYTES"`
+
+	// InfoCoalesceWindow is how long a CoalescingFileInfoReader collects GetFileInfo calls into one batch
+	InfoCoalesceWindow time.Duration `json:"info_coalesce_window" env:"RUSTFS_INFO_COALESCE_WINDOW"`
+
+	// DownloadResumeAttempts is how often a download stream cut short is resumed with a Range
+	// request; 0 surfaces the truncation to the reader as io.ErrUnexpectedEOF right away
+	DownloadResumeAttempts int `json:"download_resume_attempts" env:"RUSTFS_DOWNLOAD_RESUME_ATTEMPTS"`
+
+	// Metadata settings
+	MetadataSchemaVersion string `json:"metadata_schema_version" env:"RUSTFS_METADATA_SCHEMA_VERSION"`
+	MetadataEncoding      string `json:"metadata_encoding" env:"RUSTFS_METADATA_ENCODING"`
+	MetadataKeyCollision  string `json:"metadata_key_collision" env:"RUSTFS_METADATA_KEY_COLLISION"`
+	// MetadataValueLimit is the largest metadata value in bytes sent as a header; 0 disables the check
+	MetadataValueLimit     int    `json:"metadata_value_limit" env:"RUSTFS_METADATA_VALUE_LIMIT"`
+	MetadataOversizePolicy string `json:"metadata_oversize_policy" env:"RUSTFS_METADATA_OVERSIZE_POLICY"`
+	// DefaultMetadata is merged into the metadata of every upload; caller values win on conflict
+	DefaultMetadata map[string]string `json:"default_metadata" env:"RUSTFS_DEFAULT_METADATA"`
+
+	// Capability settings
+	CapabilityRefresh    time.Duration `json:"capability_refresh" env:"RUSTFS_CAPABILITY_REFRESH"`
+	EnabledCapabilities  []string      `json:"enabled_capabilities" env:"RUSTFS_ENABLED_CAPABILITIES"`
+	DisabledCapabilities []string      `json:"disabled_capabilities" env:"RUSTFS_DISABLED_CAPABILITIES"`
+
+	// Listing settings
+	ListDirectoryMode bool `json:"list_directory_mode" env:"RUSTFS_LIST_DIRECTORY_MODE"`
+
+	// SyntheticDirectories makes GetFileInfo on a path without an object but with children
+	// under "path/" return a directory FileInfo with IsDir set instead of ErrFileNotFound
+	SyntheticDirectories bool `json:"synthetic_directories" env:"RUSTFS_SYNTHETIC_DIRECTORIES"`
+
+	// NormalizePaths strips leading and trailing slashes and "." segments from object paths
+	// and collapses doubled slashes; disable it for servers where those are significant
+	NormalizePaths bool `json:"normalize_paths" env:"RUSTFS_NORMALIZE_PATHS"`
 }
 
 // LoadConfig loads configuration from environment variables with defaults
 func LoadConfig() *RustFSConfig {
-	config := &RustFSConfig{
+	config := loadEnvConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		panic(fmt.Sprintf("Invalid RustFS configuration: %v", err))
+	}
+
+	for _, warning := range config.Warnings() {
+		log.Printf("[RUSTFS] configuration warning: %s", warning)
+	}
+
+	return config
+}
+
+// loadEnvConfig builds the configuration from environment variables and defaults without validating it
+func loadEnvConfig() *RustFSConfig {
+	return &RustFSConfig{
 		// Connection defaults
 		BaseURL:    getEnvOrDefault("RUSTFS_BASE_URL", "http://localhost:8080"),
 		AccessKey:  getEnvOrDefault("RUSTFS_ACCESS_KEY", ""),
 		SecretKey:  getEnvOrDefault("RUSTFS_SECRET_KEY", ""),
 		Region:     getEnvOrDefault("RUSTFS_REGION", "us-east-1"),
 		BucketName: getEnvOrDefault("RUSTFS_BUCKET_NAME", "default"),
+		AuthScheme: getEnvOrDefault("RUSTFS_AUTH_SCHEME", AuthSchemeSigV4),
+		APIKey:     getEnvOrDefault("RUSTFS_API_KEY", ""),
+
+		BaseURLs:                 getStringSliceEnvOrDefault("RUSTFS_BASE_URLS", nil),
+		LoadBalanceStrategy:      getEnvOrDefault("RUSTFS_LOAD_BALANCE_STRATEGY", LoadBalanceRoundRobin),
+		EndpointFailureThreshold: getIntEnvOrDefault("RUSTFS_ENDPOINT_FAILURE_THRESHOLD", 3),
+		EndpointProbeInterval:    getDurationEnvOrDefault("RUSTFS_ENDPOINT_PROBE_INTERVAL", 10*time.Second),
 
 		// Performance defaults
-		Timeout:    getDurationEnvOrDefault("RUSTFS_TIMEOUT", 30*time.Second),
-		RetryCount: getIntEnvOrDefault("RUSTFS_RETRY_COUNT", 3),
+		Timeout:     getDurationEnvOrDefault("RUSTFS_TIMEOUT", 30*time.Second),
+		RetryCount:  getIntEnvOrDefault("RUSTFS_RETRY_COUNT", 3),
+		PingTimeout: getDurationEnvOrDefault("RUSTFS_PING_TIMEOUT", 2*time.Second),
+		RetryConfig: &types.RetryConfig{
+			MaxAttempts: getIntEnvOrDefault("RUSTFS_RETRY_COUNT", 3) + 1,
+			Delay:       getDurationEnvOrDefault("RUSTFS_RETRY_DELAY", 100*time.Millisecond),
+			Backoff:     getFloatEnvOrDefault("RUSTFS_RETRY_BACKOFF", 2.0),
+		},
+
+		ExpectContinueThreshold: getInt64EnvOrDefault("RUSTFS_EXPECT_CONTINUE_THRESHOLD", 2*1024*1024), // 2MB, 0 disables
+		ExpectContinueTimeout:   getDurationEnvOrDefault("RUSTFS_EXPECT_CONTINUE_TIMEOUT", 1*time.Second),
 
 		// File validation defaults
 		MaxFileSize:    getInt64EnvOrDefault("RUSTFS_MAX_FILE_SIZE", 100*1024*1024), // 100MB
 		AllowedTypes:   getStringSliceEnvOrDefault("RUSTFS_ALLOWED_TYPES", []string{"image/*"}),
 		ScanForMalware: getBoolEnvOrDefault("RUSTFS_SCAN_MALWARE", false),
 
+		// Integrity defaults (empty disables checksum computation)
+		ChecksumAlgorithm: getEnvOrDefault("RUSTFS_CHECKSUM_ALGORITHM", ""),
+
 		// Audit defaults
 		EnableAudit:  getBoolEnvOrDefault("RUSTFS_ENABLE_AUDIT", true),
 		AuditService: getEnvOrDefault("RUSTFS_AUDIT_SERVICE", "rustfs-client"),
@@ -70,11 +258,31 @@ func LoadConfig() *RustFSConfig {
 			"version":     "1.0.0",
 			"environment": getEnvOrDefault("ENVIRONMENT", "development"),
 		},
+		AuditOverflowPolicy:  getEnvOrDefault("RUSTFS_AUDIT_OVERFLOW_POLICY", AuditOverflowBlock),
+		AuditOverflowTimeout: getDurationEnvOrDefault("RUSTFS_AUDIT_OVERFLOW_TIMEOUT", 1*time.Second),
+		AuditWorkers:         getIntEnvOrDefault("RUSTFS_AUDIT_WORKERS", 1),
+		AuditEventMode:       getEnvOrDefault("RUSTFS_AUDIT_EVENT_MODE", AuditEventModeMulti),
+
+		// Identity defaults
+		MissingUserPolicy:  getEnvOrDefault("RUSTFS_MISSING_USER_POLICY", MissingUserPolicySystem),
+		AnonymousPrincipal: getEnvOrDefault("RUSTFS_ANONYMOUS_PRINCIPAL", "anonymous"),
 
 		// Security defaults
 		EnableEncryption: getBoolEnvOrDefault("RUSTFS_ENABLE_ENCRYPTION", false),
 		EncryptionKey:    getEnvOrDefault("RUSTFS_ENCRYPTION_KEY", ""),
-		AllowedOrigins:   getStringSliceEnvOrDefault("RUSTFS_ALLOWED_ORIGINS", []string{"*"}),
+
+		EncryptionAlgorithm: getEnvOrDefault("RUSTFS_ENCRYPTION_ALGORITHM", EncryptionAES256GCM),
+		EncryptionKeyID:     getEnvOrDefault("RUSTFS_ENCRYPTION_KEY_ID", ""),
+		AllowedOrigins:      getStringSliceEnvOrDefault("RUSTFS_ALLOWED_ORIGINS", []string{"*"}),
+		TokenEndpoint:       getEnvOrDefault("RUSTFS_TOKEN_ENDPOINT", ""), // empty disables download tokens
+
+		// TLS defaults (system roots, no client certificate)
+		CACertFile:         getEnvOrDefault("RUSTFS_CA_CERT_FILE", ""),
+		ClientCertFile:     getEnvOrDefault("RUSTFS_CLIENT_CERT_FILE", ""),
+		ClientKeyFile:      getEnvOrDefault("RUSTFS_CLIENT_KEY_FILE", ""),
+		InsecureSkipVerify: getBoolEnvOrDefault("RUSTFS_INSECURE_SKIP_VERIFY", false),
+
+		ProxyURL: getEnvOrDefault("RUSTFS_PROXY_URL", ""), // empty uses the standard proxy environment variables
 
 		// Performance tuning defaults
 		ConcurrentUploads: getIntEnvOrDefault("RUSTFS_CONCURRENT_UPLOADS", 5),
@@ -82,14 +290,33 @@ func LoadConfig() *RustFSConfig {
 		CompressionLevel:  getIntEnvOrDefault("RUSTFS_COMPRESSION_LEVEL", 6),
 		CacheEnabled:      getBoolEnvOrDefault("RUSTFS_CACHE_ENABLED", true),
 		CacheTTL:          getDurationEnvOrDefault("RUSTFS_CACHE_TTL", 1*time.Hour),
-	}
+		BandwidthLimit:    getInt64EnvOrDefault("RUSTFS_BANDWIDTH_LIMIT", 0), // bytes/s, 0 = unlimited
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		panic(fmt.Sprintf("Invalid RustFS configuration: %v", err))
-	}
+		BodyCacheMaxObjectSize: getInt64EnvOrDefault("RUSTFS_BODY_CACHE_MAX_OBJECT_SIZE", 0),      // disabled
+		BodyCacheMaxBytes:      getInt64EnvOrDefault("RUSTFS_BODY_CACHE_MAX_BYTES", 32*1024*1024), // 32MB
 
-	return config
+		InfoCoalesceWindow:     getDurationEnvOrDefault("RUSTFS_INFO_COALESCE_WINDOW", 2*time.Millisecond),
+		DownloadResumeAttempts: getIntEnvOrDefault("RUSTFS_DOWNLOAD_RESUME_ATTEMPTS", 0),
+
+		// Metadata defaults (empty disables schema version tagging)
+		MetadataSchemaVersion: getEnvOrDefault("RUSTFS_METADATA_SCHEMA_VERSION", ""),
+		MetadataEncoding:      getEnvOrDefault("RUSTFS_METADATA_ENCODING", "per-key"),
+		MetadataKeyCollision:  getEnvOrDefault("RUSTFS_METADATA_KEY_COLLISION", MetadataKeyCollisionReject),
+
+		MetadataValueLimit:     getIntEnvOrDefault("RUSTFS_METADATA_VALUE_LIMIT", 2048),
+		MetadataOversizePolicy: getEnvOrDefault("RUSTFS_METADATA_OVERSIZE_POLICY", MetadataOversizeReject),
+		DefaultMetadata:        getMapEnvOrDefault("RUSTFS_DEFAULT_METADATA", nil),
+
+		// Capability defaults (overrides force features on or off regardless of what the server reports)
+		CapabilityRefresh:    getDurationEnvOrDefault("RUSTFS_CAPABILITY_REFRESH", 5*time.Minute),
+		EnabledCapabilities:  getStringSliceEnvOrDefault("RUSTFS_ENABLED_CAPABILITIES", nil),
+		DisabledCapabilities: getStringSliceEnvOrDefault("RUSTFS_DISABLED_CAPABILITIES", nil),
+
+		// Path defaults
+		ListDirectoryMode:    getBoolEnvOrDefault("RUSTFS_LIST_DIRECTORY_MODE", false),
+		SyntheticDirectories: getBoolEnvOrDefault("RUSTFS_SYNTHETIC_DIRECTORIES", false),
+		NormalizePaths:       getBoolEnvOrDefault("RUSTFS_NORMALIZE_PATHS", true),
+	}
 }
 
 // Validate validates the configuration
@@ -98,34 +325,182 @@ func (c *RustFSConfig) Validate() error {
 		return fmt.Errorf("RUSTFS_BASE_URL is required")
 	}
 
-	if c.AccessKey == "" {
-		return fmt.Errorf("RUSTFS_ACCESS_KEY is required")
+	for _, baseURL := range c.BaseURLs {
+		if baseURL == "" {
+			return fmt.Errorf("RUSTFS_BASE_URLS cannot contain empty URLs")
+		}
+	}
+
+	switch c.LoadBalanceStrategy {
+	case "", LoadBalanceRoundRobin, LoadBalanceLeastOutstanding:
+	default:
+		return fmt.Errorf("RUSTFS_LOAD_BALANCE_STRATEGY must be round-robin or least-outstanding")
 	}
 
-	if c.SecretKey == "" {
-		return fmt.Errorf("RUSTFS_SECRET_KEY is required")
+	if c.EndpointFailureThreshold < 0 {
+		return fmt.Errorf("RUSTFS_ENDPOINT_FAILURE_THRESHOLD cannot be negative")
+	}
+
+	if c.EndpointProbeInterval < 0 {
+		return fmt.Errorf("RUSTFS_ENDPOINT_PROBE_INTERVAL cannot be negative")
+	}
+
+	switch c.AuthScheme {
+	case "", AuthSchemeSigV4:
+		if c.AccessKey == "" {
+			return fmt.Errorf("RUSTFS_ACCESS_KEY is required")
+		}
+		if c.SecretKey == "" {
+			return fmt.Errorf("RUSTFS_SECRET_KEY is required")
+		}
+	case AuthSchemeBearer:
+		if c.APIKey == "" {
+			return fmt.Errorf("RUSTFS_API_KEY is required when the auth scheme is bearer")
+		}
+	default:
+		return fmt.Errorf("RUSTFS_AUTH_SCHEME must be sigv4 or bearer")
 	}
 
 	if c.BucketName == "" {
 		return fmt.Errorf("RUSTFS_BUCKET_NAME is required")
 	}
 
+	if err := utils.ValidateBucketName(c.BucketName); err != nil {
+		return fmt.Errorf("RUSTFS_BUCKET_NAME is invalid: %w", err)
+	}
+
 	if c.MaxFileSize <= 0 {
 		return fmt.Errorf("RUSTFS_MAX_FILE_SIZE must be positive")
 	}
 
+	if len(sanitizeStringSlice(c.AllowedTypes)) == 0 {
+		return fmt.Errorf("RUSTFS_ALLOWED_TYPES must contain at least one content type; an empty list rejects every upload")
+	}
+
 	if c.Timeout <= 0 {
 		return fmt.Errorf("RUSTFS_TIMEOUT must be positive")
 	}
 
+	if c.PingTimeout < 0 {
+		return fmt.Errorf("RUSTFS_PING_TIMEOUT cannot be negative")
+	}
+
+	if c.ExpectContinueThreshold < 0 || c.ExpectContinueTimeout < 0 {
+		return fmt.Errorf("RUSTFS_EXPECT_CONTINUE_THRESHOLD and RUSTFS_EXPECT_CONTINUE_TIMEOUT cannot be negative")
+	}
+
 	if c.RetryCount < 0 {
 		return fmt.Errorf("RUSTFS_RETRY_COUNT cannot be negative")
 	}
 
+	if c.RetryConfig != nil {
+		if c.RetryConfig.MaxAttempts < 1 {
+			return fmt.Errorf("RUSTFS_RETRY_COUNT cannot be negative")
+		}
+		if c.RetryConfig.Delay < 0 {
+			return fmt.Errorf("RUSTFS_RETRY_DELAY cannot be negative")
+		}
+		if c.RetryConfig.Backoff < 1 {
+			return fmt.Errorf("RUSTFS_RETRY_BACKOFF must be at least 1")
+		}
+	}
+
+	if c.ChecksumAlgorithm != "" {
+		switch strings.ToLower(c.ChecksumAlgorithm) {
+		case "md5", "sha256":
+		default:
+			return fmt.Errorf("RUSTFS_CHECKSUM_ALGORITHM must be md5 or sha256")
+		}
+	}
+
+	switch c.MetadataEncoding {
+	case "", "per-key", "json", "base64-json":
+	default:
+		return fmt.Errorf("RUSTFS_METADATA_ENCODING must be per-key, json or base64-json")
+	}
+
+	switch c.MetadataKeyCollision {
+	case "", MetadataKeyCollisionReject, MetadataKeyCollisionMerge:
+	default:
+		return fmt.Errorf("RUSTFS_METADATA_KEY_COLLISION must be reject or merge")
+	}
+
+	if c.MetadataValueLimit < 0 {
+		return fmt.Errorf("RUSTFS_METADATA_VALUE_LIMIT cannot be negative")
+	}
+
+	switch c.MetadataOversizePolicy {
+	case "", MetadataOversizeReject, MetadataOversizeSideObject:
+	default:
+		return fmt.Errorf("RUSTFS_METADATA_OVERSIZE_POLICY must be reject or side-object")
+	}
+
+	for key := range c.DefaultMetadata {
+		if key == "" {
+			return fmt.Errorf("RUSTFS_DEFAULT_METADATA keys cannot be empty")
+		}
+	}
+
+	switch c.AuditOverflowPolicy {
+	case "", AuditOverflowBlock, AuditOverflowDropNewest, AuditOverflowDropOldest, AuditOverflowBlockWithTimeout:
+	default:
+		return fmt.Errorf("RUSTFS_AUDIT_OVERFLOW_POLICY must be block, drop_newest, drop_oldest or block_with_timeout")
+	}
+
+	if c.AuditOverflowTimeout < 0 {
+		return fmt.Errorf("RUSTFS_AUDIT_OVERFLOW_TIMEOUT cannot be negative")
+	}
+
+	if c.AuditWorkers < 0 {
+		return fmt.Errorf("RUSTFS_AUDIT_WORKERS cannot be negative")
+	}
+
+	switch c.AuditEventMode {
+	case "", AuditEventModeMulti, AuditEventModeCombined:
+	default:
+		return fmt.Errorf("RUSTFS_AUDIT_EVENT_MODE must be multi or combined")
+	}
+
+	switch c.MissingUserPolicy {
+	case "", MissingUserPolicySystem, MissingUserPolicyReject:
+	case MissingUserPolicyAnonymous:
+		if c.AnonymousPrincipal == "" {
+			return fmt.Errorf("RUSTFS_ANONYMOUS_PRINCIPAL is required when the missing user policy is anonymous")
+		}
+	default:
+		return fmt.Errorf("RUSTFS_MISSING_USER_POLICY must be system, anonymous or reject")
+	}
+
 	if c.EnableEncryption && c.EncryptionKey == "" {
 		return fmt.Errorf("RUSTFS_ENCRYPTION_KEY is required when encryption is enabled")
 	}
 
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		return fmt.Errorf("RUSTFS_CLIENT_CERT_FILE and RUSTFS_CLIENT_KEY_FILE must be set together")
+	}
+
+	if _, err := c.TLSConfig(); err != nil {
+		return err
+	}
+
+	if _, err := c.Proxy(); err != nil {
+		return err
+	}
+
+	if c.EnableEncryption {
+		if _, err := c.EncryptionKeyBytes(); err != nil {
+			return err
+		}
+	}
+
+	if c.EnableEncryption && c.EncryptionAlgorithm == "" {
+		return fmt.Errorf("RUSTFS_ENCRYPTION_ALGORITHM is required when encryption is enabled")
+	}
+
+	if c.EnableEncryption && c.EncryptionAlgorithm != EncryptionAES256GCM {
+		return fmt.Errorf("RUSTFS_ENCRYPTION_ALGORITHM must be %s", EncryptionAES256GCM)
+	}
+
 	if c.ConcurrentUploads <= 0 {
 		return fmt.Errorf("RUSTFS_CONCURRENT_UPLOADS must be positive")
 	}
@@ -134,17 +509,203 @@ func (c *RustFSConfig) Validate() error {
 		return fmt.Errorf("RUSTFS_CHUNK_SIZE must be positive")
 	}
 
+	if c.BodyCacheMaxObjectSize < 0 || c.BodyCacheMaxBytes < 0 {
+		return fmt.Errorf("RUSTFS_BODY_CACHE_MAX_OBJECT_SIZE and RUSTFS_BODY_CACHE_MAX_BYTES cannot be negative")
+	}
+
+	if c.InfoCoalesceWindow < 0 {
+		return fmt.Errorf("RUSTFS_INFO_COALESCE_WINDOW cannot be negative")
+	}
+
+	if c.DownloadResumeAttempts < 0 {
+		return fmt.Errorf("RUSTFS_DOWNLOAD_RESUME_ATTEMPTS cannot be negative")
+	}
+
+	if c.BandwidthLimit < 0 {
+		return fmt.Errorf("RUSTFS_BANDWIDTH_LIMIT cannot be negative")
+	}
+
 	if c.CompressionLevel < 0 || c.CompressionLevel > 9 {
 		return fmt.Errorf("RUSTFS_COMPRESSION_LEVEL must be between 0 and 9")
 	}
 
+	if parts := c.maxPartCount(); parts > MaxMultipartParts {
+		return fmt.Errorf("RUSTFS_CHUNK_SIZE %d is too small for RUSTFS_MAX_FILE_SIZE %d: a maximum-size file would need %d parts, exceeding the limit of %d",
+			c.PartSize(), c.MaxFileSize, parts, MaxMultipartParts)
+	}
+
 	return nil
 }
 
+// Warnings returns non-fatal configuration issues worth surfacing at load time
+func (c *RustFSConfig) Warnings() []string {
+	var warnings []string
+
+	if c.InsecureSkipVerify {
+		warnings = append(warnings, "RUSTFS_INSECURE_SKIP_VERIFY disables server certificate verification; use RUSTFS_CA_CERT_FILE for private CAs instead")
+	}
+
+	if c.ChunkSize > 0 && c.MaxFileSize > 0 {
+		if parts := c.maxPartCount(); int64(c.ConcurrentUploads) > parts {
+			warnings = append(warnings, fmt.Sprintf(
+				"RUSTFS_CONCURRENT_UPLOADS %d exceeds the %d parts of a maximum-size file; extra workers will sit idle",
+				c.ConcurrentUploads, parts))
+		}
+	}
+
+	return warnings
+}
+
+// PartSize returns the size of multipart upload parts: ChunkSize, raised to the minimum part
+// size S3 servers accept
+func (c *RustFSConfig) PartSize() int64 {
+	return max(int64(c.ChunkSize), utils.MinPartSize)
+}
+
+// maxPartCount returns the number of PartSize parts needed to upload a MaxFileSize file
+func (c *RustFSConfig) maxPartCount() int64 {
+	if c.ChunkSize <= 0 {
+		return 0
+	}
+	partSize := c.PartSize()
+	return (c.MaxFileSize + partSize - 1) / partSize
+}
+
+// RedactedValue replaces secret values in redacted configurations
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with credentials masked, safe for logging
+func (c *RustFSConfig) Redacted() *RustFSConfig {
+	redacted := *c
+
+	redacted.AccessKey = redact(c.AccessKey)
+	redacted.SecretKey = redact(c.SecretKey)
+	redacted.APIKey = redact(c.APIKey)
+	redacted.EncryptionKey = redact(c.EncryptionKey)
+	if proxy, err := url.Parse(c.ProxyURL); err == nil && proxy.User != nil {
+		redacted.ProxyURL = proxy.Redacted()
+	}
+	if endpoint, err := url.Parse(c.BaseURL); err == nil && endpoint.User != nil {
+		redacted.BaseURL = endpoint.Redacted()
+	}
+	redacted.BaseURLs = append([]string(nil), c.BaseURLs...)
+	for i, baseURL := range redacted.BaseURLs {
+		if endpoint, err := url.Parse(baseURL); err == nil && endpoint.User != nil {
+			redacted.BaseURLs[i] = endpoint.Redacted()
+		}
+	}
+
+	// Don't share slices and maps with the live configuration
+	redacted.AllowedTypes = append([]string(nil), c.AllowedTypes...)
+	redacted.AllowedOrigins = append([]string(nil), c.AllowedOrigins...)
+	redacted.EnabledCapabilities = append([]string(nil), c.EnabledCapabilities...)
+	redacted.DisabledCapabilities = append([]string(nil), c.DisabledCapabilities...)
+	if c.RetryConfig != nil {
+		retryConfig := *c.RetryConfig
+		redacted.RetryConfig = &retryConfig
+	}
+	if c.AuditMetadata != nil {
+		redacted.AuditMetadata = make(map[string]interface{}, len(c.AuditMetadata))
+		for k, v := range c.AuditMetadata {
+			redacted.AuditMetadata[k] = v
+		}
+	}
+	if c.DefaultMetadata != nil {
+		redacted.DefaultMetadata = make(map[string]string, len(c.DefaultMetadata))
+		for k, v := range c.DefaultMetadata {
+			redacted.DefaultMetadata[k] = v
+		}
+	}
+
+	return &redacted
+}
+
+// EncryptionKeyBytes decodes EncryptionKey into an AES-256 key. Hex and base64 encodings are
+// tried before the raw value.
+func (c *RustFSConfig) EncryptionKeyBytes() ([]byte, error) {
+	if key, err := hex.DecodeString(c.EncryptionKey); err == nil && len(key) == EncryptionKeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(c.EncryptionKey); err == nil && len(key) == EncryptionKeySize {
+		return key, nil
+	}
+	if len(c.EncryptionKey) == EncryptionKeySize {
+		return []byte(c.EncryptionKey), nil
+	}
+	return nil, fmt.Errorf("RUSTFS_ENCRYPTION_KEY must be 32 bytes, 64 hex characters or 32 base64-encoded bytes")
+}
+
+// TLSConfig builds the TLS settings for connections to RustFS, or returns nil when the
+// defaults apply. Certificate files are read on every call.
+func (c *RustFSConfig) TLSConfig() (*tls.Config, error) {
+	if c.CACertFile == "" && c.ClientCertFile == "" && c.ClientKeyFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("RUSTFS_CA_CERT_FILE cannot be read: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("RUSTFS_CA_CERT_FILE contains no PEM certificates")
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if c.ClientCertFile != "" && c.ClientKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("RUSTFS_CLIENT_CERT_FILE and RUSTFS_CLIENT_KEY_FILE are not a valid key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// Proxy parses ProxyURL, returning nil when no proxy is configured
+func (c *RustFSConfig) Proxy() (*url.URL, error) {
+	if c.ProxyURL == "" {
+		return nil, nil
+	}
+
+	proxy, err := url.Parse(c.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("RUSTFS_PROXY_URL is not a valid URL: %w", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("RUSTFS_PROXY_URL must use the http, https or socks5 scheme")
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("RUSTFS_PROXY_URL must include a host")
+	}
+	return proxy, nil
+}
+
+// redact masks a secret, leaving empty values empty so unset credentials remain visible
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
 // IsAllowedType checks if the content type is allowed
 func (c *RustFSConfig) IsAllowedType(contentType string) bool {
 	for _, allowedType := range c.AllowedTypes {
-		if matchContentType(allowedType, contentType) {
+		if matchContentType(strings.TrimSpace(allowedType), contentType) {
 			return true
 		}
 	}
@@ -188,6 +749,15 @@ func getInt64EnvOrDefault(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+func getFloatEnvOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getBoolEnvOrDefault(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -208,11 +778,46 @@ func getDurationEnvOrDefault(key string, defaultValue time.Duration) time.Durati
 
 func getStringSliceEnvOrDefault(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
-		return strings.Split(value, ",")
+		// Values such as "," or " , " contain no usable entries; fall back rather than
+		// returning [""] which silently matches nothing
+		if values := sanitizeStringSlice(strings.Split(value, ",")); len(values) > 0 {
+			return values
+		}
 	}
 	return defaultValue
 }
 
+// getMapEnvOrDefault parses a comma-separated list of key=value pairs. Entries without a key
+// are kept so Validate can reject them instead of silently dropping metadata.
+func getMapEnvOrDefault(key string, defaultValue map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	entries := sanitizeStringSlice(strings.Split(value, ","))
+	if len(entries) == 0 {
+		return defaultValue
+	}
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		k, v, _ := strings.Cut(entry, "=")
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// sanitizeStringSlice trims whitespace from each entry and drops empty entries
+func sanitizeStringSlice(values []string) []string {
+	sanitized := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			sanitized = append(sanitized, value)
+		}
+	}
+	return sanitized
+}
+
 func matchContentType(pattern, contentType string) bool {
 	// Exact match
 	if pattern == contentType {
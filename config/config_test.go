@@ -0,0 +1,324 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/garyjdn/go-rustfs/utils"
+)
+
+// loadTestConfig loads the default configuration with the required credentials set
+func loadTestConfig(t *testing.T) *RustFSConfig {
+	t.Helper()
+	t.Setenv("RUSTFS_ACCESS_KEY", "test-access")
+	t.Setenv("RUSTFS_SECRET_KEY", "test-secret")
+	return LoadConfig()
+}
+
+func TestPartSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		want      int64
+	}{
+		{"default chunk size is raised to the minimum", 0, utils.MinPartSize},
+		{"small chunk size is raised to the minimum", 1024, utils.MinPartSize},
+		{"minimum is kept", utils.MinPartSize, utils.MinPartSize},
+		{"larger chunk size is kept", 16 * 1024 * 1024, 16 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t)
+			if tt.chunkSize > 0 {
+				cfg.ChunkSize = tt.chunkSize
+			}
+			if got := cfg.PartSize(); got != tt.want {
+				t.Errorf("PartSize() = %d, want %d", got, tt.want)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatePartCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		chunkSize   int
+		maxFileSize int64
+		wantErr     bool
+	}{
+		{"maximum-size file at the part limit", utils.MinPartSize, utils.MinPartSize * MaxMultipartParts, false},
+		{"one byte over the part limit", utils.MinPartSize, utils.MinPartSize*MaxMultipartParts + 1, true},
+		{"small chunk size is raised to the minimum part size", 1024, utils.MinPartSize * MaxMultipartParts, false},
+		{"larger chunks fit a larger file", 2 * utils.MinPartSize, 2*utils.MinPartSize*MaxMultipartParts + 1, true},
+		{"larger chunks at the part limit", 2 * utils.MinPartSize, 2 * utils.MinPartSize * MaxMultipartParts, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t)
+			cfg.ChunkSize = tt.chunkSize
+			cfg.MaxFileSize = tt.maxFileSize
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConcurrentUploadsWarning(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		wantWarning bool
+	}{
+		{"one worker per part", 4, false},
+		{"more workers than parts", 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t)
+			cfg.ChunkSize = utils.MinPartSize
+			cfg.MaxFileSize = 4 * utils.MinPartSize
+			cfg.ConcurrentUploads = tt.concurrency
+
+			warned := false
+			for _, warning := range cfg.Warnings() {
+				warned = warned || strings.Contains(warning, "RUSTFS_CONCURRENT_UPLOADS")
+			}
+			if warned != tt.wantWarning {
+				t.Errorf("warned = %v, want %v (warnings: %v)", warned, tt.wantWarning, cfg.Warnings())
+			}
+		})
+	}
+}
+
+func TestAllowedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		want     []string
+		allowed  string
+		rejected string
+	}{
+		{"unset uses the default", "", []string{"image/*"}, "image/png", "text/plain"},
+		{"only a separator falls back to the default", ",", []string{"image/*"}, "image/png", "text/plain"},
+		{"blank entries fall back to the default", " , ", []string{"image/*"}, "image/jpeg", "application/pdf"},
+		{"whitespace is trimmed and empty entries dropped", " image/* , ", []string{"image/*"}, "image/gif", "video/mp4"},
+		{"several types", "image/png, application/pdf", []string{"image/png", "application/pdf"}, "application/pdf", "image/gif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RUSTFS_ALLOWED_TYPES", tt.env)
+			cfg := loadTestConfig(t)
+			if !slices.Equal(cfg.AllowedTypes, tt.want) {
+				t.Errorf("AllowedTypes = %q, want %q", cfg.AllowedTypes, tt.want)
+			}
+			if !cfg.IsAllowedType(tt.allowed) {
+				t.Errorf("IsAllowedType(%s) = false, want true", tt.allowed)
+			}
+			if cfg.IsAllowedType(tt.rejected) {
+				t.Errorf("IsAllowedType(%s) = true, want false", tt.rejected)
+			}
+		})
+	}
+
+	// A list set in code is validated rather than silently rejecting every upload
+	for _, allowed := range [][]string{nil, {""}, {" ", ""}} {
+		cfg := loadTestConfig(t)
+		cfg.AllowedTypes = allowed
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "RUSTFS_ALLOWED_TYPES") {
+			t.Errorf("Validate() with AllowedTypes %q = %v, want an error naming RUSTFS_ALLOWED_TYPES", allowed, err)
+		}
+	}
+}
+
+func TestAuditEventMode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"default is one event per signal", "", AuditEventModeMulti},
+		{"combined", "combined", AuditEventModeCombined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("RUSTFS_AUDIT_EVENT_MODE", tt.env)
+			}
+			cfg := loadTestConfig(t)
+			if cfg.AuditEventMode != tt.want {
+				t.Errorf("AuditEventMode = %q, want %q", cfg.AuditEventMode, tt.want)
+			}
+		})
+	}
+
+	cfg := loadTestConfig(t)
+	cfg.AuditEventMode = "single"
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Validate() accepted an unknown audit event mode")
+	}
+}
+
+func TestAuditWorkers(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want int
+	}{
+		{"default is a single ordered worker", "", 1},
+		{"from the environment", "4", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("RUSTFS_AUDIT_WORKERS", tt.env)
+			}
+			cfg := loadTestConfig(t)
+			if cfg.AuditWorkers != tt.want {
+				t.Errorf("AuditWorkers = %d, want %d", cfg.AuditWorkers, tt.want)
+			}
+		})
+	}
+
+	cfg := loadTestConfig(t)
+	cfg.AuditWorkers = -1
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Validate() accepted a negative number of audit workers")
+	}
+}
+
+func TestRedactedDoesNotShareState(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *RustFSConfig)
+		check  func(c *RustFSConfig) bool
+	}{
+		{"allowed types", func(c *RustFSConfig) { c.AllowedTypes[0] = "changed" },
+			func(c *RustFSConfig) bool { return c.AllowedTypes[0] == "image/*" }},
+		{"audit metadata", func(c *RustFSConfig) { c.AuditMetadata["team"] = "changed" },
+			func(c *RustFSConfig) bool { return c.AuditMetadata["team"] == "storage" }},
+		{"default metadata", func(c *RustFSConfig) { c.DefaultMetadata["owner"] = "changed" },
+			func(c *RustFSConfig) bool { return c.DefaultMetadata["owner"] == "platform" }},
+		{"base URLs", func(c *RustFSConfig) { c.BaseURLs[0] = "http://changed:9000" },
+			func(c *RustFSConfig) bool { return c.BaseURLs[0] == "http://a:9000" }},
+		{"retry config", func(c *RustFSConfig) { c.RetryConfig.MaxAttempts = 99 },
+			func(c *RustFSConfig) bool { return c.RetryConfig.MaxAttempts != 99 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t)
+			cfg.AllowedTypes = []string{"image/*"}
+			cfg.AuditMetadata = map[string]interface{}{"team": "storage"}
+			cfg.DefaultMetadata = map[string]string{"owner": "platform"}
+			cfg.BaseURLs = []string{"http://a:9000"}
+
+			tt.mutate(cfg.Redacted())
+			if !tt.check(cfg) {
+				t.Errorf("changing the redacted copy changed the live configuration")
+			}
+		})
+	}
+}
+
+func TestRedactedMasksURLCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{"no credentials", "http://a:9000", "http://a:9000"},
+		{"user and password", "http://admin:hunter2@b:9000", "http://admin:xxxxx@b:9000"},
+		{"user only", "https://admin@c:9000/path", "https://admin@c:9000/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t)
+			cfg.BaseURL = tt.baseURL
+			cfg.BaseURLs = []string{tt.baseURL}
+			cfg.ProxyURL = tt.baseURL
+
+			redacted := cfg.Redacted()
+			if redacted.BaseURL != tt.want || redacted.BaseURLs[0] != tt.want || redacted.ProxyURL != tt.want {
+				t.Errorf("BaseURL, BaseURLs, ProxyURL = %q, %q, %q; want %q", redacted.BaseURL, redacted.BaseURLs[0], redacted.ProxyURL, tt.want)
+			}
+			if cfg.BaseURLs[0] != tt.baseURL {
+				t.Errorf("live BaseURLs changed to %q", cfg.BaseURLs[0])
+			}
+		})
+	}
+}
+
+func TestRedactedMasksSecrets(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.AccessKey = "AKIAEXAMPLE"
+	cfg.SecretKey = "wJalrXUtnFEMI"
+	cfg.APIKey = "api-token-123"
+	cfg.EncryptionKey = "0123456789abcdef0123456789abcdef"
+	cfg.BucketName = "uploads"
+	cfg.ClientKeyFile = "/etc/rustfs/client.key"
+
+	redacted := cfg.Redacted()
+	for name, value := range map[string]string{
+		"AccessKey":     redacted.AccessKey,
+		"SecretKey":     redacted.SecretKey,
+		"APIKey":        redacted.APIKey,
+		"EncryptionKey": redacted.EncryptionKey,
+	} {
+		if value != RedactedValue {
+			t.Errorf("%s = %q, want it masked", name, value)
+		}
+	}
+	if redacted.BucketName != "uploads" || redacted.BaseURL != cfg.BaseURL || redacted.ClientKeyFile != cfg.ClientKeyFile ||
+		redacted.MaxFileSize != cfg.MaxFileSize || redacted.Timeout != cfg.Timeout {
+		t.Errorf("redacted config lost non-secret settings: %+v", redacted)
+	}
+	if cfg.SecretKey != "wJalrXUtnFEMI" {
+		t.Errorf("Redacted masked the live configuration")
+	}
+
+	// The exported form, as logged in config change events, carries no secret
+	exported, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("marshalling the redacted config: %v", err)
+	}
+	for _, secret := range []string{cfg.AccessKey, cfg.SecretKey, cfg.APIKey, cfg.EncryptionKey} {
+		if strings.Contains(string(exported), secret) {
+			t.Errorf("exported config contains the secret %q", secret)
+		}
+	}
+
+	cfg.APIKey = ""
+	if got := cfg.Redacted().APIKey; got != "" {
+		t.Errorf("unset APIKey redacted to %q, want it left empty", got)
+	}
+}
+
+func TestValidateBucketName(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.BucketName = "My..Bucket"
+	err := cfg.Validate()
+	if !errors.Is(err, utils.ErrInvalidBucketName) {
+		t.Fatalf("Validate() = %v, want ErrInvalidBucketName", err)
+	}
+
+	cfg.BucketName = "my-bucket"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a valid bucket name = %v", err)
+	}
+}
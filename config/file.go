@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// LoadConfigFromFile loads configuration from a JSON file keyed by the json tags of
+// RustFSConfig. Settings missing from the file keep their LoadConfig defaults and environment
+// variables take precedence over the file, so a committed base config can be overridden per
+// environment. Durations may be given as strings such as "30s" or as nanoseconds.
+func LoadConfigFromFile(path string) (*RustFSConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading RustFS config file: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing RustFS config file %s: %w", path, err)
+	}
+
+	config := loadEnvConfig()
+	if err := applyFileValues(config, values); err != nil {
+		return nil, fmt.Errorf("parsing RustFS config file %s: %w", path, err)
+	}
+	// Without an explicit retry_config the attempts follow retry_count, as in LoadConfig
+	if _, ok := values["retry_config"]; !ok && config.RetryConfig != nil {
+		config.RetryConfig.MaxAttempts = config.RetryCount + 1
+	}
+	applyEnvOverrides(config)
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RustFS configuration: %w", err)
+	}
+
+	for _, warning := range config.Warnings() {
+		log.Printf("[RUSTFS] configuration warning: %s", warning)
+	}
+
+	return config, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyFileValues decodes each setting of a config file into the field with the matching json
+// tag. Unknown settings are rejected so a typo doesn't silently fall back to a default.
+func applyFileValues(config *RustFSConfig, values map[string]json.RawMessage) error {
+	v := reflect.ValueOf(config).Elem()
+	fields := make(map[string]reflect.Value, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	for name, raw := range values {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+
+		if field.Type() == durationType {
+			var text string
+			if json.Unmarshal(raw, &text) == nil {
+				duration, err := time.ParseDuration(text)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				field.SetInt(int64(duration))
+				continue
+			}
+		}
+
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyEnvOverrides sets every field whose env variable is set, parsing it like LoadConfig.
+// Values that don't parse keep the current setting.
+func applyEnvOverrides(config *RustFSConfig) {
+	v := reflect.ValueOf(config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("env")
+		if key == "" || os.Getenv(key) == "" {
+			continue
+		}
+
+		switch field := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			*field = getEnvOrDefault(key, *field)
+		case *int:
+			*field = getIntEnvOrDefault(key, *field)
+		case *int64:
+			*field = getInt64EnvOrDefault(key, *field)
+		case *bool:
+			*field = getBoolEnvOrDefault(key, *field)
+		case *time.Duration:
+			*field = getDurationEnvOrDefault(key, *field)
+		case *[]string:
+			*field = getStringSliceEnvOrDefault(key, *field)
+		case *map[string]string:
+			*field = getMapEnvOrDefault(key, *field)
+		}
+	}
+
+	// Settings without a field of their own
+	if config.RetryConfig != nil {
+		if os.Getenv("RUSTFS_RETRY_COUNT") != "" {
+			config.RetryConfig.MaxAttempts = config.RetryCount + 1
+		}
+		config.RetryConfig.Delay = getDurationEnvOrDefault("RUSTFS_RETRY_DELAY", config.RetryConfig.Delay)
+		config.RetryConfig.Backoff = getFloatEnvOrDefault("RUSTFS_RETRY_BACKOFF", config.RetryConfig.Backoff)
+	}
+	if environment := os.Getenv("ENVIRONMENT"); environment != "" && config.AuditMetadata != nil {
+		config.AuditMetadata["environment"] = environment
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Kinds of metadata key changes reported by FileInfoDiff
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// FieldChange records the old and new value of a changed FileInfo attribute
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// MetadataChange records a change to a single metadata key
+type MetadataChange struct {
+	Key  string      `json:"key"`
+	Kind string      `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// InfoDiff is the structured difference between two FileInfo values
+type InfoDiff struct {
+	Fields   []FieldChange    `json:"fields,omitempty"`
+	Metadata []MetadataChange `json:"metadata,omitempty"`
+}
+
+// Empty reports whether the diff contains no changes
+func (d *InfoDiff) Empty() bool {
+	return d == nil || (len(d.Fields) == 0 && len(d.Metadata) == 0)
+}
+
+// FileInfoDiff compares two FileInfo values field by field and per metadata key.
+// A nil input is treated as an empty FileInfo, so diffing against nil reports every set field.
+func FileInfoDiff(a, b *FileInfo) *InfoDiff {
+	if a == nil {
+		a = &FileInfo{}
+	}
+	if b == nil {
+		b = &FileInfo{}
+	}
+
+	diff := &InfoDiff{}
+	addField := func(field string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			diff.Fields = append(diff.Fields, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+
+	addField("path", a.Path, b.Path)
+	addField("size", a.Size, b.Size)
+	addField("content_type", a.ContentType, b.ContentType)
+	addField("etag", a.ETag, b.ETag)
+	if !a.LastModified.Equal(b.LastModified) {
+		diff.Fields = append(diff.Fields, FieldChange{Field: "last_modified", Old: a.LastModified, New: b.LastModified})
+	}
+	addField("storage_class", a.StorageClass, b.StorageClass)
+	addField("cache_control", a.CacheControl, b.CacheControl)
+	addField("restored", a.Restored, b.Restored)
+
+	keys := make(map[string]struct{}, len(a.Metadata)+len(b.Metadata))
+	for k := range a.Metadata {
+		keys[k] = struct{}{}
+	}
+	for k := range b.Metadata {
+		keys[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		oldValue, inOld := a.Metadata[k]
+		newValue, inNew := b.Metadata[k]
+
+		switch {
+		case !inOld:
+			diff.Metadata = append(diff.Metadata, MetadataChange{Key: k, Kind: ChangeAdded, New: newValue})
+		case !inNew:
+			diff.Metadata = append(diff.Metadata, MetadataChange{Key: k, Kind: ChangeRemoved, Old: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			diff.Metadata = append(diff.Metadata, MetadataChange{Key: k, Kind: ChangeModified, Old: oldValue, New: newValue})
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileInfoDiff(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	base := func() *FileInfo {
+		return &FileInfo{
+			Path:         "docs/report.txt",
+			Size:         100,
+			ContentType:  "text/plain",
+			ETag:         "abc",
+			LastModified: modified,
+			Metadata:     map[string]interface{}{"owner": "alice", "team": "ops"},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		a, b         *FileInfo
+		wantFields   []FieldChange
+		wantMetadata []MetadataChange
+	}{
+		{name: "identical", a: base(), b: base()},
+		{name: "both nil"},
+		{
+			name: "size and etag change",
+			a:    base(),
+			b: func() *FileInfo {
+				info := base()
+				info.Size, info.ETag = 120, "def"
+				return info
+			}(),
+			wantFields: []FieldChange{{Field: "size", Old: int64(100), New: int64(120)}, {Field: "etag", Old: "abc", New: "def"}},
+		},
+		{
+			name: "same instant in another zone",
+			a:    base(),
+			b: func() *FileInfo {
+				info := base()
+				info.LastModified = modified.In(time.FixedZone("UTC+2", 2*60*60))
+				return info
+			}(),
+		},
+		{
+			name: "metadata keys added, removed and changed",
+			a:    base(),
+			b: func() *FileInfo {
+				info := base()
+				info.Metadata = map[string]interface{}{"owner": "bob", "reviewed": true}
+				return info
+			}(),
+			wantMetadata: []MetadataChange{
+				{Key: "owner", Kind: ChangeModified, Old: "alice", New: "bob"},
+				{Key: "reviewed", Kind: ChangeAdded, New: true},
+				{Key: "team", Kind: ChangeRemoved, Old: "ops"},
+			},
+		},
+		{
+			name:       "nil old info reports every set field",
+			b:          &FileInfo{Path: "new.txt", Size: 5, Metadata: map[string]interface{}{"owner": "alice"}},
+			wantFields: []FieldChange{{Field: "path", Old: "", New: "new.txt"}, {Field: "size", Old: int64(0), New: int64(5)}},
+			wantMetadata: []MetadataChange{
+				{Key: "owner", Kind: ChangeAdded, New: "alice"},
+			},
+		},
+		{
+			name:       "nil new info",
+			a:          &FileInfo{Path: "old.txt"},
+			wantFields: []FieldChange{{Field: "path", Old: "old.txt", New: ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := FileInfoDiff(tt.a, tt.b)
+			if !reflect.DeepEqual(diff.Fields, tt.wantFields) {
+				t.Errorf("fields = %+v, want %+v", diff.Fields, tt.wantFields)
+			}
+			if !reflect.DeepEqual(diff.Metadata, tt.wantMetadata) {
+				t.Errorf("metadata = %+v, want %+v", diff.Metadata, tt.wantMetadata)
+			}
+			if empty := tt.wantFields == nil && tt.wantMetadata == nil; diff.Empty() != empty {
+				t.Errorf("Empty() = %v, want %v", diff.Empty(), empty)
+			}
+		})
+	}
+}
@@ -36,6 +36,22 @@ type UploadRequest struct {
 	FileSize    int64                  `json:"file_size"`
 	BucketPath  string                 `json:"bucket_path"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// StorageClass requests a specific storage class; empty uses the bucket default
+	StorageClass string `json:"storage_class,omitempty"`
+	// CacheControl is stored with the object and sent as the Cache-Control header on download
+	CacheControl string `json:"cache_control,omitempty"`
+	// ContentDisposition is stored with the object and sent as the Content-Disposition header on download
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	// ContentEncoding is stored with the object and sent as the Content-Encoding header on
+	// download, e.g. "gzip" for a compressed upload
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	// IfNotExists makes the upload fail instead of overwriting an existing object
+	IfNotExists bool `json:"if_not_exists,omitempty"`
+
+	// CloseSource hands ownership of File to the client, which closes it once the upload
+	// completes or fails if it implements io.Closer. By default the caller owns File.
+	CloseSource bool `json:"-"`
 }
 
 // UploadResponse represents the response from a file upload
@@ -47,6 +63,22 @@ type UploadResponse struct {
 	ETag         string                 `json:"etag"`
 	LastModified time.Time              `json:"last_modified"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+
+	// Checksum is the hex-encoded digest of the uploaded content, computed with ChecksumAlgorithm
+	Checksum          string `json:"checksum,omitempty"`
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+
+	StorageClass string `json:"storage_class,omitempty"`
+
+	// DefaultMetadataKeys lists the configured default metadata keys added to the upload; keys
+	// the caller supplied itself are not included
+	DefaultMetadataKeys []string `json:"default_metadata_keys,omitempty"`
+
+	// ContentEncoding is the stored object's encoding, e.g. "gzip"
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	// UncompressedSize is the size of the source before client-side compression; Size is the
+	// stored size. It is zero when the upload wasn't compressed.
+	UncompressedSize int64 `json:"uncompressed_size,omitempty"`
 }
 
 // FileInfo represents information about a stored file
@@ -57,6 +89,36 @@ type FileInfo struct {
 	ETag         string                 `json:"etag"`
 	LastModified time.Time              `json:"last_modified"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	StorageClass string                 `json:"storage_class,omitempty"`
+	CacheControl string                 `json:"cache_control,omitempty"`
+	// ContentDisposition controls inline versus attachment display and the download filename
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	// ContentEncoding is the encoding of the stored bytes, e.g. "gzip"
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	// Restored is true when an archived object has a readable restored copy
+	Restored bool `json:"restored,omitempty"`
+	// IsDir is true for a synthetic directory entry: a path with no object of its own but with
+	// objects stored under "path/"
+	IsDir bool `json:"is_dir,omitempty"`
+}
+
+// Storage classes understood by the client
+const (
+	StorageClassStandard          = "STANDARD"
+	StorageClassStandardIA        = "STANDARD_IA"
+	StorageClassReducedRedundancy = "REDUCED_REDUNDANCY"
+	StorageClassGlacier           = "GLACIER"
+	StorageClassDeepArchive       = "DEEP_ARCHIVE"
+)
+
+// IsArchivedStorageClass checks if objects in the storage class must be restored before download
+func IsArchivedStorageClass(storageClass string) bool {
+	switch storageClass {
+	case StorageClassGlacier, StorageClassDeepArchive:
+		return true
+	default:
+		return false
+	}
 }
 
 // FileValidationResult represents the result of file validation
@@ -71,6 +133,15 @@ type RetryConfig struct {
 	MaxAttempts int           `json:"max_attempts"`
 	Delay       time.Duration `json:"delay"`
 	Backoff     float64       `json:"backoff"`
+	// MaxDelay caps the backoff delay between attempts; zero leaves it unbounded
+	MaxDelay time.Duration `json:"max_delay,omitempty"`
+	// MaxRetryAfter caps server-requested Retry-After delays; zero uses the default cap
+	MaxRetryAfter time.Duration `json:"max_retry_after"`
+	// FailOnRetryAfterExceeded gives up instead of waiting the capped delay when Retry-After exceeds the cap
+	FailOnRetryAfterExceeded bool `json:"fail_on_retry_after_exceeded"`
+	// AttemptOffset counts failures from before a resume, so the first backoff delay is
+	// Delay * Backoff^AttemptOffset instead of restarting at Delay. It does not use up MaxAttempts.
+	AttemptOffset int `json:"attempt_offset,omitempty"`
 }
 
 // UploadProgress represents upload progress information
@@ -90,3 +161,20 @@ type StorageStats struct {
 	AvailableSpace int64     `json:"available_space"`
 	LastUpdated    time.Time `json:"last_updated"`
 }
+
+// IncompleteUpload represents a multipart upload that was started but never completed or aborted
+type IncompleteUpload struct {
+	Path      string    `json:"path"`
+	UploadID  string    `json:"upload_id"`
+	Initiated time.Time `json:"initiated"`
+}
+
+// WebhookEvent is the payload RustFS delivers to a registered webhook URL
+type WebhookEvent struct {
+	Type      string                 `json:"type"`
+	Bucket    string                 `json:"bucket,omitempty"`
+	Path      string                 `json:"path"`
+	ETag      string                 `json:"etag,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
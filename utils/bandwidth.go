@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BandwidthLimiter is a token-bucket limiter on bytes per second shared by every transfer
+// it is attached to, so the aggregate rate of all concurrent transfers stays under the
+// ceiling. A rate of zero or less disables limiting. The rate can be changed at runtime.
+type BandwidthLimiter struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// NewBandwidthLimiter creates a limiter allowing bytesPerSecond in aggregate
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	return &BandwidthLimiter{
+		rate:   bytesPerSecond,
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}
+}
+
+// SetRate changes the limit; zero or less disables limiting
+func (l *BandwidthLimiter) SetRate(bytesPerSecond int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	l.rate = bytesPerSecond
+	if burst := float64(bytesPerSecond); l.tokens > burst {
+		l.tokens = burst
+	}
+}
+
+// Rate returns the current limit in bytes per second
+func (l *BandwidthLimiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// WaitN accounts for n transferred bytes, blocking until the bucket allows them or ctx is done.
+// Transfers larger than the bucket go into debt, delaying subsequent callers accordingly.
+func (l *BandwidthLimiter) WaitN(ctx context.Context, n int64) error {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	l.refill(now)
+	l.tokens -= float64(n)
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refill adds the tokens accrued since the last update, capped at one second of burst
+func (l *BandwidthLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	if l.rate <= 0 {
+		return
+	}
+
+	l.tokens += elapsed * float64(l.rate)
+	if burst := float64(l.rate); l.tokens > burst {
+		l.tokens = burst
+	}
+}
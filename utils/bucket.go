@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Bucket name length limits
+const (
+	MinBucketNameLength = 3
+	MaxBucketNameLength = 63
+)
+
+// ErrInvalidBucketName is wrapped by every error returned from ValidateBucketName
+var ErrInvalidBucketName = errors.New("invalid bucket name")
+
+// ValidateBucketName checks name against the common S3 bucket naming rules, which also keep it
+// usable as a DNS label for virtual-host addressing. The error names the rule violated.
+func ValidateBucketName(name string) error {
+	invalid := func(rule string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidBucketName, name, rule)
+	}
+
+	if len(name) < MinBucketNameLength || len(name) > MaxBucketNameLength {
+		return invalid(fmt.Sprintf("must be between %d and %d characters long", MinBucketNameLength, MaxBucketNameLength))
+	}
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+		case r >= 'A' && r <= 'Z':
+			return invalid("must not contain uppercase letters")
+		default:
+			return invalid(fmt.Sprintf("must not contain %q; only lowercase letters, digits, dots and hyphens are allowed", r))
+		}
+	}
+
+	if !isBucketNameAlnum(name[0]) || !isBucketNameAlnum(name[len(name)-1]) {
+		return invalid("must begin and end with a letter or digit")
+	}
+	if strings.Contains(name, "..") {
+		return invalid("must not contain consecutive dots")
+	}
+	if strings.Contains(name, ".-") || strings.Contains(name, "-.") {
+		return invalid("must not contain a dot next to a hyphen")
+	}
+	if net.ParseIP(name) != nil {
+		return invalid("must not be formatted as an IP address")
+	}
+	if strings.HasPrefix(name, "xn--") {
+		return invalid(`must not start with the reserved prefix "xn--"`)
+	}
+	if strings.HasSuffix(name, "-s3alias") || strings.HasSuffix(name, "--ol-s3") {
+		return invalid("must not end with a reserved access point alias suffix")
+	}
+
+	return nil
+}
+
+// CanonicalBucketName trims surrounding whitespace and lowercases name, then validates the result
+func CanonicalBucketName(name string) (string, error) {
+	canonical := strings.ToLower(strings.TrimSpace(name))
+	if err := ValidateBucketName(canonical); err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// isBucketNameAlnum reports whether c is a lowercase letter or digit
+func isBucketNameAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateBucketName(t *testing.T) {
+	tests := []struct {
+		name string
+		// rule is a fragment of the violated rule in the error, empty for valid names
+		rule string
+	}{
+		{"uploads", ""},
+		{"my-bucket.2024", ""},
+		{"abc", ""},
+		{"a1b", ""},
+		{strings.Repeat("a", MaxBucketNameLength), ""},
+		{"ab", "between 3 and 63"},
+		{"", "between 3 and 63"},
+		{strings.Repeat("a", MaxBucketNameLength+1), "between 3 and 63"},
+		{"MyBucket", "uppercase"},
+		{"my_bucket", `'_'`},
+		{"my bucket", `' '`},
+		{"-bucket", "begin and end"},
+		{"bucket.", "begin and end"},
+		{"my..bucket", "consecutive dots"},
+		{"my.-bucket", "dot next to a hyphen"},
+		{"my-.bucket", "dot next to a hyphen"},
+		{"192.168.5.4", "IP address"},
+		{"xn--bucket", "xn--"},
+		{"bucket-s3alias", "alias suffix"},
+		{"bucket--ol-s3", "alias suffix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBucketName(tt.name)
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("ValidateBucketName(%q) = %v, want nil", tt.name, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidBucketName) {
+				t.Fatalf("ValidateBucketName(%q) = %v, want ErrInvalidBucketName", tt.name, err)
+			}
+			if !strings.Contains(err.Error(), tt.rule) {
+				t.Errorf("ValidateBucketName(%q) = %v, want the rule %q", tt.name, err, tt.rule)
+			}
+		})
+	}
+}
+
+func TestCanonicalBucketName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"uploads", "uploads", false},
+		{"MyBucket", "mybucket", false},
+		{"  Uploads.Archive \n", "uploads.archive", false},
+		{" ab ", "", true},
+		{"My_Bucket", "", true},
+		{"My..Bucket", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalBucketName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanonicalBucketName(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidBucketName) {
+				t.Errorf("CanonicalBucketName(%q) = %v, want ErrInvalidBucketName", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalBucketName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
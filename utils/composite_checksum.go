@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// CompositeChecksumAlgorithm names checksums combined from per-part MD5 digests
+const CompositeChecksumAlgorithm = "md5-composite"
+
+// CompositeChecksum combines the MD5 digests of the parts of a multipart upload into the
+// multipart ETag format used by S3-compatible servers: the hex MD5 of the concatenated binary
+// part digests, a dash, then the part count, e.g. "9b2cf535f27731c974343645a3985328-3".
+// Parts are digested as they are uploaded, so the whole object is never read twice. Parts may
+// be added concurrently and in any order.
+type CompositeChecksum struct {
+	mu    sync.Mutex
+	parts map[int][]byte
+}
+
+// NewCompositeChecksum creates an empty composite checksum
+func NewCompositeChecksum() *CompositeChecksum {
+	return &CompositeChecksum{parts: make(map[int][]byte)}
+}
+
+// AddPart records the MD5 digest of the 1-based part number, replacing any earlier digest of
+// the same part, as happens when a failed part is retried
+func (c *CompositeChecksum) AddPart(number int, digest []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parts[number] = digest
+}
+
+// Sum returns the composite checksum. It fails unless parts 1 through N were all added.
+func (c *CompositeChecksum) Sum() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.parts) == 0 {
+		return "", fmt.Errorf("composite checksum has no parts")
+	}
+
+	hash := md5.New()
+	for number := 1; number <= len(c.parts); number++ {
+		digest, ok := c.parts[number]
+		if !ok {
+			return "", fmt.Errorf("composite checksum is missing part %d of %d", number, len(c.parts))
+		}
+		hash.Write(digest)
+	}
+
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(hash.Sum(nil)), len(c.parts)), nil
+}
+
+// PartMD5 returns the MD5 digest of a part body and rewinds it so it can then be uploaded
+func PartMD5(body io.ReadSeeker) ([]byte, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return nil, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// MatchesETag reports whether a checksum equals an ETag returned by the server, ignoring the
+// quotes and case differences servers introduce
+func MatchesETag(checksum, etag string) bool {
+	return strings.EqualFold(strings.Trim(etag, `"`), strings.Trim(checksum, `"`))
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/md5"
+	"testing"
+)
+
+func TestCompositeChecksum(t *testing.T) {
+	digest := func(s string) []byte {
+		sum := md5.Sum([]byte(s))
+		return sum[:]
+	}
+
+	tests := []struct {
+		name    string
+		add     func(c *CompositeChecksum)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "parts in order",
+			add: func(c *CompositeChecksum) {
+				c.AddPart(1, digest("part one"))
+				c.AddPart(2, digest("part two"))
+				c.AddPart(3, digest("part three"))
+			},
+			want: "9c46b2a5c836d5b6fff429d90cae24cf-3",
+		},
+		{
+			name: "parts out of order",
+			add: func(c *CompositeChecksum) {
+				c.AddPart(3, digest("part three"))
+				c.AddPart(1, digest("part one"))
+				c.AddPart(2, digest("part two"))
+			},
+			want: "9c46b2a5c836d5b6fff429d90cae24cf-3",
+		},
+		{
+			name: "retried part replaces its digest",
+			add: func(c *CompositeChecksum) {
+				c.AddPart(1, digest("part one"))
+				c.AddPart(2, digest("corrupt"))
+				c.AddPart(2, digest("part two"))
+				c.AddPart(3, digest("part three"))
+			},
+			want: "9c46b2a5c836d5b6fff429d90cae24cf-3",
+		},
+		{
+			name: "missing part",
+			add: func(c *CompositeChecksum) {
+				c.AddPart(1, digest("part one"))
+				c.AddPart(3, digest("part three"))
+			},
+			wantErr: true,
+		},
+		{name: "no parts", add: func(c *CompositeChecksum) {}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompositeChecksum()
+			tt.add(c)
+
+			got, err := c.Sum()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Sum = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sum: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sum = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPartMD5(t *testing.T) {
+	body := bytes.NewReader([]byte("part one"))
+	got, err := PartMD5(body)
+	if err != nil {
+		t.Fatalf("PartMD5: %v", err)
+	}
+	if want := md5.Sum([]byte("part one")); !bytes.Equal(got, want[:]) {
+		t.Errorf("PartMD5 = %x, want %x", got, want)
+	}
+	if body.Len() != len("part one") {
+		t.Errorf("body not rewound, %d bytes left", body.Len())
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	tests := []struct {
+		checksum, etag string
+		want           bool
+	}{
+		{"9c46b2a5c836d5b6fff429d90cae24cf-3", `"9c46b2a5c836d5b6fff429d90cae24cf-3"`, true},
+		{"9c46b2a5c836d5b6fff429d90cae24cf-3", `"9C46B2A5C836D5B6FFF429D90CAE24CF-3"`, true},
+		{"9c46b2a5c836d5b6fff429d90cae24cf-3", `"9c46b2a5c836d5b6fff429d90cae24cf-2"`, false},
+		{"9c46b2a5c836d5b6fff429d90cae24cf-3", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesETag(tt.checksum, tt.etag); got != tt.want {
+			t.Errorf("MatchesETag(%q, %q) = %v, want %v", tt.checksum, tt.etag, got, tt.want)
+		}
+	}
+}
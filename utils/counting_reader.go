@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ProgressFunc is called with the number of new bytes that flowed through a counting reader
+type ProgressFunc func(n int64)
+
+// CountingReader counts bytes as they are read and reports increments through a callback.
+// Only bytes past the highest offset reached are reported, so re-reading after a Seek
+// (e.g. an SDK computing a payload hash before sending) is not double counted.
+type CountingReader struct {
+	reader     io.Reader
+	onProgress ProgressFunc
+	pos        int64
+	high       int64
+}
+
+// NewCountingReader wraps reader, invoking onProgress as new bytes are read
+func NewCountingReader(reader io.Reader, onProgress ProgressFunc) *CountingReader {
+	return &CountingReader{
+		reader:     reader,
+		onProgress: onProgress,
+	}
+}
+
+// Read implements io.Reader
+func (r *CountingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.pos += int64(n)
+		if r.pos > r.high {
+			delta := r.pos - r.high
+			atomic.StoreInt64(&r.high, r.pos)
+			if r.onProgress != nil {
+				r.onProgress(delta)
+			}
+		}
+	}
+	return n, err
+}
+
+// Count returns the number of distinct bytes read so far
+func (r *CountingReader) Count() int64 {
+	return atomic.LoadInt64(&r.high)
+}
+
+// CountingReadSeeker is a CountingReader over a seekable source
+type CountingReadSeeker struct {
+	*CountingReader
+}
+
+// Seek implements io.Seeker
+func (r *CountingReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.reader.(io.Seeker)
+	if !ok {
+		return 0, errors.New("underlying reader is not seekable")
+	}
+
+	pos, err := seeker.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	r.pos = pos
+	return pos, nil
+}
+
+// WrapCountingReader wraps reader in a counting reader, preserving io.Seeker when the
+// source supports it so callers that need to rewind the body keep working
+func WrapCountingReader(reader io.Reader, onProgress ProgressFunc) io.Reader {
+	counting := NewCountingReader(reader, onProgress)
+	if _, ok := reader.(io.Seeker); ok {
+		return &CountingReadSeeker{CountingReader: counting}
+	}
+	return counting
+}
+
+// countingReadCloser pairs a counting reader with the closer of the wrapped source
+type countingReadCloser struct {
+	*CountingReader
+	closer io.Closer
+}
+
+// Close implements io.Closer
+func (r *countingReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// NewCountingReadCloser wraps a ReadCloser, invoking onProgress as new bytes are read
+func NewCountingReadCloser(reader io.ReadCloser, onProgress ProgressFunc) io.ReadCloser {
+	return &countingReadCloser{
+		CountingReader: NewCountingReader(reader, onProgress),
+		closer:         reader,
+	}
+}
@@ -75,15 +75,9 @@ func GenerateFilePath(filename string, prefix string) string {
 
 // GenerateChecksum generates checksum for file content
 func GenerateChecksum(file io.Reader, algorithm string) (string, error) {
-	var h hash.Hash
-
-	switch strings.ToLower(algorithm) {
-	case "md5":
-		h = md5.New()
-	case "sha256":
-		h = sha256.New()
-	default:
-		return "", fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	h, err := newHash(algorithm)
+	if err != nil {
+		return "", err
 	}
 
 	if _, err := io.Copy(h, file); err != nil {
@@ -93,6 +87,64 @@ func GenerateChecksum(file io.Reader, algorithm string) (string, error) {
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// ChecksumReader computes a digest of everything read through it
+type ChecksumReader struct {
+	reader    io.Reader
+	hash      hash.Hash
+	algorithm string
+}
+
+// NewChecksumReader wraps reader so that a checksum is computed as it is consumed
+func NewChecksumReader(reader io.Reader, algorithm string) (*ChecksumReader, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChecksumReader{
+		reader:    reader,
+		hash:      h,
+		algorithm: strings.ToLower(algorithm),
+	}, nil
+}
+
+// Read implements io.Reader
+func (r *ChecksumReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// Sum returns the hex-encoded checksum of the bytes read so far
+func (r *ChecksumReader) Sum() string {
+	return fmt.Sprintf("%x", r.hash.Sum(nil))
+}
+
+// Algorithm returns the checksum algorithm in use
+func (r *ChecksumReader) Algorithm() string {
+	return r.algorithm
+}
+
+// IsSupportedChecksumAlgorithm checks if the checksum algorithm is supported
+func IsSupportedChecksumAlgorithm(algorithm string) bool {
+	_, err := newHash(algorithm)
+	return err == nil
+}
+
+// newHash creates a hash for the given algorithm name
+func newHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		return md5.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+}
+
 // GetFileInfo extracts file information
 func GetFileInfo(file io.Reader, filename string) (*types.FileInfo, error) {
 	// Create a temporary buffer to calculate size and checksum
@@ -190,15 +242,15 @@ func GetFileExtension(filename string) string {
 // IsAllowedType checks if content type is in allowed list
 func isAllowedType(contentType string, allowedTypes []string) bool {
 	for _, allowedType := range allowedTypes {
-		if matchContentType(allowedType, contentType) {
+		if MatchContentType(allowedType, contentType) {
 			return true
 		}
 	}
 	return false
 }
 
-// matchContentType checks if content type matches pattern
-func matchContentType(pattern, contentType string) bool {
+// MatchContentType checks if content type matches pattern, which may be a wildcard such as "image/*"
+func MatchContentType(pattern, contentType string) bool {
 	// Exact match
 	if pattern == contentType {
 		return true
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs related tasks concurrently with a bound on parallelism. The first task to fail
+// cancels the context shared by the others, and Wait returns that first error.
+type Group struct {
+	cancel context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// NewGroup creates a group whose tasks receive a context derived from ctx. A limit of zero
+// or less allows unbounded parallelism.
+func NewGroup(ctx context.Context, limit int) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{cancel: cancel}
+	if limit > 0 {
+		g.slots = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go runs task in its own goroutine once a slot is free
+func (g *Group) Go(task func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if g.slots != nil {
+			g.slots <- struct{}{}
+			defer func() { <-g.slots }()
+		}
+
+		if err := task(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait blocks until every task has returned and reports the first error
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateCacheControl performs a minimal syntax check of a Cache-Control directive list:
+// comma-separated "token" or "token=value" entries without control characters
+func ValidateCacheControl(directive string) error {
+	if strings.TrimSpace(directive) == "" {
+		return fmt.Errorf("cache-control directive is empty")
+	}
+
+	for _, r := range directive {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("cache-control directive contains control characters")
+		}
+	}
+
+	for _, part := range strings.Split(directive, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(part), "=")
+		if !isHeaderToken(name) {
+			return fmt.Errorf("invalid cache-control directive %q", strings.TrimSpace(part))
+		}
+		if hasValue && value == "" {
+			return fmt.Errorf("cache-control directive %q has an empty value", name)
+		}
+	}
+
+	return nil
+}
+
+// isHeaderToken reports whether s is a non-empty RFC 7230 token
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateContentDisposition checks that a Content-Disposition value parses as "type; params"
+func ValidateContentDisposition(disposition string) error {
+	if _, _, err := mime.ParseMediaType(disposition); err != nil {
+		return fmt.Errorf("invalid content-disposition %q: %w", disposition, err)
+	}
+	return nil
+}
+
+// ContentDisposition builds a Content-Disposition header value per RFC 6266. Non-ASCII filenames
+// get an ASCII fallback in filename and the exact UTF-8 name in filename*.
+func ContentDisposition(dispositionType, filename string) string {
+	if dispositionType == "" {
+		dispositionType = "attachment"
+	}
+	if filename == "" {
+		return dispositionType
+	}
+
+	fallback := asciiFilename(filename)
+	value := fmt.Sprintf("%s; filename=%q", dispositionType, fallback)
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// asciiFilename replaces characters that cannot appear in a quoted ASCII filename
+func asciiFilename(filename string) string {
+	var b strings.Builder
+	for _, r := range filename {
+		switch {
+		case r >= utf8.RuneSelf, r < 0x20, r == 0x7f, r == '"', r == '\\':
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// encodeRFC5987 percent-encodes a UTF-8 string for use in an ext-value
+func encodeRFC5987(s string) string {
+	// PathEscape leaves a few characters RFC 5987 attr-char does not allow
+	return strings.NewReplacer("'", "%27", "(", "%28", ")", "%29", "*", "%2A", "=", "%3D", ",", "%2C", ";", "%3B", ":", "%3A", "@", "%40", "&", "%26", "+", "%2B", "$", "%24").
+		Replace(url.PathEscape(s))
+}
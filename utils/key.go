@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxKeyLength is the longest object key in bytes that S3-compatible servers accept
+const MaxKeyLength = 1024
+
+// ErrInvalidKey is wrapped by errors from the built-in key validators; custom validators
+// should wrap it too
+var ErrInvalidKey = errors.New("invalid object key")
+
+// KeyValidator decides whether an object key may be written
+type KeyValidator interface {
+	Validate(key string) error
+}
+
+// KeyValidatorFunc adapts a function to a KeyValidator
+type KeyValidatorFunc func(key string) error
+
+// Validate implements KeyValidator
+func (f KeyValidatorFunc) Validate(key string) error {
+	return f(key)
+}
+
+// DefaultKeyValidator enforces the limits every deployment needs: a non-empty UTF-8 key of at
+// most MaxLength bytes, without control characters or "." and ".." path segments
+type DefaultKeyValidator struct {
+	// MaxLength is the longest key in bytes; zero uses MaxKeyLength
+	MaxLength int
+}
+
+// Validate implements KeyValidator. The error names the rule violated.
+func (v DefaultKeyValidator) Validate(key string) error {
+	maxLength := v.MaxLength
+	if maxLength <= 0 {
+		maxLength = MaxKeyLength
+	}
+
+	if key == "" {
+		return invalidKey(key, "must not be empty")
+	}
+	if len(key) > maxLength {
+		return invalidKey(key, fmt.Sprintf("must be at most %d bytes long", maxLength))
+	}
+	if !utf8.ValidString(key) {
+		return invalidKey(key, "must be valid UTF-8")
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return invalidKey(key, "must not contain control characters")
+		}
+	}
+	for _, segment := range strings.Split(key, PathDelimiter) {
+		if segment == "." || segment == ".." {
+			return invalidKey(key, "must not contain . or .. path segments")
+		}
+	}
+	return nil
+}
+
+// RequireKeyPrefix returns a validator accepting only keys that start with prefix
+func RequireKeyPrefix(prefix string) KeyValidator {
+	return KeyValidatorFunc(func(key string) error {
+		if !strings.HasPrefix(key, prefix) {
+			return invalidKey(key, fmt.Sprintf("must start with %q", prefix))
+		}
+		return nil
+	})
+}
+
+// ChainKeyValidators returns a validator that accepts a key only when every validator does,
+// reporting the first rejection. Nil validators are skipped.
+func ChainKeyValidators(validators ...KeyValidator) KeyValidator {
+	return KeyValidatorFunc(func(key string) error {
+		for _, validator := range validators {
+			if validator == nil {
+				continue
+			}
+			if err := validator.Validate(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// invalidKey builds an ErrInvalidKey error for a broken rule; long keys are shortened
+func invalidKey(key, rule string) error {
+	if len(key) > 64 {
+		key = key[:64] + "..."
+	}
+	return fmt.Errorf("%w %q: %s", ErrInvalidKey, key, rule)
+}
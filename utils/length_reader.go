@@ -0,0 +1,32 @@
+package utils
+
+import "io"
+
+// LengthCheckingReadCloser fails with io.ErrUnexpectedEOF when its source ends before the
+// expected number of bytes, so a truncated stream is not mistaken for a complete one
+type LengthCheckingReadCloser struct {
+	io.ReadCloser
+	expected int64
+	read     int64
+}
+
+// NewLengthCheckingReadCloser wraps rc, expecting exactly expected bytes. A negative expected
+// length is unknown and disables the check.
+func NewLengthCheckingReadCloser(rc io.ReadCloser, expected int64) *LengthCheckingReadCloser {
+	return &LengthCheckingReadCloser{ReadCloser: rc, expected: expected}
+}
+
+// Read implements io.Reader
+func (r *LengthCheckingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err == io.EOF && r.expected >= 0 && r.read < r.expected {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// BytesRead returns the number of bytes read so far
+func (r *LengthCheckingReadCloser) BytesRead() int64 {
+	return r.read
+}
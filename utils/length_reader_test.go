@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLengthCheckingReadCloser(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int64
+		wantErr  error
+	}{
+		{"complete body", "0123456789", 10, nil},
+		{"truncated body", "01234", 10, io.ErrUnexpectedEOF},
+		{"empty body with expected bytes", "", 3, io.ErrUnexpectedEOF},
+		{"empty body", "", 0, nil},
+		{"unknown length", "01234", -1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLengthCheckingReadCloser(io.NopCloser(strings.NewReader(tt.body)), tt.expected)
+
+			data, err := io.ReadAll(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if string(data) != tt.body {
+				t.Errorf("data = %q, want %q", data, tt.body)
+			}
+			if r.BytesRead() != int64(len(tt.body)) {
+				t.Errorf("BytesRead = %d, want %d", r.BytesRead(), len(tt.body))
+			}
+		})
+	}
+}
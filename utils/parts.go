@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Multipart upload limits enforced by S3-compatible servers
+const (
+	// MaxUploadParts is the maximum number of parts in a single multipart upload
+	MaxUploadParts = 10000
+	// MinPartSize is the minimum size of every part except the last
+	MinPartSize = 5 * 1024 * 1024
+)
+
+// ErrTooManyParts is returned by a SequentialPartSource whose reader holds more than
+// MaxUploadParts parts
+var ErrTooManyParts = errors.New("upload exceeds the multipart part limit")
+
+// ErrSizeMismatch is returned by a SequentialPartSource whose reader does not hold exactly
+// its declared size
+var ErrSizeMismatch = errors.New("upload source does not match its declared size")
+
+// EstimateParts returns the number of chunkSize parts needed to upload fileSize bytes. It fails
+// when the upload would exceed MaxUploadParts or when a multi-part upload uses parts smaller
+// than MinPartSize; a file that fits in a single part is always feasible.
+func EstimateParts(fileSize, chunkSize int64) (int, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("chunk size must be positive")
+	}
+	if fileSize < 0 {
+		return 0, fmt.Errorf("file size cannot be negative")
+	}
+	if fileSize <= chunkSize {
+		return 1, nil
+	}
+
+	if chunkSize < MinPartSize {
+		return 0, fmt.Errorf("chunk size %d is below the minimum part size of %d bytes", chunkSize, MinPartSize)
+	}
+
+	parts := (fileSize + chunkSize - 1) / chunkSize
+	if parts > MaxUploadParts {
+		minChunk := (fileSize + MaxUploadParts - 1) / MaxUploadParts
+		return 0, fmt.Errorf("file of %d bytes needs %d parts of %d bytes, exceeding the limit of %d parts; use a chunk size of at least %d bytes",
+			fileSize, parts, chunkSize, MaxUploadParts, minChunk)
+	}
+
+	return int(parts), nil
+}
+
+// UploadPart is one part of a multipart upload
+type UploadPart struct {
+	// Number is the 1-based part number
+	Number int
+	// Offset is the byte offset of the part within the source
+	Offset int64
+	// Size is the number of bytes in the part
+	Size int64
+	// Body reads the part's bytes; it is seekable so a failed part can be retried
+	Body io.ReadSeeker
+}
+
+// PartSource yields the parts of an upload source in order. NextPart must be called
+// from a single goroutine, but the returned part bodies are independent and may be
+// uploaded concurrently. NextPart returns io.EOF once all parts have been produced.
+type PartSource interface {
+	NextPart() (*UploadPart, error)
+}
+
+// NewPartSource returns a PartSource splitting reader into chunkSize parts. When reader
+// implements io.ReaderAt and size is known, parts are read directly from their byte
+// ranges without buffering, so they can be read in parallel. Otherwise the source falls
+// back to buffering one chunk at a time from the sequential reader.
+func NewPartSource(reader io.Reader, size, chunkSize int64) (PartSource, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+
+	// Fail before any part is uploaded rather than on the part that crosses a server limit
+	if size > 0 {
+		if _, err := EstimateParts(size, chunkSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if readerAt, ok := reader.(io.ReaderAt); ok && size > 0 {
+		return NewReaderAtPartSource(readerAt, size, chunkSize), nil
+	}
+
+	return NewSequentialPartSource(reader, size, chunkSize), nil
+}
+
+// ReaderAtPartSource produces parts backed by section readers over an io.ReaderAt
+type ReaderAtPartSource struct {
+	reader    io.ReaderAt
+	size      int64
+	chunkSize int64
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewReaderAtPartSource creates a part source over the first size bytes of reader
+func NewReaderAtPartSource(reader io.ReaderAt, size, chunkSize int64) *ReaderAtPartSource {
+	return &ReaderAtPartSource{
+		reader:    reader,
+		size:      size,
+		chunkSize: chunkSize,
+	}
+}
+
+// NextPart implements PartSource
+func (s *ReaderAtPartSource) NextPart() (*UploadPart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset := int64(s.next) * s.chunkSize
+	if offset >= s.size {
+		return nil, io.EOF
+	}
+
+	partSize := s.chunkSize
+	if offset+partSize > s.size {
+		partSize = s.size - offset
+	}
+
+	s.next++
+	return &UploadPart{
+		Number: s.next,
+		Offset: offset,
+		Size:   partSize,
+		Body:   io.NewSectionReader(s.reader, offset, partSize),
+	}, nil
+}
+
+// PartCount returns the total number of parts the source will produce
+func (s *ReaderAtPartSource) PartCount() int {
+	return int((s.size + s.chunkSize - 1) / s.chunkSize)
+}
+
+// SequentialPartSource produces parts by buffering chunks from a plain io.Reader. A source of
+// unknown size fails with ErrTooManyParts once it would need more than MaxUploadParts parts;
+// one of declared size fails with ErrSizeMismatch as soon as the reader holds more or less.
+type SequentialPartSource struct {
+	reader    io.Reader
+	size      int64
+	chunkSize int64
+
+	mu     sync.Mutex
+	next   int
+	offset int64
+	done   bool
+}
+
+// NewSequentialPartSource creates a part source that buffers chunkSize bytes per part from
+// reader, which must hold exactly size bytes; a size of zero or less means unknown
+func NewSequentialPartSource(reader io.Reader, size, chunkSize int64) *SequentialPartSource {
+	return &SequentialPartSource{
+		reader:    reader,
+		size:      size,
+		chunkSize: chunkSize,
+	}
+}
+
+// NextPart implements PartSource
+func (s *SequentialPartSource) NextPart() (*UploadPart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done {
+		return nil, io.EOF
+	}
+
+	buf := make([]byte, s.chunkSize)
+	n, err := io.ReadFull(s.reader, buf)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		s.done = true
+		if s.size > 0 && s.offset+int64(n) != s.size {
+			return nil, fmt.Errorf("%w: read %d of %d bytes", ErrSizeMismatch, s.offset+int64(n), s.size)
+		}
+		if n == 0 {
+			return nil, io.EOF
+		}
+	default:
+		return nil, err
+	}
+
+	if s.size > 0 && s.offset+int64(n) > s.size {
+		s.done = true
+		return nil, fmt.Errorf("%w: source holds more than %d bytes", ErrSizeMismatch, s.size)
+	}
+	if s.next == MaxUploadParts {
+		s.done = true
+		return nil, fmt.Errorf("%w: source needs more than %d parts of %d bytes", ErrTooManyParts, MaxUploadParts, s.chunkSize)
+	}
+
+	part := &UploadPart{
+		Number: s.next + 1,
+		Offset: s.offset,
+		Size:   int64(n),
+		Body:   bytes.NewReader(buf[:n]),
+	}
+	s.next++
+	s.offset += int64(n)
+	return part, nil
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// onlyReader hides every method of the wrapped reader but Read
+type onlyReader struct{ io.Reader }
+
+func TestSequentialPartSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   int
+		size      int64
+		chunkSize int64
+		wantParts int
+		wantErr   error
+	}{
+		{"unknown size", 10, 0, 4, 3, nil},
+		{"declared size", 10, 10, 4, 3, nil},
+		{"declared size of whole chunks", 12, 12, 4, 3, nil},
+		{"source shorter than declared", 10, 11, 4, 2, ErrSizeMismatch},
+		{"source ends on a chunk before its declared size", 8, 11, 4, 2, ErrSizeMismatch},
+		{"source longer than declared", 10, 6, 4, 1, ErrSizeMismatch},
+		{"unknown size at the part limit", MaxUploadParts, 0, 1, MaxUploadParts, nil},
+		{"unknown size past the part limit", MaxUploadParts + 1, 0, 1, MaxUploadParts, ErrTooManyParts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewSequentialPartSource(onlyReader{strings.NewReader(strings.Repeat("x", tt.content))}, tt.size, tt.chunkSize)
+
+			parts := 0
+			var err error
+			for {
+				var part *UploadPart
+				part, err = source.NextPart()
+				if err != nil {
+					break
+				}
+				parts++
+				if part.Number != parts {
+					t.Fatalf("part %d is numbered %d", parts, part.Number)
+				}
+			}
+
+			if tt.wantErr == nil && err != io.EOF {
+				t.Fatalf("NextPart = %v, want io.EOF after every part", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NextPart = %v, want %v", err, tt.wantErr)
+			}
+			if parts != tt.wantParts {
+				t.Errorf("got %d parts, want %d", parts, tt.wantParts)
+			}
+			if _, err := source.NextPart(); err != io.EOF {
+				t.Errorf("NextPart after the end = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestEstimateParts(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileSize  int64
+		chunkSize int64
+		wantParts int
+		wantErr   bool
+	}{
+		{"empty file", 0, MinPartSize, 1, false},
+		{"single part below the minimum part size", 100, 1024, 1, false},
+		{"exactly one chunk", MinPartSize, MinPartSize, 1, false},
+		{"one byte over a chunk", MinPartSize + 1, MinPartSize, 2, false},
+		{"exactly the part limit", MaxUploadParts * MinPartSize, MinPartSize, MaxUploadParts, false},
+		{"one byte past the part limit", MaxUploadParts*MinPartSize + 1, MinPartSize, 0, true},
+		{"chunk below the minimum part size", 2 * 1024, 1024, 0, true},
+		{"zero chunk size", 100, 0, 0, true},
+		{"negative file size", -1, MinPartSize, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := EstimateParts(tt.fileSize, tt.chunkSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EstimateParts(%d, %d) error = %v, want error %v", tt.fileSize, tt.chunkSize, err, tt.wantErr)
+			}
+			if parts != tt.wantParts {
+				t.Errorf("EstimateParts(%d, %d) = %d, want %d", tt.fileSize, tt.chunkSize, parts, tt.wantParts)
+			}
+		})
+	}
+
+	t.Run("error names the smallest feasible chunk", func(t *testing.T) {
+		_, err := EstimateParts(MaxUploadParts*MinPartSize+1, MinPartSize)
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("at least %d bytes", MinPartSize+1)) {
+			t.Errorf("error = %v, want the minimum chunk size", err)
+		}
+	})
+
+	t.Run("part source fails before reading", func(t *testing.T) {
+		source := &onlyReader{strings.NewReader("content")}
+		if _, err := NewPartSource(source, MaxUploadParts*MinPartSize+1, MinPartSize); err == nil {
+			t.Error("NewPartSource accepted an upload past the part limit")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PathDelimiter is the separator used for hierarchical object keys
+const PathDelimiter = "/"
+
+// NormalizePath turns a user-supplied object path into a canonical key: leading and trailing
+// delimiters are removed, runs of delimiters collapse to one and "." segments are dropped, so
+// "/a//./b/" becomes "a/b". ".." segments are kept as is.
+func NormalizePath(path string) string {
+	segments := strings.Split(path, PathDelimiter)
+	kept := segments[:0]
+	for _, segment := range segments {
+		if segment != "" && segment != "." {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, PathDelimiter)
+}
+
+// NormalizeDirectoryPath normalizes path as a directory marker key, which keeps one trailing delimiter
+func NormalizeDirectoryPath(path string) string {
+	if normalized := NormalizePath(path); normalized != "" {
+		return normalized + PathDelimiter
+	}
+	return ""
+}
+
+// EscapePath percent-encodes each segment of an object key for use in a URL path, keeping the
+// delimiters, so "folder/my file#1.png" becomes "folder/my%20file%231.png". "+" is encoded too,
+// since some servers read it as a space.
+func EscapePath(path string) string {
+	segments := strings.Split(path, PathDelimiter)
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, PathDelimiter)
+}
+
+// MatchPrefix checks if an object key falls under prefix.
+//
+// In raw mode the prefix is a plain string prefix, so "img" matches both "img/a.png"
+// and "images/b.png". In directory mode the prefix is treated as a path segment
+// boundary: it only matches the key itself or keys where it is followed by the
+// delimiter, so "img" (or "img/") matches "img/a.png" but not "images/b.png".
+func MatchPrefix(path, prefix string, directoryMode bool) bool {
+	if prefix == "" {
+		return true
+	}
+
+	if !directoryMode {
+		return strings.HasPrefix(path, prefix)
+	}
+
+	dir := strings.TrimSuffix(prefix, PathDelimiter)
+	if dir == "" {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+PathDelimiter)
+}
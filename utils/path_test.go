@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"plain.txt", "plain.txt"},
+		{"folder/my file (1).png", "folder/my%20file%20%281%29.png"},
+		{"a/b+c#d.txt", "a/b%2Bc%23d.txt"},
+		{"query?x=1&y=2", "query%3Fx=1&y=2"},
+		{"percent/100%.txt", "percent/100%25.txt"},
+		{"unicode/résumé.pdf", "unicode/r%C3%A9sum%C3%A9.pdf"},
+		{"nested/dir/", "nested/dir/"},
+		{"//double", "//double"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := EscapePath(tt.path)
+			if got != tt.want {
+				t.Errorf("EscapePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+
+			// The escaped path must decode back to the key, with "+" not read as a space
+			decoded, err := url.PathUnescape(got)
+			if err != nil || decoded != tt.path {
+				t.Errorf("PathUnescape(%q) = %q, %v; want %q", got, decoded, err, tt.path)
+			}
+			parsed, err := url.Parse("http://host/bucket/" + got)
+			if err != nil {
+				t.Fatalf("parsing URL with %q: %v", got, err)
+			}
+			if parsed.Path != "/bucket/"+tt.path || parsed.RawQuery != "" || parsed.Fragment != "" {
+				t.Errorf("URL with %q parses to path %q, query %q, fragment %q", got, parsed.Path, parsed.RawQuery, parsed.Fragment)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path      string
+		want      string
+		directory string
+	}{
+		{"a/b.txt", "a/b.txt", "a/b.txt/"},
+		{"/a/b.txt", "a/b.txt", "a/b.txt/"},
+		{"///a/b.txt", "a/b.txt", "a/b.txt/"},
+		{"a//b///c.txt", "a/b/c.txt", "a/b/c.txt/"},
+		{"/a//b/", "a/b", "a/b/"},
+		{"./a/b.txt", "a/b.txt", "a/b.txt/"},
+		{"a/./b/./c.txt", "a/b/c.txt", "a/b/c.txt/"},
+		{"/./a//.//b/", "a/b", "a/b/"},
+		{"a/../b", "a/../b", "a/../b/"},
+		{".hidden/file.", ".hidden/file.", ".hidden/file./"},
+		{"/", "", ""},
+		{"./", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := NormalizePath(tt.path); got != tt.want {
+				t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			if got := NormalizeDirectoryPath(tt.path); got != tt.directory {
+				t.Errorf("NormalizeDirectoryPath(%q) = %q, want %q", tt.path, got, tt.directory)
+			}
+			// Normalizing is idempotent
+			if got := NormalizePath(NormalizePath(tt.path)); got != tt.want {
+				t.Errorf("NormalizePath twice on %q = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
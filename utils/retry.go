@@ -2,10 +2,16 @@ package utils
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
 	"strings"
 	"time"
 
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/garyjdn/go-rustfs/types"
 )
 
@@ -33,6 +39,17 @@ func RetryWithBackoff(fn RetryableFunc, config *types.RetryConfig) *RetryResult
 
 // RetryWithBackoffWithContext executes a function with exponential backoff retry and context
 func RetryWithBackoffWithContext(ctx context.Context, fn RetryableFuncWithContext, config *types.RetryConfig) *RetryResult {
+	return retryWithBackoff(ctx, fn, config, nil)
+}
+
+// RetryRetryableWithContext is RetryWithBackoffWithContext that gives up at once when an
+// error is not retryable according to IsRetryableError, such as a 400 or 404 response
+func RetryRetryableWithContext(ctx context.Context, fn RetryableFuncWithContext, config *types.RetryConfig) *RetryResult {
+	return retryWithBackoff(ctx, fn, config, IsRetryableError)
+}
+
+// retryWithBackoff runs fn until it succeeds or attempts run out. A nil retryable retries every error.
+func retryWithBackoff(ctx context.Context, fn RetryableFuncWithContext, config *types.RetryConfig, retryable func(error) bool) *RetryResult {
 	if config == nil {
 		config = &types.RetryConfig{
 			MaxAttempts: 3,
@@ -71,10 +88,33 @@ func RetryWithBackoffWithContext(ctx context.Context, fn RetryableFuncWithContex
 
 		lastError = err
 
+		if retryable != nil && !retryable(err) {
+			return &RetryResult{
+				Success:    false,
+				Attempts:   attempt + 1,
+				Duration:   time.Since(startTime),
+				LastError:  err,
+				TotalDelay: totalDelay,
+			}
+		}
+
 		// Don't wait on the last attempt
 		if attempt < config.MaxAttempts-1 {
-			// Calculate delay with exponential backoff
-			delay := calculateDelay(attempt, config.Delay, config.Backoff)
+			// Calculate delay with exponential backoff, deferring to the server's Retry-After if given
+			delay := calculateDelay(config.AttemptOffset+attempt, config.Delay, config.Backoff, config.MaxDelay)
+			if retryAfter, ok := RetryAfterFromError(err); ok {
+				capped, proceed := capRetryAfter(retryAfter, config.MaxRetryAfter, config.FailOnRetryAfterExceeded)
+				if !proceed {
+					return &RetryResult{
+						Success:    false,
+						Attempts:   attempt + 1,
+						Duration:   time.Since(startTime),
+						LastError:  err,
+						TotalDelay: totalDelay,
+					}
+				}
+				delay = capped
+			}
 			totalDelay += delay
 
 			// Wait for the delay or context cancellation
@@ -122,12 +162,24 @@ func RetryWithFixedDelayWithContext(ctx context.Context, fn RetryableFuncWithCon
 	return RetryWithBackoffWithContext(ctx, fn, config)
 }
 
-// IsRetryableError checks if an error should trigger a retry
+// IsRetryableError checks if an error should trigger a retry. Errors carrying an HTTP status
+// are retried only for 429 and 500, 502, 503 and 504; other statuses, such as 4xx client
+// errors, are never retried. Errors without a status are matched against common transient
+// network failures.
 func IsRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	if status, ok := HTTPStatusFromError(err); ok {
+		return IsRetryableStatus(status)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
 	// Common retryable error patterns
 	retryablePatterns := []string{
 		"connection refused",
@@ -163,23 +215,88 @@ func IsRetryableError(err error) bool {
 	}
 }
 
-// GetRetryDelay calculates delay for a specific attempt
+// IsRetryableStatus reports whether a request that failed with an HTTP status may succeed on retry
+func IsRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// StatusError is an error carrying the HTTP status of the response that caused it
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %v", e.StatusCode, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the response status
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// HTTPStatusFromError extracts the HTTP status behind err. The status of a server response,
+// such as an S3 error response, takes precedence over a status assigned while wrapping it.
+func HTTPStatusFromError(err error) (int, bool) {
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.HTTPStatusCode(), true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode, true
+	}
+
+	var withStatus interface{ HTTPStatus() int }
+	if errors.As(err, &withStatus) {
+		return withStatus.HTTPStatus(), true
+	}
+
+	return 0, false
+}
+
+// GetRetryDelay calculates delay for a specific attempt, without a cap
 func GetRetryDelay(attempt int, baseDelay time.Duration, backoff float64) time.Duration {
-	return calculateDelay(attempt, baseDelay, backoff)
+	return calculateDelay(attempt, baseDelay, backoff, 0)
 }
 
-// calculateDelay calculates delay using exponential backoff with jitter
-func calculateDelay(attempt int, baseDelay time.Duration, backoff float64) time.Duration {
+// calculateDelay calculates delay using exponential backoff with jitter, clamped to maxDelay
+// unless it is zero
+func calculateDelay(attempt int, baseDelay time.Duration, backoff float64, maxDelay time.Duration) time.Duration {
 	// Exponential backoff: delay = baseDelay * backoff^attempt
 	delay := float64(baseDelay) * math.Pow(backoff, float64(attempt))
 
-	// Add jitter to prevent thundering herd (±25%)
+	// Add jitter to prevent thundering herd, uniform in ±25%. It is not floored at baseDelay,
+	// which would pile half of the first attempts' delays onto the same value.
 	jitter := delay * 0.25 * (2*randomFloat64() - 1)
 	delay += jitter
 
-	// Ensure minimum delay
-	if delay < float64(baseDelay) {
-		delay = float64(baseDelay)
+	// Clamp before converting, so huge exponents can't overflow time.Duration. An infinite
+	// delay plus negative jitter is NaN, which compares false, so it is clamped explicitly.
+	if math.IsNaN(delay) {
+		delay = math.Inf(1)
+	}
+	if maxDelay > 0 && delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
 	}
 
 	return time.Duration(delay)
@@ -281,9 +398,10 @@ func containsIgnoreCase(s, substr string) bool {
 	return strings.Contains(sLower, substrLower)
 }
 
-// randomFloat64 generates a random float64 between 0 and 1
+// randomFloat64 generates a random float64 in [0, 1) from the randomly seeded, concurrency-safe
+// global source, so consecutive calls in a tight loop still differ
 func randomFloat64() float64 {
-	return float64(time.Now().UnixNano()%1000) / 1000.0
+	return rand.Float64()
 }
 
 // RetryConfigBuilder helps build retry configurations
@@ -320,6 +438,25 @@ func (b *RetryConfigBuilder) WithBackoff(backoff float64) *RetryConfigBuilder {
 	return b
 }
 
+// WithMaxRetryAfter sets the cap on server-requested Retry-After delays
+func (b *RetryConfigBuilder) WithMaxRetryAfter(maxDelay time.Duration, failFast bool) *RetryConfigBuilder {
+	b.config.MaxRetryAfter = maxDelay
+	b.config.FailOnRetryAfterExceeded = failFast
+	return b
+}
+
+// WithMaxDelay caps the delay between attempts; zero leaves it unbounded
+func (b *RetryConfigBuilder) WithMaxDelay(maxDelay time.Duration) *RetryConfigBuilder {
+	b.config.MaxDelay = maxDelay
+	return b
+}
+
+// WithAttemptOffset starts the backoff as if offset attempts had already failed
+func (b *RetryConfigBuilder) WithAttemptOffset(offset int) *RetryConfigBuilder {
+	b.config.AttemptOffset = offset
+	return b
+}
+
 // Build creates the retry configuration
 func (b *RetryConfigBuilder) Build() *types.RetryConfig {
 	return b.config
@@ -330,12 +467,26 @@ func DefaultRetryConfig() *types.RetryConfig {
 	return NewRetryConfigBuilder().Build()
 }
 
+// ResumeRetryConfig returns a copy of config, or of the default configuration when nil, whose
+// backoff continues from priorFailures attempts, for resuming an operation that was already failing
+func ResumeRetryConfig(config *types.RetryConfig, priorFailures int) *types.RetryConfig {
+	if config == nil {
+		config = DefaultRetryConfig()
+	}
+	resumed := *config
+	if priorFailures > 0 {
+		resumed.AttemptOffset = priorFailures
+	}
+	return &resumed
+}
+
 // FastRetryConfig returns a retry configuration for fast operations
 func FastRetryConfig() *types.RetryConfig {
 	return NewRetryConfigBuilder().
 		WithMaxAttempts(5).
 		WithDelay(100 * time.Millisecond).
 		WithBackoff(1.5).
+		WithMaxDelay(2 * time.Second).
 		Build()
 }
 
@@ -345,5 +496,6 @@ func SlowRetryConfig() *types.RetryConfig {
 		WithMaxAttempts(3).
 		WithDelay(5 * time.Second).
 		WithBackoff(3.0).
+		WithMaxDelay(time.Minute).
 		Build()
 }
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// DefaultMaxRetryAfter caps server-requested Retry-After delays when RetryConfig.MaxRetryAfter is zero
+const DefaultMaxRetryAfter = 60 * time.Second
+
+// RetryAfterError annotates an error with the delay a server asked the client to wait before retrying
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the server-requested delay
+func (e *RetryAfterError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// RetryAfterFromError extracts a server-requested retry delay from err, either from an error
+// implementing RetryAfter() or from the Retry-After header of an S3 HTTP error response
+func RetryAfterFromError(err error) (time.Duration, bool) {
+	var hinted interface{ RetryAfter() time.Duration }
+	if errors.As(err, &hinted) {
+		return hinted.RetryAfter(), true
+	}
+
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.Response != nil {
+		return ParseRetryAfter(responseErr.Response.Header.Get("Retry-After"), time.Now())
+	}
+
+	return 0, false
+}
+
+// ParseRetryAfter parses a Retry-After header value given either as delay seconds or an HTTP date
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// capRetryAfter limits a Retry-After delay to maxDelay. It reports false when the delay exceeds
+// the cap and failFast is set, meaning the caller should give up instead of retrying.
+func capRetryAfter(delay, maxDelay time.Duration, failFast bool) (time.Duration, bool) {
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryAfter
+	}
+	if delay <= maxDelay {
+		return delay, true
+	}
+
+	if failFast {
+		log.Printf("[RUSTFS] Retry-After of %s exceeds cap of %s, giving up", delay, maxDelay)
+		return 0, false
+	}
+
+	log.Printf("[RUSTFS] Retry-After of %s exceeds cap of %s, waiting %s instead", delay, maxDelay, maxDelay)
+	return maxDelay, true
+}
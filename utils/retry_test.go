@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/garyjdn/go-rustfs/types"
+)
+
+func TestRetryAttemptOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		want   time.Duration
+	}{
+		{"fresh operation starts at the base delay", 0, 2 * time.Millisecond},
+		{"resume after one failure", 1, 4 * time.Millisecond},
+		{"resume after three failures", 3, 16 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ResumeRetryConfig(&types.RetryConfig{MaxAttempts: 2, Delay: 2 * time.Millisecond, Backoff: 2}, tt.offset)
+
+			calls := 0
+			result := RetryWithBackoffWithContext(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls == 1 {
+					return errors.New("service unavailable")
+				}
+				return nil
+			}, config)
+
+			if !result.Success || result.Attempts != 2 {
+				t.Fatalf("result = %+v, want success after 2 attempts", result)
+			}
+			low, high := tt.want*3/4, tt.want*5/4
+			if result.TotalDelay < low || result.TotalDelay > high {
+				t.Errorf("first delay = %v, want within [%v, %v]", result.TotalDelay, low, high)
+			}
+		})
+	}
+}
+
+func TestResumeRetryConfig(t *testing.T) {
+	base := &types.RetryConfig{MaxAttempts: 4, Delay: time.Second, Backoff: 2}
+
+	tests := []struct {
+		name          string
+		config        *types.RetryConfig
+		priorFailures int
+		wantOffset    int
+		wantAttempts  int
+	}{
+		{"no prior failures", base, 0, 0, 4},
+		{"prior failures", base, 2, 2, 4},
+		{"negative count is ignored", base, -1, 0, 4},
+		{"nil config uses the default", nil, 1, 1, DefaultRetryConfig().MaxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResumeRetryConfig(tt.config, tt.priorFailures)
+			if got.AttemptOffset != tt.wantOffset || got.MaxAttempts != tt.wantAttempts {
+				t.Errorf("config = %+v, want offset %d and %d attempts", got, tt.wantOffset, tt.wantAttempts)
+			}
+			if got == tt.config {
+				t.Errorf("ResumeRetryConfig returned its argument instead of a copy")
+			}
+		})
+	}
+	if base.AttemptOffset != 0 {
+		t.Errorf("base config modified: offset %d", base.AttemptOffset)
+	}
+}
+
+func TestCalculateDelayJitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		base     time.Duration
+		backoff  float64
+		maxDelay time.Duration
+		center   time.Duration
+	}{
+		{"first attempt", 0, 100 * time.Millisecond, 2, 0, 100 * time.Millisecond},
+		{"later attempt", 3, 100 * time.Millisecond, 2, 0, 800 * time.Millisecond},
+		{"resumed attempt below the cap", 2, time.Second, 3, time.Minute, 9 * time.Second},
+	}
+
+	const samples = 2000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high := tt.center*3/4, tt.center*5/4
+			minDelay, maxDelay := high, low
+			distinct := make(map[time.Duration]bool)
+			var sum time.Duration
+
+			for i := 0; i < samples; i++ {
+				delay := calculateDelay(tt.attempt, tt.base, tt.backoff, tt.maxDelay)
+				if delay < low || delay > high {
+					t.Fatalf("delay %v outside ±25%% of %v", delay, tt.center)
+				}
+				if delay < minDelay {
+					minDelay = delay
+				}
+				if delay > maxDelay {
+					maxDelay = delay
+				}
+				distinct[delay] = true
+				sum += delay
+			}
+
+			// A uniform ±25% jitter covers nearly the whole window; the old clock-based source
+			// returned runs of identical values in a tight loop
+			if spread := maxDelay - minDelay; spread < tt.center*45/100 {
+				t.Errorf("spread = %v (%v..%v), want at least 45%% of %v", spread, minDelay, maxDelay, tt.center)
+			}
+			if len(distinct) < samples*9/10 {
+				t.Errorf("%d distinct delays in %d samples", len(distinct), samples)
+			}
+			if mean := sum / samples; mean < tt.center*95/100 || mean > tt.center*105/100 {
+				t.Errorf("mean delay = %v, want about %v", mean, tt.center)
+			}
+		})
+	}
+}
+
+func TestCalculateDelayClamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{"clamped to the cap", 10, 5 * time.Second, 5 * time.Second},
+		{"huge exponent doesn't overflow", 5000, time.Minute, time.Minute},
+		{"huge exponent without a cap", 5000, 0, time.Duration(math.MaxInt64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := calculateDelay(tt.attempt, time.Second, 2, tt.maxDelay); got != tt.want {
+					t.Fatalf("calculateDelay = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfterCap(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryAfter   time.Duration
+		maxDelay     time.Duration
+		failFast     bool
+		wantSuccess  bool
+		wantAttempts int
+		wantDelay    time.Duration
+	}{
+		{"delay under the cap is honoured", 20 * time.Millisecond, 50 * time.Millisecond, false, true, 2, 20 * time.Millisecond},
+		{"delay over the cap waits only the cap", 2 * time.Hour, 30 * time.Millisecond, false, true, 2, 30 * time.Millisecond},
+		{"delay over the cap fails fast", 2 * time.Hour, 30 * time.Millisecond, true, false, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &types.RetryConfig{MaxAttempts: 2, Delay: time.Millisecond, Backoff: 2}
+			config.MaxRetryAfter, config.FailOnRetryAfterExceeded = tt.maxDelay, tt.failFast
+			throttled := &RetryAfterError{Err: errors.New("slow down"), Delay: tt.retryAfter}
+
+			calls := 0
+			start := time.Now()
+			result := RetryWithBackoffWithContext(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls == 1 {
+					return throttled
+				}
+				return nil
+			}, config)
+			elapsed := time.Since(start)
+
+			if result.Success != tt.wantSuccess || result.Attempts != tt.wantAttempts {
+				t.Fatalf("result = %+v, want success %v after %d attempts", result, tt.wantSuccess, tt.wantAttempts)
+			}
+			if result.TotalDelay != tt.wantDelay {
+				t.Errorf("delay = %v, want %v", result.TotalDelay, tt.wantDelay)
+			}
+			if !tt.wantSuccess && !errors.Is(result.LastError, throttled) {
+				t.Errorf("last error = %v, want the server's error", result.LastError)
+			}
+			if elapsed > time.Second {
+				t.Errorf("retry took %v, want the Retry-After capped", elapsed)
+			}
+		})
+	}
+
+	t.Run("zero cap uses the default", func(t *testing.T) {
+		if got, ok := capRetryAfter(time.Hour, 0, false); !ok || got != DefaultMaxRetryAfter {
+			t.Errorf("capRetryAfter = %v, %v; want %v", got, ok, DefaultMaxRetryAfter)
+		}
+	})
+}
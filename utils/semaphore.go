@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// PrioritySemaphore is a counting semaphore whose waiters are admitted by priority.
+// When a slot frees up, the waiter with the highest priority acquires it; waiters
+// with equal priority are admitted in FIFO order.
+type PrioritySemaphore struct {
+	mu       sync.Mutex
+	capacity int
+	inUse    int
+	seq      uint64
+	waiters  waiterQueue
+}
+
+// NewPrioritySemaphore creates a new priority semaphore with the given number of slots
+func NewPrioritySemaphore(capacity int) *PrioritySemaphore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &PrioritySemaphore{
+		capacity: capacity,
+		waiters:  make(waiterQueue, 0),
+	}
+}
+
+// Acquire blocks until a slot is available or the context is cancelled
+func (s *PrioritySemaphore) Acquire(ctx context.Context, priority int) error {
+	s.mu.Lock()
+	if s.inUse < s.capacity && len(s.waiters) == 0 {
+		s.inUse++
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{
+		priority: priority,
+		seq:      s.seq,
+		ready:    make(chan struct{}),
+	}
+	s.seq++
+	heap.Push(&s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Slot was granted concurrently with cancellation, hand it back
+			s.releaseLocked()
+		default:
+			heap.Remove(&s.waiters, w.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// Release returns a slot to the semaphore, admitting the highest-priority waiter if any
+func (s *PrioritySemaphore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+// InUse returns the number of slots currently held
+func (s *PrioritySemaphore) InUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inUse
+}
+
+// Waiting returns the number of callers waiting for a slot
+func (s *PrioritySemaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+func (s *PrioritySemaphore) releaseLocked() {
+	if len(s.waiters) > 0 {
+		// Hand the slot directly to the next waiter without decrementing inUse
+		w := heap.Pop(&s.waiters).(*waiter)
+		close(w.ready)
+		return
+	}
+	if s.inUse > 0 {
+		s.inUse--
+	}
+}
+
+// waiter represents a caller blocked in Acquire
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+// waiterQueue is a max-heap of waiters ordered by priority, then arrival
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
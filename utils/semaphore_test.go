@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForWaiters polls until n callers are blocked in Acquire
+func waitForWaiters(t *testing.T, s *PrioritySemaphore, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.Waiting() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d waiters, want %d", s.Waiting(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPrioritySemaphoreOrder(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 0); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	waiters := []struct {
+		name     string
+		priority int
+	}{
+		{"low-1", 0},
+		{"low-2", 0},
+		{"high", 10},
+		{"medium", 5},
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i, w := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Acquire(ctx, w.priority); err != nil {
+				t.Errorf("Acquire(%s): %v", w.name, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, w.name)
+			mu.Unlock()
+			s.Release()
+		}()
+		// Queue the waiters one at a time so equal priorities have a known arrival order
+		waitForWaiters(t, s, i+1)
+	}
+
+	s.Release()
+	wg.Wait()
+
+	want := []string{"high", "medium", "low-1", "low-2"}
+	if !slices.Equal(order, want) {
+		t.Errorf("admitted %v, want %v", order, want)
+	}
+	if s.InUse() != 0 {
+		t.Errorf("%d slots still held", s.InUse())
+	}
+}
+
+func TestPrioritySemaphoreCancelledWaiter(t *testing.T) {
+	s := NewPrioritySemaphore(1)
+	if err := s.Acquire(context.Background(), 0); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- s.Acquire(ctx, 10) }()
+	waitForWaiters(t, s, 1)
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Acquire = %v, want context.Canceled", err)
+	}
+	if s.Waiting() != 0 {
+		t.Errorf("cancelled waiter still queued")
+	}
+
+	// The slot isn't handed to the cancelled waiter
+	s.Release()
+	if s.InUse() != 0 {
+		t.Errorf("%d slots held after release, want 0", s.InUse())
+	}
+}
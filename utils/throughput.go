@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultThroughputWindow is the interval over which peak throughput is measured
+const DefaultThroughputWindow = time.Second
+
+// ThroughputStats summarizes the transfers recorded by a ThroughputMeter
+type ThroughputStats struct {
+	TotalBytes int64
+	Duration   time.Duration
+	// AggregateMBps is TotalBytes over the wall-clock Duration, in MB/s
+	AggregateMBps float64
+	// PeakMBps is the highest throughput seen in any single window, in MB/s
+	PeakMBps float64
+}
+
+// ThroughputMeter measures the combined throughput of a set of concurrent transfers. Unlike
+// summing per-transfer throughputs, it divides the total bytes by the wall-clock time the set
+// took. It is safe for concurrent use.
+type ThroughputMeter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	start   time.Time
+	end     time.Time
+	total   int64
+	buckets map[int64]int64
+}
+
+// NewThroughputMeter creates a meter measuring peak throughput over windows of the given
+// length; zero or less uses DefaultThroughputWindow
+func NewThroughputMeter(window time.Duration) *ThroughputMeter {
+	if window <= 0 {
+		window = DefaultThroughputWindow
+	}
+	return &ThroughputMeter{
+		window:  window,
+		buckets: make(map[int64]int64),
+	}
+}
+
+// Start marks the beginning of the measured period. Add starts it implicitly if needed.
+func (m *ThroughputMeter) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.start.IsZero() {
+		m.start = time.Now()
+	}
+}
+
+// Add records n bytes transferred now
+func (m *ThroughputMeter) Add(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.start.IsZero() {
+		m.start = now
+	}
+	m.total += n
+	m.buckets[int64(now.Sub(m.start)/m.window)] += n
+}
+
+// AddBytes records n bytes, so the meter can be used as a client metrics recorder
+func (m *ThroughputMeter) AddBytes(operation string, n int64) {
+	m.Add(n)
+}
+
+// Stop marks the end of the measured period; until then Stats measures up to the current time
+func (m *ThroughputMeter) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.end.IsZero() {
+		m.end = time.Now()
+	}
+}
+
+// Stats returns the throughput measured so far
+func (m *ThroughputMeter) Stats() ThroughputStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := ThroughputStats{TotalBytes: m.total}
+	if m.start.IsZero() {
+		return stats
+	}
+
+	end := m.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	stats.Duration = end.Sub(m.start)
+	stats.AggregateMBps = mbps(m.total, stats.Duration)
+
+	// A period shorter than one window has its peak bounded by the aggregate
+	peakWindow := m.window
+	if stats.Duration < peakWindow {
+		peakWindow = stats.Duration
+	}
+	for _, n := range m.buckets {
+		if peak := mbps(n, peakWindow); peak > stats.PeakMBps {
+			stats.PeakMBps = peak
+		}
+	}
+
+	return stats
+}
+
+// mbps converts bytes over duration to MB/s
+func mbps(bytes int64, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(bytes) / duration.Seconds() / 1024 / 1024
+}
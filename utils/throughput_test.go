@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThroughputMeterAggregate(t *testing.T) {
+	const mb = 1024 * 1024
+
+	tests := []struct {
+		name      string
+		transfers int
+		bytesEach int64
+		// each transfer sends its bytes in chunks with a pause between them
+		chunks int
+		pause  time.Duration
+	}{
+		{"single transfer", 1, 4 * mb, 4, 5 * time.Millisecond},
+		{"concurrent transfers", 8, 2 * mb, 4, 5 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meter := NewThroughputMeter(time.Hour)
+			meter.Start()
+
+			durations := make([]time.Duration, tt.transfers)
+			var wg sync.WaitGroup
+			for i := 0; i < tt.transfers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					start := time.Now()
+					for c := 0; c < tt.chunks; c++ {
+						time.Sleep(tt.pause)
+						meter.Add(tt.bytesEach / int64(tt.chunks))
+					}
+					durations[i] = time.Since(start)
+				}(i)
+			}
+			wg.Wait()
+			meter.Stop()
+
+			stats := meter.Stats()
+			total := int64(tt.transfers) * tt.bytesEach
+			if stats.TotalBytes != total {
+				t.Fatalf("total = %d bytes, want %d", stats.TotalBytes, total)
+			}
+			if want := float64(total) / stats.Duration.Seconds() / mb; stats.AggregateMBps != want {
+				t.Errorf("aggregate = %.2f MB/s, want total over wall-clock time, %.2f MB/s", stats.AggregateMBps, want)
+			}
+
+			// Overlapping transfers share the wall clock, so summing their individual rates
+			// overstates what was achieved
+			var summed float64
+			for _, d := range durations {
+				summed += float64(tt.bytesEach) / d.Seconds() / mb
+			}
+			if tt.transfers > 1 && stats.AggregateMBps >= summed {
+				t.Errorf("aggregate %.2f MB/s not below the summed per-transfer %.2f MB/s", stats.AggregateMBps, summed)
+			}
+
+			// A period shorter than one window has its peak bounded by the aggregate
+			if stats.PeakMBps != stats.AggregateMBps {
+				t.Errorf("peak = %.2f MB/s, want the aggregate %.2f MB/s within one window", stats.PeakMBps, stats.AggregateMBps)
+			}
+		})
+	}
+}
+
+func TestThroughputMeterPeak(t *testing.T) {
+	const mb = 1024 * 1024
+	window := 10 * time.Millisecond
+	meter := NewThroughputMeter(window)
+
+	// One burst followed by an idle stretch several windows long
+	meter.Start()
+	meter.Add(mb)
+	time.Sleep(5 * window)
+	meter.Add(0)
+	meter.Stop()
+
+	stats := meter.Stats()
+	if want := float64(mb) / window.Seconds() / mb; stats.PeakMBps != want {
+		t.Errorf("peak = %.2f MB/s, want the burst over one window, %.2f MB/s", stats.PeakMBps, want)
+	}
+	if stats.AggregateMBps >= stats.PeakMBps {
+		t.Errorf("aggregate %.2f MB/s not below the peak %.2f MB/s", stats.AggregateMBps, stats.PeakMBps)
+	}
+}
+
+func TestThroughputMeterEmpty(t *testing.T) {
+	meter := NewThroughputMeter(0)
+	if stats := meter.Stats(); stats != (ThroughputStats{}) {
+		t.Errorf("stats before any transfer = %+v, want zero", stats)
+	}
+}